@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/hooks"
 	"github.com/spf13/cobra"
@@ -22,7 +23,7 @@ func runActivate(cmd *cobra.Command, args []string) {
 
 	worktreePath, err := os.Getwd()
 	if err != nil {
-		config.Die("Failed to get current directory: %v", err)
+		config.Fail(fmt.Errorf("failed to get current directory: %w", err))
 	}
 
 	repoInfo, _ := git.GetRepoInfo()
@@ -33,8 +34,10 @@ func runActivate(cmd *cobra.Command, args []string) {
 		fmt.Println("Create one of:")
 		fmt.Println("  .gwi/activate (in worktree or main repo)")
 		fmt.Printf("  %s/<org>/<repo>/activate\n", cfg.HookDir)
-		os.Exit(1)
+		config.Exit(1)
 	}
 
-	hooks.RunHook("activate", worktreePath, cfg, repoInfo)
+	if ran, err := runHook("activate", worktreePath, cfg, repoInfo); ran && err == nil {
+		deps.Record(worktreePath)
+	}
 }
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [issue-number]",
+	Short: "Preserve a worktree and remove it",
+	Long: `Save a worktree you aren't working on and remove it: the branch is stored as a git
+bundle and uncommitted changes (untracked files included) as a patch under archive_dir.
+The worktree and its local branch are then removed. Restore it with 'gwi unarchive'.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runArchive,
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive [issue-number]",
+	Short: "Restore an archived worktree",
+	Long: `Recreate an archived worktree with its branch and uncommitted changes. Without an
+issue number, choose from the archives of the current repository.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runUnarchive,
+}
+
+const (
+	archiveKeyPrefix = "archive/"
+	archiveBundle    = "branch.bundle"
+	archivePatch     = "uncommitted.patch"
+)
+
+// archivedWorktree records an archived worktree in the metadata store
+type archivedWorktree struct {
+	Branch   string    `json:"branch"`
+	Dir      string    `json:"dir"`    // Directory holding the bundle and patch
+	Head     string    `json:"head"`   // Commit the branch pointed to
+	Bundle   bool      `json:"bundle"` // False when the branch had no commits of its own
+	Patch    bool      `json:"patch"`  // True when there were uncommitted changes
+	Archived time.Time `json:"archived"`
+}
+
+func archiveKey(repoInfo *git.RepoInfo, branch string) string {
+	return fmt.Sprintf("%s%s/%s/%s", archiveKeyPrefix, repoInfo.Org, repoInfo.Repo, branch)
+}
+
+func runArchive(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	id, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	archiveWorktree(cfg, repoInfo, id, worktreePath)
+}
+
+// archiveWorktree saves a worktree's branch and changes to the archive
+// directory and removes the worktree
+func archiveWorktree(cfg *config.Config, repoInfo *git.RepoInfo, id issueid.ID, worktreePath string) {
+	branchName := filepath.Base(worktreePath)
+	guardMainWorktree(cfg, worktreePath, "archive")
+
+	pf := newPreflight("archive " + branchName)
+	pf.checkWorktreeRemoval(worktreePath)
+	deleteLocal := pf.checkBranchDeletion(branchName, worktreePath)
+	pf.report()
+
+	dir := filepath.Join(cfg.ArchiveDir, repoInfo.Org, repoInfo.Repo, branchName)
+	if _, err := os.Stat(dir); err == nil {
+		config.Fail(errs.Conflict("%s is already archived in %s", branchName, dir))
+	}
+	archive, err := writeArchive(cfg, worktreePath, branchName, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		config.Fail(fmt.Errorf("failed to archive %s: %w", branchName, err))
+	}
+	store.Update(func(s *store.Store) error {
+		return s.Set(archiveKey(repoInfo, branchName), archive)
+	})
+
+	if git.IsInsideWorktree(worktreePath) {
+		if mainPath, err := git.GetMainWorktreePath(); err == nil {
+			printCdTo(mainPath)
+		}
+	}
+
+	// Everything is saved, so uncommitted changes don't block the removal
+	if err := git.RemoveWorktree(worktreePath, true); err != nil {
+		config.Fail(fmt.Errorf("failed to remove worktree: %w", err))
+	}
+	git.PruneWorktrees()
+	if deleteLocal {
+		git.DeleteBranch(branchName)
+	}
+	index.Refresh(cfg, repoInfo)
+	deps.Forget(worktreePath)
+	if issueNumber, ok := id.Number(); ok {
+		forgetActiveIssue(repoInfo, issueNumber)
+	}
+
+	config.Success("Archived %s to %s", branchName, dir)
+}
+
+// writeArchive saves the branch and uncommitted changes of a worktree to dir
+func writeArchive(cfg *config.Config, worktreePath, branchName, dir string) (*archivedWorktree, error) {
+	head, err := git.GetHeadCommit(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	archive := &archivedWorktree{Branch: branchName, Dir: dir, Head: head, Archived: time.Now()}
+
+	// Only bundle the branch's own commits; without the remote main, bundle everything
+	exclude := git.RemoteRef(cfg.MainBranch)
+	count, err := git.CountCommits(worktreePath, exclude+".."+branchName)
+	if err != nil {
+		exclude, count = "", 1
+	}
+	if count > 0 {
+		if err := git.CreateBundle(worktreePath, filepath.Join(dir, archiveBundle), branchName, exclude); err != nil {
+			return nil, err
+		}
+		archive.Bundle = true
+	}
+
+	if git.HasUncommittedChanges(worktreePath) {
+		patch, err := git.DiffWorkingTree(worktreePath)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, archivePatch), patch, 0644); err != nil {
+			return nil, err
+		}
+		archive.Patch = true
+	}
+	return archive, nil
+}
+
+func runUnarchive(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	archive := selectArchive(repoInfo, args)
+	worktreePath := filepath.Join(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo), archive.Branch)
+	if _, err := os.Stat(worktreePath); err == nil {
+		config.Fail(errs.Conflict("%s already exists", worktreePath))
+	}
+	if git.BranchExists(archive.Branch) {
+		config.Fail(errs.Conflict("Branch %s already exists. Delete it first to restore the archived one.", archive.Branch))
+	}
+
+	if archive.Bundle {
+		if err := git.FetchBundle(filepath.Join(archive.Dir, archiveBundle), archive.Branch); err != nil {
+			config.Fail(err)
+		}
+		err = git.CreateWorktreeFromBranch(worktreePath, archive.Branch)
+	} else {
+		err = git.CreateWorktree(worktreePath, archive.Branch, archive.Head)
+	}
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to create worktree: %w", err))
+	}
+	index.Refresh(cfg, repoInfo)
+	if err := applyWorktreeGitConfig(cfg, repoInfo, worktreePath, false); err != nil {
+		config.Warn("%v", err)
+	}
+
+	if archive.Patch {
+		if err := git.ApplyPatch(worktreePath, filepath.Join(archive.Dir, archivePatch)); err != nil {
+			config.Warn("%v", err)
+			config.Warn("Keeping the archive; apply %s by hand", filepath.Join(archive.Dir, archivePatch))
+			printCdTo(worktreePath)
+			config.Exit(1)
+		}
+	}
+
+	store.Update(func(s *store.Store) error {
+		s.Delete(archiveKey(repoInfo, archive.Branch))
+		return nil
+	})
+	os.RemoveAll(archive.Dir)
+
+	config.Success("Restored %s", worktreePath)
+	printCdTo(worktreePath)
+}
+
+// selectArchive finds the archive for the issue in args, or lets the user
+// choose one of the repository's archives
+func selectArchive(repoInfo *git.RepoInfo, args []string) *archivedWorktree {
+	s, err := store.Load()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	var archives []archivedWorktree
+	for _, key := range s.Keys(archiveKey(repoInfo, "")) {
+		var archive archivedWorktree
+		if s.Get(key, &archive) {
+			archives = append(archives, archive)
+		}
+	}
+	if len(archives) == 0 {
+		config.Fail(errs.NotFound("No archived worktrees for %s/%s", repoInfo.Org, repoInfo.Repo))
+	}
+
+	if len(args) > 0 {
+		id := parseIssueID(args[0])
+		for i, archive := range archives {
+			if got, ok := issueid.FromName(archive.Branch); ok && got == id {
+				return &archives[i]
+			}
+		}
+		config.Fail(errs.NotFound("No archived worktree for issue %s", id))
+	}
+
+	var options []tui.Option
+	for _, archive := range archives {
+		var saved []string
+		if archive.Bundle {
+			saved = append(saved, "commits")
+		}
+		if archive.Patch {
+			saved = append(saved, "uncommitted changes")
+		}
+		options = append(options, tui.Option{
+			Label: archive.Branch,
+			Value: archive.Branch,
+			Hint:  strings.TrimSpace(config.FormatTime(archive.Archived) + " " + strings.Join(saved, ", ")),
+		})
+	}
+	selected, err := tui.Select(fmt.Sprintf("Restore archived worktree (%s/%s)", repoInfo.Org, repoInfo.Repo), options)
+	if err != nil {
+		config.Fail(errs.Aborted("No archive selected"))
+	}
+	for i, archive := range archives {
+		if archive.Branch == selected {
+			return &archives[i]
+		}
+	}
+	return nil
+}
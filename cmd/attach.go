@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <files...>",
+	Short: "Attach screenshots and other files to the PR",
+	Long: `Upload files, such as before/after screenshots of a UI change, and link them from
+the description of the issue's pull request, under an "Attachments" heading. Images are
+embedded, other files linked. Without a pull request, or with --issue, they are linked
+from the issue instead.
+
+GitHub has no API to upload comment attachments, so the files become assets of a
+prerelease tagged attach.release_tag (default gwi-attachments), created on first use.
+In private repositories they are only visible to people with access to the repository.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runAttach,
+}
+
+var (
+	attachTo    string
+	attachIssue bool
+)
+
+func init() {
+	attachCmd.Flags().StringVar(&attachTo, "to", "", "Issue to attach to, instead of the current worktree's")
+	attachCmd.Flags().BoolVar(&attachIssue, "issue", false, "Link the files from the issue instead of its PR")
+}
+
+// attachmentsHeading starts the section of a description gwi attach adds links to
+const attachmentsHeading = "### Attachments"
+
+// imageExtensions are the file types GitHub renders inline in Markdown
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg"}
+
+// unsafeAssetChars are the characters GitHub would replace in asset names
+var unsafeAssetChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func runAttach(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	for _, file := range args {
+		info, err := os.Stat(file)
+		if err != nil {
+			config.Fail(errs.NotFound("No such file: %s", file))
+		}
+		if !info.Mode().IsRegular() {
+			config.Fail(errs.New(errs.KindUsage, "Not a file: %s", file))
+		}
+	}
+	if err := github.CheckAuth(); err != nil {
+		config.Fail(err)
+	}
+
+	var issueArgs []string
+	if attachTo != "" {
+		issueArgs = []string{strings.TrimPrefix(attachTo, "#")}
+	}
+	branchName, _ := resolveIssueBranch(cfg, repoInfo, issueArgs)
+	issueNumber, ok := github.ParseIssueFromBranch(branchName)
+	if !ok {
+		config.Fail(errs.New(errs.KindUsage, "Can't tell the issue of branch %s", branchName))
+	}
+
+	prNumber := 0
+	if !attachIssue {
+		if n, err := findOpenPRForBranch(repoInfo, branchName); err == nil {
+			prNumber = n
+		} else {
+			config.Info("No pull request for %s, attaching to issue #%d", branchName, issueNumber)
+		}
+	}
+	if _, ok := azureBoards(cfg); ok && prNumber == 0 {
+		config.Fail(errs.New(errs.KindUsage, "Can't attach files to Azure Boards work items; open a pull request first"))
+	}
+
+	tag := cfg.Attach.ReleaseTag
+	if err := github.EnsureAttachmentRelease(tag); err != nil {
+		config.Fail(err)
+	}
+	stamp := strconv.FormatInt(time.Now().UnixNano(), 36)
+	var links []string
+	for _, file := range args {
+		base := filepath.Base(file)
+		name := fmt.Sprintf("%d-%s-%s", issueNumber, stamp, unsafeAssetChars.ReplaceAllString(base, "-"))
+		config.Info("Uploading %s...", base)
+		url, err := github.UploadReleaseAsset(tag, file, name)
+		if err != nil {
+			config.Fail(err)
+		}
+		links = append(links, attachmentLink(base, url))
+	}
+
+	target := fmt.Sprintf("issue #%d", issueNumber)
+	if prNumber != 0 {
+		target = fmt.Sprintf("PR #%d", prNumber)
+		body, err := github.GetPRBody(prNumber)
+		if err == nil {
+			err = github.EditPRBody(prNumber, appendAttachments(body, links))
+		}
+		if err != nil {
+			config.Fail(err)
+		}
+	} else {
+		issue, err := github.GetIssue(issueNumber)
+		if err == nil {
+			err = github.EditIssueBody(issueNumber, appendAttachments(issue.Body, links))
+		}
+		if err != nil {
+			config.Fail(err)
+		}
+	}
+
+	config.Success("Attached %d file(s) to %s", len(links), target)
+	for _, link := range links {
+		fmt.Println(link)
+	}
+}
+
+// attachmentLink returns the Markdown for an uploaded file: an image for
+// pictures, a link otherwise
+func attachmentLink(name, url string) string {
+	if slices.Contains(imageExtensions, strings.ToLower(filepath.Ext(name))) {
+		return fmt.Sprintf("![%s](%s)", name, url)
+	}
+	return fmt.Sprintf("[%s](%s)", name, url)
+}
+
+// appendAttachments adds links to the end of a description, under the
+// attachments heading unless it already ends with that section
+func appendAttachments(body string, links []string) string {
+	body = strings.TrimRight(body, "\n")
+	if !endsWithAttachments(body) {
+		if body != "" {
+			body += "\n\n"
+		}
+		body += attachmentsHeading
+	}
+	return body + "\n\n" + strings.Join(links, "\n\n")
+}
+
+// endsWithAttachments reports whether the last section of a description is
+// the one gwi attach adds
+func endsWithAttachments(body string) bool {
+	lines := strings.Split(body, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "#") {
+			return strings.TrimSpace(lines[i]) == attachmentsHeading
+		}
+	}
+	return false
+}
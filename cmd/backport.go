@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// backportResult is the outcome of backporting a merge to one branch
+type backportResult struct {
+	target    string
+	prURL     string
+	conflicts []string
+	err       error
+}
+
+// checkBackportTargets verifies the --backport branches exist on the remote
+// before anything is merged
+func checkBackportTargets(targets []string, mainBranch string) error {
+	for _, target := range targets {
+		if target == mainBranch {
+			return errs.New(errs.KindUsage, "Cannot backport to %s, the branch being merged into", target)
+		}
+		if git.RemoteBranchRef(target) == "" {
+			return errs.NotFound("Backport branch %s not found on %s. Fetch it first: git fetch %s", target, git.FetchRemote(), git.FetchRemote())
+		}
+	}
+	return nil
+}
+
+// backportMerge cherry-picks the commits a merge added to mainBranch (the
+// range before..after) onto each target branch and opens a PR for each
+func backportMerge(cfg *config.Config, issueNumber int, branchName, before, after string, targets []string) []backportResult {
+	mainWorktree, _ := git.GetMainWorktreePath()
+	shas, err := git.GetCommitSHAs(mainWorktree, before+".."+after)
+	if err != nil || len(shas) == 0 {
+		config.Warn("No merged commits found to backport")
+		return nil
+	}
+
+	title := branchName
+	if issue, err := getIssue(cfg, issueNumber); err == nil {
+		title = issue.Title
+	}
+
+	var results []backportResult
+	for _, target := range targets {
+		config.Info("Backporting %d commit(s) to %s...", len(shas), target)
+		result := backportTo(cfg, issueNumber, branchName, title, target, shas)
+		if result.err == nil && len(result.conflicts) == 0 {
+			config.Success("Backport PR for %s: %s", target, result.prURL)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// backportTo applies the commits to a new branch off target in a temporary
+// worktree, pushes it and opens the backport PR
+func backportTo(cfg *config.Config, issueNumber int, branchName, title, target string, shas []string) backportResult {
+	result := backportResult{target: target}
+	backportBranch := "backport/" + target + "/" + branchName
+
+	dir, err := os.MkdirTemp("", "gwi-backport-")
+	if err != nil {
+		result.err = err
+		return result
+	}
+	defer os.RemoveAll(dir)
+	if err := git.CreateWorktree(dir, backportBranch, git.RemoteBranchRef(target)); err != nil {
+		result.err = fmt.Errorf("failed to create worktree: %w", err)
+		return result
+	}
+	// The local branch is only needed to push
+	defer func() {
+		git.RemoveWorktree(dir, true)
+		git.DeleteBranch(backportBranch)
+	}()
+
+	if err := git.CherryPick(dir, shas...); err != nil {
+		result.conflicts = git.GetConflictedFiles(dir)
+		git.CherryPickAbort(dir)
+		if len(result.conflicts) == 0 {
+			result.err = err
+		}
+		return result
+	}
+
+	if err := git.Push(dir, backportBranch); err != nil {
+		result.err = fmt.Errorf("failed to push %s: %w", backportBranch, err)
+		return result
+	}
+	reference := fmt.Sprintf("#%d", issueNumber)
+	if _, ok := azureBoards(cfg); ok {
+		reference = fmt.Sprintf("AB#%d", issueNumber)
+	}
+	body := fmt.Sprintf("Backport of %s to `%s`.\n\nCherry-picked from %s.", reference, target, strings.Join(shortSHAs(shas), ", "))
+	prURL, err := github.CreatePR(dir, fmt.Sprintf("[%s] %s", target, title), body, backportBranch, target)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.prURL = prURL
+
+	var labels []string
+	for _, label := range cfg.Backport.Labels {
+		labels = append(labels, strings.ReplaceAll(label, "{branch}", target))
+	}
+	if len(labels) > 0 {
+		if err := github.AddPRLabels(prURL, labels); err != nil {
+			config.Warn("%v", err)
+		}
+	}
+	return result
+}
+
+// reportBackports lists the outcome per target branch and returns how many failed
+func reportBackports(results []backportResult) int {
+	if len(results) == 0 {
+		return 0
+	}
+	failed := 0
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Backports:")
+	for _, result := range results {
+		switch {
+		case len(result.conflicts) > 0:
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: conflicts in %s\n", config.Red(config.Glyph("failure")), result.target, strings.Join(result.conflicts, ", "))
+		case result.err != nil:
+			failed++
+			fmt.Fprintf(os.Stderr, "  %s %s: %v\n", config.Red(config.Glyph("failure")), result.target, result.err)
+		default:
+			fmt.Fprintf(os.Stderr, "  %s %s: %s\n", config.Green(config.Glyph("success")), result.target, result.prURL)
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintln(os.Stderr, "Backport the failed ones by hand: git cherry-pick -x <commits> on a branch off the target")
+	}
+	return failed
+}
+
+// shortSHAs abbreviates commit hashes for messages
+func shortSHAs(shas []string) []string {
+	short := make([]string, len(shas))
+	for i, sha := range shas {
+		short[i] = sha
+		if len(sha) > 12 {
+			short[i] = sha[:12]
+		}
+	}
+	return short
+}
@@ -0,0 +1,425 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	boardRepo    string
+	boardProject string
+	boardLimit   int
+	boardSelect  bool
+)
+
+var boardCmd = &cobra.Command{
+	Use:   "board",
+	Short: "Show the GitHub Project board",
+	Long: `Show the repository's GitHub Project board as columns in the terminal.
+
+Issues that have a local worktree are marked with a dot. Use --select to pick an issue
+from the board and cd into its worktree, creating the worktree if needed.`,
+	Args: cobra.NoArgs,
+	Run:  runBoard,
+}
+
+var boardMoveCmd = &cobra.Command{
+	Use:   "move <status> [issue-number...]",
+	Short: "Move issues to a status column",
+	Long: `Move one or more issues to a status column in every GitHub Project containing them.
+
+The status may be an option name from the project (e.g. "In Progress") or one of the
+configured shortcuts: todo, in-progress, in-review, done. All moves are validated against
+the project's options before anything is changed. Without issue numbers, the issue of the
+current worktree is used.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runBoardMove,
+}
+
+func init() {
+	boardCmd.PersistentFlags().StringVarP(&boardRepo, "repo", "R", "", "Repository as owner/name (default: current repository)")
+	boardCmd.Flags().StringVarP(&boardProject, "project", "p", "", "Project number or title (default: first project linked to the repository)")
+	boardCmd.Flags().IntVarP(&boardLimit, "limit", "n", 15, "Maximum issues shown per column")
+	boardCmd.Flags().BoolVarP(&boardSelect, "select", "s", false, "Select an issue to cd into (creating its worktree if needed)")
+	boardCmd.AddCommand(boardMoveCmd)
+}
+
+func runBoard(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+
+	owner, repo, err := resolveBoardRepo()
+	if err != nil {
+		config.Fail(err)
+	}
+	if err := github.CheckAuth(); err != nil {
+		config.Fail(err)
+	}
+
+	project, err := resolveBoardProject(cfg, owner, repo)
+	if err != nil {
+		config.Fail(err)
+	}
+
+	items, err := github.ListProjectIssues(project.ID, cfg.GitHub.StatusFieldName)
+	if err != nil {
+		config.Fail(err)
+	}
+
+	// Only show this repository's issues; projects can span repositories
+	repoName := owner + "/" + repo
+	var issues []github.BoardItem
+	for _, item := range items {
+		if strings.EqualFold(item.Repo, repoName) {
+			issues = append(issues, item)
+		}
+	}
+
+	repoInfo := &git.RepoInfo{Org: owner, Repo: repo}
+	existing := getExistingWorktreeIssues(cfg, repoInfo)
+
+	// stdout is captured by the shell integration when selecting, so render to stderr then
+	var out io.Writer = os.Stdout
+	if boardSelect {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "%s%s%s (%s)\n\n", config.Blue(""), project.Title, config.Blue(""), repoName)
+	renderBoard(out, boardColumns(cfg, issues), issues, existing)
+
+	if boardSelect {
+		selectFromBoard(cfg, repoInfo, issues, existing)
+	}
+}
+
+// boardColumns returns the configured status columns followed by any other statuses in use
+func boardColumns(cfg *config.Config, issues []github.BoardItem) []string {
+	columns := []string{cfg.GitHub.TodoValue, cfg.GitHub.InProgressValue, cfg.GitHub.InReviewValue, cfg.GitHub.DoneValue}
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		seen[strings.ToLower(column)] = true
+	}
+	for _, issue := range issues {
+		status := issue.Status
+		if status == "" {
+			status = "No Status"
+		}
+		if !seen[strings.ToLower(status)] {
+			seen[strings.ToLower(status)] = true
+			columns = append(columns, status)
+		}
+	}
+	return columns
+}
+
+// renderBoard prints the issues side by side in status columns
+func renderBoard(out io.Writer, columns []string, issues []github.BoardItem, existing map[int]bool) {
+	width := 120
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		width = cols
+	}
+	colWidth := (width - 3*(len(columns)-1)) / len(columns)
+	if colWidth < 16 {
+		colWidth = 16
+	}
+
+	cells := make([][]string, len(columns))
+	for i, column := range columns {
+		var inColumn []github.BoardItem
+		for _, issue := range issues {
+			status := issue.Status
+			if status == "" {
+				status = "No Status"
+			}
+			if strings.EqualFold(status, column) {
+				inColumn = append(inColumn, issue)
+			}
+		}
+
+		cells[i] = append(cells[i], config.Blue(padCell(fmt.Sprintf("%s (%d)", column, len(inColumn)), colWidth)))
+		cells[i] = append(cells[i], strings.Repeat(config.Glyph("hline"), colWidth))
+		for j, issue := range inColumn {
+			if boardLimit > 0 && j >= boardLimit {
+				cells[i] = append(cells[i], padCell(fmt.Sprintf("  %s %d more", config.Glyph("more"), len(inColumn)-j), colWidth))
+				break
+			}
+			marker := "  "
+			if existing[issue.Number] {
+				marker = config.Glyph("dot") + " "
+			}
+			cell := padCell(fmt.Sprintf("%s#%d %s", marker, issue.Number, issue.Title), colWidth)
+			if existing[issue.Number] {
+				cell = config.Green(cell)
+			}
+			cells[i] = append(cells[i], cell)
+		}
+	}
+
+	rows := 0
+	for _, column := range cells {
+		if len(column) > rows {
+			rows = len(column)
+		}
+	}
+	for row := 0; row < rows; row++ {
+		var line []string
+		for _, column := range cells {
+			if row < len(column) {
+				line = append(line, column[row])
+			} else {
+				line = append(line, strings.Repeat(" ", colWidth))
+			}
+		}
+		fmt.Fprintln(out, strings.TrimRight(strings.Join(line, " "+config.Glyph("vline")+" "), " "))
+	}
+}
+
+// padCell truncates or pads s to exactly width runes
+func padCell(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		more := []rune(config.Glyph("more"))
+		return string(runes[:max(width-len(more), 0)]) + string(more)
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// selectFromBoard lets the user pick a board issue and cds into (or creates) its worktree
+func selectFromBoard(cfg *config.Config, repoInfo *git.RepoInfo, issues []github.BoardItem, existing map[int]bool) {
+	var options []tui.Option
+	for _, issue := range issues {
+		if strings.EqualFold(issue.Status, cfg.GitHub.DoneValue) {
+			continue
+		}
+		hint := issue.Status
+		if existing[issue.Number] {
+			hint = "worktree"
+		}
+		options = append(options, tui.Option{
+			Label:      fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+			Value:      strconv.Itoa(issue.Number),
+			Hint:       hint,
+			InProgress: strings.EqualFold(issue.Status, cfg.GitHub.InProgressValue),
+		})
+	}
+
+	header := fmt.Sprintf("Select issue (%s/%s)", repoInfo.Org, repoInfo.Repo)
+	selected, err := tui.Select(header, options)
+	if err != nil {
+		return
+	}
+	issueNumber, _ := strconv.Atoi(selected)
+
+	if existing[issueNumber] {
+		base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+		printCdTo(git.FindWorktreeByIssue(base, issueNumber))
+		return
+	}
+
+	// Creating a worktree needs the local clone
+	current, err := git.GetRepoInfo()
+	if err != nil || current.Org != repoInfo.Org || current.Repo != repoInfo.Repo {
+		config.Fail(errs.New(errs.KindUsage, "Run gwi board inside %s/%s to create a worktree for #%d", repoInfo.Org, repoInfo.Repo, issueNumber))
+	}
+	createWorktree(cfg, repoInfo, issueNumber, false)
+}
+
+// resolveBoardProject finds the project selected by --project, or the first
+// linked (or github.project_owner) project matching github.projects
+func resolveBoardProject(cfg *config.Config, owner, repo string) (*github.Project, error) {
+	projects, err := github.ListRepoProjects(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GitHub.ProjectOwner != "" {
+		ownerProjects, err := github.ListOwnerProjects(cfg.GitHub.ProjectOwner)
+		if err != nil {
+			return nil, err
+		}
+		linked := make(map[string]bool)
+		for _, project := range projects {
+			linked[project.ID] = true
+		}
+		for _, project := range ownerProjects {
+			if !linked[project.ID] {
+				projects = append(projects, project)
+			}
+		}
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no GitHub Projects linked to %s/%s", owner, repo)
+	}
+	if boardProject == "" {
+		for i, project := range projects {
+			if github.ProjectSelected(cfg.GitHub.Projects, project.Title, project.Number) {
+				return &projects[i], nil
+			}
+		}
+		return nil, fmt.Errorf("none of the projects in github.projects is linked to %s/%s", owner, repo)
+	}
+
+	for i, project := range projects {
+		if strconv.Itoa(project.Number) == boardProject || strings.EqualFold(project.Title, boardProject) {
+			return &projects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("project %q not found for %s/%s", boardProject, owner, repo)
+}
+
+// boardMove is a validated status change for a single project item
+type boardMove struct {
+	issue    int
+	item     github.ProjectItem
+	fieldID  string
+	optionID string
+}
+
+func runBoardMove(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	status := resolveStatusName(args[0], cfg)
+
+	issueNumbers, err := parseIssueArgs(args[1:])
+	if err != nil {
+		config.Fail(err)
+	}
+	if len(issueNumbers) == 0 {
+		num, ok := detectCurrentIssue(cfg)
+		if !ok {
+			config.Fail(errs.New(errs.KindUsage, "No issue numbers given and not inside an issue worktree"))
+		}
+		issueNumbers = []int{num}
+	}
+
+	owner, repo, err := resolveBoardRepo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	if err := github.CheckAuth(); err != nil {
+		config.Fail(err)
+	}
+	if cfg.GitHub.CheckScopes {
+		if err := github.CheckProjectScopes(cfg); err != nil {
+			config.Fail(err)
+		}
+	}
+
+	// Resolve and validate every move before changing anything
+	var moves []boardMove
+	var problems []string
+	for _, num := range issueNumbers {
+		// Issues are only added to projects once every move validated
+		items, err := github.FindProjectItems(cfg, owner, repo, num)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if len(items) == 0 {
+			problems = append(problems, fmt.Sprintf("issue #%d is not in any selected GitHub Project", num))
+			continue
+		}
+
+		for _, item := range items {
+			field, err := github.GetProjectField(item.ProjectID, cfg.GitHub.StatusFieldName)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("issue #%d (%s): %v", num, item.ProjectTitle, err))
+				continue
+			}
+			optionID, err := github.GetFieldOptionID(field, status)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("issue #%d (%s): '%s' is not a valid status. Options: %s",
+					num, item.ProjectTitle, status, strings.Join(field.OptionNames(), ", ")))
+				continue
+			}
+			moves = append(moves, boardMove{issue: num, item: item, fieldID: field.ID, optionID: optionID})
+		}
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			config.Error("%s", problem)
+		}
+		config.Fail(errs.New(errs.KindUsage, "Nothing moved"))
+	}
+
+	failed := 0
+	for _, move := range moves {
+		err := github.AddProjectItem(cfg, owner, repo, move.issue, &move.item)
+		if err == nil {
+			err = github.UpdateProjectItemStatus(move.item, move.fieldID, move.optionID, cfg)
+		}
+		if err != nil {
+			config.Error("Issue #%d (%s): %v", move.issue, move.item.ProjectTitle, err)
+			failed++
+			continue
+		}
+		config.Success("Moved #%d to '%s' in %s", move.issue, status, move.item.ProjectTitle)
+	}
+
+	if failed > 0 {
+		config.Fail(errs.New(errs.KindGeneral, "%d of %d update(s) failed", failed, len(moves)))
+	}
+}
+
+// resolveStatusName maps status shortcuts to the configured column names
+func resolveStatusName(name string, cfg *config.Config) string {
+	switch strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(name)) {
+	case "todo":
+		return cfg.GitHub.TodoValue
+	case "inprogress":
+		return cfg.GitHub.InProgressValue
+	case "inreview", "review":
+		return cfg.GitHub.InReviewValue
+	case "done":
+		return cfg.GitHub.DoneValue
+	}
+	return name
+}
+
+// parseIssueArgs parses issue numbers given as "42" or "#42"
+func parseIssueArgs(args []string) ([]int, error) {
+	var numbers []int
+	for _, arg := range args {
+		num, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid issue number: %s", arg)
+		}
+		numbers = append(numbers, num)
+	}
+	return numbers, nil
+}
+
+// detectCurrentIssue returns the issue number of the worktree containing the current directory
+func detectCurrentIssue(cfg *config.Config) (int, bool) {
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return 0, false
+	}
+	return git.DetectIssueNumber(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo))
+}
+
+// resolveBoardRepo returns the repository from --repo or the current directory
+func resolveBoardRepo() (string, string, error) {
+	if boardRepo == "" {
+		owner, repo, err := github.CurrentRepo()
+		if err != nil {
+			return "", "", fmt.Errorf("not in a GitHub repository. Use --repo owner/name")
+		}
+		return owner, repo, nil
+	}
+	parts := strings.Split(boardRepo, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid --repo %q, expected owner/name", boardRepo)
+	}
+	return parts[0], parts[1], nil
+}
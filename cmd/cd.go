@@ -2,21 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
 	"github.com/enterprisemodules/gwi/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var cdCmd = &cobra.Command{
-	Use:   "cd [number|pattern]",
+	Use:   "cd [number|key|pr/number|pattern]",
 	Short: "Navigate to worktree",
-	Long:  `Navigate to a worktree by issue number or pattern. If no argument is provided, opens an interactive selector.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Navigate to a worktree by issue number, project key (e.g. JIRA-123) or pattern. If no argument is provided, opens an interactive selector.
+
+A pull request is given as pr/128 or its URL: the worktree of the PR's head
+branch is used, and created when needed. On GitHub issues and PRs share their
+numbers, so '#128' also finds the PR when there is no worktree for issue #128.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Public cd just tells user to use shell integration
 		fmt.Println("Use 'gwi cd' with shell integration. Add to your shell config:")
@@ -28,47 +38,75 @@ var internalCdCmd = &cobra.Command{
 	Use:    "_cd [pattern]",
 	Hidden: true,
 	Args:   cobra.MaximumNArgs(1),
-	Run:    runInternalCd,
+	RunE:   runInternalCd,
 }
 
-func runInternalCd(cmd *cobra.Command, args []string) {
+func runInternalCd(cmd *cobra.Command, args []string) error {
+	// Fast path: resolve from the cached index without loading config or running git
+	if len(args) == 1 {
+		if repo := cachedRepo(); repo != nil {
+			if path, ok := repo.Resolve(args[0]); ok {
+				printCdPath(path)
+				return nil
+			}
+		}
+	}
+
 	cfg := config.Load()
+	useRepoRemotes(cfg)
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		return err
 	}
+	index.Refresh(cfg, repoInfo)
 
 	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
 
 	// No pattern - show interactive selector
 	if len(args) == 0 {
-		issueNumber, err := selectWorktree(repoInfo, cfg)
+		id, err := selectWorktree(repoInfo, cfg)
 		if err != nil {
-			config.Die("No worktree selected")
+			return errs.Aborted("No worktree selected")
 		}
-		worktreePath := git.FindWorktreeByIssue(base, issueNumber)
+		worktreePath := git.FindWorktreeByID(base, id)
 		if worktreePath == "" {
-			config.Die("No worktree found for issue #%d", issueNumber)
+			return errs.NotFound("No worktree found for issue %s", id)
 		}
-		fmt.Println(worktreePath)
-		return
+		printCdPath(worktreePath)
+		return nil
 	}
 
 	pattern := args[0]
 
-	// Exact match by issue number
-	if num, err := strconv.Atoi(pattern); err == nil {
-		worktreePath := git.FindWorktreeByIssue(base, num)
+	if prNumber, ok := parsePRRef(pattern); ok {
+		worktreePath, err := prWorktree(cfg, repoInfo, prNumber)
+		if err != nil {
+			return err
+		}
+		printCdPath(worktreePath)
+		return nil
+	}
+
+	// Exact match by issue number or project key
+	if id, ok := issueid.Parse(pattern); ok {
+		worktreePath := git.FindWorktreeByID(base, id)
 		if worktreePath != "" {
-			fmt.Println(worktreePath)
-			return
+			printCdPath(worktreePath)
+			return nil
+		}
+		// #128 without an issue worktree may be a PR
+		if n, isNumber := id.Number(); isNumber && strings.HasPrefix(pattern, "#") {
+			if worktreePath, err := prWorktree(cfg, repoInfo, n); err == nil {
+				printCdPath(worktreePath)
+				return nil
+			}
 		}
 	}
 
 	// Fuzzy match
 	worktrees, err := git.ListWorktrees(base)
 	if err != nil {
-		config.Die("Failed to list worktrees: %v", err)
+		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
 	var matches []string
@@ -81,68 +119,188 @@ func runInternalCd(cmd *cobra.Command, args []string) {
 
 	switch len(matches) {
 	case 0:
-		config.Die("No worktree found matching: %s", pattern)
+		return errs.NotFound("No worktree found matching: %s", pattern)
 	case 1:
-		fmt.Println(matches[0])
+		printCdPath(matches[0])
 	default:
 		// Multiple matches - use selector
+		hints := worktreeHints(repoInfo, matches)
 		var options []tui.Option
 		for _, match := range matches {
 			name := filepath.Base(match)
 			options = append(options, tui.Option{
 				Label: name,
 				Value: match,
+				Hint:  hints[match],
 			})
 		}
 
 		header := fmt.Sprintf("Multiple matches (%s/%s)", repoInfo.Org, repoInfo.Repo)
 		selected, err := tui.Select(header, options)
 		if err != nil {
-			config.Die("No selection made")
+			return errs.Aborted("No selection made")
+		}
+		printCdPath(selected)
+	}
+	return nil
+}
+
+// parsePRRef parses a pull request given as pr/128 or by its URL
+func parsePRRef(arg string) (int, bool) {
+	if number, ok := strings.CutPrefix(strings.ToLower(arg), "pr/"); ok {
+		n, err := strconv.Atoi(number)
+		return n, err == nil && n > 0
+	}
+	if m := prURLRegexp.FindStringSubmatch(arg); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n, true
+	}
+	return 0, false
+}
+
+// prURLRegexp matches a pull request URL, e.g. https://github.com/org/repo/pull/128/files
+var prURLRegexp = regexp.MustCompile(`^https?://[^/]+/[^/]+/[^/]+/pull/(\d+)(?:[/?#].*)?$`)
+
+// prWorktree returns the worktree of a PR's head branch, creating it when the
+// branch is named after an issue and has no worktree yet
+func prWorktree(cfg *config.Config, repoInfo *git.RepoInfo, prNumber int) (string, error) {
+	pr, err := github.GetPRStatus(prNumber)
+	if err != nil {
+		return "", errs.NotFound("PR #%d not found", prNumber)
+	}
+	if pr.State != "OPEN" {
+		config.Warn("PR #%d is %s", prNumber, strings.ToLower(pr.State))
+	}
+
+	worktrees, _ := git.ListAllWorktrees()
+	for _, wt := range worktrees {
+		if wt.Branch == pr.HeadRefName {
+			return wt.Path, nil
+		}
+	}
+
+	id, ok := issueid.FromName(pr.HeadRefName)
+	issueNumber, isNumber := id.Number()
+	if !ok || !isNumber {
+		return "", errs.NotFound("PR #%d's branch %s isn't named after an issue, so it has no gwi worktree. Check it out with: gh pr checkout %d", prNumber, pr.HeadRefName, prNumber)
+	}
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	if worktreePath := git.FindWorktreeByID(base, id); worktreePath != "" {
+		return worktreePath, nil
+	}
+
+	// A fork's branch, or one deleted after merging, isn't on the remote;
+	// without it the worktree would start as a new branch off main
+	if !git.BranchExists(pr.HeadRefName) {
+		if !pr.IsCrossRepository && git.RemoteBranchRef(pr.HeadRefName) == "" {
+			if err := git.Fetch(); err != nil {
+				config.Warn("Failed to fetch: %v", err)
+			}
+		}
+		if pr.IsCrossRepository || git.RemoteBranchRef(pr.HeadRefName) == "" {
+			config.Info("Fetching the head of PR #%d, as %s isn't on %s...", prNumber, pr.HeadRefName, git.FetchRemote())
+			if err := git.FetchPullHead(prNumber, pr.HeadRefName); err != nil {
+				return "", errs.NotFound("PR #%d's branch %s isn't on %s, and its head couldn't be fetched: %v", prNumber, pr.HeadRefName, git.FetchRemote(), err)
+			}
+			if pr.IsCrossRepository {
+				config.Warn("PR #%d comes from a fork: commits pushed from this worktree go to %s, not to the fork", prNumber, git.PushRemote())
+			}
+		}
+	}
+
+	config.Info("Creating a worktree for PR #%d (%s)...", prNumber, pr.HeadRefName)
+	// The worktree step checks out the existing branch of that name
+	createBranch = pr.HeadRefName
+	return createWorktree(cfg, repoInfo, issueNumber, true), nil
+}
+
+// resolveIssueWorktree determines the target issue from args, the current directory
+// or an interactive selection, and returns it with its worktree path
+func resolveIssueWorktree(cfg *config.Config, repoInfo *git.RepoInfo, args []string) (issueid.ID, string) {
+	var id issueid.ID
+	var err error
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+
+	if len(args) > 0 {
+		id = parseIssueID(args[0])
+	} else if detected, ok := git.DetectIssueID(base); ok {
+		id = detected
+	} else {
+		id, err = selectWorktree(repoInfo, cfg)
+		if err != nil {
+			config.Fail(errs.Aborted("No worktree selected"))
 		}
-		fmt.Println(selected)
 	}
+
+	worktreePath := git.FindWorktreeByID(base, id)
+	if worktreePath == "" {
+		config.Fail(errs.NotFound("No worktree found for issue %s", id))
+	}
+	return id, worktreePath
+}
+
+// parseIssueID parses an issue number or project key given on the command line
+func parseIssueID(arg string) issueid.ID {
+	id, ok := issueid.Parse(arg)
+	if !ok {
+		config.Fail(errs.New(errs.KindUsage, "Invalid issue number or key: %s", arg))
+	}
+	return id
+}
+
+// githubIssueNumber returns the GitHub issue number of id. Commands that talk
+// to GitHub can't work with worktrees named after another tracker's keys.
+func githubIssueNumber(id issueid.ID, action string) int {
+	n, ok := id.Number()
+	if !ok {
+		config.Fail(errs.New(errs.KindUsage, "Can't %s for %s: it isn't a GitHub issue number", action, id))
+	}
+	return n
 }
 
-func selectWorktree(repoInfo *git.RepoInfo, cfg *config.Config) (int, error) {
+// cachedRepo returns the index entry for the repository containing the current directory
+func cachedRepo() *index.Repo {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	return index.Lookup(cwd)
+}
+
+func selectWorktree(repoInfo *git.RepoInfo, cfg *config.Config) (issueid.ID, error) {
 	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
 	worktrees, err := git.ListWorktrees(base)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
 	if len(worktrees) == 0 {
-		return 0, fmt.Errorf("no worktrees found for %s/%s", repoInfo.Org, repoInfo.Repo)
+		return "", fmt.Errorf("no worktrees found for %s/%s", repoInfo.Org, repoInfo.Repo)
 	}
 
+	hints := worktreeHints(repoInfo, worktrees)
 	var options []tui.Option
 	for _, wt := range worktrees {
 		name := filepath.Base(wt)
-		// Extract issue number from name
-		var issueNum string
-		for i, c := range name {
-			if c == '-' {
-				issueNum = name[:i]
-				break
-			}
-		}
-		if issueNum != "" {
+		// Extract the issue number or project key from the name
+		if id, ok := issueid.FromName(name); ok {
 			options = append(options, tui.Option{
 				Label: name,
-				Value: issueNum,
+				Value: string(id),
+				Hint:  hints[wt],
 			})
 		}
 	}
 
 	if len(options) == 0 {
-		return 0, fmt.Errorf("no valid worktrees found")
+		return "", fmt.Errorf("no valid worktrees found")
 	}
 
 	header := fmt.Sprintf("Select worktree (%s/%s)", repoInfo.Org, repoInfo.Repo)
 	selected, err := tui.Select(header, options)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
-	return strconv.Atoi(selected)
+	return issueid.ID(selected), nil
 }
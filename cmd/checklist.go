@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+)
+
+// checklistItem matches a Markdown task list item: "- [ ] text" or "- [x] text"
+var checklistItem = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+)$`)
+
+// uncheckedTasks returns the text of the task list items in an issue body
+// that aren't checked, skipping fenced code blocks
+func uncheckedTasks(body string) []string {
+	var tasks []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := checklistItem.FindStringSubmatch(strings.TrimRight(line, "\r")); match != nil && match[1] == " " {
+			tasks = append(tasks, strings.TrimSpace(match[2]))
+		}
+	}
+	return tasks
+}
+
+// checkIssueChecklist warns before merging when the issue still has unchecked
+// tasks, since merging closes it. Interactively, it offers to merge anyway or
+// to ask on the issue whether the tasks are deferred and stop; with --yes or
+// without a terminal it only warns.
+func checkIssueChecklist(cfg *config.Config, issueNumber int) error {
+	issue, err := getIssue(cfg, issueNumber)
+	if err != nil {
+		config.Warn("Could not fetch issue #%d to check its task list: %v", issueNumber, err)
+		return nil
+	}
+	tasks := uncheckedTasks(issue.Body)
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	config.Warn("Issue #%d has %d unchecked task(s), merging will close it:", issueNumber, len(tasks))
+	for _, task := range tasks {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Yellow("[ ]"), task)
+	}
+	if mergeYes || config.Headless() {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "  [m] Merge anyway\n  [a] Ask on the issue whether they're deferred, and stop\n  [q] Quit\n")
+	switch strings.ToLower(promptLine("Choice [m/a/q]: ")) {
+	case "m", "merge":
+		return nil
+	case "a", "ask":
+		lines := []string{fmt.Sprintf("This issue is ready to merge, but %d task(s) are still unchecked:", len(tasks)), ""}
+		for _, task := range tasks {
+			lines = append(lines, "- [ ] "+task)
+		}
+		lines = append(lines, "", "Are they deferred to a follow-up, or still to be done here? Check them off or answer here, then merge again.")
+		if err := commentOnIssue(cfg, issueNumber, strings.Join(lines, "\n")); err != nil {
+			return fmt.Errorf("failed to comment on #%d: %w", issueNumber, err)
+		}
+		config.Info("Asked on #%d whether the unchecked tasks are deferred", issueNumber)
+		return errs.Aborted("Merge stopped until the unchecked tasks are answered")
+	default:
+		return errs.Aborted("Aborted")
+	}
+}
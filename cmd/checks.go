@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var checksCmd = &cobra.Command{
+	Use:   "checks",
+	Short: "Work with the CI checks of an issue's PR",
+}
+
+var checksRerunSelect bool
+
+var checksRerunCmd = &cobra.Command{
+	Use:   "rerun [issue-number]",
+	Short: "Re-run failed checks of the PR",
+	Long: `Re-run the failed GitHub Actions jobs for the head commit of the issue's pull
+request. With --select, pick a single failed check to re-run instead of all of them.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runChecksRerun,
+}
+
+func init() {
+	checksRerunCmd.Flags().BoolVarP(&checksRerunSelect, "select", "s", false, "Choose which failed check to re-run")
+	checksCmd.AddCommand(checksRerunCmd)
+}
+
+func runChecksRerun(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	if err := github.CheckAuth(); err != nil {
+		config.Fail(err)
+	}
+
+	branchName, _ := resolveIssueBranch(cfg, repoInfo, args)
+	prNumber, err := findOpenPRForBranch(repoInfo, branchName)
+	if err != nil {
+		config.Fail(errs.NotFound("No pull request found for %s", branchName))
+	}
+	sha, err := github.GetPRHeadSHA(prNumber)
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to get the head commit of PR #%d: %w", prNumber, err))
+	}
+
+	runs, err := github.ListWorkflowRuns(sha)
+	if err != nil {
+		config.Fail(err)
+	}
+	var failedRuns []github.WorkflowRun
+	for _, run := range runs {
+		if run.Failed() {
+			failedRuns = append(failedRuns, run)
+		}
+	}
+	if len(failedRuns) == 0 {
+		config.Info("No failed workflow runs for PR #%d (%s)", prNumber, sha[:min(7, len(sha))])
+		return
+	}
+
+	if checksRerunSelect {
+		rerunSelectedJob(prNumber, failedRuns)
+		return
+	}
+
+	failed := 0
+	for _, run := range failedRuns {
+		if err := github.RerunFailedJobs(run.ID); err != nil {
+			config.Error("Failed to re-run %s: %v", run.Name, err)
+			failed++
+			continue
+		}
+		config.Success("Re-running failed jobs of %s", run.Name)
+	}
+	if failed > 0 {
+		config.Exit(1)
+	}
+}
+
+// rerunSelectedJob lets the user pick one of the failed jobs and re-runs it
+func rerunSelectedJob(prNumber int, runs []github.WorkflowRun) {
+	var options []tui.Option
+	for _, run := range runs {
+		jobs, err := github.ListWorkflowJobs(run.ID)
+		if err != nil {
+			config.Warn("Failed to list jobs of %s: %v", run.Name, err)
+			continue
+		}
+		for _, job := range jobs {
+			if !job.Failed() {
+				continue
+			}
+			options = append(options, tui.Option{
+				Label: fmt.Sprintf("%s / %s", run.Name, job.Name),
+				Value: strconv.FormatInt(job.ID, 10),
+				Hint:  job.Conclusion,
+			})
+		}
+	}
+	if len(options) == 0 {
+		config.Info("No failed checks for PR #%d", prNumber)
+		return
+	}
+
+	selected, err := tui.Select(fmt.Sprintf("Re-run a failed check (PR #%d)", prNumber), options)
+	if err != nil {
+		config.Fail(errs.Aborted("No check selected"))
+	}
+	jobID, _ := strconv.ParseInt(selected, 10, 64)
+	if err := github.RerunJob(jobID); err != nil {
+		config.Fail(fmt.Errorf("failed to re-run check: %w", err))
+	}
+	for _, option := range options {
+		if option.Value == selected {
+			config.Success("Re-running %s", option.Label)
+		}
+	}
+}
+
+const (
+	// checksPollInterval is how often --wait-checks polls the check rollup
+	checksPollInterval = 15 * time.Second
+	// checksStartGrace is how long --wait-checks waits for CI to report any
+	// check before concluding the PR has none
+	checksStartGrace = 2 * time.Minute
+)
+
+// waitForChecks polls the checks of a PR until they have all finished,
+// printing each check's transitions. It fails when a check failed or when
+// checks are still pending after timeout.
+func waitForChecks(cfg *config.Config, prNumber int, timeout time.Duration) error {
+	config.Info("Waiting for the checks of PR #%d (timeout %s)...", prNumber, timeout)
+	start := time.Now()
+	states := make(map[string]string)
+	configWatcher := config.NewWatcher(cfg)
+	defer configWatcher.Close()
+
+	for {
+		// Reloading applies display settings to the transitions printed
+		configWatcher.Check()
+
+		pr, err := github.GetPRStatus(prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get the checks of PR #%d: %w", prNumber, err)
+		}
+
+		var failing, pending []string
+		for _, check := range pr.StatusCheckRollup {
+			state := "passed"
+			switch {
+			case check.Pending():
+				state = "pending"
+				pending = append(pending, check.Label())
+			case !check.Passed():
+				state = "failed"
+				failing = append(failing, check.Label())
+			}
+			if states[check.Label()] != state {
+				states[check.Label()] = state
+				printCheckTransition(check.Label(), state)
+			}
+		}
+
+		switch {
+		case len(pr.StatusCheckRollup) == 0 && time.Since(start) >= checksStartGrace:
+			config.Info("No checks reported for PR #%d", prNumber)
+			return nil
+		case len(pr.StatusCheckRollup) > 0 && len(pending) == 0:
+			if len(failing) > 0 {
+				return errs.NotReady("checks_failing", "PR #%d has failing checks: %s", prNumber, strings.Join(failing, ", "))
+			}
+			config.Success("All %d check(s) of PR #%d passed", len(pr.StatusCheckRollup), prNumber)
+			return nil
+		case time.Since(start) >= timeout:
+			return errs.NotReady("checks_pending", "PR #%d still has pending checks after %s: %s", prNumber, timeout, strings.Join(pending, ", "))
+		}
+
+		time.Sleep(checksPollInterval)
+		github.Refresh()
+	}
+}
+
+// printCheckTransition prints a check's new state as a feed line on stderr
+func printCheckTransition(label, state string) {
+	glyph := config.Yellow(config.Glyph("running"))
+	switch state {
+	case "passed":
+		glyph = config.Green(config.Glyph("success"))
+	case "failed":
+		glyph = config.Red(config.Glyph("failure"))
+	}
+	fmt.Fprintf(os.Stderr, "%s %s %s %s\n", config.Blue(time.Now().Format("15:04")), glyph, label, state)
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var cherryCmd = &cobra.Command{
+	Use:   "cherry [sha] --to <issue-number>",
+	Short: "Port a commit to another issue's worktree",
+	Long: `Cherry-pick a commit (default: HEAD of the current worktree) into the worktree of
+another issue, creating that worktree if needed. On conflicts, gwi waits while you
+resolve them and then completes the cherry-pick.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runCherry,
+}
+
+var cherryTo int
+
+func init() {
+	cherryCmd.Flags().IntVar(&cherryTo, "to", 0, "Issue number of the target worktree")
+	cherryCmd.MarkFlagRequired("to")
+}
+
+func runCherry(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	ref := "HEAD"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	cwd, _ := os.Getwd()
+	sha, err := git.ResolveCommit(cwd, ref)
+	if err != nil {
+		config.Fail(err)
+	}
+	message, _ := git.GetCommitMessage(cwd, sha)
+	subject, _, _ := strings.Cut(message, "\n")
+
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	target := git.FindWorktreeByIssue(base, cherryTo)
+	if target == "" {
+		config.Info("No worktree for issue #%d yet, creating it...", cherryTo)
+		target = createWorktree(cfg, repoInfo, cherryTo, true)
+	}
+	branchName := filepath.Base(target)
+
+	if git.IsInsideWorktree(target) {
+		config.Fail(errs.New(errs.KindUsage, "Already in %s. Run gwi cherry from the worktree the commit belongs to.", branchName))
+	}
+	if git.HasUncommittedChanges(target) {
+		config.Fail(errs.Conflict("Worktree %s has uncommitted changes. Commit them or run: gwi snapshot %d", branchName, cherryTo))
+	}
+
+	config.Info("Cherry-picking %s %s into %s...", sha[:7], subject, branchName)
+	if err := git.CherryPick(target, sha); err != nil {
+		conflicts := git.GetConflictedFiles(target)
+		if len(conflicts) == 0 {
+			git.CherryPickAbort(target)
+			config.Fail(err)
+		}
+		resolveCherryConflicts(target, conflicts)
+	}
+
+	config.Success("Cherry-picked %s into %s", sha[:7], branchName)
+	config.Info("Switch to it with: gwi cd %d", cherryTo)
+}
+
+// resolveCherryConflicts waits for the user to resolve conflicts in the
+// target worktree, then completes the cherry-pick (or aborts it on request)
+func resolveCherryConflicts(target string, conflicts []string) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		config.Warn("Conflicts in %s:", target)
+		for _, file := range conflicts {
+			fmt.Fprintf(os.Stderr, "  %s\n", config.Red(file))
+		}
+		fmt.Fprint(os.Stderr, "Resolve them, then press Enter to continue (or type 'abort'): ")
+		input, err := reader.ReadString('\n')
+		if err != nil || strings.TrimSpace(strings.ToLower(input)) == "abort" {
+			git.CherryPickAbort(target)
+			config.Fail(errs.Conflict("Cherry-pick aborted"))
+		}
+
+		var remaining []string
+		for _, file := range conflicts {
+			if git.HasConflictMarkers(target, file) {
+				remaining = append(remaining, file)
+			}
+		}
+		if len(remaining) > 0 {
+			conflicts = remaining
+			continue
+		}
+
+		if err := git.CherryPickContinue(target); err != nil {
+			config.Warn("%v", err)
+			conflicts = git.GetConflictedFiles(target)
+			if len(conflicts) == 0 {
+				git.CherryPickAbort(target)
+				config.Fail(errs.Conflict("Cherry-pick aborted"))
+			}
+			continue
+		}
+		return
+	}
+}
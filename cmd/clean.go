@@ -4,24 +4,45 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/enterprisemodules/gwi/internal/config"
 	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
 	"github.com/spf13/cobra"
 )
 
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
 	Short: "Remove orphaned worktrees and branches",
-	Long:  `Prune worktrees that no longer exist and remove branches whose remotes have been deleted.`,
-	Run:   runClean,
+	Long: `Prune worktrees that no longer exist and remove branches whose remotes have been deleted.
+
+With --policy, remove worktrees matching the retention policy in config.yaml
+instead: clean.merged_pr (PR merged), clean.max_age (no activity for that long)
+and clean.max_count (least recently active beyond that number). Worktrees with
+uncommitted changes are always kept, as are unpushed commits unless their PR was
+merged. Use --dry-run to see what would be removed and why.`,
+	Args: cobra.NoArgs,
+	Run:  runClean,
+}
+
+var (
+	cleanPolicy bool
+	cleanDryRun bool
+	cleanYes    bool
+)
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanPolicy, "policy", false, "Remove worktrees matching the retention policy (clean.* in config)")
+	cleanCmd.Flags().BoolVarP(&cleanDryRun, "dry-run", "n", false, "Only show what would be removed and why")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Skip confirmation prompt")
 }
 
 func runClean(cmd *cobra.Command, args []string) {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
 	config.Info("Checking for orphaned worktrees...")
@@ -31,6 +52,12 @@ func runClean(cmd *cobra.Command, args []string) {
 	if err == nil && output != "" && output != "nothing to prune\n" {
 		fmt.Print(output)
 	}
+	index.Refresh(cfg, repoInfo)
+
+	if cleanPolicy {
+		runCleanPolicy(cfg, repoInfo)
+		return
+	}
 
 	// Find merged branches that can be cleaned up
 	config.Info("Checking for merged branches...")
@@ -44,11 +71,11 @@ func runClean(cmd *cobra.Command, args []string) {
 	// Find local branches that track deleted remotes
 	branches, err := git.GetLocalBranches()
 	if err != nil {
-		config.Die("Failed to list branches: %v", err)
+		config.Fail(fmt.Errorf("failed to list branches: %w", err))
 	}
 
 	for _, branch := range branches {
-		if branch == "main" || branch == "master" {
+		if isProtectedBranch(cfg, branch) {
 			continue
 		}
 
@@ -65,6 +92,17 @@ func runClean(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Branches checked out somewhere (e.g. the main worktree) can't be deleted
+	pf := newPreflight("clean up branches")
+	var deletable []string
+	for _, branchInfo := range branchesToDelete {
+		if pf.checkBranchDeletion(strings.Fields(branchInfo)[0], "") {
+			deletable = append(deletable, branchInfo)
+		}
+	}
+	pf.report()
+	branchesToDelete = deletable
+
 	if len(branchesToDelete) == 0 {
 		config.Success("No orphaned branches found.")
 		return
@@ -77,7 +115,7 @@ func runClean(cmd *cobra.Command, args []string) {
 	}
 	fmt.Println()
 
-	if !confirmPrompt("Delete these branches?") {
+	if cleanDryRun || !cleanYes && !confirmPrompt("Delete these branches?") {
 		return
 	}
 
@@ -90,6 +128,7 @@ func runClean(cmd *cobra.Command, args []string) {
 				break
 			}
 		}
+		journalRemoval(repoInfo, undoEntry{Op: "clean", Branch: branch})
 		if err := git.DeleteBranch(branch); err == nil {
 			config.Success("Deleted branch: %s", branch)
 		}
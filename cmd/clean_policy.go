@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
+)
+
+// policyWorktree is a worktree matched by the retention policy
+type policyWorktree struct {
+	path     string
+	activity time.Time
+	reason   string // Why the policy removes it
+	kept     string // Why it is kept anyway, if it is
+	merged   bool
+}
+
+// runCleanPolicy applies the clean retention policy to the current
+// repository's worktrees
+func runCleanPolicy(cfg *config.Config, repoInfo *git.RepoInfo) {
+	policy := cfg.Clean
+	if policy.MaxAge == 0 && policy.MaxCount == 0 && !policy.MergedPR {
+		config.Info("No retention policy configured (clean.max_age, clean.max_count, clean.merged_pr)")
+		return
+	}
+
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		config.Fail(errs.NotFound("Could not find main repository"))
+	}
+	worktrees, err := git.ListWorktrees(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo))
+	if err != nil || len(worktrees) == 0 {
+		config.Success("No worktrees found.")
+		return
+	}
+
+	config.Info("Evaluating retention policy for %d worktree(s)...", len(worktrees))
+	matched := evaluateCleanPolicy(cfg, repoInfo, worktrees)
+	if len(matched) == 0 {
+		config.Success("No worktrees match the retention policy.")
+		return
+	}
+
+	var removable []policyWorktree
+	fmt.Println()
+	fmt.Println("Worktrees matching the retention policy:")
+	for _, wt := range matched {
+		line := fmt.Sprintf("  - %s %s", filepath.Base(wt.path), config.Yellow("("+wt.reason+")"))
+		if wt.kept != "" {
+			line += " kept: " + wt.kept
+		} else {
+			removable = append(removable, wt)
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+
+	if cleanDryRun || len(removable) == 0 {
+		return
+	}
+	if !cleanYes && !confirmPrompt(fmt.Sprintf("Remove %d worktree(s)?", len(removable))) {
+		return
+	}
+
+	repo := index.Repo{Org: repoInfo.Org, Repo: repoInfo.Repo, MainPath: mainPath}
+	for _, wt := range removable {
+		removeStaleWorktree(staleWorktree{repo: repo, path: wt.path, reason: wt.reason})
+		if wt.merged {
+			unlinkPR(repoInfo, filepath.Base(wt.path))
+		}
+	}
+}
+
+// evaluateCleanPolicy returns the worktrees the policy removes, with the
+// reason, and why some of them must be kept anyway
+func evaluateCleanPolicy(cfg *config.Config, repoInfo *git.RepoInfo, worktrees []string) []policyWorktree {
+	policy := cfg.Clean
+	var matched, remaining []policyWorktree
+	for _, path := range worktrees {
+		wt := policyWorktree{path: path, activity: git.LastActivity(path)}
+		name := filepath.Base(path)
+		idle := time.Since(wt.activity)
+
+		if policy.MergedPR {
+			if prNumber, ok := mergedPRForBranch(repoInfo, name); ok {
+				wt.reason, wt.merged = fmt.Sprintf("PR #%d merged", prNumber), true
+			}
+		}
+		if wt.reason == "" && policy.MaxAge > 0 && idle > policy.MaxAge {
+			wt.reason = "inactive for " + formatIdle(idle)
+		}
+
+		if wt.reason == "" {
+			remaining = append(remaining, wt)
+		} else {
+			matched = append(matched, wt)
+		}
+	}
+
+	// Over max_count, the least recently active worktrees go first
+	if policy.MaxCount > 0 && len(remaining) > policy.MaxCount {
+		sort.Slice(remaining, func(i, j int) bool {
+			return remaining[i].activity.After(remaining[j].activity)
+		})
+		for _, wt := range remaining[policy.MaxCount:] {
+			wt.reason = fmt.Sprintf("over max_count of %d, inactive for %s", policy.MaxCount, formatIdle(time.Since(wt.activity)))
+			matched = append(matched, wt)
+		}
+	}
+
+	for i := range matched {
+		matched[i].kept = cleanPolicyKeepReason(cfg, matched[i])
+	}
+	return matched
+}
+
+// cleanPolicyKeepReason explains why a matched worktree can't be removed.
+// Commits of a merged PR are on main; otherwise unpushed commits would be lost.
+func cleanPolicyKeepReason(cfg *config.Config, wt policyWorktree) string {
+	switch {
+	case isProtectedBranch(cfg, filepath.Base(wt.path)):
+		return "protected branch"
+	case git.IsInsideWorktree(wt.path):
+		return "you are inside it"
+	case git.HasUncommittedChanges(wt.path):
+		return "uncommitted changes"
+	}
+	if !wt.merged {
+		if unpushed, err := git.CountUnpushed(wt.path); err == nil && unpushed > 0 {
+			return fmt.Sprintf("%d unpushed commit(s)", unpushed)
+		}
+	}
+	return ""
+}
+
+// formatIdle rounds a duration to days, hours or minutes
+func formatIdle(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
@@ -1,8 +1,16 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
 	"github.com/spf13/cobra"
 )
 
@@ -58,3 +66,105 @@ PowerShell:
 		}
 	},
 }
+
+func init() {
+	for _, cmd := range []*cobra.Command{cdCmd, rmCmd, prCmd, mergeCmd, diffCmd, snapshotCmd, parkCmd, archiveCmd, checksRerunCmd} {
+		cmd.ValidArgsFunction = completeWorktrees
+	}
+	for _, cmd := range []*cobra.Command{createCmd, watchCmd, takeoverCmd} {
+		cmd.ValidArgsFunction = completeOpenIssues
+	}
+	cherryCmd.RegisterFlagCompletionFunc("to", completeWorktrees)
+}
+
+// completionRepo returns the index entry of the current repository,
+// refreshing the index if the repository isn't in it yet
+func completionRepo() *index.Repo {
+	if repo := cachedRepo(); repo != nil {
+		return repo
+	}
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return nil
+	}
+	index.Refresh(config.Load(), repoInfo)
+	return cachedRepo()
+}
+
+// completeWorktrees completes the issue IDs of the repository's worktrees,
+// described by their branch names
+func completeWorktrees(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	repo := completionRepo()
+	if repo == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, path := range repo.Existing() {
+		name := filepath.Base(path)
+		id, ok := issueid.FromName(name)
+		if !ok || !strings.HasPrefix(string(id), strings.ToUpper(toComplete)) {
+			continue
+		}
+		completions = append(completions, string(id)+"\t"+name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOpenIssues completes the numbers of open issues without a worktree,
+// described by their titles. Issues are cached for index.IssueTTL.
+func completeOpenIssues(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	repo := completionRepo()
+	if repo == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	issues, fresh := index.OpenIssues(repo.Org, repo.Repo)
+	if !fresh {
+		if fetched, err := fetchOpenIssues(config.Load(), &git.RepoInfo{Org: repo.Org, Repo: repo.Repo}); err == nil {
+			issues = fetched
+		}
+	}
+
+	started := make(map[issueid.ID]bool)
+	for _, path := range repo.Existing() {
+		if id, ok := issueid.FromName(filepath.Base(path)); ok {
+			started[id] = true
+		}
+	}
+
+	var completions []string
+	for _, issue := range issues {
+		number := fmt.Sprint(issue.Number)
+		if started[issueid.FromNumber(issue.Number)] || !strings.HasPrefix(number, toComplete) {
+			continue
+		}
+		completions = append(completions, number+"\t"+issue.Title)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// fetchOpenIssues lists the repository's open issues and caches them for completion
+func fetchOpenIssues(cfg *config.Config, repoInfo *git.RepoInfo) ([]index.Issue, error) {
+	issues, err := listOpenIssues(cfg, 50, "newest")
+	if err != nil {
+		return nil, err
+	}
+	return cacheOpenIssues(repoInfo, issues), nil
+}
+
+// cacheOpenIssues caches the numbers and titles of open issues for completion
+func cacheOpenIssues(repoInfo *git.RepoInfo, issues []github.Issue) []index.Issue {
+	cached := make([]index.Issue, 0, len(issues))
+	for _, issue := range issues {
+		cached = append(cached, index.Issue{Number: issue.Number, Title: issue.Title})
+	}
+	index.SaveOpenIssues(repoInfo.Org, repoInfo.Repo, cached)
+	return cached
+}
@@ -4,93 +4,131 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/github"
-	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/index"
 	"github.com/enterprisemodules/gwi/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	includeInProgress bool
+	installGitHooks   bool
+	createBranch      string
+	createForce       bool
+	assignedToMe      bool
+	createStatus      string
+	createCached      bool
+	createEpic        int
+	createSort        string
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create [issue-number]",
 	Short: "Create worktree from GitHub issue",
-	Long:  `Create a new git worktree for a GitHub issue. If no issue number is provided, opens an interactive selector.`,
-	Args:  cobra.MaximumNArgs(1),
-	Run:   runCreate,
+	Long: `Create a new git worktree for a GitHub issue. If no issue number is provided, opens an interactive selector.
+
+The selector shows the epic an issue belongs to, as a sub-issue or tracked in another
+issue's tasklist. With --epic, it only lists the open children of that epic.
+
+Issues are listed newest first, or in the order of --sort (create.sort): oldest,
+updated (recent activity), reactions (most 👍 first), comments, or priority (by
+create.priority_labels). Their 👍 reactions and comments are shown after the labels.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCreate,
 }
 
 var internalCreateCmd = &cobra.Command{
 	Use:    "_create [issue-number]",
 	Hidden: true,
 	Args:   cobra.MaximumNArgs(1),
-	Run:    runInternalCreate,
+	RunE:   runInternalCreate,
 }
 
-func runCreate(cmd *cobra.Command, args []string) {
+func runCreate(cmd *cobra.Command, args []string) error {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		return err
 	}
 
 	var issueNumber int
 	if len(args) > 0 {
 		issueNumber, err = strconv.Atoi(args[0])
 		if err != nil {
-			config.Die("Invalid issue number: %s", args[0])
+			return errs.New(errs.KindUsage, "Invalid issue number: %s", args[0])
+		}
+		if createEpic != 0 {
+			return errs.New(errs.KindUsage, "--epic selects one of the epic's issues; leave out the issue number")
 		}
 	} else {
 		issueNumber, err = selectIssue(repoInfo)
 		if err != nil {
-			config.Die("No issue selected")
+			// Keep classified errors, e.g. no issues assigned to you
+			if errs.KindOf(err) != errs.KindGeneral {
+				return err
+			}
+			return errs.Aborted("No issue selected")
 		}
 	}
 
 	createWorktree(cfg, repoInfo, issueNumber, false)
+	return nil
 }
 
-func runInternalCreate(cmd *cobra.Command, args []string) {
+func runInternalCreate(cmd *cobra.Command, args []string) error {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		return err
 	}
 
 	var issueNumber int
 	if len(args) > 0 {
 		issueNumber, err = strconv.Atoi(args[0])
 		if err != nil {
-			config.Die("Invalid issue number: %s", args[0])
+			return errs.New(errs.KindUsage, "Invalid issue number: %s", args[0])
+		}
+		if createEpic != 0 {
+			return errs.New(errs.KindUsage, "--epic selects one of the epic's issues; leave out the issue number")
 		}
 	} else {
 		issueNumber, err = selectIssue(repoInfo)
 		if err != nil {
-			config.Die("No issue selected")
+			// Keep classified errors, e.g. no issues assigned to you
+			if errs.KindOf(err) != errs.KindGeneral {
+				return err
+			}
+			return errs.Aborted("No issue selected")
 		}
 	}
 
 	// Create worktree silently and output just the path
 	worktreePath := createWorktree(cfg, repoInfo, issueNumber, true)
-	fmt.Println(worktreePath)
+	printCdPath(worktreePath)
+	return nil
 }
 
 func selectIssue(repoInfo *git.RepoInfo) (int, error) {
 	cfg := config.Load()
 
-	if err := github.CheckAuth(); err != nil {
+	if err := checkTrackerAuth(cfg); err != nil {
+		return 0, err
+	}
+
+	sortOrder, err := issueSort(cfg)
+	if err != nil {
 		return 0, err
 	}
 
 	// Get issues with their project status
-	issues, err := github.ListOpenIssuesWithStatus(50, cfg.GitHub.StatusFieldName)
+	issues, err := listOpenIssues(cfg, 50, sortOrder)
 	if err != nil {
 		return 0, err
 	}
@@ -98,6 +136,28 @@ func selectIssue(repoInfo *git.RepoInfo) (int, error) {
 	if len(issues) == 0 {
 		return 0, fmt.Errorf("no open issues found")
 	}
+	cacheOpenIssues(repoInfo, issues)
+
+	header := fmt.Sprintf("Select issue (%s/%s)", repoInfo.Org, repoInfo.Repo)
+	var epics map[int]github.IssueRef
+	if createEpic != 0 {
+		title, children, err := epicIssues(cfg, issues, createEpic)
+		if err != nil {
+			return 0, err
+		}
+		issues = children
+		header = fmt.Sprintf("Select issue of epic #%d %s (%s/%s)", createEpic, title, repoInfo.Org, repoInfo.Repo)
+	} else {
+		epics = issueEpics(cfg, 50)
+	}
+
+	if assignedToMe {
+		if issues, err = filterAssignedToMe(cfg, issues); err != nil {
+			return 0, err
+		}
+	}
+
+	sortIssues(cfg, issues, sortOrder)
 
 	// Get existing worktrees to mark them as disabled
 	existingIssues := getExistingWorktreeIssues(cfg, repoInfo)
@@ -107,7 +167,7 @@ func selectIssue(repoInfo *git.RepoInfo) (int, error) {
 		_, exists := existingIssues[issue.Number]
 
 		// Check if issue is in progress
-		isInProgress := issue.ProjectStatus == cfg.GitHub.InProgressValue
+		isInProgress := issue.ProjectStatus == statusValue(cfg, statusInProgress)
 
 		// Determine if option should be disabled
 		disabled := exists || (isInProgress && !includeInProgress)
@@ -126,10 +186,11 @@ func selectIssue(repoInfo *git.RepoInfo) (int, error) {
 			Disabled:   disabled,
 			Hint:       hint,
 			InProgress: isInProgress && !exists, // Mark as in-progress only if not already existing
+			Detail:     issueDetail(issue, epics),
+			Labels:     tuiLabels(issue.Labels),
 		})
 	}
 
-	header := fmt.Sprintf("Select issue (%s/%s)", repoInfo.Org, repoInfo.Repo)
 	selected, err := tui.Select(header, options)
 	if err != nil {
 		return 0, err
@@ -138,6 +199,124 @@ func selectIssue(repoInfo *git.RepoInfo) (int, error) {
 	return strconv.Atoi(selected)
 }
 
+// filterAssignedToMe keeps the issues assigned to the authenticated GitHub user
+func filterAssignedToMe(cfg *config.Config, issues []github.Issue) ([]github.Issue, error) {
+	if _, ok := azureBoards(cfg); ok {
+		return nil, errs.New(errs.KindUsage, "--assigned-to-me is not supported with Azure Boards")
+	}
+	me, err := github.CurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub user: %w", err)
+	}
+	var mine []github.Issue
+	for _, issue := range issues {
+		if issue.IsAssignedTo(me) {
+			mine = append(mine, issue)
+		}
+	}
+	if len(mine) == 0 {
+		return nil, errs.NotFound("No open issues assigned to %s", me)
+	}
+	return mine, nil
+}
+
+// issueDetail describes an issue's reactions and comments, the epic it
+// belongs to, if any, and its people
+func issueDetail(issue github.Issue, epics map[int]github.IssueRef) string {
+	var parts []string
+	if badge := issueBadge(issue); badge != "" {
+		parts = append(parts, badge)
+	}
+	if epic, ok := epics[issue.Number]; ok {
+		parts = append(parts, epicName(epic))
+	}
+	if people := issuePeople(issue); people != "" {
+		parts = append(parts, people)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// epicName names an epic briefly, for selectors and listings
+func epicName(epic github.IssueRef) string {
+	title := epic.Title
+	if runes := []rune(title); len(runes) > 30 {
+		title = string(runes[:29]) + "…"
+	}
+	return fmt.Sprintf("epic %s %s", epic.Ref(), title)
+}
+
+// issueEpics returns the epics of the repository's open issues by issue
+// number. Work items and failed lookups have none.
+func issueEpics(cfg *config.Config, limit int) map[int]github.IssueRef {
+	if _, ok := azureBoards(cfg); ok {
+		return nil
+	}
+	epics, err := github.ListIssueParents(limit)
+	if err != nil && cfg.Verbose {
+		config.Warn("Could not look up the epics of issues: %v", err)
+	}
+	return epics
+}
+
+// epicIssues returns an epic's title and its open children, with the project
+// status the open issues have
+func epicIssues(cfg *config.Config, open []github.Issue, epicNumber int) (string, []github.Issue, error) {
+	if _, ok := azureBoards(cfg); ok {
+		return "", nil, errs.New(errs.KindUsage, "--epic needs GitHub issues; it isn't supported for Azure Boards work items")
+	}
+	title, children, err := github.ListSubIssues(epicNumber)
+	if err != nil {
+		// Classified, so the selector reports it instead of "No issue selected"
+		return "", nil, errs.NotFound("Could not look up epic #%d: %v", epicNumber, err)
+	}
+	if len(children) == 0 {
+		return "", nil, errs.NotFound("Epic #%d has no open sub-issues", epicNumber)
+	}
+
+	status := make(map[int]string, len(open))
+	for _, issue := range open {
+		status[issue.Number] = issue.ProjectStatus
+	}
+	for i := range children {
+		children[i].ProjectStatus = status[children[i].Number]
+	}
+	return title, children, nil
+}
+
+// issuePeople describes who opened an issue and who it is assigned to
+func issuePeople(issue github.Issue) string {
+	var parts []string
+	if issue.Author.Login != "" {
+		parts = append(parts, "by "+issue.Author.Login)
+	}
+	if len(issue.Assignees) > 0 {
+		logins := make([]string, len(issue.Assignees))
+		for i, assignee := range issue.Assignees {
+			logins[i] = assignee.Login
+		}
+		parts = append(parts, "assigned to "+strings.Join(logins, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// tuiLabels converts GitHub labels for display in selectors and listings
+func tuiLabels(labels []github.Label) []tui.Label {
+	result := make([]tui.Label, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, tui.Label{Name: label.Name, Color: label.Color})
+	}
+	return result
+}
+
+// worktreeLabels renders the labels of the issue a worktree belongs to
+func worktreeLabels(labels map[int][]github.Label, worktreePath string) string {
+	issueNumber, ok := github.ParseIssueFromBranch(filepath.Base(worktreePath))
+	if !ok {
+		return ""
+	}
+	return tui.FormatLabels(tuiLabels(labels[issueNumber]))
+}
+
 // getExistingWorktreeIssues returns a set of issue numbers that have existing worktrees
 func getExistingWorktreeIssues(cfg *config.Config, repoInfo *git.RepoInfo) map[int]bool {
 	result := make(map[int]bool)
@@ -161,116 +340,255 @@ func getExistingWorktreeIssues(cfg *config.Config, repoInfo *git.RepoInfo) map[i
 	return result
 }
 
+// cachedIssue stands in for an issue that couldn't be fetched, with its cached
+// title, when --cached is given. Otherwise it fails with the fetch error.
+func cachedIssue(repoInfo *git.RepoInfo, issueNumber int, fetchErr error) *github.Issue {
+	title, ok := index.IssueTitle(repoInfo.Org, repoInfo.Repo, issueNumber)
+	if !createCached {
+		if ok {
+			config.Info("The title of #%d is cached: retry with --cached to create the worktree without GitHub", issueNumber)
+		}
+		config.Fail(fetchErr)
+	}
+	if !ok {
+		config.Fail(errs.NotFound("Issue #%d isn't cached, and fetching it failed: %v", issueNumber, fetchErr))
+	}
+	config.Warn("Could not fetch issue #%d, using its cached title: %v", issueNumber, fetchErr)
+	return &github.Issue{Number: issueNumber, Title: title, State: "OPEN"}
+}
+
 func createWorktree(cfg *config.Config, repoInfo *git.RepoInfo, issueNumber int, silent bool) string {
-	if err := github.CheckAuth(); err != nil {
-		config.Die("%v", err)
+	overrideStatus(cfg, statusInProgress, createStatus)
+	steps, err := createSteps(cfg)
+	if err != nil {
+		config.Fail(err)
+	}
+
+	if err := checkTrackerAuth(cfg); err != nil {
+		if !createCached {
+			// Fails, pointing at --cached when the title is cached
+			cachedIssue(repoInfo, issueNumber, err)
+		}
+		config.Warn("%v", err)
 	}
 
 	if !silent {
 		config.Info("Fetching issue #%d...", issueNumber)
 	}
 
-	issue, err := github.GetIssue(issueNumber)
+	issue, err := getIssue(cfg, issueNumber)
 	if err != nil {
-		config.Die("%v", err)
+		issue = cachedIssue(repoInfo, issueNumber, err)
+	} else {
+		index.RememberIssue(repoInfo.Org, repoInfo.Repo, index.Issue{Number: issue.Number, Title: issue.Title})
 	}
 
 	if issue.State == "CLOSED" {
 		config.Warn("Issue #%d is closed", issueNumber)
 	}
 
-	slug := git.Slugify(issue.Title)
-	branchName := fmt.Sprintf("%d-%s", issueNumber, slug)
+	branchName := issueBranchName(cfg, issueNumber, issue.Title)
 	worktreePath := filepath.Join(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo), branchName)
 
 	// Check if worktree already exists
 	if _, err := os.Stat(worktreePath); err == nil {
 		if !silent {
-			config.Die("Worktree for issue #%d already exists.\n\n  Path: %s\n\n  Use 'gwi cd %d' to navigate to it, or 'gwi rm %d' to remove it first.", issueNumber, worktreePath, issueNumber, issueNumber)
+			config.Fail(errs.Conflict("Worktree for issue #%d already exists.\n\n  Path: %s\n\n  Use 'gwi cd %d' to navigate to it, or 'gwi rm %d' to remove it first.", issueNumber, worktreePath, issueNumber, issueNumber))
 		}
-		// In silent mode (shell integration), just return the path to cd to it
-		fmt.Println(worktreePath)
+		// In silent mode (shell integration), just return the path to cd to it.
+		// That starts work on the issue again, so it counts like creating it.
+		checkWIPLimit(cfg, repoInfo, issueNumber)
+		recordActiveIssue(repoInfo, issueNumber, branchName, issue.Title)
 		return worktreePath
 	}
 
-	if !silent {
-		config.Info("Fetching from origin...")
-	}
-	if err := git.Fetch(); err != nil {
-		config.Die("Failed to fetch: %v", err)
+	// The branch may be checked out outside gwi's worktree directory, e.g. by
+	// a worktree added by hand or one from before the directory was renamed
+	if other := git.BranchWorktree(branchName); other != "" {
+		var adopt bool
+		branchName, adopt = resolveCheckedOutBranch(issueNumber, branchName, other)
+		if adopt {
+			if !silent {
+				printCdTo(other)
+			}
+			return other
+		}
+		worktreePath = filepath.Join(filepath.Dir(worktreePath), branchName)
 	}
 
-	// Create worktree directory structure
-	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
-		config.Die("Failed to create directory: %v", err)
+	// A branch with the issue's name may have been made for another issue with
+	// the same number, e.g. before switching trackers
+	if other := branchOwner(cfg, issueNumber, branchName); other != "" {
+		branchName = resolveBranchCollision(issueNumber, branchName, other)
+		worktreePath = filepath.Join(filepath.Dir(worktreePath), branchName)
 	}
 
-	// Check if branch already exists (local or remote)
-	if git.BranchExists(branchName) {
-		if !silent {
-			config.Info("Using existing local branch: %s", branchName)
-		}
-		if err := git.CreateWorktreeFromBranch(worktreePath, branchName); err != nil {
-			config.Die("Failed to create worktree: %v", err)
-		}
-	} else if git.RemoteBranchExists(branchName) {
-		if !silent {
-			config.Info("Using existing remote branch: %s", branchName)
-		}
-		if err := git.CreateWorktreeFromRemote(worktreePath, branchName, "origin/"+branchName); err != nil {
-			config.Die("Failed to create worktree: %v", err)
-		}
-	} else {
-		if !silent {
-			config.Info("Creating worktree: %s", branchName)
-		}
-		if err := git.CreateWorktree(worktreePath, branchName, "origin/main"); err != nil {
-			config.Die("Failed to create worktree: %v", err)
-		}
+	rules, err := applyRules(cfg, issue, "create", createForce)
+	if err != nil {
+		config.Fail(err)
 	}
 
+	checkWIPLimit(cfg, repoInfo, issueNumber)
+
+	runCreateSteps(&createContext{
+		cfg:          cfg,
+		repoInfo:     repoInfo,
+		issue:        issue,
+		issueNumber:  issueNumber,
+		branchName:   branchName,
+		worktreePath: worktreePath,
+		base:         rules.baseBranch(cfg),
+		silent:       silent,
+	}, steps)
+	postProgress(cfg, issueNumber, progressCreated, "`"+branchName+"`")
+
+	// Output cd instruction for shell wrapper (only in interactive mode)
 	if !silent {
-		config.Success("Worktree created at: %s", worktreePath)
+		printCdTo(worktreePath)
 	}
 
-	// Run create hook if it exists
-	hooks.RunHook("create", worktreePath, cfg, repoInfo)
+	return worktreePath
+}
+
+func init() {
+	createCmd.Flags().BoolVar(&includeInProgress, "include-in-progress", false, "Allow selecting issues that are already in progress")
+	createCmd.Flags().BoolVar(&installGitHooks, "install-hooks", false, "Enable the repository's git hooks (e.g. .githooks) in the new worktree")
+	internalCreateCmd.Flags().BoolVar(&installGitHooks, "install-hooks", false, "Enable the repository's git hooks in the new worktree")
+	createCmd.Flags().StringVarP(&createBranch, "branch", "b", "", "Branch name to use instead of one generated from the issue title")
+	internalCreateCmd.Flags().StringVarP(&createBranch, "branch", "b", "", "Branch name to use instead of one generated from the issue title")
+	createCmd.Flags().BoolVar(&createForce, "force", false, "Create the worktree even if a rule requires --force for the issue's labels")
+	internalCreateCmd.Flags().BoolVar(&createForce, "force", false, "Create the worktree even if a rule requires --force")
+	createCmd.Flags().BoolVar(&assignedToMe, "assigned-to-me", false, "Only show issues assigned to you in the selector")
+	internalCreateCmd.Flags().BoolVar(&assignedToMe, "assigned-to-me", false, "Only show issues assigned to you in the selector")
+	createCmd.Flags().StringVar(&createStatus, "status", "", "Status to move the issue to instead of the in-progress value, e.g. \"Doing\"")
+	internalCreateCmd.Flags().StringVar(&createStatus, "status", "", "Status to move the issue to instead of the in-progress value")
+	createCmd.Flags().BoolVar(&createCached, "cached", false, "Use the cached issue title when GitHub can't be reached")
+	internalCreateCmd.Flags().BoolVar(&createCached, "cached", false, "Use the cached issue title when GitHub can't be reached")
+	createCmd.Flags().IntVar(&createEpic, "epic", 0, "Only show the open sub-issues of this epic in the selector")
+	internalCreateCmd.Flags().IntVar(&createEpic, "epic", 0, "Only show the open sub-issues of this epic in the selector")
+	createCmd.Flags().StringVar(&createSort, "sort", "", "Order of the selector: newest, oldest, updated, reactions, comments or priority")
+	internalCreateCmd.Flags().StringVar(&createSort, "sort", "", "Order of the selector: newest, oldest, updated, reactions, comments or priority")
+}
+
+// resolveCheckedOutBranch asks what to do about a branch that is already
+// checked out in another worktree: adopt that worktree, or continue on a new
+// branch with a suffix. It returns the branch to create and whether to adopt.
+func resolveCheckedOutBranch(issueNumber int, branchName, other string) (string, bool) {
+	suffixed := suffixedBranch(branchName)
+	if config.Headless() {
+		config.Fail(errs.Conflict("Branch %s is already checked out in another worktree.\n\n  Path: %s\n\n  Continue there, or start a separate branch with 'gwi create %d --branch %s'.", branchName, other, issueNumber, strings.TrimPrefix(suffixed, fmt.Sprintf("%d-", issueNumber))))
+	}
 
-	// Update GitHub Project status to "In Progress"
-	// This happens even in silent mode, messages go to stderr so they don't break shell integration
-	if cfg.Verbose {
-		config.Info("GitHub Projects enabled: %v", cfg.GitHub.ProjectsEnabled)
+	config.Warn("Branch %s is already checked out at %s", branchName, other)
+	fmt.Fprintf(os.Stderr, "  [a] Adopt that worktree\n  [s] Create branch %s instead\n  [q] Quit\n", suffixed)
+	switch strings.ToLower(promptLine("Choice [a/s/q]: ")) {
+	case "a", "adopt":
+		config.Info("Adopting the worktree at %s", other)
+		return branchName, true
+	case "s", "suffix":
+		return suffixed, false
+	default:
+		config.Fail(errs.Aborted("Aborted"))
+		return "", false
 	}
-	if cfg.GitHub.ProjectsEnabled {
-		branchName := filepath.Base(worktreePath)
-		if cfg.Verbose {
-			config.Info("Branch name: %s", branchName)
+}
+
+// branchIssueID identifies an issue in the tracker, as recorded on branches
+func branchIssueID(cfg *config.Config, issueNumber int) string {
+	return fmt.Sprintf("%s#%d", cfg.Tracker, issueNumber)
+}
+
+// branchOwner describes the other issue an existing branch was made for, or
+// returns "" when the branch doesn't exist or is the issue's. Local branches
+// record their issue; for others, commits closing another issue and not this
+// one give it away.
+func branchOwner(cfg *config.Config, issueNumber int, branchName string) string {
+	ref := branchName
+	if !git.BranchExists(branchName) {
+		if ref = git.RemoteBranchRef(branchName); ref == "" {
+			return ""
+		}
+	} else if recorded := git.BranchIssue(branchName); recorded != "" {
+		if recorded == branchIssueID(cfg, issueNumber) {
+			return ""
 		}
-		if issueNum, ok := github.ParseIssueFromBranch(branchName); ok {
-			if cfg.Verbose {
-				config.Info("Parsed issue number: %d", issueNum)
-				config.Info("Attempting to update to: %s", cfg.GitHub.InProgressValue)
+		return recorded
+	}
+
+	messages, err := git.GetCommitMessages("", git.RemoteRef(cfg.MainBranch)+".."+ref)
+	if err != nil {
+		return ""
+	}
+	var others []string
+	for _, message := range messages {
+		for _, match := range closingRef.FindAllStringSubmatch(message, -1) {
+			if match[1] == strconv.Itoa(issueNumber) {
+				return ""
 			}
-			if err := github.UpdateIssueStatus(issueNum, cfg.GitHub.InProgressValue, cfg); err != nil {
-				if cfg.Verbose {
-					config.Warn("Failed to update project status: %v", err)
-				}
-			} else {
-				config.Info("Updated issue #%d to '%s' in GitHub Projects", issueNum, cfg.GitHub.InProgressValue)
+			if !slices.Contains(others, "#"+match[1]) {
+				others = append(others, "#"+match[1])
 			}
-		} else if cfg.Verbose {
-			config.Warn("Could not parse issue number from branch: %s", branchName)
 		}
 	}
+	return strings.Join(others, ", ")
+}
 
-	// Output cd instruction for shell wrapper (only in interactive mode)
-	if !silent {
-		fmt.Printf("__GWI_CD_TO__:%s\n", worktreePath)
+// resolveBranchCollision picks the branch to use when branchName belongs to
+// another issue: interactively reuse it or take a suffixed name, without a
+// terminal always the suffixed name
+func resolveBranchCollision(issueNumber int, branchName, other string) string {
+	suffixed := suffixedBranch(branchName)
+	if config.Headless() {
+		config.Warn("Branch %s was made for %s, using %s for #%d instead", branchName, other, suffixed, issueNumber)
+		return suffixed
 	}
 
-	return worktreePath
+	config.Warn("Branch %s exists but was made for %s, not #%d", branchName, other, issueNumber)
+	fmt.Fprintf(os.Stderr, "  [s] Create branch %s instead\n  [r] Reuse %s anyway\n  [q] Quit\n", suffixed, branchName)
+	switch strings.ToLower(promptLine("Choice [s/r/q]: ")) {
+	case "s", "suffix", "":
+		return suffixed
+	case "r", "reuse":
+		return branchName
+	default:
+		config.Fail(errs.Aborted("Aborted"))
+		return ""
+	}
 }
 
-func init() {
-	createCmd.Flags().BoolVar(&includeInProgress, "include-in-progress", false, "Allow selecting issues that are already in progress")
+// suffixedBranch returns branchName with the first -2, -3, ... suffix that
+// isn't taken by a local or remote branch
+func suffixedBranch(branchName string) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", branchName, n)
+		if !git.BranchExists(candidate) && git.RemoteBranchRef(candidate) == "" {
+			return candidate
+		}
+	}
+}
+
+// issueBranchName returns the branch (and worktree directory) name for an issue:
+// --branch when given, otherwise a slug of the title. The issue number prefix
+// is always kept so gwi can find the worktree again.
+func issueBranchName(cfg *config.Config, issueNumber int, title string) string {
+	prefix := fmt.Sprintf("%d-", issueNumber)
+
+	if createBranch != "" {
+		if strings.ContainsAny(createBranch, "/\\ \t") || strings.HasPrefix(createBranch, "-") {
+			config.Fail(errs.New(errs.KindUsage, "Invalid branch name: %q (no slashes, spaces or leading dash)", createBranch))
+		}
+		if strings.HasPrefix(createBranch, prefix) {
+			return createBranch
+		}
+		return prefix + createBranch
+	}
+
+	slug := git.SlugifyWith(title, git.SlugOptions{
+		MaxLength: cfg.Slug.MaxLength,
+		StopWords: cfg.Slug.StopWords,
+	})
+	if slug == "" {
+		slug = "issue"
+	}
+	return prefix + slug
 }
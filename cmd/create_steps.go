@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/stats"
+)
+
+// createContext is the state shared by the steps of gwi create
+type createContext struct {
+	cfg          *config.Config
+	repoInfo     *git.RepoInfo
+	issue        *github.Issue
+	issueNumber  int
+	branchName   string
+	worktreePath string
+	base         string // Branch a new branch starts from: main_branch, or a rule's base
+	silent       bool
+}
+
+// createStep is one step of the create pipeline. Steps before "worktree" run
+// without a worktree; a failing step is reported once all steps have run.
+type createStep struct {
+	name string
+	run  func(c *createContext) error
+}
+
+// builtinCreateSteps are the steps that can be listed in create.steps, besides
+// "hook:<name>" (run a named hook) and "run:<command>" (run a shell command)
+var builtinCreateSteps = map[string]func(c *createContext) error{
+	"fetch":          stepFetch,
+	"worktree":       stepWorktree,
+	"copy_files":     stepCopyFiles,
+	"hooks":          stepHooks,
+	"project_update": stepProjectUpdate,
+	"open_editor":    stepOpenEditor,
+}
+
+// createSteps resolves and validates the configured create pipeline
+func createSteps(cfg *config.Config) ([]createStep, error) {
+	var steps []createStep
+	seen := make(map[string]bool)
+	hasWorktree := false
+
+	for _, name := range cfg.Create.Steps {
+		name = strings.TrimSpace(name)
+		var run func(c *createContext) error
+
+		switch {
+		case strings.HasPrefix(name, "hook:"):
+			hookName := strings.TrimPrefix(name, "hook:")
+			run = func(c *createContext) error { return stepNamedHook(c, hookName) }
+		case strings.HasPrefix(name, "run:"):
+			command := strings.TrimSpace(strings.TrimPrefix(name, "run:"))
+			run = func(c *createContext) error { return stepRunCommand(c, command) }
+		default:
+			run = builtinCreateSteps[name]
+			if run == nil {
+				return nil, fmt.Errorf("unknown create step %q in create.steps", name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("create step %q is listed twice in create.steps", name)
+			}
+			seen[name] = true
+		}
+
+		if !hasWorktree && name != "fetch" && name != "worktree" {
+			return nil, fmt.Errorf("create step %q must come after \"worktree\" in create.steps", name)
+		}
+		hasWorktree = hasWorktree || name == "worktree"
+		steps = append(steps, createStep{name: name, run: run})
+	}
+
+	if !hasWorktree {
+		return nil, fmt.Errorf("create.steps must include \"worktree\"")
+	}
+	return steps, nil
+}
+
+// runCreateSteps runs the pipeline and summarizes failed steps at the end, so
+// their errors aren't lost between the output of later steps
+func runCreateSteps(c *createContext, steps []createStep) {
+	var failures []string
+	for _, step := range steps {
+		end := stats.Step(step.name)
+		err := step.run(c)
+		end()
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", step.name, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		config.Warn("%d create step(s) failed:", len(failures))
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "  %s\n", config.Red(failure))
+		}
+	}
+}
+
+func stepFetch(c *createContext) error {
+	if !c.silent {
+		config.Info("Fetching from %s...", git.FetchRemote())
+	}
+	if err := git.Fetch(); err != nil {
+		if !createCached {
+			return fmt.Errorf("failed to fetch: %w", err)
+		}
+		// With --cached the worktree is based on the remote refs of the last fetch
+		last := "never"
+		if fetched := git.LastFetch("."); !fetched.IsZero() {
+			last = config.FormatTime(fetched)
+		}
+		config.Warn("Could not fetch from %s, using the refs fetched last (%s): %v", git.FetchRemote(), last, err)
+	}
+	if !c.silent {
+		checkMainBranch(c.cfg, true)
+	}
+	return nil
+}
+
+func stepWorktree(c *createContext) error {
+	// Undo everything below if creating the worktree fails or is interrupted
+	rb := beginCreate(c.worktreePath, c.branchName)
+
+	// Create worktree directory structure
+	if err := os.MkdirAll(filepath.Dir(c.worktreePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Check if branch already exists (local or remote)
+	if git.BranchExists(c.branchName) {
+		if !c.silent {
+			config.Info("Using existing local branch: %s", c.branchName)
+		}
+		if err := git.CreateWorktreeFromBranch(c.worktreePath, c.branchName); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	} else if remoteRef := git.RemoteBranchRef(c.branchName); remoteRef != "" {
+		if !c.silent {
+			config.Info("Using existing remote branch: %s", c.branchName)
+		}
+		if err := git.CreateWorktreeFromRemote(c.worktreePath, c.branchName, remoteRef); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	} else {
+		if !c.silent {
+			config.Info("Creating worktree: %s", c.branchName)
+		}
+		if err := git.CreateWorktree(c.worktreePath, c.branchName, git.RemoteRef(c.base)); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+	}
+
+	rb.commit()
+	if err := git.SetBranchIssue(c.branchName, branchIssueID(c.cfg, c.issueNumber)); err != nil && c.cfg.Verbose {
+		config.Warn("Failed to record the issue of %s: %v", c.branchName, err)
+	}
+	index.Refresh(c.cfg, c.repoInfo)
+	recordActiveIssue(c.repoInfo, c.issueNumber, c.branchName, c.issue.Title)
+
+	if !c.silent {
+		config.Success("Worktree created at: %s", c.worktreePath)
+	}
+	enableFSMonitor(c.worktreePath, c.silent)
+	return applyWorktreeGitConfig(c.cfg, c.repoInfo, c.worktreePath, c.silent)
+}
+
+// applyWorktreeGitConfig sets the git_config entries matching the repository
+// in a new worktree
+func applyWorktreeGitConfig(cfg *config.Config, repoInfo *git.RepoInfo, worktreePath string, silent bool) error {
+	entries := cfg.WorktreeGitConfig(repoInfo.Org, repoInfo.Repo)
+	if len(entries) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var failed []string
+	for _, key := range keys {
+		if err := git.SetWorktreeConfig(worktreePath, key, entries[key]); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", key, err))
+			continue
+		}
+		if cfg.Verbose {
+			config.Info("Set %s=%s", key, entries[key])
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to set git config %s", strings.Join(failed, ", "))
+	}
+	if !silent {
+		config.Info("Applied %d git config setting(s) from git_config", len(keys))
+	}
+	return nil
+}
+
+// stepCopyFiles copies untracked files such as .env from the main worktree
+func stepCopyFiles(c *createContext) error {
+	if len(c.cfg.Create.CopyFiles) == 0 {
+		return nil
+	}
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return fmt.Errorf("failed to find main worktree: %w", err)
+	}
+
+	var failed []string
+	for _, pattern := range c.cfg.Create.CopyFiles {
+		matches, err := filepath.Glob(filepath.Join(mainPath, pattern))
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, src := range matches {
+			rel, _ := filepath.Rel(mainPath, src)
+			dst := filepath.Join(c.worktreePath, rel)
+			if info, err := os.Stat(src); err != nil || info.IsDir() {
+				continue
+			}
+			// Never overwrite what the branch itself provides
+			if _, err := os.Stat(dst); err == nil {
+				continue
+			}
+			if err := copyWorktreeFile(src, dst); err != nil {
+				failed = append(failed, rel)
+				continue
+			}
+			config.Info("Copied %s", rel)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to copy %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func copyWorktreeFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// stepHooks enables the repository's git hooks and runs the create hook
+func stepHooks(c *createContext) error {
+	if c.cfg.GitHooks.Install || installGitHooks {
+		if err := hooks.InstallGitHooks(c.worktreePath, c.cfg); err != nil {
+			config.Warn("%v", err)
+		}
+	}
+
+	_, err := runHook("create", c.worktreePath, c.cfg, c.repoInfo)
+	return err
+}
+
+// stepProjectUpdate moves the issue to "In Progress". This happens even in
+// silent mode; messages go to stderr so they don't break shell integration.
+func stepProjectUpdate(c *createContext) error {
+	cfg := c.cfg
+	if cfg.Verbose {
+		config.Info("%s enabled: %v", trackerName(cfg), tracksStatus(cfg))
+	}
+	if !tracksStatus(cfg) {
+		return nil
+	}
+
+	branchName := filepath.Base(c.worktreePath)
+	if cfg.Verbose {
+		config.Info("Branch name: %s", branchName)
+	}
+	issueNum, ok := github.ParseIssueFromBranch(branchName)
+	if !ok {
+		if cfg.Verbose {
+			config.Warn("Could not parse issue number from branch: %s", branchName)
+		}
+		return nil
+	}
+	if cfg.Verbose {
+		config.Info("Parsed issue number: %d", issueNum)
+		config.Info("Attempting to update to: %s", statusValue(cfg, statusInProgress))
+	}
+	updates, err := updateIssueStatus(cfg, issueNum, statusInProgress)
+	if err != nil {
+		// Repositories without projects fail here too; only report it when
+		// asked, or when the status was given with --status
+		if cfg.Verbose || createStatus != "" {
+			return fmt.Errorf("failed to update project status: %w", err)
+		}
+		return nil
+	}
+	config.Info("Updated issue #%d to '%s' in %s", issueNum, statusValue(cfg, statusInProgress), trackerName(cfg))
+	printProjectUpdates(updates)
+	return nil
+}
+
+// stepOpenEditor opens the worktree with create.editor, without waiting for it
+func stepOpenEditor(c *createContext) error {
+	args := strings.Fields(c.cfg.Create.Editor)
+	if len(args) == 0 {
+		return fmt.Errorf("set create.editor (e.g. \"code\") to open new worktrees")
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], c.worktreePath)...)
+	cmd.Dir = c.worktreePath
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	config.Info("Opened in %s", args[0])
+	return cmd.Process.Release()
+}
+
+func stepNamedHook(c *createContext, hookName string) error {
+	ran, err := runHook(hookName, c.worktreePath, c.cfg, c.repoInfo)
+	if !ran {
+		return fmt.Errorf("no '%s' hook found", hookName)
+	}
+	return err
+}
+
+// stepRunCommand runs a shell command in the worktree. Its output goes to
+// stderr, as stdout carries the worktree path for shell integration.
+func stepRunCommand(c *createContext, command string) error {
+	config.Info("Running: %s", command)
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = c.worktreePath
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
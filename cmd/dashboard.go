@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// worktreeContext is the state of the worktree bare gwi runs in
+type worktreeContext struct {
+	name     string
+	title    string // Cached issue title; empty when not cached
+	changes  int
+	commits  int // Commits on the branch that aren't on the main branch
+	unpushed int
+	behind   int // Commits on the main branch the branch doesn't have
+	pr       int
+	prState  string // OPEN, MERGED or CLOSED
+	server   bool
+	upHook   bool
+}
+
+// runDashboard is what bare gwi does: inside an issue's worktree it shows the
+// issue, its state and what to do next; elsewhere in a repository it lists
+// the worktrees. Outside a repository the help is shown.
+func runDashboard(cmd *cobra.Command, args []string) {
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		cmd.Help()
+		return
+	}
+	cfg := config.Load()
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+
+	if id, ok := git.DetectIssueID(base); ok {
+		if path := git.FindWorktreeByID(base, id); path != "" {
+			printWorktreeDashboard(collectWorktreeContext(cfg, repoInfo, path))
+			return
+		}
+	}
+	printRepoDashboard(cfg, repoInfo)
+}
+
+// collectWorktreeContext gathers what the dashboard shows about a worktree
+func collectWorktreeContext(cfg *config.Config, repoInfo *git.RepoInfo, path string) worktreeContext {
+	name := filepath.Base(path)
+	wt := worktreeContext{name: name}
+	if issueNumber, ok := github.ParseIssueFromBranch(name); ok {
+		wt.title, _ = index.IssueTitle(repoInfo.Org, repoInfo.Repo, issueNumber)
+	}
+
+	if !skipChangeScan(path) {
+		wt.changes = git.GetUncommittedCount(path)
+	}
+	mainRef := git.RemoteRef(cfg.MainBranch)
+	wt.commits, _ = git.CountCommits(path, mainRef+"..HEAD")
+	wt.behind, _ = git.CountCommits(path, "HEAD.."+mainRef)
+	wt.unpushed, _ = git.CountUnpushed(path)
+
+	linkOpenPRs(repoInfo, []string{name})
+	var link linkedPR
+	if meta, _ := store.Load(); meta != nil && meta.Get(prKey(repoInfo, name), &link) {
+		if state, err := github.GetPRState(link.Number); err == nil {
+			wt.pr, wt.prState = link.Number, state
+		}
+	}
+
+	wt.server = tmuxSessionExists(name)
+	wt.upHook = hooks.FindHook("up", path, cfg, repoInfo) != ""
+	return wt
+}
+
+// nextAction suggests the command that moves the worktree's issue along, with
+// the reason for it. The command is empty when there is nothing to run.
+func nextAction(wt worktreeContext) (command, reason string) {
+	switch {
+	case wt.prState == "MERGED":
+		return "gwi rm", fmt.Sprintf("PR #%d is merged; remove the worktree", wt.pr)
+	case wt.prState == "CLOSED":
+		return "gwi rm", fmt.Sprintf("PR #%d was closed without merging", wt.pr)
+	case wt.changes > 0:
+		return "git commit", fmt.Sprintf("%d uncommitted change(s); or put them aside with gwi snapshot", wt.changes)
+	case wt.prState == "OPEN" && wt.unpushed > 0:
+		return "git push", fmt.Sprintf("%d commit(s) aren't on PR #%d yet", wt.unpushed, wt.pr)
+	case wt.prState == "OPEN":
+		return "gwi merge", fmt.Sprintf("once PR #%d's checks and reviews pass", wt.pr)
+	case wt.commits > 0:
+		return "gwi pr", fmt.Sprintf("%d commit(s) ready for review", wt.commits)
+	case wt.upHook && !wt.server:
+		return "gwi up", "start the dev server and make your changes"
+	}
+	return "", "make your changes and commit them"
+}
+
+// printWorktreeDashboard shows the context panel of a worktree
+func printWorktreeDashboard(wt worktreeContext) {
+	heading := config.Blue(wt.name)
+	if wt.title != "" {
+		heading = config.Blue(wt.title) + " " + config.Dim("("+wt.name+")")
+	}
+	fmt.Println(heading)
+	fmt.Println()
+
+	var state []string
+	switch {
+	case wt.changes == 1:
+		state = append(state, config.Yellow(config.Glyph("dirty")+" 1 change"))
+	case wt.changes > 1:
+		state = append(state, config.Yellow(fmt.Sprintf("%s %d changes", config.Glyph("dirty"), wt.changes)))
+	default:
+		state = append(state, config.Green(config.Glyph("clean")+" clean"))
+	}
+	state = append(state, fmt.Sprintf("%d commit(s)", wt.commits))
+	if wt.unpushed > 0 {
+		state = append(state, fmt.Sprintf("%s%d unpushed", config.Glyph("up"), wt.unpushed))
+	}
+	if wt.behind > 0 {
+		state = append(state, fmt.Sprintf("%s%d behind main", config.Glyph("down"), wt.behind))
+	}
+	fmt.Printf("  %-8s %s\n", "branch", strings.Join(state, ", "))
+
+	pr := config.Yellow("none")
+	if wt.pr > 0 {
+		pr = fmt.Sprintf("#%d %s", wt.pr, strings.ToLower(wt.prState))
+	}
+	fmt.Printf("  %-8s %s\n", "PR", pr)
+
+	server := config.Dim("stopped")
+	if wt.server {
+		server = config.Green(config.Glyph("running") + " running")
+	}
+	fmt.Printf("  %-8s %s\n", "server", server)
+
+	fmt.Println()
+	if command, reason := nextAction(wt); command != "" {
+		fmt.Printf("Next: %s %s\n", config.Cyan(command), config.Dim("- "+reason))
+	} else {
+		fmt.Printf("Next: %s\n", reason)
+	}
+	fmt.Println(config.Dim("Run gwi --help for all commands."))
+}
+
+// printRepoDashboard lists the repository's worktrees with their hints
+func printRepoDashboard(cfg *config.Config, repoInfo *git.RepoInfo) {
+	fmt.Printf("Worktrees for %s%s/%s%s:\n", config.Blue(""), repoInfo.Org, repoInfo.Repo, config.Blue(""))
+	fmt.Println()
+
+	worktrees, _ := git.ListWorktrees(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo))
+	if len(worktrees) == 0 {
+		fmt.Println("  No worktrees yet.")
+		fmt.Println()
+		fmt.Println(config.Dim("Run gwi start to pick an issue, or gwi --help for all commands."))
+		return
+	}
+
+	width := 0
+	for _, wt := range worktrees {
+		width = max(width, len(filepath.Base(wt)))
+	}
+	hints := worktreeHints(repoInfo, worktrees)
+	for _, wt := range worktrees {
+		fmt.Printf("  %-*s  %s\n", width, filepath.Base(wt), config.Dim(hints[wt]))
+	}
+	fmt.Println()
+	fmt.Println(config.Dim("Run gwi cd <issue> to switch, gwi start to pick an issue, or gwi --help for all commands."))
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os/exec"
 	"strconv"
+	"strings"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/github"
 	"github.com/spf13/cobra"
 )
@@ -13,9 +15,12 @@ import (
 var debugCmd = &cobra.Command{
 	Use:   "debug [issue-number]",
 	Short: "Debug GitHub Projects integration",
-	Long:  `Test GitHub Projects integration and show detailed information about configuration and API calls.`,
-	Args:  cobra.ExactArgs(1),
-	Run:   runDebug,
+	Long: `Test GitHub Projects integration and show detailed information about configuration and API calls.
+
+Tokens are always masked. Use --redact to also mask account names, project IDs and
+titles before pasting the output into an issue.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runDebug,
 }
 
 func runDebug(cmd *cobra.Command, args []string) {
@@ -23,118 +28,145 @@ func runDebug(cmd *cobra.Command, args []string) {
 
 	issueNumber, err := strconv.Atoi(args[0])
 	if err != nil {
-		config.Die("Invalid issue number: %s", args[0])
+		config.Fail(errs.New(errs.KindUsage, "Invalid issue number: %s", args[0]))
 	}
 
-	fmt.Println("=== Configuration ===")
-	fmt.Printf("Verbose: %v\n", cfg.Verbose)
-	fmt.Printf("Projects Enabled: %v\n", cfg.GitHub.ProjectsEnabled)
-	fmt.Printf("Status Field Name: %s\n", cfg.GitHub.StatusFieldName)
-	fmt.Printf("Todo Value: %s\n", cfg.GitHub.TodoValue)
-	fmt.Printf("In Progress Value: %s\n", cfg.GitHub.InProgressValue)
-	fmt.Printf("In Review Value: %s\n", cfg.GitHub.InReviewValue)
-	fmt.Printf("Done Value: %s\n", cfg.GitHub.DoneValue)
-	fmt.Printf("Check Scopes: %v\n", cfg.GitHub.CheckScopes)
-	fmt.Println()
-
-	fmt.Println("=== GitHub CLI ===")
+	debugln("=== Configuration ===")
+	debugf("Verbose: %v\n", cfg.Verbose)
+	debugf("Projects Enabled: %v\n", cfg.GitHub.ProjectsEnabled)
+	debugf("Status Field Name: %s\n", cfg.GitHub.StatusFieldName)
+	debugf("Todo Value: %s\n", cfg.GitHub.TodoValue)
+	debugf("In Progress Value: %s\n", cfg.GitHub.InProgressValue)
+	debugf("In Review Value: %s\n", cfg.GitHub.InReviewValue)
+	debugf("Done Value: %s\n", cfg.GitHub.DoneValue)
+	debugf("Check Scopes: %v\n", cfg.GitHub.CheckScopes)
+	debugf("Missing Scopes: %s\n", cfg.GitHub.MissingScopes)
+	debugf("Headless: %v\n", config.Headless())
+	debugf("Project Owner: %s\n", cfg.GitHub.ProjectOwner)
+	debugf("Projects: %s\n", strings.Join(cfg.GitHub.Projects, ", "))
+	debugln()
+
+	debugln("=== GitHub CLI ===")
 	ghPath, err := exec.LookPath("gh")
 	if err != nil {
-		config.Die("gh CLI not found in PATH")
+		config.Fail(errs.NotFound("gh CLI not found in PATH"))
 	}
-	fmt.Printf("gh CLI Path: %s\n", ghPath)
+	debugf("gh CLI Path: %s\n", ghPath)
 
 	// Check auth status
-	authCmd := exec.Command("gh", "auth", "status")
+	authCmd := github.Command("auth", "status")
 	authOutput, _ := authCmd.CombinedOutput()
-	fmt.Printf("Auth Status:\n%s\n", string(authOutput))
+	debugf("Auth Status:\n%s\n", string(authOutput))
 
-	fmt.Println("=== Issue Information ===")
-	fmt.Printf("Testing with issue #%d\n\n", issueNumber)
+	debugln("=== Issue Information ===")
+	debugf("Testing with issue #%d\n\n", issueNumber)
 
 	// Get project items
-	fmt.Println("→ Getting project items...")
+	debugln("→ Getting project items...")
 	items, err := github.GetProjectItemsForIssue(issueNumber)
 	if err != nil {
-		config.Die("Failed to get project items: %v", err)
+		config.Fail(fmt.Errorf("failed to get project items: %w", err))
 	}
 
 	if len(items) == 0 {
 		config.Warn("Issue #%d is not in any GitHub Project!", issueNumber)
-		fmt.Println("\nTo fix this:")
-		fmt.Println("1. Go to your GitHub Project board")
-		fmt.Println("2. Add this issue to the project")
-		fmt.Println("3. Run this command again")
+		debugln("\nTo fix this:")
+		debugln("1. Go to your GitHub Project board")
+		debugln("2. Add this issue to the project")
+		debugln("3. Run this command again")
 		return
 	}
 
-	fmt.Printf("✓ Found issue in %d project(s)\n\n", len(items))
+	debugf("✓ Found issue in %d project(s)\n\n", len(items))
 
 	for i, item := range items {
-		fmt.Printf("=== Project %d ===\n", i+1)
-		fmt.Printf("Item ID: %s\n", item.ID)
-		fmt.Printf("Project ID: %s\n\n", item.ProjectID)
+		debugf("=== Project %d ===\n", i+1)
+		debugf("Item ID: %s\n", item.ID)
+		debugf("Project ID: %s\n", item.ProjectID)
+		debugf("Project: %s (#%d, owner %s)\n", item.ProjectTitle, item.ProjectNumber, item.ProjectOwner)
+		if !github.ProjectSelected(cfg.GitHub.Projects, item.ProjectTitle, item.ProjectNumber) {
+			config.Warn("Not in github.projects, status updates skip this project")
+		}
+		debugln()
 
 		// Get field information
-		fmt.Printf("→ Getting '%s' field...\n", cfg.GitHub.StatusFieldName)
+		debugf("→ Getting '%s' field...\n", cfg.GitHub.StatusFieldName)
 		field, err := github.GetProjectField(item.ProjectID, cfg.GitHub.StatusFieldName)
 		if err != nil {
 			config.Warn("Failed to get field: %v", err)
-			fmt.Println("\nAvailable fields in this project:")
-			listFieldsCmd := exec.Command("gh", "project", "field-list", item.ProjectID, "--format", "json")
+			debugln("\nAvailable fields in this project:")
+			listFieldsCmd := github.Command("project", "field-list", item.ProjectID, "--format", "json")
 			if output, err := listFieldsCmd.Output(); err == nil {
-				fmt.Printf("%s\n", string(output))
+				debugf("%s\n", string(output))
 			}
 			continue
 		}
 
-		fmt.Printf("✓ Field ID: %s\n", field.ID)
-		fmt.Printf("  Field Name: %s\n", field.Name)
-		fmt.Printf("  Available Options:\n")
+		debugf("✓ Field ID: %s\n", field.ID)
+		debugf("  Field Name: %s\n", field.Name)
+		debugf("  Available Options:\n")
 		for _, opt := range field.Options {
-			fmt.Printf("    - %s (ID: %s)\n", opt.Name, opt.ID)
+			debugf("    - %s (ID: %s)\n", opt.Name, opt.ID)
 		}
-		fmt.Println()
+		debugln()
 
 		// Check if the configured status values exist
-		fmt.Printf("→ Checking status values...\n")
+		debugf("→ Checking status values...\n")
 		if _, err := github.GetFieldOptionID(field, cfg.GitHub.TodoValue); err != nil {
 			config.Warn("'%s' not found in options!", cfg.GitHub.TodoValue)
 		} else {
-			fmt.Printf("✓ '%s' exists\n", cfg.GitHub.TodoValue)
+			debugf("✓ '%s' exists\n", cfg.GitHub.TodoValue)
 		}
 
 		if _, err := github.GetFieldOptionID(field, cfg.GitHub.InProgressValue); err != nil {
 			config.Warn("'%s' not found in options!", cfg.GitHub.InProgressValue)
 		} else {
-			fmt.Printf("✓ '%s' exists\n", cfg.GitHub.InProgressValue)
+			debugf("✓ '%s' exists\n", cfg.GitHub.InProgressValue)
 		}
 
 		if _, err := github.GetFieldOptionID(field, cfg.GitHub.InReviewValue); err != nil {
 			config.Warn("'%s' not found in options!", cfg.GitHub.InReviewValue)
 		} else {
-			fmt.Printf("✓ '%s' exists\n", cfg.GitHub.InReviewValue)
+			debugf("✓ '%s' exists\n", cfg.GitHub.InReviewValue)
 		}
 
 		if _, err := github.GetFieldOptionID(field, cfg.GitHub.DoneValue); err != nil {
 			config.Warn("'%s' not found in options!", cfg.GitHub.DoneValue)
 		} else {
-			fmt.Printf("✓ '%s' exists\n", cfg.GitHub.DoneValue)
+			debugf("✓ '%s' exists\n", cfg.GitHub.DoneValue)
 		}
-		fmt.Println()
+		debugln()
 	}
 
-	fmt.Println("=== Test Update ===")
-	fmt.Printf("Would you like to test updating issue #%d to '%s'? [y/N]: ", issueNumber, cfg.GitHub.InProgressValue)
+	debugln("=== Test Update ===")
+	debugf("Would you like to test updating issue #%d to '%s'? [y/N]: ", issueNumber, cfg.GitHub.InProgressValue)
 	var response string
 	fmt.Scanln(&response)
 	if response == "y" || response == "Y" {
-		fmt.Println("\n→ Updating issue status...")
-		if err := github.UpdateIssueStatus(issueNumber, cfg.GitHub.InProgressValue, cfg); err != nil {
-			config.Die("Update failed: %v", err)
+		debugln("\n→ Updating issue status...")
+		updates, err := github.UpdateIssueStatus(issueNumber, cfg.GitHub.InProgressValue, cfg)
+		for _, update := range updates {
+			if update.Err != nil {
+				debugf("  %s: %v\n", update.Project, update.Err)
+			} else {
+				debugf("  %s: updated and verified\n", update.Project)
+			}
+		}
+		if err != nil {
+			config.Fail(fmt.Errorf("update failed: %w", err))
 		}
 		config.Success("Issue #%d updated to '%s'", issueNumber, cfg.GitHub.InProgressValue)
 	} else {
-		fmt.Println("Skipped update test.")
+		debugln("Skipped update test.")
 	}
 }
+
+// debugf prints formatted debug output with sensitive values masked
+func debugf(format string, a ...interface{}) {
+	fmt.Print(config.Redact(fmt.Sprintf(format, a...)))
+}
+
+// debugln prints a line of debug output with sensitive values masked
+func debugln(a ...interface{}) {
+	fmt.Print(config.Redact(fmt.Sprintln(a...)))
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Show which worktrees need a dependency install",
+	Long: `Compare each worktree's lockfiles (Gemfile.lock, package-lock.json, go.sum, ...) with
+their state the last time dependencies were installed through gwi (activate or deps install).`,
+	Args: cobra.NoArgs,
+	Run:  runDeps,
+}
+
+var depsInstallCmd = &cobra.Command{
+	Use:   "install [issue-number]",
+	Short: "Install dependencies in a worktree",
+	Long: `Install dependencies by running the activate hook, or, when there is none, the
+standard tool for each lockfile found (bundle install, npm ci, go mod download, ...).`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDepsInstall,
+}
+
+func init() {
+	depsCmd.AddCommand(depsInstallCmd)
+}
+
+func runDeps(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	worktrees, err := git.ListWorktrees(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo))
+	if err != nil || len(worktrees) == 0 {
+		fmt.Println("No worktrees found.")
+		return
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	for _, wt := range worktrees {
+		name := filepath.Base(wt)
+		changed, known := deps.Changed(s, wt)
+		switch {
+		case !known:
+			fmt.Printf("  %s %s never installed via gwi\n", config.Yellow("?"), name)
+		case len(changed) > 0:
+			fmt.Printf("  %s %s out of date (%s)\n", config.Red(config.Glyph("dirty")), name, strings.Join(changed, ", "))
+		default:
+			fmt.Printf("  %s %s up to date\n", config.Green(config.Glyph("clean")), name)
+		}
+	}
+}
+
+func runDepsInstall(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	_, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+
+	if hooks.FindHook("activate", worktreePath, cfg, repoInfo) != "" {
+		if _, err := runHook("activate", worktreePath, cfg, repoInfo); err != nil {
+			config.Fail(errs.New(errs.KindGeneral, "Dependency install failed"))
+		}
+	} else {
+		commands := deps.InstallCommands(worktreePath)
+		if len(commands) == 0 {
+			config.Fail(errs.NotFound("No activate hook and no known lockfiles in %s", worktreePath))
+		}
+		for _, command := range commands {
+			config.Info("Running %s...", strings.Join(command, " "))
+			if err := deps.RunInstall(worktreePath, command); err != nil {
+				config.Fail(fmt.Errorf("%s failed: %w", command[0], err))
+			}
+		}
+	}
+
+	if err := deps.Record(worktreePath); err != nil {
+		config.Warn("Failed to record dependency state: %v", err)
+	}
+	config.Success("Dependencies installed.")
+}
+
+// depsIndicator returns a short marker when a worktree's lockfiles changed since the last install
+func depsIndicator(s *store.Store, worktreePath string) string {
+	if s == nil {
+		return ""
+	}
+	if changed, known := deps.Changed(s, worktreePath); known && len(changed) > 0 {
+		return " " + config.Yellow("deps out of date")
+	}
+	return ""
+}
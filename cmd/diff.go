@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [issue-number]",
+	Short: "Show the worktree's changes against main",
+	Long: `Show everything the worktree's branch changes: commits and uncommitted changes to
+tracked files since the merge base with origin/main. With --pr, show the pull request's diff as
+GitHub sees it instead, i.e. only what has been pushed.
+
+Set diff.tool (or GWI_DIFF_TOOL, or --tool) to "delta" or "difftastic" to view the
+diff with those tools instead of git's configured pager.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runDiff,
+}
+
+var (
+	diffStat     bool
+	diffNameOnly bool
+	diffPR       bool
+	diffTool     string
+)
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a diffstat instead of the patch")
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Show only the names of changed files")
+	diffCmd.Flags().BoolVar(&diffPR, "pr", false, "Show the diff of the pull request on GitHub")
+	diffCmd.Flags().StringVar(&diffTool, "tool", "", "Diff viewer: delta or difftastic (overrides diff.tool)")
+}
+
+// diffTools are the supported diff viewers and their executables
+var diffTools = map[string]string{
+	"delta":      "delta",
+	"difftastic": "difft",
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	if diffStat && diffNameOnly {
+		config.Fail(errs.New(errs.KindUsage, "--stat and --name-only can't be combined"))
+	}
+
+	tool := cfg.Diff.Tool
+	if diffTool != "" {
+		tool = diffTool
+	}
+	if tool != "" {
+		executable, ok := diffTools[tool]
+		if !ok {
+			config.Fail(errs.New(errs.KindUsage, "Unknown diff tool %q, use delta or difftastic", tool))
+		}
+		if _, err := exec.LookPath(executable); err != nil {
+			config.Warn("%s not found in PATH, using git's pager", executable)
+			tool = ""
+		}
+	}
+
+	_, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	if diffPR {
+		showPRDiff(repoInfo, filepath.Base(worktreePath), tool)
+		return
+	}
+
+	mainRef := git.RemoteBranchRef(cfg.MainBranch)
+	if mainRef == "" {
+		mainRef = cfg.MainBranch
+	}
+	base, err := git.MergeBase(worktreePath, mainRef, "HEAD")
+	if err != nil {
+		config.Fail(err)
+	}
+
+	var gitConfig, diffArgs []string
+	switch {
+	case diffStat:
+		diffArgs = append(diffArgs, "--stat")
+	case diffNameOnly:
+		diffArgs = append(diffArgs, "--name-only")
+	case tool == "delta":
+		gitConfig = append(gitConfig, "core.pager=delta")
+	case tool == "difftastic":
+		gitConfig = append(gitConfig, "diff.external=difft")
+		diffArgs = append(diffArgs, "--ext-diff")
+	}
+	if err := git.ShowDiff(worktreePath, base, gitConfig, diffArgs); err != nil {
+		config.Fail(fmt.Errorf("git diff failed: %w", err))
+	}
+}
+
+// showPRDiff shows the diff of the branch's pull request
+func showPRDiff(repoInfo *git.RepoInfo, branchName, tool string) {
+	prNumber, err := findPRForBranch(repoInfo, branchName)
+	if err != nil {
+		config.Fail(errs.NotFound("No pull request found for %s", branchName))
+	}
+
+	if diffStat {
+		patch, err := github.GetPRDiff(prNumber)
+		if err != nil {
+			config.Fail(err)
+		}
+		stat, err := git.PatchStat(patch)
+		if err != nil {
+			config.Fail(err)
+		}
+		fmt.Print(stat)
+		return
+	}
+
+	pager := ""
+	switch tool {
+	case "delta":
+		pager = "delta"
+	case "difftastic":
+		config.Warn("difftastic can't show PR diffs, using gh's pager")
+	}
+	if err := github.ShowPRDiff(prNumber, diffNameOnly, pager); err != nil {
+		config.Fail(fmt.Errorf("failed to show diff of PR #%d: %w", prNumber, err))
+	}
+}
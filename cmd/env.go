@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print GWI_* variables for the current worktree",
+	Long: `Print export statements for GWI_REPO, GWI_WORKTREE, GWI_BRANCH and GWI_ISSUE describing
+the worktree containing the current directory, or unset them outside of one.
+
+Shell integration sets these automatically when gwi changes directory. To set them
+manually, run: eval "$(gwi env)"`,
+	Args: cobra.NoArgs,
+	Run:  runEnv,
+}
+
+// envVars are the variables managed by gwi env, in output order
+var envVars = []string{"GWI_REPO", "GWI_WORKTREE", "GWI_BRANCH", "GWI_ISSUE"}
+
+func runEnv(cmd *cobra.Command, args []string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	// Fast path: this runs on every gwi cd, so prefer the cached index over git
+	repo := index.Lookup(cwd)
+	if repo == nil {
+		cfg := config.Load()
+		useRepoRemotes(cfg)
+		if repoInfo, err := git.GetRepoInfo(); err == nil {
+			index.Refresh(cfg, repoInfo)
+			repo = index.Lookup(cwd)
+		}
+	}
+
+	values := worktreeEnv(repo, cwd)
+	if values == nil {
+		fmt.Printf("unset %s\n", strings.Join(envVars, " "))
+		return
+	}
+	for _, name := range envVars {
+		if values[name] == "" {
+			fmt.Printf("unset %s\n", name)
+		} else {
+			fmt.Printf("export %s=%s\n", name, shellQuote(values[name]))
+		}
+	}
+}
+
+// worktreeEnv describes the worktree of repo that contains dir
+func worktreeEnv(repo *index.Repo, dir string) map[string]string {
+	if repo == nil {
+		return nil
+	}
+	values := map[string]string{"GWI_REPO": repo.Org + "/" + repo.Repo}
+
+	if rel, ok := strings.CutPrefix(dir, repo.Base+string(os.PathSeparator)); ok {
+		name := strings.Split(rel, string(os.PathSeparator))[0]
+		values["GWI_WORKTREE"] = filepath.Join(repo.Base, name)
+		values["GWI_BRANCH"] = name
+		if id, ok := issueid.FromName(name); ok {
+			values["GWI_ISSUE"] = string(id)
+		}
+		return values
+	}
+
+	if dir == repo.MainPath || strings.HasPrefix(dir, repo.MainPath+string(os.PathSeparator)) {
+		values["GWI_WORKTREE"] = repo.MainPath
+		values["GWI_BRANCH"] = headBranch(repo.MainPath)
+		return values
+	}
+	return nil
+}
+
+// headBranch reads the checked out branch of the main worktree without running git
+func headBranch(mainPath string) string {
+	data, err := os.ReadFile(filepath.Join(mainPath, ".git", "HEAD"))
+	if err != nil {
+		return ""
+	}
+	branch, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
+		return "" // Detached HEAD
+	}
+	return branch
+}
+
+// shellQuote quotes a value for safe use in sh-compatible shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
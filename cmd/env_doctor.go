@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var envDoctorCmd = &cobra.Command{
+	Use:   "doctor [hook]",
+	Short: "Check that the tools hooks need are available",
+	Long: `Check that the tools the worktree's hooks need, such as direnv, rvm or nvm, are
+available in the shell tmux runs them in. A hook declares them in a comment at the
+top of its script, or hook_requires lists them in the config:
+
+  # gwi-requires: direnv nvm
+
+Tools are looked up in an interactive login shell (tmux's default-shell or $SHELL),
+so shell functions and PATH changes of its startup files count. gwi up runs the same
+check for its hook before starting a session.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runEnvDoctor,
+}
+
+func init() {
+	envCmd.AddCommand(envDoctorCmd)
+}
+
+func runEnvDoctor(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, _ := git.GetRepoInfo()
+	cwd, _ := os.Getwd()
+
+	names := hooks.ListHooks(cwd, cfg, repoInfo)
+	if len(args) > 0 {
+		if hooks.FindHook(args[0], cwd, cfg, repoInfo) == "" {
+			config.Fail(errs.NotFound("No '%s' hook found", args[0]))
+		}
+		names = args
+	}
+	if len(names) == 0 {
+		config.Fail(errs.NotFound("No hooks found for this worktree"))
+	}
+
+	shell := hooks.TmuxShell()
+	fmt.Printf("Checking hooks in %s\n", shell)
+	missing := 0
+	for _, name := range names {
+		script := hooks.FindHook(name, cwd, cfg, repoInfo)
+		tools, err := hooks.Requirements(name, script, cfg, repoInfo)
+		if err != nil {
+			config.Fail(err)
+		}
+		fmt.Printf("\n%s %s\n", name, config.Dim("("+displayHookPath(cwd, script)+")"))
+		if len(tools) == 0 {
+			fmt.Printf("  %s\n", config.Dim("no tools declared"))
+			continue
+		}
+		absent, err := hooks.MissingTools(shell, tools)
+		if err != nil {
+			config.Fail(err)
+		}
+		for _, tool := range tools {
+			if slices.Contains(absent, tool) {
+				fmt.Printf("  %s %s %s\n", config.Red(config.Glyph("failure")), tool, config.Dim("not found"))
+				missing++
+			} else {
+				fmt.Printf("  %s %s\n", config.Green(config.Glyph("success")), tool)
+			}
+		}
+	}
+
+	if missing > 0 {
+		config.Fail(errs.NotFound("%d required tool(s) missing in %s. Install them, or load them in its startup files (tmux runs a login shell).", missing, filepath.Base(shell)))
+	}
+}
+
+// checkHookTools stops before a hook runs in tmux without the tools it needs,
+// which would otherwise leave a session that fails on its first line
+func checkHookTools(hookName, script string, cfg *config.Config, repoInfo *git.RepoInfo) error {
+	tools, err := hooks.Requirements(hookName, script, cfg, repoInfo)
+	if err != nil || len(tools) == 0 {
+		return err
+	}
+	shell := hooks.TmuxShell()
+	missing, err := hooks.MissingTools(shell, tools)
+	if err != nil {
+		config.Warn("Could not check the tools the %s hook needs: %v", hookName, err)
+		return nil
+	}
+	if len(missing) > 0 {
+		return errs.NotFound("The %s hook needs %s, not found in %s.\n\n  Install them, or load them in %s's startup files (tmux runs a login shell). Check all hooks with: gwi env doctor",
+			hookName, strings.Join(missing, ", "), shell, filepath.Base(shell))
+	}
+	return nil
+}
+
+// displayHookPath shortens a hook path inside the worktree to a relative one
+func displayHookPath(worktreePath, script string) string {
+	if rel, err := filepath.Rel(worktreePath, script); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return script
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var parkCmd = &cobra.Command{
+	Use:   "park [issue-number]",
+	Short: "Put an issue back on hold",
+	Long: `Park an issue you stopped working on: stash the worktree's uncommitted changes
+(restore them with 'gwi snapshot --pop'), move the issue back to Todo in GitHub
+Projects and stop counting it towards the WIP limit. The worktree is kept.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPark,
+}
+
+func runPark(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	id, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	parkIssue(cfg, repoInfo, githubIssueNumber(id, "park"), worktreePath)
+}
+
+// parkIssue stashes the work on an issue and moves it back to Todo
+func parkIssue(cfg *config.Config, repoInfo *git.RepoInfo, issueNumber int, worktreePath string) {
+	if git.HasUncommittedChanges(worktreePath) {
+		if err := takeSnapshot(cfg, worktreePath, filepath.Base(worktreePath), "parked", true); err != nil {
+			config.Fail(fmt.Errorf("failed to stash changes of issue #%d: %w", issueNumber, err))
+		}
+	}
+
+	if tracksStatus(cfg) {
+		if updates, err := updateIssueStatus(cfg, issueNumber, statusTodo); err != nil {
+			config.Warn("Failed to update project status: %v", err)
+		} else {
+			config.Info("Updated issue #%d to '%s' in %s", issueNumber, statusValue(cfg, statusTodo), trackerName(cfg))
+			printProjectUpdates(updates)
+		}
+	}
+
+	forgetActiveIssue(repoInfo, issueNumber)
+	config.Success("Parked issue #%d", issueNumber)
+}
+
+// checkWIPLimit enforces focus.wip_limit before starting work on issueNumber.
+// Over the limit it offers to park one of the issues worked on in this
+// repository, then warns or, with focus.block, refuses to continue.
+func checkWIPLimit(cfg *config.Config, repoInfo *git.RepoInfo, issueNumber int) {
+	limit := cfg.Focus.WIPLimit
+	if limit <= 0 {
+		return
+	}
+
+	var active []trackedIssue
+	for _, issue := range listActiveIssues() {
+		if issue.Org == repoInfo.Org && issue.Repo == repoInfo.Repo && issue.Number == issueNumber {
+			continue
+		}
+		active = append(active, issue)
+	}
+	if len(active) < limit {
+		return
+	}
+
+	config.Warn("WIP limit reached: %d of %d issues in progress", len(active), limit)
+	for _, issue := range active {
+		fmt.Fprintf(os.Stderr, "  %s/%s#%d %s %s\n", issue.Org, issue.Repo, issue.Number, issue.Title, config.Yellow("("+issue.where()+")"))
+	}
+
+	// Only issues with a worktree here can be parked: their changes get stashed
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	var options []tui.Option
+	for _, issue := range active {
+		if issue.Org != repoInfo.Org || issue.Repo != repoInfo.Repo {
+			continue
+		}
+		if git.FindWorktreeByIssue(base, issue.Number) == "" {
+			continue
+		}
+		options = append(options, tui.Option{
+			Label: fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+			Value: strconv.Itoa(issue.Number),
+		})
+	}
+
+	if len(options) > 0 {
+		options = append(options, tui.Option{Label: "Don't park anything", Value: "none"})
+		selected, err := tui.Select("Park an issue to make room?", options)
+		if n, convErr := strconv.Atoi(selected); err == nil && convErr == nil {
+			parkIssue(cfg, repoInfo, n, git.FindWorktreeByIssue(base, n))
+			return
+		}
+	}
+
+	if cfg.Focus.Block {
+		config.Fail(errs.Conflict("Finish or park an issue before starting #%d (gwi park <issue-number>)", issueNumber))
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Keep commits off the default branch in the main worktree",
+	Long: `Install git hooks that refuse commits and pushes on the default branch (and
+safety.protected_branches) in the main worktree, and suggest gwi create instead.
+Worktrees are unaffected.
+
+The hooks are installed into the repository's hooks directory, shared by all
+worktrees. Disable them for a repository without uninstalling with:
+
+  git config gwi.guard false
+
+A single commit can still be made with: git commit --no-verify`,
+	Args: cobra.NoArgs,
+	Run:  runGuardStatus,
+}
+
+var guardInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit and pre-push guard hooks",
+	Args:  cobra.NoArgs,
+	Run:   runGuardInstall,
+}
+
+var guardUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the guard hooks",
+	Args:  cobra.NoArgs,
+	Run:   runGuardUninstall,
+}
+
+// Internal command run by the guard hooks
+var internalGuardCmd = &cobra.Command{
+	Use:    "_guard <hook>",
+	Hidden: true,
+	Args:   cobra.MinimumNArgs(1),
+	Run:    runInternalGuard,
+}
+
+var guardInstallForce bool
+
+func init() {
+	guardInstallCmd.Flags().BoolVar(&guardInstallForce, "force", false, "Replace existing pre-commit and pre-push hooks")
+	guardCmd.AddCommand(guardInstallCmd)
+	guardCmd.AddCommand(guardUninstallCmd)
+}
+
+// guardRepoPath returns the main worktree of the current repository
+func guardRepoPath() string {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil || mainPath == "" {
+		config.Fail(errs.New(errs.KindUsage, "Not in a git repository"))
+	}
+	return mainPath
+}
+
+// guardDisabled reports whether the guard is switched off for the repository at path
+func guardDisabled(path string) bool {
+	return git.GetConfig(path, "gwi.guard") == "false"
+}
+
+func runGuardStatus(cmd *cobra.Command, args []string) {
+	mainPath := guardRepoPath()
+	statuses, err := hooks.GetGuardStatus(mainPath)
+	if err != nil {
+		config.Fail(err)
+	}
+	for _, status := range statuses {
+		switch {
+		case status.Installed:
+			fmt.Printf("%s: %s (%s)\n", status.Hook, config.Green("installed"), status.Path)
+		case status.Foreign:
+			fmt.Printf("%s: %s (%s)\n", status.Hook, config.Yellow("other hook"), status.Path)
+		default:
+			fmt.Printf("%s: not installed\n", status.Hook)
+		}
+	}
+	if guardDisabled(mainPath) {
+		config.Warn("The guard is disabled for this repository (git config gwi.guard false)")
+	}
+}
+
+func runGuardInstall(cmd *cobra.Command, args []string) {
+	mainPath := guardRepoPath()
+	statuses, err := hooks.InstallGuard(mainPath, guardInstallForce)
+	if err != nil {
+		config.Fail(errs.New(errs.KindConflict, "%v", err))
+	}
+	for _, status := range statuses {
+		config.Success("Installed %s guard in %s", status.Hook, status.Path)
+	}
+	if guardDisabled(mainPath) {
+		config.Warn("The guard is disabled for this repository. Enable it with: git config --unset gwi.guard")
+	}
+}
+
+func runGuardUninstall(cmd *cobra.Command, args []string) {
+	removed, err := hooks.UninstallGuard(guardRepoPath())
+	if err != nil {
+		config.Fail(err)
+	}
+	if removed == 0 {
+		config.Info("No guard hooks installed")
+		return
+	}
+	config.Success("Removed %d guard hook(s)", removed)
+}
+
+// runInternalGuard checks a commit or push from a guard hook; a non-zero exit
+// makes git abort it
+func runInternalGuard(cmd *cobra.Command, args []string) {
+	if os.Getenv("GWI_GUARD") == "0" || guardDisabled(".") {
+		return
+	}
+	// Hooks run in the top level of the worktree
+	cwd, err := os.Getwd()
+	if err != nil || !isMainWorktree(cwd) {
+		return
+	}
+	cfg := config.Load()
+
+	switch args[0] {
+	case "pre-commit":
+		branch, err := git.GetCurrentBranch(".")
+		if err != nil || !isProtectedBranch(cfg, branch) {
+			return
+		}
+		config.Fail(errs.Conflict("Refusing to commit on '%s' in the main worktree.\n\n"+
+			"  Start a worktree for your change with: gwi create <issue>\n"+
+			"  To commit here anyway: git commit --no-verify", branch))
+	case "pre-push":
+		// git passes the refs being pushed on stdin:
+		// <local ref> <local sha> <remote ref> <remote sha>
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 4 {
+				continue
+			}
+			branch := strings.TrimPrefix(fields[2], "refs/heads/")
+			if branch == fields[2] || !isProtectedBranch(cfg, branch) {
+				continue
+			}
+			config.Fail(errs.Conflict("Refusing to push '%s' from the main worktree.\n\n"+
+				"  Open a pull request from a worktree instead: gwi create <issue>, then gwi pr\n"+
+				"  To push anyway: git push --no-verify", branch))
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// worktreeHintTimeout bounds how long selectors wait for worktree details
+const worktreeHintTimeout = 1500 * time.Millisecond
+
+// worktreeHints returns a short status per worktree path for the selectors:
+// uncommitted changes, unpushed commits and the linked PR, e.g.
+// "● 3 changes, ↑2 unpushed, PR #12 open", after the issue's title when it is
+// cached. The worktrees are inspected concurrently; those not done within
+// worktreeHintTimeout only get the title, so a slow disk or GitHub never holds
+// up the picker. Large repositories skip the uncommitted changes.
+func worktreeHints(repoInfo *git.RepoInfo, paths []string) map[string]string {
+	meta, _ := store.Load()
+	scanChanges := len(paths) == 0 || !skipChangeScan(paths[0])
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		hints = make(map[string]string, len(paths))
+	)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			hint := worktreeHint(meta, repoInfo, path, scanChanges)
+			mu.Lock()
+			hints[path] = hint
+			mu.Unlock()
+		}(path)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(worktreeHintTimeout):
+	}
+
+	titles := index.IssueTitles(repoInfo.Org, repoInfo.Repo)
+	mu.Lock()
+	defer mu.Unlock()
+	result := make(map[string]string, len(hints))
+	for _, path := range paths {
+		hint := hints[path]
+		if id, ok := issueid.FromName(filepath.Base(path)); ok {
+			if n, ok := id.Number(); ok && titles[n] != "" {
+				hint = strings.TrimSuffix(titles[n]+" · "+hint, " · ")
+			}
+		}
+		if hint != "" {
+			result[path] = hint
+		}
+	}
+	return result
+}
+
+// worktreeHint describes the state of one worktree, empty when it is clean,
+// pushed and has no PR
+func worktreeHint(meta *store.Store, repoInfo *git.RepoInfo, path string, scanChanges bool) string {
+	var parts []string
+	if scanChanges {
+		if count := git.GetUncommittedCount(path); count == 1 {
+			parts = append(parts, config.Glyph("dirty")+" 1 change")
+		} else if count > 1 {
+			parts = append(parts, fmt.Sprintf("%s %d changes", config.Glyph("dirty"), count))
+		}
+	}
+	if count, err := git.CountUnpushed(path); err == nil && count > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d unpushed", config.Glyph("up"), count))
+	}
+	var link linkedPR
+	if meta != nil && meta.Get(prKey(repoInfo, filepath.Base(path)), &link) {
+		if state, err := github.GetPRState(link.Number); err == nil {
+			parts = append(parts, fmt.Sprintf("PR #%d %s", link.Number, strings.ToLower(state)))
+		} else {
+			parts = append(parts, fmt.Sprintf("PR #%d", link.Number))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/spf13/cobra"
+)
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List the built-in hook templates",
+	Long: `List the built-in hook templates for common stacks. gwi hooks add scaffolds a
+template's activate, up and down hooks in .gwi/ of the current worktree, ready to
+adjust and commit.`,
+	Args: cobra.NoArgs,
+	Run:  runHooksList,
+}
+
+var hooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the built-in hook templates",
+	Args:  cobra.NoArgs,
+	Run:   runHooksList,
+}
+
+var hooksAddCmd = &cobra.Command{
+	Use:   "add [template]",
+	Short: "Scaffold .gwi/activate, up and down from a template",
+	Long: `Write the activate, up and down hooks of a built-in template into .gwi/ of the
+current worktree. Without a template, the stack is detected from the files in the
+worktree (Gemfile and bin/rails, package.json, go.mod, pyproject.toml, ...).
+Existing hooks are kept unless --force is given.`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: templateNames(),
+	Run:       runHooksAdd,
+}
+
+var hooksAddForce bool
+
+func init() {
+	hooksAddCmd.Flags().BoolVar(&hooksAddForce, "force", false, "Replace existing hooks")
+	hooksCmd.AddCommand(hooksListCmd)
+	hooksCmd.AddCommand(hooksAddCmd)
+}
+
+// templateNames lists the names of the built-in hook templates
+func templateNames() []string {
+	names := make([]string, len(hooks.Templates))
+	for i, t := range hooks.Templates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func runHooksList(cmd *cobra.Command, args []string) {
+	detected := ""
+	if top, err := git.GetTopLevel("."); err == nil {
+		if t, ok := hooks.DetectTemplate(top); ok {
+			detected = t.Name
+		}
+	}
+	for _, t := range hooks.Templates {
+		line := fmt.Sprintf("  %-8s %s", t.Name, t.Description)
+		if t.Name == detected {
+			line += " " + config.Green("(detected)")
+		}
+		fmt.Println(line)
+	}
+}
+
+func runHooksAdd(cmd *cobra.Command, args []string) {
+	top, err := git.GetTopLevel(".")
+	if err != nil {
+		config.Fail(errs.New(errs.KindUsage, "Not in a git repository"))
+	}
+
+	var template *hooks.Template
+	if len(args) == 1 {
+		t, ok := hooks.FindTemplate(args[0])
+		if !ok {
+			config.Fail(errs.New(errs.KindUsage, "Unknown template '%s'. Available: %s", args[0], strings.Join(templateNames(), ", ")))
+		}
+		template = t
+	} else {
+		t, ok := hooks.DetectTemplate(top)
+		if !ok {
+			config.Fail(errs.New(errs.KindUsage, "Could not detect the stack. Pick a template: gwi hooks add <%s>", strings.Join(templateNames(), "|")))
+		}
+		config.Info("Detected %s", t.Name)
+		template = t
+	}
+
+	written, skipped, err := hooks.WriteTemplate(*template, top, hooksAddForce)
+	for _, path := range written {
+		config.Success("Wrote %s", path)
+	}
+	if err != nil {
+		config.Fail(err)
+	}
+	for _, path := range skipped {
+		config.Warn("Kept existing %s (use --force to replace it)", path)
+	}
+	if len(written) > 0 {
+		fmt.Fprintln(os.Stderr, "Review the hooks, then commit .gwi/ to share them with your team.")
+	}
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -9,59 +11,129 @@ import (
 var initCmd = &cobra.Command{
 	Use:   "init [shell]",
 	Short: "Output shell integration code",
-	Long:  `Output shell integration code for zsh or bash. Add to your shell config with: eval "$(gwi init zsh)"`,
-	Args:  cobra.MaximumNArgs(1),
-	Run:   runInit,
+	Long: `Output shell integration code for zsh or bash. Add to your shell config with: eval "$(gwi init zsh)"
+
+With --startup-check, new interactive shells report worktrees whose PR was merged
+or whose issue was closed (at most once a day) and offer to remove them.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runInit,
+}
+
+var initStartupCheck bool
+
+func init() {
+	initCmd.Flags().BoolVar(&initStartupCheck, "startup-check", false, "Offer to clean up finished worktrees when a shell starts (once a day)")
 }
 
+// The shell integration learns which directory to change to over a file
+// descriptor of its own: it runs gwi with GWI_CD_FD=3 and fd 3 redirected to a
+// temporary file, and gwi writes the directory there, NUL-terminated. Nothing
+// else writes to it, so hook output and messages can't be mistaken for a
+// directory. Integration scripts from before don't set GWI_CD_FD and read stdout
+// instead: the path itself from the internal commands (_cd, _create, ...), a
+// __GWI_CD_TO__: line from the others.
+
+// cdFile is the descriptor named by GWI_CD_FD, or nil
+var cdFile = openCdFile()
+
+func openCdFile() *os.File {
+	fd, err := strconv.Atoi(os.Getenv("GWI_CD_FD"))
+	// Not for hooks and the commands gwi runs, which don't get the descriptor
+	os.Unsetenv("GWI_CD_FD")
+	if err != nil || fd < 3 {
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "gwi-cd")
+}
+
+// printCdPath reports the directory an internal command resolved for the
+// shell integration to change to
+func printCdPath(path string) {
+	if cdFile != nil {
+		fmt.Fprintf(cdFile, "%s\x00", path)
+		return
+	}
+	fmt.Println(path)
+}
+
+// printCdTo asks the shell integration to change to path once the command is done
+func printCdTo(path string) {
+	if cdFile != nil {
+		fmt.Fprintf(cdFile, "%s\x00", path)
+		return
+	}
+	fmt.Printf("__GWI_CD_TO__:%s\n", path)
+}
+
+const startupCheckIntegration = `
+# Offer to clean up worktrees of merged PRs and closed issues, at most once a day
+[[ -t 0 && -t 2 ]] && command gwi _startup-check`
+
 const shellIntegration = `# gwi - Git Worktree Issue CLI shell integration
-gwi() {
-  if [[ "$1" == "cd" ]]; then
-    shift
-    local path=$(command gwi _cd "$@")
-    if [[ -d "$path" ]]; then
-      cd "$path"
-      [[ "${GWI_AUTO_ACTIVATE:-0}" == "1" ]] && command gwi activate 2>/dev/null
-    else
-      echo "Not found" >&2
-    fi
-  elif [[ "$1" == "main" ]]; then
-    local path=$(command gwi _main)
-    [[ -d "$path" ]] && cd "$path" || echo "Not found" >&2
-  elif [[ "$1" == "list" ]]; then
-    local path=$(command gwi _list)
-    if [[ -n "$path" && -d "$path" ]]; then
-      cd "$path"
-      [[ "${GWI_AUTO_ACTIVATE:-0}" == "1" ]] && command gwi activate 2>/dev/null
-    fi
-  elif [[ "$1" == "start" ]]; then
-    local path=$(command gwi _start)
-    if [[ -n "$path" && -d "$path" ]]; then
-      cd "$path"
-      [[ "${GWI_AUTO_ACTIVATE:-0}" == "1" ]] && command gwi activate 2>/dev/null
-    fi
-  elif [[ "$1" == "create" ]]; then
-    local path=$(command gwi _create "${@:2}")
-    if [[ -n "$path" && -d "$path" ]]; then
-      cd "$path"
-      [[ "${GWI_AUTO_ACTIVATE:-0}" == "1" ]] && command gwi activate 2>/dev/null
-    fi
-  elif [[ "$1" == "rm" ]]; then
-    local output=$(command gwi rm "${@:2}")
-    echo "$output" | grep -v "^__GWI_CD_TO__:"
-    local cd_path=$(echo "$output" | grep "^__GWI_CD_TO__:" | sed 's/^__GWI_CD_TO__://')
-    [[ -n "$cd_path" && -d "$cd_path" ]] && cd "$cd_path"
-  elif [[ "$1" == "merge" ]]; then
-    local output=$(command gwi merge "${@:2}")
-    echo "$output" | grep -v "^__GWI_CD_TO__:"
-    local cd_path=$(echo "$output" | grep "^__GWI_CD_TO__:" | sed 's/^__GWI_CD_TO__://')
-    [[ -n "$cd_path" && -d "$cd_path" ]] && cd "$cd_path"
-  else
-    command gwi "$@"
+
+# Export GWI_REPO, GWI_WORKTREE, GWI_BRANCH and GWI_ISSUE for the current worktree
+_gwi_env() {
+  eval "$(command gwi env 2>/dev/null)"
+}
+
+# Clear the GWI_* variables once the shell leaves the worktree they describe
+_gwi_chpwd() {
+  if [[ -n "$GWI_WORKTREE" && "$PWD" != "$GWI_WORKTREE" && "$PWD" != "$GWI_WORKTREE"/* ]]; then
+    unset GWI_REPO GWI_WORKTREE GWI_BRANCH GWI_ISSUE
+  fi
+}
+if [[ -n "$ZSH_VERSION" ]]; then
+  autoload -Uz add-zsh-hook && add-zsh-hook chpwd _gwi_chpwd
+elif [[ "$PROMPT_COMMAND" != *_gwi_chpwd* ]]; then
+  PROMPT_COMMAND="_gwi_chpwd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+
+# Run gwi and change to the directory it reports on fd 3, NUL-terminated (the
+# last one wins), keeping its exit status
+_gwi_run() {
+  local tmp dir= entry rc
+  tmp=$(mktemp "${TMPDIR:-/tmp}/gwi-cd.XXXXXX") || return 1
+  GWI_CD_FD=3 command gwi "$@" 3>"$tmp"
+  rc=$?
+  while IFS= read -r -d '' entry; do dir=$entry; done <"$tmp"
+  rm -f "$tmp"
+  if [[ -n "$dir" && -d "$dir" ]]; then
+    cd "$dir" && _gwi_env || return 1
   fi
+  return $rc
+}
+
+# Run the activate hook in a new worktree with GWI_AUTO_ACTIVATE=1
+_gwi_activate() {
+  [[ "${GWI_AUTO_ACTIVATE:-0}" == "1" && "$PWD" != "$1" ]] && command gwi activate 2>/dev/null
+  return 0
+}
+
+gwi() {
+  local before=$PWD
+  case "$1" in
+    cd|list|start|create)
+      local internal="_$1"
+      shift
+      _gwi_run "$internal" "$@" && _gwi_activate "$before"
+      ;;
+    main)
+      shift
+      _gwi_run _main "$@"
+      ;;
+    rm|undo|archive|unarchive|takeover|resume|issues|board|merge)
+      _gwi_run "$@"
+      ;;
+    *)
+      command gwi "$@"
+      ;;
+  esac
 }`
 
 func runInit(cmd *cobra.Command, args []string) {
 	// Shell type doesn't matter - we output the same for both zsh and bash
 	fmt.Println(shellIntegration)
+	if initStartupCheck {
+		fmt.Println(startupCheckIntegration)
+	}
 }
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	issuesLimit int
+	issuesList  bool
+)
+
+var issuesCmd = &cobra.Command{
+	Use:   "issues",
+	Short: "Work with the repository's issues",
+}
+
+var issuesMineCmd = &cobra.Command{
+	Use:   "mine",
+	Short: "Triage the open issues assigned to or mentioning you",
+	Long: `List the repository's open issues assigned to you or mentioning you, most
+recently updated first, with the issue shown next to the list. Pick an issue to
+act on it:
+
+  enter    cd into its worktree, creating it if needed
+  ctrl-o   comment on it
+  ctrl-u   unsubscribe from its notifications
+  ctrl-s   change its status
+
+Without fzf, the issue and then the action are picked from numbered lists. Use
+--list to only print the issues.`,
+	Args: cobra.NoArgs,
+	RunE: runIssuesMine,
+}
+
+func init() {
+	issuesMineCmd.Flags().IntVarP(&issuesLimit, "limit", "n", 50, "Maximum issues fetched per filter (assigned, mentioned)")
+	issuesMineCmd.Flags().BoolVarP(&issuesList, "list", "l", false, "Print the issues instead of selecting one")
+	issuesCmd.AddCommand(issuesMineCmd)
+}
+
+// Actions of the issues inbox
+const (
+	issueActionWorktree    = "worktree"
+	issueActionComment     = "comment"
+	issueActionUnsubscribe = "unsubscribe"
+	issueActionStatus      = "status"
+)
+
+func runIssuesMine(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return err
+	}
+	if _, ok := azureBoards(cfg); ok {
+		return errs.New(errs.KindUsage, "gwi issues mine lists GitHub issues and is not supported with Azure Boards")
+	}
+	if err := github.CheckAuth(); err != nil {
+		return err
+	}
+
+	issues, err := github.ListMyIssues(issuesLimit)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		config.Success("No open issues assigned to or mentioning you")
+		return nil
+	}
+	me, _ := github.CurrentUser()
+	existing := getExistingWorktreeIssues(cfg, repoInfo)
+
+	if issuesList {
+		for _, issue := range issues {
+			fmt.Printf("#%d\t%s\t%s\t%s\n", issue.Number, issueInvolvement(issue, me), issueUpdated(issue), issue.Title)
+		}
+		return nil
+	}
+
+	// stdout is captured by the shell integration, so the inbox stays on stderr
+	// until an issue's worktree is chosen
+	actions := []tui.Action{
+		{Key: "ctrl-w", Name: issueActionWorktree},
+		{Key: "ctrl-o", Name: issueActionComment},
+		{Key: "ctrl-u", Name: issueActionUnsubscribe},
+		{Key: "ctrl-s", Name: issueActionStatus},
+	}
+	header := fmt.Sprintf("Your issues (%s/%s)", repoInfo.Org, repoInfo.Repo)
+	for {
+		var options []tui.Option
+		for _, issue := range issues {
+			hint := ""
+			if existing[issue.Number] {
+				hint = "worktree"
+			}
+			options = append(options, tui.Option{
+				Label:  fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+				Value:  strconv.Itoa(issue.Number),
+				Hint:   hint,
+				Detail: issueInvolvement(issue, me) + " · " + issueUpdated(issue),
+				Labels: tuiLabels(issue.Labels),
+			})
+		}
+
+		action, selected, err := tui.SelectAction(header, options, github.ShellCommand("issue view {}"), actions)
+		if err != nil {
+			return nil
+		}
+		issueNumber, _ := strconv.Atoi(selected)
+
+		switch action {
+		case issueActionWorktree:
+			if existing[issueNumber] {
+				base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+				printCdTo(git.FindWorktreeByIssue(base, issueNumber))
+				return nil
+			}
+			createWorktree(cfg, repoInfo, issueNumber, false)
+			return nil
+		case issueActionComment:
+			comment := promptLine(fmt.Sprintf("Comment on #%d (empty to cancel): ", issueNumber))
+			if comment == "" {
+				continue
+			}
+			if err := github.CommentOnIssue(issueNumber, comment); err != nil {
+				config.Error("Failed to comment on #%d: %v", issueNumber, err)
+				continue
+			}
+			config.Success("Commented on #%d", issueNumber)
+		case issueActionUnsubscribe:
+			if err := github.UnsubscribeFromIssue(repoInfo.Org, repoInfo.Repo, issueNumber); err != nil {
+				config.Error("%v", err)
+				continue
+			}
+			config.Success("Unsubscribed from #%d", issueNumber)
+		case issueActionStatus:
+			changeIssueStatus(cfg, issueNumber)
+		}
+	}
+}
+
+// changeIssueStatus lets the user pick a new status for an issue
+func changeIssueStatus(cfg *config.Config, issueNumber int) {
+	if !tracksStatus(cfg) {
+		config.Warn("Issue statuses are not tracked; enable github.projects_enabled")
+		return
+	}
+	var options []tui.Option
+	for _, status := range []issueStatus{statusTodo, statusInProgress, statusInReview, statusDone, statusPaused} {
+		value := statusValue(cfg, status)
+		if value == "" {
+			continue
+		}
+		options = append(options, tui.Option{Label: value, Value: strconv.Itoa(int(status))})
+	}
+	selected, err := tui.Select(fmt.Sprintf("Move #%d to", issueNumber), options)
+	if err != nil {
+		return
+	}
+	n, _ := strconv.Atoi(selected)
+	status := issueStatus(n)
+	updates, err := updateIssueStatus(cfg, issueNumber, status)
+	if err != nil {
+		config.Error("Failed to update #%d: %v", issueNumber, err)
+		printProjectUpdates(updates)
+		return
+	}
+	config.Success("Moved #%d to '%s'", issueNumber, statusValue(cfg, status))
+	printProjectUpdates(updates)
+}
+
+// issueInvolvement tells why an issue is in your inbox
+func issueInvolvement(issue github.Issue, me string) string {
+	if me != "" && issue.IsAssignedTo(me) {
+		return "assigned"
+	}
+	return "mentioned"
+}
+
+// issueUpdated tells when an issue was last updated
+func issueUpdated(issue github.Issue) string {
+	return "updated " + config.FormatTime(issue.UpdatedAt)
+}
+
+// promptLine asks for a line of text on stderr
+func promptLine(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	input, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(input)
+}
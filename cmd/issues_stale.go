@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	staleDays int
+	staleList bool
+)
+
+var issuesStaleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "Nudge In Progress issues without recent commits",
+	Long: `List the repository's issues that are In Progress on the GitHub Project board
+but whose branch has had no commits for --days days, least recently active
+first. Activity is taken from the local worktree, or else from the pushed
+branch; issues without either are listed as having no branch. Pick an issue
+to act on it:
+
+  enter    ask the assignees for a status update in a comment
+  ctrl-t   move it back to Todo
+  ctrl-a   archive its worktree (see gwi archive)
+
+Without fzf, the issue and then the action are picked from numbered lists. Use
+--list to only print the issues.`,
+	Args: cobra.NoArgs,
+	RunE: runIssuesStale,
+}
+
+func init() {
+	issuesStaleCmd.Flags().IntVarP(&staleDays, "days", "d", 14, "Days without commits before an issue is stale")
+	issuesStaleCmd.Flags().BoolVarP(&staleList, "list", "l", false, "Print the stale issues instead of selecting one")
+	issuesCmd.AddCommand(issuesStaleCmd)
+}
+
+// Actions on stale issues
+const (
+	staleActionNudge   = "request status"
+	staleActionTodo    = "move to todo"
+	staleActionArchive = "archive worktree"
+)
+
+// staleIssue is an In Progress issue without recent commits
+type staleIssue struct {
+	github.BoardItem
+	worktree string    // Local worktree, if any
+	branch   string    // Local or pushed branch, if any
+	activity time.Time // Last activity on the branch; zero without a branch
+}
+
+// idle describes how long the issue has been without activity
+func (s staleIssue) idle() string {
+	if s.branch == "" {
+		return "no branch"
+	}
+	return "idle " + formatIdle(time.Since(s.activity))
+}
+
+func runIssuesStale(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return err
+	}
+	if _, ok := azureBoards(cfg); ok {
+		return errs.New(errs.KindUsage, "gwi issues stale reads the GitHub Project board and is not supported with Azure Boards")
+	}
+	if !cfg.GitHub.ProjectsEnabled {
+		return errs.New(errs.KindUsage, "gwi issues stale needs the GitHub Project board; enable github.projects_enabled")
+	}
+	if staleDays < 1 {
+		return errs.New(errs.KindUsage, "--days must be at least 1")
+	}
+	if err := github.CheckAuth(); err != nil {
+		return err
+	}
+
+	project, err := resolveBoardProject(cfg, repoInfo.Org, repoInfo.Repo)
+	if err != nil {
+		return err
+	}
+	items, err := github.ListProjectIssues(project.ID, cfg.GitHub.StatusFieldName)
+	if err != nil {
+		return err
+	}
+
+	// Pushed branches are only as fresh as the last fetch
+	if err := git.Fetch(); err != nil {
+		config.Warn("Failed to fetch, pushed branches may be stale: %v", err)
+	}
+	stale := findStaleIssues(cfg, repoInfo, items, time.Duration(staleDays)*24*time.Hour)
+	if len(stale) == 0 {
+		config.Success("No In Progress issues without commits for %d days", staleDays)
+		return nil
+	}
+
+	if staleList {
+		for _, issue := range stale {
+			fmt.Printf("#%d\t%s\t%s\n", issue.Number, issue.idle(), issue.Title)
+		}
+		return nil
+	}
+
+	actions := []tui.Action{
+		{Key: "ctrl-o", Name: staleActionNudge},
+		{Key: "ctrl-t", Name: staleActionTodo},
+		{Key: "ctrl-a", Name: staleActionArchive},
+	}
+	header := fmt.Sprintf("In Progress without commits for %d days (%s/%s)", staleDays, repoInfo.Org, repoInfo.Repo)
+	for len(stale) > 0 {
+		var options []tui.Option
+		for _, issue := range stale {
+			hint := ""
+			if issue.worktree != "" {
+				hint = "worktree"
+			}
+			options = append(options, tui.Option{
+				Label:  fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+				Value:  strconv.Itoa(issue.Number),
+				Hint:   hint,
+				Detail: issue.idle(),
+			})
+		}
+
+		action, selected, err := tui.SelectAction(header, options, github.ShellCommand("issue view {}"), actions)
+		if err != nil {
+			return nil
+		}
+		i := slices.IndexFunc(stale, func(s staleIssue) bool { return strconv.Itoa(s.Number) == selected })
+		issue := stale[i]
+
+		switch action {
+		case staleActionNudge:
+			if err := requestIssueStatus(cfg, issue); err != nil {
+				config.Error("Failed to comment on #%d: %v", issue.Number, err)
+				continue
+			}
+			config.Success("Asked for a status update on #%d", issue.Number)
+		case staleActionTodo:
+			updates, err := updateIssueStatus(cfg, issue.Number, statusTodo)
+			if err != nil {
+				config.Error("Failed to update #%d: %v", issue.Number, err)
+				printProjectUpdates(updates)
+				continue
+			}
+			config.Success("Moved #%d back to '%s'", issue.Number, cfg.GitHub.TodoValue)
+			printProjectUpdates(updates)
+			stale = append(stale[:i], stale[i+1:]...)
+		case staleActionArchive:
+			if issue.worktree == "" {
+				config.Warn("#%d has no worktree on this machine", issue.Number)
+				continue
+			}
+			archiveWorktree(cfg, repoInfo, issueid.ID(strconv.Itoa(issue.Number)), issue.worktree)
+			stale[i].worktree = ""
+		}
+	}
+	return nil
+}
+
+// findStaleIssues returns the repository's In Progress issues whose branch has
+// been idle for longer than maxIdle, least recently active first
+func findStaleIssues(cfg *config.Config, repoInfo *git.RepoInfo, items []github.BoardItem, maxIdle time.Duration) []staleIssue {
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	mainWorktree, _ := git.GetMainWorktreePath()
+	repoName := repoInfo.Org + "/" + repoInfo.Repo
+
+	var stale []staleIssue
+	for _, item := range items {
+		if !strings.EqualFold(item.Repo, repoName) || !strings.EqualFold(item.Status, cfg.GitHub.InProgressValue) || item.State == "CLOSED" {
+			continue
+		}
+		issue := staleIssue{BoardItem: item}
+		if path := git.FindWorktreeByIssue(base, item.Number); path != "" {
+			issue.worktree, issue.branch = path, filepath.Base(path)
+			issue.activity = git.LastActivity(path)
+		} else if branches := git.RemoteBranchesWithPrefix(fmt.Sprintf("%d-", item.Number)); len(branches) > 0 {
+			issue.branch = branches[0]
+			issue.activity, _ = git.CommitTime(mainWorktree, git.RemoteBranchRef(issue.branch))
+		}
+		if issue.branch != "" && time.Since(issue.activity) <= maxIdle {
+			continue
+		}
+		stale = append(stale, issue)
+	}
+
+	// Issues without a branch first, then the longest idle
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].activity.Before(stale[j].activity)
+	})
+	return stale
+}
+
+// requestIssueStatus comments on a stale issue, asking its assignees whether
+// work on it continues
+func requestIssueStatus(cfg *config.Config, issue staleIssue) error {
+	var mentions []string
+	if details, err := getIssue(cfg, issue.Number); err == nil {
+		for _, assignee := range details.Assignees {
+			mentions = append(mentions, "@"+assignee.Login)
+		}
+	}
+
+	var comment strings.Builder
+	if len(mentions) > 0 {
+		comment.WriteString(strings.Join(mentions, " ") + " ")
+	}
+	if issue.branch == "" {
+		comment.WriteString("This issue is In Progress, but no branch has been pushed for it yet.")
+	} else {
+		fmt.Fprintf(&comment, "This issue is In Progress, but `%s` has had no commits for %s.", issue.branch, formatIdle(time.Since(issue.activity)))
+	}
+	comment.WriteString(" Is it still being worked on? If not, please move it back to Todo so someone else can pick it up.")
+	return commentOnIssue(cfg, issue.Number, comment.String())
+}
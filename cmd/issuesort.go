@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// issueSorts are the orders the issue selector can list issues in
+var issueSorts = []string{"newest", "oldest", "updated", "reactions", "comments", "priority"}
+
+// issueSort returns the order of the issue selector, from --sort or
+// create.sort, failing when it is unknown
+func issueSort(cfg *config.Config) (string, error) {
+	order := cfg.Create.Sort
+	if createSort != "" {
+		order = createSort
+	}
+	order = strings.ToLower(order)
+	if !slices.Contains(issueSorts, order) {
+		return "", errs.New(errs.KindUsage, "Unknown issue sort %q; use one of: %s", order, strings.Join(issueSorts, ", "))
+	}
+	if order == "priority" && len(cfg.Create.PriorityLabels) == 0 {
+		return "", errs.New(errs.KindUsage, "Sorting by priority needs create.priority_labels, e.g. [P0, P1, P2]")
+	}
+	return order, nil
+}
+
+// sortIssues orders issues for the selector. GitHub already lists them in
+// order, except by priority; sorting again keeps issues that tie newest first.
+func sortIssues(cfg *config.Config, issues []github.Issue, order string) {
+	slices.SortStableFunc(issues, func(a, b github.Issue) int { return b.Number - a.Number })
+	switch order {
+	case "oldest":
+		slices.Reverse(issues)
+	case "updated":
+		slices.SortStableFunc(issues, func(a, b github.Issue) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	case "reactions":
+		slices.SortStableFunc(issues, func(a, b github.Issue) int { return b.ThumbsUp - a.ThumbsUp })
+	case "comments":
+		slices.SortStableFunc(issues, func(a, b github.Issue) int { return b.Comments - a.Comments })
+	case "priority":
+		rank := func(issue github.Issue) int {
+			for i, name := range cfg.Create.PriorityLabels {
+				if slices.ContainsFunc(issue.Labels, func(label github.Label) bool { return strings.EqualFold(label.Name, name) }) {
+					return i
+				}
+			}
+			return len(cfg.Create.PriorityLabels)
+		}
+		slices.SortStableFunc(issues, func(a, b github.Issue) int { return rank(a) - rank(b) })
+	}
+}
+
+// issueBadge shows how much attention an issue gets: its 👍 reactions and
+// comments, leaving out counts of zero
+func issueBadge(issue github.Issue) string {
+	var parts []string
+	if issue.ThumbsUp > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", config.Glyph("reactions"), issue.ThumbsUp))
+	}
+	if issue.Comments > 0 {
+		parts = append(parts, fmt.Sprintf("%s%d", config.Glyph("comments"), issue.Comments))
+	}
+	return strings.Join(parts, " ")
+}
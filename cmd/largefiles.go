@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+const megabyte = 1 << 20
+
+// githubFileLimit is the size above which GitHub rejects a pushed file
+const githubFileLimit = 100 * megabyte
+
+// guardLargeFiles checks the unpushed commits of the worktree for files of
+// large_files.max_mb or more that aren't in Git LFS. With git lfs installed
+// they can be migrated to LFS, which rewrites the unpushed commits; otherwise
+// gwi warns, or refuses to push with large_files.block or when GitHub would
+// reject a file.
+func guardLargeFiles(cfg *config.Config, worktreePath string, migrate bool) {
+	if cfg.LargeFiles.MaxMB <= 0 {
+		return
+	}
+	files, err := git.LargeUnpushedFiles(worktreePath, int64(cfg.LargeFiles.MaxMB)*megabyte)
+	if err != nil {
+		config.Warn("Could not check for large files: %v", err)
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	config.Warn("%d file(s) of %dMB or more aren't in Git LFS:", len(files), cfg.LargeFiles.MaxMB)
+	printLargeFiles(files)
+
+	if git.HasLFS() && (migrate || confirmPrompt("Migrate them to Git LFS? This rewrites the unpushed commits")) {
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		config.Info("Migrating to Git LFS: %s", strings.Join(paths, ", "))
+		if err := git.MigrateToLFS(worktreePath, paths); err != nil {
+			config.Fail(fmt.Errorf("failed to migrate to Git LFS: %w", err))
+		}
+		if files, err = git.LargeUnpushedFiles(worktreePath, int64(cfg.LargeFiles.MaxMB)*megabyte); err != nil || len(files) == 0 {
+			config.Success("Moved %d file(s) to Git LFS", len(paths))
+			return
+		}
+		config.Warn("Still not in Git LFS:")
+		printLargeFiles(files)
+	} else if migrate {
+		config.Fail(errs.New(errs.KindUsage, "--lfs-migrate needs Git LFS; install it from https://git-lfs.com"))
+	}
+
+	rejected := files[0].Size > githubFileLimit
+	if !cfg.LargeFiles.Block && !rejected {
+		return
+	}
+	reason := "large_files.block is set"
+	if rejected {
+		reason = "GitHub rejects files over 100MB"
+	}
+	config.Fail(errs.Conflict("Refusing to push: %s.\n\n  Store them in Git LFS with: gwi pr --lfs-migrate\n  Or remove them from the branch's commits and run gwi pr again.", reason))
+}
+
+// printLargeFiles lists files with their sizes, largest first
+func printLargeFiles(files []git.LargeFile) {
+	for _, file := range files {
+		fmt.Fprintf(os.Stderr, "  %8s  %s\n", formatMB(file.Size), file.Path)
+	}
+}
+
+// formatMB renders a size in bytes as megabytes, e.g. 12.5MB
+func formatMB(size int64) string {
+	return fmt.Sprintf("%.1fMB", float64(size)/megabyte)
+}
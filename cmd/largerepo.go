@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// Large repository mode is for repositories where git status takes seconds.
+// It is enabled per repository with git config gwi.largeRepo:
+//
+//	true       don't scan worktrees for uncommitted changes in gwi status,
+//	           the selectors and the status summary, and enable git's file
+//	           system monitor in new worktrees
+//	fsmonitor  only enable the file system monitor in new worktrees, which
+//	           usually makes the scans fast enough to keep
+func largeRepoMode(path string) string {
+	return strings.ToLower(git.GetConfig(path, "gwi.largeRepo"))
+}
+
+// skipChangeScan reports whether uncommitted changes of the repository at
+// path are left unchecked
+func skipChangeScan(path string) bool {
+	return largeRepoMode(path) == "true"
+}
+
+// enableFSMonitor speeds up git status in a new worktree of a large
+// repository with the untracked cache and, where git has one, the builtin
+// file system monitor (macOS and Windows only)
+func enableFSMonitor(worktreePath string, silent bool) {
+	if mode := largeRepoMode(worktreePath); mode != "true" && mode != "fsmonitor" {
+		return
+	}
+	settings := [][2]string{{"core.untrackedCache", "true"}}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		settings = append(settings, [2]string{"core.fsmonitor", "true"})
+	}
+	var keys []string
+	for _, setting := range settings {
+		if err := git.SetWorktreeConfig(worktreePath, setting[0], setting[1]); err != nil {
+			config.Warn("Failed to set %s: %v", setting[0], err)
+			return
+		}
+		keys = append(keys, setting[0])
+	}
+	if !silent {
+		config.Info("Large repository: set %s", strings.Join(keys, ", "))
+	}
+}
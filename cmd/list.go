@@ -6,7 +6,11 @@ import (
 	"strconv"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/store"
 	"github.com/enterprisemodules/gwi/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -32,7 +36,7 @@ func runListDisplay() {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
 	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
@@ -47,54 +51,70 @@ func runListDisplay() {
 	}
 
 	// Show issue worktrees
+	meta, _ := store.Load()
+	labels, _ := github.ListIssueLabels(200)
 	worktrees, _ := git.ListWorktrees(base)
 	for _, wt := range worktrees {
-		fmt.Printf("  %s\n", filepath.Base(wt))
+		fmt.Printf("  %s%s%s\n", filepath.Base(wt), worktreeLabels(labels, wt), depsIndicator(meta, wt))
 	}
 }
 
 func runInternalList(cmd *cobra.Command, args []string) {
-	cfg := config.Load()
-	repoInfo, err := git.GetRepoInfo()
-	if err != nil {
-		config.Die("%v", err)
-	}
+	var org, repo, mainPath string
+	var worktrees []string
+
+	// Fast path: use the cached index instead of loading config and running git
+	if cached := cachedRepo(); cached != nil {
+		org, repo, mainPath = cached.Org, cached.Repo, cached.MainPath
+		worktrees = cached.Existing()
+	} else {
+		cfg := config.Load()
+		useRepoRemotes(cfg)
+		repoInfo, err := git.GetRepoInfo()
+		if err != nil {
+			config.Fail(err)
+		}
+		index.Refresh(cfg, repoInfo)
 
-	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
-	mainPath, _ := git.GetMainWorktreePath()
+		org, repo = repoInfo.Org, repoInfo.Repo
+		mainPath, _ = git.GetMainWorktreePath()
+		worktrees, _ = git.ListWorktrees(cfg.WorktreeBasePath(org, repo))
+	}
 
 	var options []tui.Option
+	hints := worktreeHints(&git.RepoInfo{Org: org, Repo: repo}, append([]string{mainPath}, worktrees...))
 
 	// Add main as first option
 	if mainPath != "" {
 		options = append(options, tui.Option{
 			Label: fmt.Sprintf("main (%s)", filepath.Base(mainPath)),
 			Value: mainPath,
+			Hint:  hints[mainPath],
 		})
 	}
 
 	// Add issue worktrees
-	worktrees, _ := git.ListWorktrees(base)
 	for _, wt := range worktrees {
 		name := filepath.Base(wt)
 		options = append(options, tui.Option{
 			Label: name,
 			Value: wt,
+			Hint:  hints[wt],
 		})
 	}
 
 	if len(options) == 0 {
-		config.Die("No worktrees found")
+		config.Fail(errs.NotFound("No worktrees found"))
 	}
 
-	header := fmt.Sprintf("Worktrees for %s/%s", repoInfo.Org, repoInfo.Repo)
+	header := fmt.Sprintf("Worktrees for %s/%s", org, repo)
 	selected, err := tui.Select(header, options)
 	if err != nil {
 		// Silent exit for no selection
 		return
 	}
 
-	fmt.Println(selected)
+	printCdPath(selected)
 }
 
 func selectWorktreeWithMain(repoInfo *git.RepoInfo, cfg *config.Config) (string, error) {
@@ -102,17 +122,19 @@ func selectWorktreeWithMain(repoInfo *git.RepoInfo, cfg *config.Config) (string,
 	mainPath, _ := git.GetMainWorktreePath()
 
 	var options []tui.Option
+	worktrees, _ := git.ListWorktrees(base)
+	hints := worktreeHints(repoInfo, append([]string{mainPath}, worktrees...))
 
 	// Add main as first option
 	if mainPath != "" {
 		options = append(options, tui.Option{
 			Label: fmt.Sprintf("main (%s)", filepath.Base(mainPath)),
 			Value: mainPath,
+			Hint:  hints[mainPath],
 		})
 	}
 
 	// Add issue worktrees
-	worktrees, _ := git.ListWorktrees(base)
 	for _, wt := range worktrees {
 		name := filepath.Base(wt)
 		// Extract issue number
@@ -128,6 +150,7 @@ func selectWorktreeWithMain(repoInfo *git.RepoInfo, cfg *config.Config) (string,
 				options = append(options, tui.Option{
 					Label: name,
 					Value: wt,
+					Hint:  hints[wt],
 				})
 			}
 		}
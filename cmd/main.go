@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/spf13/cobra"
 )
@@ -27,7 +28,7 @@ var internalMainCmd = &cobra.Command{
 func runInternalMain(cmd *cobra.Command, args []string) {
 	mainPath, err := git.GetMainWorktreePath()
 	if err != nil {
-		config.Die("Could not find main repository")
+		config.Fail(errs.NotFound("Could not find main repository"))
 	}
-	fmt.Println(mainPath)
+	printCdPath(mainPath)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// checkMainBranch warns when the local main branch is behind or has diverged
+// from the remote, which makes new worktrees and rebases start from stale
+// code. With offer set, a branch that is only behind can be fast-forwarded;
+// worktrees share the repository, so this updates it for all of them.
+func checkMainBranch(cfg *config.Config, offer bool) {
+	branch := cfg.MainBranch
+	upstream := git.RemoteRef(branch)
+	if !git.BranchExists(branch) || git.RemoteBranchRef(branch) == "" {
+		return
+	}
+	mainWorktree, err := git.GetMainWorktreePath()
+	if err != nil {
+		return
+	}
+	ahead, behind, err := git.BranchDivergence(mainWorktree, branch, upstream)
+	if err != nil || behind == 0 {
+		return
+	}
+
+	if ahead > 0 {
+		config.Warn("Local %s has diverged from %s (%d ahead, %d behind)", branch, upstream, ahead, behind)
+		config.Info("Reconcile it in %s, e.g. git rebase %s", mainWorktree, upstream)
+		return
+	}
+
+	config.Warn("Local %s is %d commit(s) behind %s", branch, behind, upstream)
+	if !offer || !confirmPrompt("Fast-forward it?") {
+		config.Info("Update it with: git -C %s merge --ff-only %s", mainWorktree, upstream)
+		return
+	}
+	if err := git.FastForwardBranch(branch, upstream); err != nil {
+		config.Warn("Failed to fast-forward %s: %v", branch, err)
+		return
+	}
+	config.Success("Fast-forwarded %s to %s", branch, upstream)
+}
@@ -2,111 +2,280 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
-	"strconv"
+	"regexp"
+	"strings"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
 	"github.com/spf13/cobra"
 )
 
 var mergeCmd = &cobra.Command{
 	Use:   "merge [issue-number]",
 	Short: "Merge worktree into main and cleanup",
-	Long:  `Merge the worktree branch into main, close the issue with commit info, and remove the worktree.`,
-	Args:  cobra.MaximumNArgs(1),
-	Run:   runMerge,
+	Long: `Merge the worktree branch into main, close the issue with commit info, and remove the worktree.
+
+With --then-next, choose the next issue to work on afterwards, or name it with
+--next; its worktree is created and, with shell integration, you end up in it.
+
+The commits about to land on main are listed first. fixup!, squash! and WIP commits
+are highlighted, with an offer to clean them up in an interactive rebase.
+
+Unchecked task list items ("- [ ] ...") in the issue are listed too, with an offer
+to ask on the issue whether they're deferred instead of merging.
+
+With --backport release/1.2 (repeatable, or comma-separated), the merged commits
+are cherry-picked onto each release branch and a backport PR is opened against it,
+labeled with backport.labels. Conflicts are reported per branch.
+
+The issue is closed with a comment rendered from the merge.comment template, which
+can link the PR and list the changed files, deploy notes and co-authors. Review it
+in $EDITOR first with --edit-comment, or close the issue without one with --no-comment.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMerge,
+}
+
+var (
+	mergeOpen      bool
+	mergeNoOpen    bool
+	mergeSkipCheck bool
+	mergeGreen     bool
+	mergeYes       bool
+	mergeThenNext  bool
+	mergeNext      string
+	mergeNoReview  bool
+	mergeBackport  []string
+	mergeStatus    string
+
+	mergeEditComment bool
+	mergeNoComment   bool
+)
+
+func init() {
+	mergeCmd.Flags().BoolVar(&mergeOpen, "open", false, "Open the merged commit in the browser")
+	mergeCmd.Flags().BoolVar(&mergeNoOpen, "no-open", false, "Don't open the merged commit in the browser")
+	mergeCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+	mergeCmd.Flags().BoolVar(&mergeSkipCheck, "skip-check", false, "Skip the conflict pre-check against the latest main")
+	mergeCmd.Flags().BoolVar(&mergeGreen, "require-green", false, "Only merge an open, mergeable, approved PR whose checks all passed")
+	mergeCmd.Flags().BoolVarP(&mergeYes, "yes", "y", false, "Never prompt; fail instead of asking which worktree to merge")
+	mergeCmd.Flags().BoolVar(&mergeThenNext, "then-next", false, "Select the next issue and create its worktree after merging")
+	mergeCmd.Flags().StringVar(&mergeNext, "next", "", "Create the worktree for this issue after merging")
+	mergeCmd.MarkFlagsMutuallyExclusive("then-next", "next")
+	mergeCmd.Flags().BoolVar(&mergeNoReview, "no-review", false, "Don't list the commits being merged")
+	mergeCmd.Flags().StringSliceVar(&mergeBackport, "backport", nil, "Afterwards, open backport PRs against these release branches")
+	mergeCmd.Flags().StringVar(&mergeStatus, "status", "", "Status to move the issue to instead of the done value")
+	mergeCmd.Flags().BoolVar(&mergeEditComment, "edit-comment", false, "Review the closing comment in $EDITOR before posting it")
+	mergeCmd.Flags().BoolVar(&mergeNoComment, "no-comment", false, "Close the issue without a comment")
+	mergeCmd.MarkFlagsMutuallyExclusive("edit-comment", "no-comment")
 }
 
-func runMerge(cmd *cobra.Command, args []string) {
+func runMerge(cmd *cobra.Command, args []string) error {
 	cfg := config.Load()
+	overrideStatus(cfg, statusDone, mergeStatus)
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		return err
 	}
 
-	var issueNumber int
+	var id issueid.ID
 	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
 
+	// Validate the next issue before merging anything
+	var nextIssue int
+	if mergeNext != "" {
+		nextIssue = githubIssueNumber(parseIssueID(mergeNext), "use --next")
+	}
+	if mergeThenNext && mergeYes {
+		return errs.New(errs.KindUsage, "--then-next asks for the next issue; use --next <issue> with --yes")
+	}
+	commentTmpl, err := parseMergeComment(cfg)
+	if err != nil {
+		return err
+	}
+
 	if len(args) > 0 {
-		issueNumber, err = strconv.Atoi(args[0])
-		if err != nil {
-			config.Die("Invalid issue number: %s", args[0])
-		}
+		id = parseIssueID(args[0])
 	} else {
 		// Try to detect from current directory
-		if num, ok := git.DetectIssueNumber(base); ok {
-			issueNumber = num
+		if detected, ok := git.DetectIssueID(base); ok {
+			id = detected
+		} else if mergeYes {
+			return errs.New(errs.KindUsage, "Specify the issue to merge; --yes never prompts")
 		} else {
 			// Interactive selection
-			issueNumber, err = selectWorktree(repoInfo, cfg)
+			id, err = selectWorktree(repoInfo, cfg)
 			if err != nil {
-				config.Die("No worktree selected")
+				return errs.Aborted("No worktree selected")
 			}
 		}
 	}
+	issueNumber := githubIssueNumber(id, "merge")
 
-	worktreePath := git.FindWorktreeByIssue(base, issueNumber)
+	worktreePath := git.FindWorktreeByID(base, id)
 	if worktreePath == "" {
-		config.Die("No worktree found for issue #%d", issueNumber)
+		return errs.NotFound("No worktree found for issue #%d", issueNumber)
 	}
 
 	branchName := filepath.Base(worktreePath)
 
+	guardMainWorktree(cfg, worktreePath, "merge and remove")
+	guardBranch(cfg, branchName, "merge and delete")
+
 	// Check for uncommitted changes
 	if git.HasUncommittedChanges(worktreePath) {
-		config.Die("Worktree has uncommitted changes. Commit or stash them first.")
+		return errs.Conflict("Worktree has uncommitted changes. Commit or stash them first.")
 	}
 
 	// Get the main worktree path
 	mainWorktree, err := git.GetMainWorktreePath()
 	if err != nil {
-		config.Die("Failed to get main worktree: %v", err)
+		return fmt.Errorf("failed to get main worktree: %w", err)
 	}
 
-	// Get the last commit message before merge
-	lastCommitMsg, _ := git.GetLastCommitMessage("")
+	// Merging pushes main and removes the worktree and branch; check it all first
+	pf := newPreflight("merge " + branchName)
+	pf.checkPushAccess()
+	pf.checkWorktreeRemoval(worktreePath)
+	deleteLocal := pf.checkBranchDeletion(branchName, worktreePath)
+	pf.report()
+
+	var greenHead string
+	if mergeGreen {
+		if greenHead, err = requireGreenPR(repoInfo, branchName); err != nil {
+			return err
+		}
+	}
 
-	mainBranch := cfg.MainBranch
+	// Rules for the issue's labels may merge elsewhere, or with another strategy
+	mainBranch, strategy := cfg.MainBranch, cfg.MergeStrategy
+	if len(cfg.Rules) > 0 {
+		if issue, err := getIssue(cfg, issueNumber); err != nil {
+			config.Warn("Could not fetch issue #%d to check rules: %v", issueNumber, err)
+		} else {
+			rules, _ := applyRules(cfg, issue, "merge", true)
+			mainBranch = rules.baseBranch(cfg)
+			if rules.mergeStrategy != "" {
+				strategy = rules.mergeStrategy
+			}
+		}
+	}
+
+	if err := checkBackportTargets(mergeBackport, mainBranch); err != nil {
+		return err
+	}
+
+	if !mergeNoReview {
+		if err := reviewCommits(worktreePath, mainBranch, branchName); err != nil {
+			return err
+		}
+	}
+
+	guardSignedCommits(cfg, mainWorktree, mainBranch+".."+branchName, "merge")
+
+	if err := checkIssueChecklist(cfg, issueNumber); err != nil {
+		return err
+	}
+
+	if !mergeSkipCheck {
+		checkMergeable(repoInfo, mainWorktree, worktreePath, mainBranch, branchName)
+	}
+
+	// The branch's commits are gone from view once it is squashed into main
+	var commentData mergeCommentData
+	if commentTmpl != nil {
+		prNumber := 0
+		if usesField(commentTmpl, "PR") || usesField(commentTmpl, "DeployNotes") {
+			prNumber, _ = findOpenPRForBranch(repoInfo, branchName)
+		}
+		commentData = mergeCommentContext(commentTmpl, worktreePath, mainBranch, issueNumber, prNumber)
+	}
 
 	// Checkout main branch
 	config.Info("Switching to %s branch...", mainBranch)
 	if err := git.Checkout(mainWorktree, mainBranch); err != nil {
-		config.Die("Failed to checkout %s: %v", mainBranch, err)
+		return fmt.Errorf("failed to checkout %s: %w", mainBranch, err)
+	}
+
+	premergeSHA, _ := git.GetHeadCommit(mainWorktree)
+
+	// The commits were checked on the PR; cleaning them up since changes them
+	if greenHead != "" {
+		if err := checkPRHead(greenHead, branchName); err != nil {
+			return err
+		}
 	}
 
 	// Merge the worktree branch
 	config.Info("Merging %s into %s...", branchName, mainBranch)
-	if err := git.MergeBranch(mainWorktree, branchName); err != nil {
-		config.Die("Merge failed: %v", err)
+	merge := git.MergeBranch
+	if strategy == "merge" {
+		merge = git.MergeCommit
+	}
+	if err := merge(mainWorktree, branchName); err != nil {
+		return fmt.Errorf("merge failed: %w", err)
 	}
 
-	// Push main to origin
-	config.Info("Pushing %s to origin...", mainBranch)
+	if requireSigned(cfg, mainWorktree) {
+		if err := checkMergeSignature(mainWorktree, mainBranch, premergeSHA); err != nil {
+			return err
+		}
+	}
+
+	// Push main to the push remote
+	config.Info("Pushing %s to %s...", mainBranch, git.PushRemote())
 	if err := git.PushMain(mainWorktree, mainBranch); err != nil {
-		config.Die("Failed to push: %v", err)
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	mergedSHA, _ := git.GetHeadCommit(mainWorktree)
+	// Resolve the commit URL while the worktree gh runs in still exists
+	commitURL := ""
+	if mergedSHA != "" {
+		commitURL, _ = github.CommitURL(mergedSHA)
+	}
+	if mainBranch != cfg.MainBranch {
+		if err := git.Checkout(mainWorktree, cfg.MainBranch); err != nil {
+			config.Warn("Failed to switch back to %s: %v", cfg.MainBranch, err)
+		}
+	}
+
+	var backports []backportResult
+	if len(mergeBackport) > 0 && premergeSHA != "" && mergedSHA != "" {
+		backports = backportMerge(cfg, issueNumber, branchName, premergeSHA, mergedSHA, mergeBackport)
 	}
 
-	// Close the issue with the commit message
+	// Close the issue with the merge comment
 	config.Info("Closing issue #%d...", issueNumber)
-	comment := fmt.Sprintf("**Merged into %s**\n\n%s", mainBranch, lastCommitMsg)
-	if err := github.CloseIssue(issueNumber, comment); err != nil {
+	comment := ""
+	if commentTmpl != nil {
+		commentData.Commit = commitURL
+		comment = renderMergeComment(cfg, commentTmpl, commentData)
+	}
+	if err := closeIssue(cfg, issueNumber, comment); err != nil {
 		config.Warn("Failed to close issue: %v", err)
 	}
+	postProgress(cfg, issueNumber, progressMerged, "into `"+mainBranch+"`")
 
-	// Update GitHub Project status to "Done"
-	if cfg.GitHub.ProjectsEnabled {
-		if err := github.UpdateIssueStatus(issueNumber, cfg.GitHub.DoneValue, cfg); err != nil {
-			if cfg.Verbose {
+	// Move the issue to "Done" in GitHub Projects or Azure Boards
+	if tracksStatus(cfg) {
+		if updates, err := updateIssueStatus(cfg, issueNumber, statusDone); err != nil {
+			if cfg.Verbose || mergeStatus != "" {
 				config.Warn("Failed to update project status: %v", err)
 			}
 		} else {
-			config.Info("Updated issue #%d to '%s' in GitHub Projects", issueNumber, cfg.GitHub.DoneValue)
+			config.Info("Updated issue #%d to '%s' in %s", issueNumber, statusValue(cfg, statusDone), trackerName(cfg))
+			printProjectUpdates(updates)
 		}
 	}
 
 	// Remove worktree
+	journalRemoval(repoInfo, undoEntry{Op: "merge", Branch: branchName, Worktree: worktreePath, Remote: true})
 	config.Info("Removing worktree...")
 	if err := git.RemoveWorktree(worktreePath, false); err != nil {
 		// Try force remove
@@ -114,14 +283,222 @@ func runMerge(cmd *cobra.Command, args []string) {
 	}
 	// Prune any stale worktree entries
 	git.PruneWorktrees()
+	index.Refresh(cfg, repoInfo)
+	forgetActiveIssue(repoInfo, issueNumber)
+	unlinkPR(repoInfo, branchName)
 
 	// Clean up local and remote branch
 	config.Info("Deleting branch %s...", branchName)
-	git.DeleteBranch(branchName)
+	if deleteLocal {
+		git.DeleteBranch(branchName)
+	}
 	git.DeleteRemoteBranch(branchName)
 
 	config.Success("Merged into %s and cleaned up!", mainBranch)
 
+	if mergedSHA != "" {
+		if shouldOpenBrowser(mergeOpen, mergeNoOpen, cfg) {
+			if err := github.OpenCommitInBrowser(mergedSHA); err != nil {
+				config.Warn("Failed to open browser: %v", err)
+			}
+		}
+		// Final visible line (the cd marker below is consumed by the shell wrapper)
+		if commitURL != "" {
+			fmt.Println(commitURL)
+		} else {
+			fmt.Println(mergedSHA)
+		}
+	}
+
+	cdTo := mainWorktree
+	if mergeThenNext || nextIssue != 0 {
+		if nextPath := startNextIssue(cfg, repoInfo, mainWorktree, nextIssue); nextPath != "" {
+			cdTo = nextPath
+		}
+	}
+
 	// Output cd marker for shell integration
-	fmt.Printf("__GWI_CD_TO__:%s\n", mainWorktree)
+	printCdTo(cdTo)
+
+	if failed := reportBackports(backports); failed > 0 {
+		return errs.Conflict("%d of %d backport(s) failed", failed, len(backports))
+	}
+	return nil
+}
+
+// startNextIssue creates the worktree for the next issue after a merge,
+// choosing the issue interactively when issueNumber is 0. It returns the new
+// worktree, or "" when no issue was chosen.
+func startNextIssue(cfg *config.Config, repoInfo *git.RepoInfo, mainWorktree string, issueNumber int) string {
+	// The merged worktree is gone, and git runs in the current directory
+	if err := os.Chdir(mainWorktree); err != nil {
+		config.Warn("Failed to change to %s: %v", mainWorktree, err)
+		return ""
+	}
+	if issueNumber == 0 {
+		var err error
+		if issueNumber, err = selectIssue(repoInfo); err != nil {
+			config.Info("No next issue selected")
+			return ""
+		}
+	}
+	worktreePath := createWorktree(cfg, repoInfo, issueNumber, true)
+	config.Success("Ready for issue #%d in %s", issueNumber, worktreePath)
+	return worktreePath
+}
+
+// requireGreenPR checks that the branch's pull request can be merged without
+// anyone looking at it, and that the local branch is what was checked. It
+// returns the PR's head commit; the error's reason names the first unmet
+// requirement.
+func requireGreenPR(repoInfo *git.RepoInfo, branchName string) (string, error) {
+	prNumber, err := findOpenPRForBranch(repoInfo, branchName)
+	if err != nil {
+		linked, ok := linkedPRNumber(repoInfo, branchName)
+		if !ok {
+			return "", errs.NotReady("no_pr", "No pull request found for %s", branchName)
+		}
+		prNumber = linked
+	}
+	pr, err := github.GetPRStatus(prNumber)
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of PR #%d: %w", prNumber, err)
+	}
+	if pr.State != "OPEN" {
+		return "", errs.NotReady("not_open", "PR #%d is %s", prNumber, strings.ToLower(pr.State))
+	}
+	if err := checkPRHead(pr.HeadRefOid, branchName); err != nil {
+		return "", err
+	}
+
+	mergeable, err := github.WaitForMergeable(prNumber, 5)
+	if err != nil {
+		return "", fmt.Errorf("failed to get mergeability of PR #%d: %w", prNumber, err)
+	}
+	if mergeable != "MERGEABLE" {
+		return "", errs.NotReady("not_mergeable", "PR #%d is not mergeable (%s)", prNumber, strings.ToLower(mergeable))
+	}
+
+	var failing, pending []string
+	for _, check := range pr.StatusCheckRollup {
+		switch {
+		case check.Pending():
+			pending = append(pending, check.Label())
+		case !check.Passed():
+			failing = append(failing, check.Label())
+		}
+	}
+	if len(failing) > 0 {
+		return "", errs.NotReady("checks_failing", "PR #%d has failing checks: %s", prNumber, strings.Join(failing, ", "))
+	}
+	if len(pending) > 0 {
+		return "", errs.NotReady("checks_pending", "PR #%d has pending checks: %s", prNumber, strings.Join(pending, ", "))
+	}
+
+	if !pr.Approved() {
+		if pr.ReviewDecision == "CHANGES_REQUESTED" {
+			return "", errs.NotReady("changes_requested", "PR #%d has changes requested", prNumber)
+		}
+		return "", errs.NotReady("not_approved", "PR #%d is not approved", prNumber)
+	}
+	if pr.MergeStateStatus == "BLOCKED" {
+		return "", errs.NotReady("blocked", "PR #%d is blocked by branch protection", prNumber)
+	}
+
+	config.Success("PR #%d is mergeable, approved and all checks passed", prNumber)
+	return pr.HeadRefOid, nil
+}
+
+// checkPRHead fails when the local branch isn't at head, the PR's head commit,
+// as its commits then never ran the PR's checks
+func checkPRHead(head, branchName string) error {
+	local, err := git.ResolveCommit(".", "refs/heads/"+branchName)
+	if err != nil {
+		return err
+	}
+	if local != head {
+		short := shortSHAs([]string{local, head})
+		return errs.NotReady("head_mismatch", "%s is at %s, but its PR was checked at %s. Push it and wait for the checks, or merge without --require-green",
+			branchName, short[0], short[1])
+	}
+	return nil
+}
+
+// fixupCommit matches the subjects of commits that shouldn't end up on main as they are
+var fixupCommit = regexp.MustCompile(`(?i)^(?:fixup!|squash!|amend!|\[?wip\b)`)
+
+// reviewCommits lists the commits about to land on main, highlighting fixup!
+// and WIP commits, and offers to clean those up with an interactive rebase
+func reviewCommits(worktreePath, mainBranch, branchName string) error {
+	commits, err := git.GetCommits(worktreePath, mainBranch+".."+branchName)
+	if err != nil || len(commits) == 0 {
+		return nil
+	}
+
+	config.Info("Commits to merge into %s:", mainBranch)
+	flagged := 0
+	for _, c := range commits {
+		line := fmt.Sprintf("%s %s (%s)", c.Hash, c.Subject, c.Author)
+		if fixupCommit.MatchString(c.Subject) {
+			line = config.Yellow(line)
+			flagged++
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+	if flagged == 0 {
+		return nil
+	}
+
+	config.Warn("%d fixup/WIP commit(s) would end up on %s", flagged, mainBranch)
+	if mergeYes || !confirmPrompt("Clean them up with an interactive rebase first?") {
+		return nil
+	}
+	if err := git.InteractiveRebase(worktreePath, mainBranch); err != nil {
+		return errs.Conflict("Rebase stopped; finish it in %s (or run git rebase --abort), then merge again", worktreePath)
+	}
+	config.Success("Rebased %s onto %s", branchName, mainBranch)
+	return nil
+}
+
+// checkMergeable fetches the latest base branch and stops the merge early when
+// the branch can't be fast-forwarded, reporting whether a rebase would conflict
+func checkMergeable(repoInfo *git.RepoInfo, mainWorktree, worktreePath, mainBranch, branchName string) {
+	base := git.RemoteRef(mainBranch)
+	config.Info("Checking %s against %s...", branchName, base)
+	if err := git.Fetch(); err != nil {
+		config.Warn("Failed to fetch, checking against the last known %s", base)
+	}
+
+	// A pull request may report conflicts that aren't visible locally, e.g. with branch protection rules
+	if prNumber, err := findOpenPRForBranch(repoInfo, branchName); err == nil {
+		mergeable, err := github.WaitForMergeable(prNumber, 5)
+		switch {
+		case err != nil:
+			config.Warn("Could not check PR #%d: %v", prNumber, err)
+		case mergeable == "CONFLICTING":
+			config.Warn("GitHub reports PR #%d as conflicting with %s", prNumber, mainBranch)
+		case mergeable == "UNKNOWN":
+			config.Warn("GitHub has not computed mergeability for PR #%d yet", prNumber)
+		}
+	}
+
+	if git.IsAncestor(mainWorktree, base, branchName) {
+		return
+	}
+
+	conflicts, err := git.MergeTreeConflicts(mainWorktree, base, branchName)
+	if err != nil {
+		config.Warn("%v", err)
+		config.Fail(errs.Conflict("%s is behind %s and can't be fast-forwarded. Rebase it first.", branchName, base))
+	}
+	if len(conflicts) == 0 {
+		config.Fail(errs.Conflict("%s is behind %s and can't be fast-forwarded. A rebase merges cleanly:\n\n  git -C %s rebase %s",
+			branchName, base, worktreePath, base))
+	}
+
+	config.Error("%s conflicts with %s in %d file(s):", branchName, base, len(conflicts))
+	for _, file := range conflicts {
+		fmt.Fprintf(os.Stderr, "  %s\n", config.Red(file))
+	}
+	config.Fail(errs.Conflict("Rebase %s onto %s and resolve the conflicts before merging.", branchName, base))
 }
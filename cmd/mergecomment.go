@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// mergeCommentData is what merge.comment templates are rendered with, e.g.
+// "Merged into {{.Branch}} in {{.PR}}"
+type mergeCommentData struct {
+	Issue       int
+	Branch      string   // Branch merged into, e.g. main
+	Message     string   // Full message of the branch's last commit
+	Commit      string   // URL of the merged commit
+	PR          string   // URL of the branch's pull request, or "" without one
+	Commits     []string // Subjects of the branch's commits, oldest first
+	Files       []string // Files the branch changed
+	DeployNotes string   // "Deploy notes" section of the PR description
+	CoAuthors   []string // Other authors and co-authors of the commits, as "Name <email>"
+}
+
+// deployNotesHeading is the PR description section DeployNotes comes from
+const deployNotesHeading = "deploy notes"
+
+// htmlComments are the hints PR templates leave in sections
+var htmlComments = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// parseMergeComment parses the merge.comment template; nil means no comment
+func parseMergeComment(cfg *config.Config) (*template.Template, error) {
+	text := cfg.Merge.Comment
+	if mergeNoComment || text == "" || text == "off" {
+		return nil, nil
+	}
+	tmpl, err := template.New("merge.comment").Parse(text)
+	if err != nil {
+		return nil, errs.New(errs.KindUsage, "Invalid merge.comment template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// usesField reports whether a template refers to a field, so data that costs
+// a GitHub call is only looked up when needed
+func usesField(tmpl *template.Template, field string) bool {
+	return tmpl.Tree != nil && strings.Contains(tmpl.Tree.Root.String(), "."+field)
+}
+
+// renderMergeComment renders the closing comment of a merged branch, and
+// offers it for review in $EDITOR with --edit-comment
+func renderMergeComment(cfg *config.Config, tmpl *template.Template, data mergeCommentData) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		config.Warn("Failed to render merge.comment, using the default: %v", err)
+		buf.Reset()
+		template.Must(template.New("default").Parse(config.DefaultMergeComment)).Execute(&buf, data)
+	}
+	comment := strings.TrimSpace(buf.String())
+
+	if !(mergeEditComment || cfg.Merge.EditComment) || config.Headless() {
+		return comment
+	}
+	edited, err := editText("merge-comment-*.md", comment)
+	if err != nil {
+		config.Warn("Failed to edit the comment, posting it as rendered: %v", err)
+		return comment
+	}
+	return strings.TrimSpace(edited)
+}
+
+// mergeCommentContext collects the template data of a branch about to be
+// merged into mainBranch from its commits and its pull request. The merged
+// commit is filled in once it exists.
+func mergeCommentContext(tmpl *template.Template, worktreePath, mainBranch string, issueNumber, prNumber int) mergeCommentData {
+	data := mergeCommentData{Issue: issueNumber, Branch: mainBranch}
+	revRange := mainBranch + "..HEAD"
+	data.Message, _ = git.GetCommitMessage(worktreePath, "HEAD")
+	data.Commits, _ = git.GetCommitSubjects(worktreePath, revRange)
+	data.Files, _ = git.GetChangedFiles(worktreePath, mainBranch+"...HEAD")
+
+	self := strings.ToLower(git.GetConfig(worktreePath, "user.email"))
+	contributors, _ := git.GetContributors(worktreePath, revRange)
+	for _, contributor := range contributors {
+		if self == "" || !strings.HasSuffix(strings.ToLower(contributor), "<"+self+">") {
+			data.CoAuthors = append(data.CoAuthors, contributor)
+		}
+	}
+
+	if prNumber != 0 {
+		data.PR, _ = github.GetPRURL(prNumber)
+		if usesField(tmpl, "DeployNotes") {
+			if body, err := github.GetPRBody(prNumber); err == nil {
+				data.DeployNotes = markdownSection(body, deployNotesHeading)
+			}
+		}
+	}
+	return data
+}
+
+// markdownSection returns the text under the heading titled title (ignoring
+// case), up to the next heading of the same or a higher level. HTML comments
+// are left out; "" means there is no such section or it is empty.
+func markdownSection(body, title string) string {
+	var section []string
+	level := 0
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		depth := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+		isHeading := depth > 0 && depth <= 6 && strings.HasPrefix(trimmed[depth:], " ")
+		if level > 0 {
+			if isHeading && depth <= level {
+				break
+			}
+			section = append(section, line)
+		} else if isHeading && strings.EqualFold(strings.TrimSpace(trimmed[depth:]), title) {
+			level = depth
+		}
+	}
+	return strings.TrimSpace(htmlComments.ReplaceAllString(strings.Join(section, "\n"), ""))
+}
+
+// editText opens text in the user's editor ($VISUAL, $EDITOR or vi) and
+// returns it as saved. The editor draws on stderr, as stdout is reserved for
+// paths.
+func editText(pattern, text string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return "", err
+	}
+	f.Close()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may come with arguments, e.g. "code --wait"
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w", editor, err)
+	}
+	content, err := os.ReadFile(f.Name())
+	return string(content), err
+}
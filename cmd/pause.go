@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause [issue-number]",
+	Short: "Put an issue aside for days",
+	Long: `Pause an issue for a longer context switch: save uncommitted changes as a WIP commit,
+push the worktree's HEAD to refs/gwi/paused/<branch> on the remote as a backup, stop its
+tmux session and move the issue to github.paused_value (azure.paused_value for work
+items). The worktree is kept. 'gwi resume' reverses all of it.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPause,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume [issue-number]",
+	Short: "Pick a paused issue up again",
+	Long: `Resume an issue paused with 'gwi pause': restore the changes of its WIP commit, delete
+the backup ref, move the issue back to the status it had, restart its tmux session if it
+was running, and change to its worktree.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runResume,
+}
+
+// pausedKeyPrefix namespaces the issues put aside with gwi pause
+const pausedKeyPrefix = "paused/"
+
+// pausedRefPrefix is where gwi pause backs up a worktree's HEAD on the remote
+const pausedRefPrefix = "refs/gwi/paused/"
+
+// pausedIssue records what gwi pause did, for gwi resume to undo
+type pausedIssue struct {
+	Branch  string    `json:"branch"`
+	Title   string    `json:"title,omitempty"`
+	WIP     bool      `json:"wip"`              // Uncommitted changes were saved as a WIP commit
+	Ref     string    `json:"ref,omitempty"`    // Backup ref on the remote; empty when the push failed
+	Status  string    `json:"status,omitempty"` // Status to go back to
+	Session bool      `json:"session"`          // A tmux session was stopped
+	Paused  time.Time `json:"paused"`
+}
+
+func pausedKey(repoInfo *git.RepoInfo, issueNumber int) string {
+	return fmt.Sprintf("%s%s/%s/%d", pausedKeyPrefix, repoInfo.Org, repoInfo.Repo, issueNumber)
+}
+
+func runPause(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	id, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	issueNumber := githubIssueNumber(id, "pause")
+	branchName := filepath.Base(worktreePath)
+	guardMainWorktree(cfg, worktreePath, "pause")
+
+	meta, _ := store.Load()
+	if meta != nil && meta.Get(pausedKey(repoInfo, issueNumber), &pausedIssue{}) {
+		config.Fail(errs.Conflict("Issue #%d is already paused. Resume it with: gwi resume %d", issueNumber, issueNumber))
+	}
+	paused := pausedIssue{Branch: branchName, Paused: time.Now()}
+	var active activeIssue
+	if meta != nil && meta.Get(activeKey(repoInfo, issueNumber), &active) {
+		paused.Title = active.Title
+	}
+
+	if git.HasUncommittedChanges(worktreePath) {
+		if err := takeSnapshot(cfg, worktreePath, branchName, "paused", false); err != nil {
+			config.Fail(fmt.Errorf("failed to save the changes of issue #%d: %w", issueNumber, err))
+		}
+		paused.WIP = true
+	}
+
+	ref := pausedRefPrefix + branchName
+	if err := git.PushRef(worktreePath, "HEAD", ref); err != nil {
+		config.Warn("Could not back up the work to %s: %v", ref, err)
+	} else {
+		paused.Ref = ref
+		config.Info("Backed up the work to %s", ref)
+	}
+
+	if hasTmux() && tmuxSessionExists(branchName) {
+		if err := stopSession(cfg, branchName, worktreePath); err != nil {
+			config.Warn("Failed to stop the session: %v", err)
+		} else {
+			paused.Session = true
+		}
+	}
+
+	if tracksStatus(cfg) && statusValue(cfg, statusPaused) != "" {
+		paused.Status, _ = currentStatus(cfg, issueNumber)
+		moveIssueTo(cfg, issueNumber, statusValue(cfg, statusPaused), "paused_value")
+	}
+
+	store.Update(func(s *store.Store) error {
+		return s.Set(pausedKey(repoInfo, issueNumber), paused)
+	})
+	forgetActiveIssue(repoInfo, issueNumber)
+	config.Success("Paused issue #%d. Pick it up again with: gwi resume %d", issueNumber, issueNumber)
+}
+
+func runResume(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	id, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	issueNumber := githubIssueNumber(id, "resume")
+
+	var paused pausedIssue
+	meta, _ := store.Load()
+	if meta == nil || !meta.Get(pausedKey(repoInfo, issueNumber), &paused) {
+		config.Fail(errs.NotFound("Issue #%d isn't paused", issueNumber))
+	}
+	checkWIPLimit(cfg, repoInfo, issueNumber)
+
+	if paused.WIP {
+		msg, _ := git.GetCommitMessage(worktreePath, "HEAD")
+		if strings.Contains(msg, snapshotTrailer) {
+			if err := popSnapshot(worktreePath, paused.Branch); err != nil {
+				config.Fail(fmt.Errorf("failed to restore the changes of issue #%d: %w", issueNumber, err))
+			}
+		} else {
+			config.Warn("HEAD is no longer the WIP commit of the pause; its changes stay committed")
+		}
+	}
+
+	if paused.Ref != "" {
+		if err := git.DeleteRemoteRef(worktreePath, paused.Ref); err != nil {
+			config.Warn("Could not delete the backup %s: %v", paused.Ref, err)
+		}
+	}
+
+	if tracksStatus(cfg) && statusValue(cfg, statusPaused) != "" {
+		status := paused.Status
+		if status == "" || status == statusValue(cfg, statusPaused) {
+			status = statusValue(cfg, statusInProgress)
+		}
+		moveIssueTo(cfg, issueNumber, status, "")
+	}
+
+	store.Update(func(s *store.Store) error {
+		s.Delete(pausedKey(repoInfo, issueNumber))
+		return nil
+	})
+	recordActiveIssue(repoInfo, issueNumber, paused.Branch, paused.Title)
+	config.Success("Resumed issue #%d", issueNumber)
+
+	inside := git.IsInsideWorktree(worktreePath)
+	if paused.Session && hasTmux() {
+		// gwi up starts the session of the current directory
+		if err := os.Chdir(worktreePath); err != nil {
+			config.Warn("Failed to restart the session: %v", err)
+		} else {
+			runUp(cmd, nil)
+		}
+	}
+	if !inside {
+		printCdTo(worktreePath)
+	}
+}
+
+// moveIssueTo sets an issue's status and reports it. A value the project
+// lacks points to setting, the config key choosing it.
+func moveIssueTo(cfg *config.Config, issueNumber int, value, setting string) {
+	updates, err := setIssueStatus(cfg, issueNumber, value)
+	switch {
+	case errors.Is(err, github.ErrOptionNotFound) && setting != "":
+		config.Warn("The project has no '%s' status; choose one with %s. Leaving the status as is", value, setting)
+	case err != nil:
+		config.Warn("Failed to update project status: %v", err)
+	default:
+		config.Info("Updated issue #%d to '%s' in %s", issueNumber, value, trackerName(cfg))
+		printProjectUpdates(updates)
+	}
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planDryRun bool
+	planYes    bool
+	planLabels []string
+	planStatus string
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Turn a plan into issues",
+}
+
+var planImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create issues in bulk from a CSV file or Markdown checklist",
+	Long: `Create an issue for every entry of a plan, add it to the project in Todo and print its
+number, e.g. to kick off an epic from a planning document. Use - to read the plan from stdin.
+
+A .csv file has the columns title, labels, estimate and body, in this order or as named
+by a header row. In any other file each unchecked Markdown task is an issue, with labels
+and an estimate after bars; lines indented below a task become its description:
+
+  - [ ] Add the login page | auth, ui | 3
+    Users sign in with their GitHub account.
+    - [ ] Redirect back after signing in
+  - [ ] Rate-limit the API | api
+
+Labels are separated by commas or semicolons. The estimate goes to the project field
+github.estimate_field. Checked tasks are skipped.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanImport,
+}
+
+func init() {
+	planImportCmd.Flags().BoolVarP(&planDryRun, "dry-run", "n", false, "Show the issues without creating them")
+	planImportCmd.Flags().BoolVarP(&planYes, "yes", "y", false, "Skip confirmation prompt")
+	planImportCmd.Flags().StringSliceVarP(&planLabels, "label", "l", nil, "Label to add to every issue, e.g. the epic's (repeatable)")
+	planImportCmd.Flags().StringVar(&planStatus, "status", "", "Project status to put the issues in, instead of github.todo_value")
+	planCmd.AddCommand(planImportCmd)
+}
+
+// plannedIssue is an issue to create from a plan
+type plannedIssue struct {
+	Title    string
+	Labels   []string
+	Estimate string
+	Body     string
+}
+
+func runPlanImport(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	if _, ok := azureBoards(cfg); ok {
+		config.Fail(errs.New(errs.KindUsage, "Can't create Azure Boards work items; gwi plan import only creates GitHub issues"))
+	}
+	overrideStatus(cfg, statusTodo, planStatus)
+
+	issues, err := readPlan(args[0])
+	if err != nil {
+		config.Fail(err)
+	}
+	for i := range issues {
+		issues[i].Labels = appendUnique(issues[i].Labels, planLabels...)
+	}
+
+	config.Info("%d issue(s) in %s:", len(issues), args[0])
+	for _, issue := range issues {
+		line := "  - " + issue.Title
+		if len(issue.Labels) > 0 {
+			line += " " + config.Dim("["+strings.Join(issue.Labels, ", ")+"]")
+		}
+		if issue.Estimate != "" {
+			line += " " + config.Dim("("+issue.Estimate+")")
+		}
+		fmt.Fprintln(os.Stderr, line)
+	}
+	if planDryRun {
+		return
+	}
+	if !planYes {
+		if config.Headless() {
+			config.Fail(errs.New(errs.KindUsage, "Nobody can confirm creating %d issue(s) without a terminal; pass --yes", len(issues)))
+		}
+		if !confirmPrompt(fmt.Sprintf("Create %d issue(s)?", len(issues))) {
+			return
+		}
+	}
+
+	projects := cfg.GitHub.ProjectsEnabled
+	if !projects && slices.ContainsFunc(issues, func(issue plannedIssue) bool { return issue.Estimate != "" }) {
+		config.Warn("Estimates are kept in a project field; they are dropped with github.projects_enabled off")
+	}
+	var owner, repo string
+	if projects {
+		if owner, repo, err = github.CurrentRepo(); err != nil {
+			config.Fail(err)
+		}
+		if cfg.GitHub.CheckScopes {
+			if err := github.CheckProjectScopes(cfg); errors.Is(err, github.ErrProjectsSkipped) {
+				config.Info("Not adding the issues to a project (github.missing_scopes: skip)")
+				projects = false
+			} else if err != nil {
+				config.Fail(err)
+			}
+		}
+	}
+
+	failed := 0
+	for _, issue := range issues {
+		number, err := github.CreateIssue(issue.Title, issue.Body, issue.Labels...)
+		if err != nil {
+			config.Error("Failed to create %q: %v", issue.Title, err)
+			failed++
+			continue
+		}
+		config.Success("Created #%d %s", number, issue.Title)
+		fmt.Println(number)
+
+		if projects {
+			projects = addPlannedToProjects(cfg, owner, repo, number, issue)
+		}
+	}
+
+	if failed > 0 {
+		config.Fail(errs.New(errs.KindGeneral, "%d of %d issue(s) could not be created", failed, len(issues)))
+	}
+}
+
+// addPlannedToProjects puts a created issue on the selected projects in the
+// Todo status and sets its estimate. It returns false when the projects can't
+// be found, so the remaining issues don't repeat the same warning.
+func addPlannedToProjects(cfg *config.Config, owner, repo string, number int, issue plannedIssue) bool {
+	items, err := github.AddIssueToProjects(cfg, owner, repo, number)
+	if err != nil {
+		config.Warn("Not adding the issues to a project: %v", err)
+		return false
+	}
+	if len(items) == 0 {
+		config.Warn("Not adding the issues to a project: %s/%s has no selected project", owner, repo)
+		return false
+	}
+
+	status := statusValue(cfg, statusTodo)
+	for _, item := range items {
+		if err := github.SetProjectItemStatus(item, status, cfg); err != nil {
+			config.Warn("#%d: could not set the status in %s: %v", number, item.ProjectTitle, err)
+		}
+		if issue.Estimate == "" {
+			continue
+		}
+		if err := github.SetProjectItemField(item, cfg.GitHub.EstimateField, issue.Estimate); err != nil {
+			config.Warn("#%d: could not set the estimate in %s: %v", number, item.ProjectTitle, err)
+		}
+	}
+	return true
+}
+
+// readPlan reads the issues of a plan file, or of stdin for "-"
+func readPlan(path string) ([]plannedIssue, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errs.NotFound("Failed to read the plan: %v", err)
+	}
+
+	var issues []plannedIssue
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		if issues, err = parsePlanCSV(string(data)); err != nil {
+			return nil, errs.New(errs.KindUsage, "Invalid plan %s: %v", path, err)
+		}
+	} else {
+		issues = parsePlanMarkdown(string(data))
+	}
+	if len(issues) == 0 {
+		return nil, errs.New(errs.KindUsage, "No issues found in %s: expected CSV rows or unchecked Markdown tasks (- [ ] Title)", path)
+	}
+	return issues, nil
+}
+
+// parsePlanCSV reads issues from CSV rows. Columns are title, labels,
+// estimate and body, unless a header row starting with "title" names them.
+func parsePlanCSV(text string) ([]plannedIssue, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{"title": 0, "labels": 1, "estimate": 2, "body": 3}
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "title") {
+		columns = make(map[string]int)
+		for i, name := range records[0] {
+			switch name = strings.ToLower(strings.TrimSpace(name)); name {
+			case "label":
+				name = "labels"
+			case "description":
+				name = "body"
+			}
+			columns[name] = i
+		}
+		records = records[1:]
+	}
+
+	cell := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var issues []plannedIssue
+	for _, record := range records {
+		issue := plannedIssue{
+			Title:    cell(record, "title"),
+			Labels:   splitLabels(cell(record, "labels")),
+			Estimate: cell(record, "estimate"),
+			Body:     cell(record, "body"),
+		}
+		if issue.Title != "" {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// parsePlanMarkdown reads issues from the unchecked tasks of a Markdown
+// checklist: "- [ ] Title | labels | estimate". Lines indented below a task,
+// nested tasks included, form its body; those of a checked task are skipped
+// with it.
+func parsePlanMarkdown(text string) []plannedIssue {
+	var issues []plannedIssue
+	var current *plannedIssue
+	var body []string
+	done := false
+	indent := 0
+	inFence := false
+
+	finish := func() {
+		if current != nil && !done {
+			current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			issues = append(issues, *current)
+		}
+		current, body = nil, nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		depth := len(line) - len(strings.TrimLeft(line, " \t"))
+		fence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+
+		if current != nil && (trimmed == "" || inFence || depth > indent) {
+			body = append(body, line[min(depth, indent+2):])
+			if fence {
+				inFence = !inFence
+			}
+			continue
+		}
+		finish()
+
+		if fence {
+			inFence = !inFence
+		}
+		match := checklistItem.FindStringSubmatch(line)
+		if inFence || match == nil {
+			continue
+		}
+		fields := strings.Split(match[2], "|")
+		current = &plannedIssue{Title: strings.TrimSpace(fields[0])}
+		if len(fields) > 1 {
+			current.Labels = splitLabels(fields[1])
+		}
+		if len(fields) > 2 {
+			current.Estimate = strings.TrimSpace(fields[2])
+		}
+		done = match[1] != " "
+		indent = depth
+	}
+	finish()
+	return issues
+}
+
+// splitLabels splits a list of labels separated by commas or semicolons
+func splitLabels(list string) []string {
+	var labels []string
+	for _, label := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ';' }) {
+		labels = appendUnique(labels, strings.TrimSpace(label))
+	}
+	return labels
+}
+
+// appendUnique appends the non-empty values that aren't in list yet, ignoring case
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if value != "" && !slices.ContainsFunc(list, func(item string) bool { return strings.EqualFold(item, value) }) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -4,55 +4,121 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
 	"github.com/spf13/cobra"
 )
 
 var prCmd = &cobra.Command{
 	Use:   "pr [issue-number]",
 	Short: "Push and create PR",
-	Long:  `Push branch, create a pull request with "Closes #N", then remove the worktree.`,
-	Args:  cobra.MaximumNArgs(1),
-	Run:   runPR,
+	Long: `Push branch, create a pull request with "Closes #N", then remove the worktree.
+
+The PR body comes from a template in .github/PULL_REQUEST_TEMPLATE: the one named with
+--template, the one pr.templates maps an issue label to, or one named after a label.
+Otherwise the repository's default PR template is used, if any. Templates can use the
+issue context: {{.Number}}, {{.Title}}, {{.Labels}}, {{.Branch}} and {{.Closes}}.
+
+Before pushing, a size summary is printed: files and lines changed, the largest
+files, and changed migrations and lockfiles. It warns when the PR exceeds
+pr.size.max_files or pr.size.max_lines; --size-in-body adds it to the PR body.
+
+Commit trailers such as "Changelog:", "Breaking:" and "Test-Plan:" are collected
+from the branch's commits into sections of the PR body, as pr.sections configures.
+
+In a monorepo with pr.components, the title is scoped to the components whose
+paths the diff touches (e.g. "api: Fix login timeout") and their labels are
+added. Without any, the component of the current directory is used. Name the
+components with --component, or leave the title alone with --no-component.
+
+With --verify, or always with verify.on_pr, the verify pipeline (see gwi verify)
+runs first: the PR isn't created when a step fails, and the passed steps are
+listed in a "Verification" section of the body.
+
+Before pushing, the unpushed commits are checked for files of large_files.max_mb
+or more that aren't in Git LFS. With Git LFS installed, gwi offers to migrate
+them, which rewrites those commits; --lfs-migrate does so without asking.
+Otherwise it warns, or refuses to push with large_files.block or when a file is
+over GitHub's 100MB limit.
+
+With --wait-checks, the PR's checks are followed until they finish; gwi pr exits
+with code 7 when one of them failed or they're still pending after --checks-timeout.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPR,
+}
+
+var prAmendTitleCmd = &cobra.Command{
+	Use:   "amend-title [issue-number]",
+	Short: "Retitle a PR from its conventional commits",
+	Long: `Derive the pull request title from the branch's conventional commits
+(e.g. "feat(api): add pagination") and update the existing PR after confirmation.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runPRAmendTitle,
+}
+
+var (
+	prOpen     bool
+	prNoOpen   bool
+	prSnapshot bool
+	prNoReview bool
+
+	prConventionalTitle bool
+	prIssueTitle        bool
+	prTemplate          string
+	prSizeInBody        bool
+	prComponents        []string
+	prNoComponent       bool
+	prStatus            string
+	prWaitChecks        bool
+	prChecksTimeout     time.Duration
+	prVerify            bool
+	prNoVerify          bool
+	prLFSMigrate        bool
+)
+
+func init() {
+	prCmd.Flags().BoolVar(&prOpen, "open", false, "Open the created PR in the browser")
+	prCmd.Flags().BoolVar(&prNoOpen, "no-open", false, "Don't open the created PR in the browser")
+	prCmd.MarkFlagsMutuallyExclusive("open", "no-open")
+	prCmd.Flags().BoolVar(&prSnapshot, "snapshot", false, "Commit uncommitted changes as a WIP snapshot before pushing")
+	prCmd.Flags().BoolVar(&prNoReview, "no-reviewers", false, "Don't request reviews from CODEOWNERS")
+	prCmd.Flags().BoolVar(&prConventionalTitle, "conventional-title", false, "Derive the PR title from conventional commits")
+	prCmd.Flags().BoolVar(&prIssueTitle, "issue-title", false, "Use the issue title as PR title (overrides pr.conventional_title)")
+	prCmd.MarkFlagsMutuallyExclusive("conventional-title", "issue-title")
+	prCmd.Flags().StringVar(&prTemplate, "template", "", "PR template from .github/PULL_REQUEST_TEMPLATE to use, e.g. bugfix")
+	prCmd.Flags().BoolVar(&prSizeInBody, "size-in-body", false, "Append the size summary to the PR body")
+	prCmd.Flags().StringSliceVar(&prComponents, "component", nil, "Components to scope the PR title to, instead of detecting them")
+	prCmd.Flags().BoolVar(&prNoComponent, "no-component", false, "Don't scope the PR title to the components it touches")
+	prCmd.Flags().BoolVar(&prWaitChecks, "wait-checks", false, "Wait for the PR's checks to finish and fail if any of them failed")
+	prCmd.Flags().DurationVar(&prChecksTimeout, "checks-timeout", 30*time.Minute, "How long --wait-checks waits for pending checks")
+	prCmd.Flags().StringVar(&prStatus, "status", "", "Status to move the issue to instead of the in-review value, e.g. \"Review\"")
+	prCmd.MarkFlagsMutuallyExclusive("component", "no-component")
+	prCmd.Flags().BoolVar(&prVerify, "verify", false, "Run the verify pipeline first and don't create the PR when it fails")
+	prCmd.Flags().BoolVar(&prNoVerify, "no-verify", false, "Don't run the verify pipeline (overrides verify.on_pr)")
+	prCmd.MarkFlagsMutuallyExclusive("verify", "no-verify")
+	prCmd.Flags().BoolVar(&prLFSMigrate, "lfs-migrate", false, "Move large files of the unpushed commits to Git LFS without asking")
+	prCmd.AddCommand(prAmendTitleCmd)
 }
 
 func runPR(cmd *cobra.Command, args []string) {
 	cfg := config.Load()
+	overrideStatus(cfg, statusInReview, prStatus)
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
-	var issueNumber int
-	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
-
-	if len(args) > 0 {
-		issueNumber, err = strconv.Atoi(args[0])
-		if err != nil {
-			config.Die("Invalid issue number: %s", args[0])
-		}
-	} else {
-		// Try to detect from current directory
-		if num, ok := git.DetectIssueNumber(base); ok {
-			issueNumber = num
-		} else {
-			// Interactive selection
-			issueNumber, err = selectWorktree(repoInfo, cfg)
-			if err != nil {
-				config.Die("No worktree selected")
-			}
-		}
-	}
-
-	worktreePath := git.FindWorktreeByIssue(base, issueNumber)
-	if worktreePath == "" {
-		config.Die("No worktree found for issue #%d", issueNumber)
-	}
+	id, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	issueNumber := githubIssueNumber(id, "create a pull request")
+	branchName := filepath.Base(worktreePath)
 
 	// Check for uncommitted changes
 	if git.HasUncommittedChanges(worktreePath) {
@@ -65,49 +131,364 @@ func runPR(cmd *cobra.Command, args []string) {
 			}
 			fmt.Printf("  %s%s%s\n", config.Yellow(""), line, config.Yellow(""))
 		}
-		if !confirmPrompt("Continue anyway?") {
-			config.Die("Aborted. Commit your changes first.")
+		if prSnapshot || confirmPrompt("Snapshot them as a WIP commit and include them in the PR?") {
+			if err := takeSnapshot(cfg, worktreePath, branchName, "before pr", false); err != nil {
+				config.Fail(err)
+			}
+		} else if !confirmPrompt("Continue without them?") {
+			config.Fail(errs.Aborted("Aborted. Commit your changes first, or run: gwi snapshot"))
 		}
 	}
 
-	branchName := filepath.Base(worktreePath)
+	// verify.on_pr skips repositories without a pipeline; --verify requires one
+	var verified []verifyResult
+	if prVerify || (cfg.Verify.OnPR && !prNoVerify && len(verifySteps(cfg, repoInfo, worktreePath)) > 0) {
+		results, err := verifyWorktree(cfg, repoInfo, worktreePath)
+		if len(results) > 0 {
+			printVerifyResults(results)
+		}
+		if err != nil {
+			config.Fail(fmt.Errorf("%w\n\n  No PR was created. Fix it and run gwi pr again, or skip verifying with --no-verify", err))
+		}
+		verified = results
+	}
 
 	config.Info("Fetching issue #%d...", issueNumber)
-	issue, err := github.GetIssue(issueNumber)
+	issue, err := getIssue(cfg, issueNumber)
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
+	rules, _ := applyRules(cfg, issue, "pr", true)
+	base := git.RemoteRef(rules.baseBranch(cfg))
+
+	body := closingReference(cfg, issueNumber)
+	templatePath, err := findPRTemplate(cfg, worktreePath, prTemplate, issue.Labels)
+	if err != nil {
+		config.Fail(err)
+	}
+	if templatePath != "" {
+		config.Info("Using PR template: %s", filepath.Base(templatePath))
+		var labels []string
+		for _, label := range issue.Labels {
+			labels = append(labels, label.Name)
+		}
+		body, err = renderPRBody(templatePath, prTemplateData{
+			Number: issueNumber,
+			Title:  issue.Title,
+			Labels: strings.Join(labels, ", "),
+			Branch: branchName,
+			Closes: body,
+		})
+		if err != nil {
+			config.Fail(err)
+		}
+	}
+
+	if sections := trailerSections(cfg, worktreePath, base+"..HEAD"); len(sections) > 0 {
+		var headings []string
+		for _, section := range sections {
+			headings = append(headings, section.heading)
+		}
+		config.Info("Adding sections from commit trailers: %s", strings.Join(headings, ", "))
+		body = addPRSections(body, sections)
+	}
+
+	if len(verified) > 0 {
+		body = addPRSections(body, []prBodySection{verifySection(verified)})
+	}
+
+	// Before measuring, as migrating to LFS changes the diff
+	guardLargeFiles(cfg, worktreePath, prLFSMigrate)
+
+	if size, err := measurePR(worktreePath, base+"...HEAD"); err == nil && len(size.files) > 0 {
+		printPRSize(cfg, size)
+		if prSizeInBody || cfg.PR.Size.InBody {
+			body += "\n\n" + size.markdown()
+		}
+	}
+
+	guardSignedCommits(cfg, worktreePath, base+"..HEAD", "push")
+
 	config.Info("Pushing branch: %s", branchName)
 	if err := git.Push(worktreePath, branchName); err != nil {
-		config.Die("Failed to push: %v", err)
+		config.Fail(fmt.Errorf("failed to push: %w", err))
 	}
 
 	config.Info("Creating pull request...")
-	prURL, err := github.CreatePR(worktreePath, issue.Title, fmt.Sprintf("Closes #%d", issueNumber), branchName)
+	title := issue.Title
+	if !prIssueTitle && (prConventionalTitle || cfg.PR.ConventionalTitle) {
+		title = conventionalPRTitle(worktreePath, base+"..HEAD", issue.Title)
+	}
+	var componentNames, labels []string
+	if !prNoComponent {
+		components := cfg.PRComponents(repoInfo.Org, repoInfo.Repo)
+		if len(prComponents) > 0 {
+			componentNames = prComponents
+			components = slices.DeleteFunc(components, func(c config.PRComponent) bool {
+				return !slices.Contains(prComponents, c.Name)
+			})
+			labels = componentLabels(components)
+		} else if found := findPRComponents(components, worktreePath, base+"...HEAD"); len(found) > 0 {
+			for _, component := range found {
+				componentNames = append(componentNames, component.Name)
+			}
+			labels = componentLabels(found)
+			config.Info("Components: %s", strings.Join(componentNames, ", "))
+		}
+		title = componentTitle(title, componentNames)
+	}
+
+	prURL, err := github.CreatePR(worktreePath, title, body, branchName, rules.base)
 	if err != nil {
-		config.Die("Failed to create PR: %v", err)
+		config.Fail(fmt.Errorf("failed to create PR: %w", err))
 	}
 
 	config.Success("Pull request created: %s", prURL)
+	linkPRURL(repoInfo, branchName, prURL)
+	postProgress(cfg, issueNumber, progressPROpened, prURL)
+
+	if len(labels) > 0 {
+		if err := github.AddPRLabels(prURL, labels); err != nil {
+			config.Warn("%v", err)
+		}
+	}
+
+	if !prNoReview {
+		if reviewers := codeownerReviewers(worktreePath, base+"...HEAD"); len(reviewers) > 0 {
+			config.Info("Requesting review from: %s", strings.Join(reviewers, ", "))
+			if err := github.RequestReviewers(prURL, reviewers); err != nil {
+				config.Warn("Failed to request reviewers: %v", err)
+			}
+		}
+	}
 
-	// Update GitHub Project status to "In Review"
-	if cfg.GitHub.ProjectsEnabled {
-		if err := github.UpdateIssueStatus(issueNumber, cfg.GitHub.InReviewValue, cfg); err != nil {
-			if cfg.Verbose {
+	// Move the issue to "In Review" in GitHub Projects or Azure Boards
+	if tracksStatus(cfg) {
+		if updates, err := updateIssueStatus(cfg, issueNumber, statusInReview); err != nil {
+			if cfg.Verbose || prStatus != "" {
 				config.Warn("Failed to update project status: %v", err)
 			}
 		} else {
-			config.Info("Updated issue #%d to '%s' in GitHub Projects", issueNumber, cfg.GitHub.InReviewValue)
+			config.Info("Updated issue #%d to '%s' in %s", issueNumber, statusValue(cfg, statusInReview), trackerName(cfg))
+			printProjectUpdates(updates)
 		}
 	}
 
-	config.Info("Removing worktree...")
-	if err := git.RemoveWorktree(worktreePath, false); err != nil {
-		config.Warn("Failed to remove worktree: %v", err)
+	// Removing the worktree would discard leftover changes; keep them in a stash instead
+	removeWorktree := true
+	if git.HasUncommittedChanges(worktreePath) {
+		if err := takeSnapshot(cfg, worktreePath, branchName, "left over after pr", true); err != nil {
+			config.Warn("Keeping worktree, could not snapshot changes: %v", err)
+			removeWorktree = false
+		}
+	}
+
+	if removeWorktree {
+		config.Info("Removing worktree...")
+		if err := git.RemoveWorktree(worktreePath, false); err != nil {
+			config.Warn("Failed to remove worktree: %v", err)
+		}
+		index.Refresh(cfg, repoInfo)
+		forgetActiveIssue(repoInfo, issueNumber)
 	}
 
 	config.Success("Done! PR is ready for review.")
+
+	if shouldOpenBrowser(prOpen, prNoOpen, cfg) {
+		if err := github.OpenPRInBrowser(prURL); err != nil {
+			config.Warn("Failed to open browser: %v", err)
+		}
+	}
+
+	// Final line on stdout so the URL is easy to copy or pipe
+	fmt.Println(prURL)
+
+	if prWaitChecks {
+		prNumber, err := strconv.Atoi(filepath.Base(prURL))
+		if err != nil {
+			config.Fail(errs.New(errs.KindGeneral, "Cannot wait for checks: no PR number in %s", prURL))
+		}
+		if err := waitForChecks(cfg, prNumber, prChecksTimeout); err != nil {
+			config.Fail(err)
+		}
+	}
+}
+
+// conventionalPRTitle previews the title derived from the commits in revRange
+// and returns it if confirmed, otherwise the fallback title
+func conventionalPRTitle(path, revRange, fallback string) string {
+	subjects, err := git.GetCommitSubjects(path, revRange)
+	if err != nil {
+		config.Warn("Failed to read commits: %v", err)
+		return fallback
+	}
+	title, ok := git.ConventionalTitle(subjects)
+	if !ok {
+		config.Warn("No conventional commits found, using the issue title")
+		return fallback
+	}
+
+	config.Info("Title from %d commit(s):", len(subjects))
+	fmt.Fprintf(os.Stderr, "  %s\n", config.Green(title))
+	if !confirmPrompt("Use this title?") {
+		return fallback
+	}
+	return title
+}
+
+func runPRAmendTitle(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	branchName, path := resolveIssueBranch(cfg, repoInfo, args)
+
+	prNumber, err := findOpenPRForBranch(repoInfo, branchName)
+	if err != nil {
+		config.Fail(errs.NotFound("No pull request found for %s", branchName))
+	}
+	pr := strconv.Itoa(prNumber)
+	current, _ := github.GetPRTitle(pr)
+
+	subjects, err := git.GetCommitSubjects(path, git.RemoteRef(cfg.MainBranch)+".."+branchName)
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to read commits: %w", err))
+	}
+	title, ok := git.ConventionalTitle(subjects)
+	if !ok {
+		config.Fail(errs.NotFound("No conventional commits found on %s", branchName))
+	}
+	if title == current {
+		config.Info("PR #%d already has this title: %s", prNumber, title)
+		return
+	}
+
+	config.Info("PR #%d title from %d commit(s):", prNumber, len(subjects))
+	fmt.Fprintf(os.Stderr, "  %s\n", config.Red(current))
+	fmt.Fprintf(os.Stderr, "  %s\n", config.Green(title))
+	if !confirmPrompt("Update the title?") {
+		config.Fail(errs.Aborted("Aborted"))
+	}
+	if err := github.EditPRTitle(pr, title); err != nil {
+		config.Fail(fmt.Errorf("failed to update PR title: %w", err))
+	}
+	config.Success("Updated PR #%d title", prNumber)
+}
+
+// resolveIssueBranch determines the target issue from args or the current
+// directory and returns its branch and a path to run git in
+func resolveIssueBranch(cfg *config.Config, repoInfo *git.RepoInfo, args []string) (string, string) {
+	var issueNumber int
+	var err error
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	if len(args) > 0 {
+		issueNumber, err = strconv.Atoi(args[0])
+		if err != nil {
+			config.Fail(errs.New(errs.KindUsage, "Invalid issue number: %s", args[0]))
+		}
+	} else if num, ok := git.DetectIssueNumber(base); ok {
+		issueNumber = num
+	} else {
+		config.Fail(errs.New(errs.KindUsage, "Specify an issue number, or run this inside an issue worktree"))
+	}
+
+	// gwi pr removes the worktree, so fall back to the local branch
+	path := git.FindWorktreeByIssue(base, issueNumber)
+	branchName := filepath.Base(path)
+	if path == "" {
+		branchName = issueBranch(issueNumber)
+		if branchName == "" {
+			config.Fail(errs.NotFound("No worktree or branch found for issue #%d", issueNumber))
+		}
+		if path, err = git.GetMainWorktreePath(); err != nil {
+			config.Fail(fmt.Errorf("failed to get main worktree: %w", err))
+		}
+	}
+
+	return branchName, path
+}
+
+// issueBranch returns the local branch for an issue ("<N>-..."), if any
+func issueBranch(issueNumber int) string {
+	branches, _ := git.GetLocalBranches()
+	prefix := fmt.Sprintf("%d-", issueNumber)
+	for _, branch := range branches {
+		if strings.HasPrefix(branch, prefix) {
+			return branch
+		}
+	}
+	return ""
+}
+
+// codeownerReviewers shows which CODEOWNERS entries match the changed files
+// and returns the owners that can be requested as reviewers
+func codeownerReviewers(worktreePath, revRange string) []string {
+	co, err := github.LoadCodeowners(worktreePath)
+	if err != nil {
+		config.Warn("Failed to read CODEOWNERS: %v", err)
+		return nil
+	}
+	if co == nil {
+		return nil
+	}
+	files, err := git.GetChangedFiles(worktreePath, revRange)
+	if err != nil || len(files) == 0 {
+		return nil
+	}
+
+	// Group changed files by the rule that owns them, in CODEOWNERS order
+	counts := make(map[*github.CodeownersRule]int)
+	unowned := 0
+	for _, file := range files {
+		if rule := co.Match(file); rule != nil && len(rule.Owners) > 0 {
+			counts[rule]++
+		} else {
+			unowned++
+		}
+	}
+
+	self, _ := github.CurrentUser()
+	var reviewers []string
+	seen := make(map[string]bool)
+
+	config.Info("Code owners (%s):", co.Path)
+	for i := range co.Rules {
+		rule := &co.Rules[i]
+		if counts[rule] == 0 {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  %s → %s (%d files)\n", rule.Pattern, strings.Join(rule.Owners, " "), counts[rule])
+		for _, owner := range rule.Owners {
+			// Emails can't be requested through gh, and authors can't review their own PR
+			if !strings.HasPrefix(owner, "@") {
+				continue
+			}
+			name := strings.TrimPrefix(owner, "@")
+			if strings.EqualFold(name, self) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			reviewers = append(reviewers, name)
+		}
+	}
+	if unowned > 0 {
+		fmt.Fprintf(os.Stderr, "  (no owner) (%d files)\n", unowned)
+	}
+	return reviewers
+}
+
+// shouldOpenBrowser resolves --open/--no-open against the open_browser setting
+func shouldOpenBrowser(open, noOpen bool, cfg *config.Config) bool {
+	if open {
+		return true
+	}
+	if noOpen {
+		return false
+	}
+	return cfg.OpenBrowser
 }
 
 func confirmPrompt(prompt string) bool {
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// findPRComponents returns the pr.components whose paths the changes in
+// revRange touch, in configuration order. When the changes touch none of
+// them, the component of the current directory is used, so running gwi pr
+// from a package's directory scopes the PR to that package.
+func findPRComponents(components []config.PRComponent, worktreePath, revRange string) []config.PRComponent {
+	if len(components) == 0 {
+		return nil
+	}
+
+	touched := make([]bool, len(components))
+	if files, err := git.GetChangedFiles(worktreePath, revRange); err == nil {
+		for _, file := range files {
+			if i := componentOf(components, file); i >= 0 {
+				touched[i] = true
+			}
+		}
+	}
+
+	var found []config.PRComponent
+	for i, component := range components {
+		if touched[i] {
+			found = append(found, component)
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(worktreePath, cwd)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	if i := componentOf(components, filepath.ToSlash(rel)+"/"); i >= 0 {
+		return components[i : i+1]
+	}
+	return nil
+}
+
+// componentOf returns the index of the first component with a path prefix
+// of file, or -1
+func componentOf(components []config.PRComponent, file string) int {
+	for i, component := range components {
+		for _, prefix := range component.Paths {
+			if strings.HasPrefix(file, strings.TrimPrefix(prefix, "./")) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// componentTitle scopes a PR title to components: conventional titles without
+// a scope get them as scope ("fix(api): ..."), other titles a prefix ("api: ...").
+// Titles that already carry the scope or prefix are left alone.
+func componentTitle(title string, names []string) string {
+	if len(names) == 0 {
+		return title
+	}
+	scope := strings.Join(names, ",")
+	if commit, ok := git.ParseConventionalCommit(title); ok {
+		if commit.Scope == "" {
+			commit.Scope = scope
+		}
+		return commit.String()
+	}
+	prefix := strings.Join(names, ", ") + ": "
+	if strings.HasPrefix(strings.ToLower(title), strings.ToLower(prefix)) {
+		return title
+	}
+	return prefix + title
+}
+
+// componentLabels collects the labels of components, without duplicates
+func componentLabels(components []config.PRComponent) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for _, component := range components {
+		for _, label := range component.Labels {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
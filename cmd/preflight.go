@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// preflight collects the problems found before a destructive operation, so
+// they can be reported together instead of failing one at a time halfway through
+type preflight struct {
+	action    string
+	worktrees []git.WorktreeInfo
+	blockers  []string
+	warnings  []string
+}
+
+func newPreflight(action string) *preflight {
+	worktrees, _ := git.ListAllWorktrees()
+	return &preflight{action: action, worktrees: worktrees}
+}
+
+func (p *preflight) block(format string, args ...interface{}) {
+	p.blockers = append(p.blockers, fmt.Sprintf(format, args...))
+}
+
+func (p *preflight) warn(format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, args...))
+}
+
+// checkWorktreeRemoval makes sure path isn't the repository's only worktree
+func (p *preflight) checkWorktreeRemoval(path string) {
+	if len(p.worktrees) == 1 && samePath(p.worktrees[0].Path, path) {
+		p.block("%s is the repository's only worktree", path)
+	}
+}
+
+// checkBranchDeletion reports whether branch can be deleted locally: it must
+// not be checked out in any worktree other than the one being removed
+func (p *preflight) checkBranchDeletion(branch, removedPath string) bool {
+	for _, wt := range p.worktrees {
+		if wt.Branch == branch && (removedPath == "" || !samePath(wt.Path, removedPath)) {
+			p.warn("branch %s is checked out in %s; keeping it", branch, wt.Path)
+			return false
+		}
+	}
+	return true
+}
+
+// checkRemoteDeletion reports whether the remote branch can be deleted: the
+// user needs push access, and deleting it must not close an open pull request
+func (p *preflight) checkRemoteDeletion(branch string) bool {
+	if !git.RemoteBranchExists(branch) {
+		return false
+	}
+	if prNumber, err := github.GetPRForBranch(branch); err == nil {
+		p.warn("PR #%d for %s is still open; keeping the remote branch", prNumber, branch)
+		return false
+	}
+	if ok, err := github.HasPushAccess(); err == nil && !ok {
+		p.warn("no push access to %s; keeping the remote branch %s", git.PushRemote(), branch)
+		return false
+	}
+	return true
+}
+
+// checkPushAccess blocks operations that have to push to the push remote
+func (p *preflight) checkPushAccess() {
+	if ok, err := github.HasPushAccess(); err == nil && !ok {
+		p.block("no push access to %s", git.PushRemote())
+	}
+}
+
+// report prints the consolidated summary and stops if anything blocks the action
+func (p *preflight) report() {
+	if len(p.blockers) == 0 && len(p.warnings) == 0 {
+		return
+	}
+
+	config.Warn("Before you %s:", p.action)
+	for _, blocker := range p.blockers {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Red(config.Glyph("failure")), blocker)
+	}
+	for _, warning := range p.warnings {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Yellow("!"), warning)
+	}
+	if len(p.blockers) > 0 {
+		config.Fail(errs.Conflict("Refusing to %s.", p.action))
+	}
+}
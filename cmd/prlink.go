@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// prKeyPrefix namespaces the pull requests linked to worktree branches
+const prKeyPrefix = "pr/"
+
+// linkedPR records the pull request of a branch, whether it was opened by gwi
+// pr or elsewhere, so later commands don't have to look it up again
+type linkedPR struct {
+	Number int       `json:"number"`
+	Linked time.Time `json:"linked"`
+}
+
+func prKey(repoInfo *git.RepoInfo, branch string) string {
+	return fmt.Sprintf("%s%s/%s/%s", prKeyPrefix, repoInfo.Org, repoInfo.Repo, branch)
+}
+
+// linkPR associates a pull request with a branch
+func linkPR(repoInfo *git.RepoInfo, branch string, prNumber int) {
+	store.Update(func(s *store.Store) error {
+		return setPRLink(s, prKey(repoInfo, branch), prNumber)
+	})
+}
+
+// setPRLink stores a link unless it is already there, keeping its timestamp
+func setPRLink(s *store.Store, key string, prNumber int) error {
+	var current linkedPR
+	if s.Get(key, &current) && current.Number == prNumber {
+		return nil
+	}
+	return s.Set(key, linkedPR{Number: prNumber, Linked: time.Now()})
+}
+
+// linkPRURL links the pull request at a github.com URL, as printed by gh pr create
+func linkPRURL(repoInfo *git.RepoInfo, branch, prURL string) {
+	if prNumber, err := strconv.Atoi(filepath.Base(prURL)); err == nil {
+		linkPR(repoInfo, branch, prNumber)
+	}
+}
+
+// unlinkPR forgets the pull request of a branch that was removed
+func unlinkPR(repoInfo *git.RepoInfo, branch string) {
+	store.Update(func(s *store.Store) error {
+		s.Delete(prKey(repoInfo, branch))
+		return nil
+	})
+}
+
+// linkedPRNumber returns the pull request linked to a branch, if any
+func linkedPRNumber(repoInfo *git.RepoInfo, branch string) (int, bool) {
+	s, err := store.Load()
+	if err != nil {
+		return 0, false
+	}
+	var link linkedPR
+	if !s.Get(prKey(repoInfo, branch), &link) {
+		return 0, false
+	}
+	return link.Number, true
+}
+
+// findPRForBranch returns the pull request of a branch: the linked one, or
+// the open one on GitHub, which is then linked. The linked PR may have been
+// merged or closed since, so check its state where that matters.
+func findPRForBranch(repoInfo *git.RepoInfo, branch string) (int, error) {
+	if prNumber, ok := linkedPRNumber(repoInfo, branch); ok {
+		return prNumber, nil
+	}
+	prNumber, err := github.GetPRForBranch(branch)
+	if err != nil {
+		return 0, err
+	}
+	linkPR(repoInfo, branch, prNumber)
+	return prNumber, nil
+}
+
+// findOpenPRForBranch returns the open pull request of a branch. A linked PR
+// that was closed without merging is replaced by a newer open one, if any.
+func findOpenPRForBranch(repoInfo *git.RepoInfo, branch string) (int, error) {
+	if prNumber, ok := linkedPRNumber(repoInfo, branch); ok {
+		if state, err := github.GetPRState(prNumber); err == nil && state == "OPEN" {
+			return prNumber, nil
+		}
+	}
+	prNumber, err := github.GetPRForBranch(branch)
+	if err != nil {
+		return 0, err
+	}
+	linkPR(repoInfo, branch, prNumber)
+	return prNumber, nil
+}
+
+// linkOpenPRs links the open pull requests of the given branches with a
+// single GitHub call, picking up PRs opened outside gwi
+func linkOpenPRs(repoInfo *git.RepoInfo, branches []string) {
+	prs, err := github.ListOpenPRs()
+	if err != nil {
+		return
+	}
+	wanted := make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		wanted[branch] = true
+	}
+	store.Update(func(s *store.Store) error {
+		for _, pr := range prs {
+			if wanted[pr.HeadRefName] {
+				if err := setPRLink(s, prKey(repoInfo, pr.HeadRefName), pr.Number); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
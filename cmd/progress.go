@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// Progress events posted on the issue
+const (
+	progressCreated  = "created"
+	progressPROpened = "pr-opened"
+	progressMerged   = "merged"
+)
+
+// progressStatusMarker identifies the single comment progress.mode status edits
+const progressStatusMarker = "<!-- gwi:status -->"
+
+// progressTexts describe the events for collaborators reading the issue
+var progressTexts = map[string]string{
+	progressCreated:  "Work started in a worktree",
+	progressPROpened: "Pull request opened",
+	progressMerged:   "Merged",
+}
+
+// postProgress records an event on the issue's timeline according to
+// progress.mode, so collaborators can follow the work without asking. detail,
+// such as the branch or PR URL, is appended to the event's text. Failures only
+// warn; progress updates never stop the command.
+func postProgress(cfg *config.Config, issueNumber int, event, detail string) {
+	text := progressTexts[event]
+	if detail != "" {
+		text += ": " + detail
+	}
+
+	var err error
+	switch mode := strings.ToLower(cfg.Progress.Mode); mode {
+	case "", "off":
+		return
+	case "comments":
+		// Merging already closes the issue with a comment
+		if event == progressMerged {
+			return
+		}
+		err = postProgressComment(cfg, issueNumber, event, text)
+	case "status":
+		// Work item comments can't be edited through gwi's Azure client
+		if _, ok := azureBoards(cfg); ok {
+			err = postProgressComment(cfg, issueNumber, event, text)
+			break
+		}
+		err = updateProgressStatus(issueNumber, text)
+	default:
+		config.Warn("Unknown progress.mode %q, expected off, comments or status", mode)
+		return
+	}
+	if err != nil {
+		config.Warn("Failed to post progress on #%d: %v", issueNumber, err)
+	}
+}
+
+// postProgressComment adds a comment for one event, marked so tools can find it
+func postProgressComment(cfg *config.Config, issueNumber int, event, text string) error {
+	return commentOnIssue(cfg, issueNumber, fmt.Sprintf("<!-- gwi:progress event=%s -->\n%s", event, text))
+}
+
+// updateProgressStatus appends a row to the issue's "gwi status" comment,
+// creating the comment on the first event
+func updateProgressStatus(issueNumber int, text string) error {
+	row := fmt.Sprintf("| %s | %s |", time.Now().UTC().Format("2006-01-02 15:04 UTC"), strings.ReplaceAll(text, "|", `\|`))
+
+	comment, err := github.FindIssueComment(issueNumber, progressStatusMarker)
+	if err != nil {
+		return err
+	}
+	if comment == nil {
+		body := strings.Join([]string{progressStatusMarker, "**gwi status**", "", "| When | Progress |", "| --- | --- |", row}, "\n")
+		return github.CommentOnIssue(issueNumber, body)
+	}
+	return github.EditIssueComment(comment.ID, strings.TrimRight(comment.Body, "\n")+"\n"+row)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// prBodySection is a section of the PR body and the trailer values it lists
+type prBodySection struct {
+	heading string
+	items   []string
+}
+
+// trailerSections collects the values of the pr.sections trailers of the
+// commits in revRange, in the configured order. Sections without any are left
+// out, as are values repeated by several commits.
+func trailerSections(cfg *config.Config, worktreePath, revRange string) []prBodySection {
+	if len(cfg.PR.Sections) == 0 {
+		return nil
+	}
+	trailers, err := git.GetTrailers(worktreePath, revRange)
+	if err != nil {
+		config.Warn("Could not read commit trailers: %v", err)
+		return nil
+	}
+
+	var sections []prBodySection
+	for _, configured := range cfg.PR.Sections {
+		section := prBodySection{heading: configured.Heading}
+		if section.heading == "" {
+			section.heading = configured.Trailer
+		}
+		for _, trailer := range trailers {
+			if strings.EqualFold(trailer.Key, configured.Trailer) {
+				section.items = appendUnique(section.items, trailer.Value)
+			}
+		}
+		if len(section.items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// addPRSections fills the sections into the PR body. A section goes below a
+// heading of the body with the same text, such as one of the PR template;
+// otherwise it is appended as a new heading.
+func addPRSections(body string, sections []prBodySection) string {
+	for _, section := range sections {
+		list := "- " + strings.Join(section.items, "\n- ")
+		heading := regexp.MustCompile(`(?im)^#{1,6}[ \t]+` + regexp.QuoteMeta(section.heading) + `[ \t]*:?[ \t]*$`)
+		if loc := heading.FindStringIndex(body); loc != nil {
+			body = body[:loc[1]] + "\n\n" + list + "\n" + body[loc[1]:]
+			continue
+		}
+		body = strings.TrimRight(body, "\n") + "\n\n### " + section.heading + "\n\n" + list + "\n"
+	}
+	return body
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// prSizeLargest is how many of the largest files the size summary names
+const prSizeLargest = 3
+
+// migrationDirs are directories holding database migrations in common frameworks
+var migrationDirs = []string{"db/migrate/", "migrations/", "alembic/versions/", "prisma/migrations/"}
+
+// prSize summarizes the changes a PR is about to propose
+type prSize struct {
+	files      []git.FileStat // Largest first
+	added      int
+	deleted    int
+	lines      int // Lines changed, lockfiles excluded
+	migrations []string
+	lockfiles  []string
+}
+
+// measurePR collects the size of the changes in revRange
+func measurePR(worktreePath, revRange string) (*prSize, error) {
+	stats, err := git.GetDiffStats(worktreePath, revRange)
+	if err != nil {
+		return nil, err
+	}
+	size := &prSize{files: stats}
+	for _, stat := range stats {
+		size.added += stat.Added
+		size.deleted += stat.Deleted
+		if isLockfile(stat.Path) {
+			size.lockfiles = append(size.lockfiles, stat.Path)
+			continue
+		}
+		size.lines += stat.Added + stat.Deleted
+		if isMigration(stat.Path) {
+			size.migrations = append(size.migrations, stat.Path)
+		}
+	}
+	sort.SliceStable(size.files, func(i, j int) bool {
+		return size.files[i].Added+size.files[i].Deleted > size.files[j].Added+size.files[j].Deleted
+	})
+	return size, nil
+}
+
+// isLockfile reports whether path is a dependency lockfile gwi knows
+func isLockfile(path string) bool {
+	for _, lock := range deps.Lockfiles {
+		if filepath.Base(path) == lock.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// isMigration reports whether path is in a database migrations directory
+func isMigration(path string) bool {
+	for _, dir := range migrationDirs {
+		if strings.HasPrefix(path, dir) || strings.Contains(path, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// largest describes the files with the most changed lines, lockfiles excluded
+func (s *prSize) largest(quote string) []string {
+	var names []string
+	for _, stat := range s.files {
+		if len(names) == prSizeLargest {
+			break
+		}
+		if isLockfile(stat.Path) || stat.Binary {
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s%s%s (+%d -%d)", quote, stat.Path, quote, stat.Added, stat.Deleted))
+	}
+	return names
+}
+
+// totals is the one-line size, e.g. "12 files changed, +340 -120"
+func (s *prSize) totals() string {
+	return fmt.Sprintf("%d file(s) changed, +%d -%d", len(s.files), s.added, s.deleted)
+}
+
+// tooLarge explains which of the configured thresholds the PR exceeds
+func (s *prSize) tooLarge(cfg *config.Config) []string {
+	var reasons []string
+	if max := cfg.PR.Size.MaxFiles; max > 0 && len(s.files) > max {
+		reasons = append(reasons, fmt.Sprintf("%d files changed (pr.size.max_files: %d)", len(s.files), max))
+	}
+	if max := cfg.PR.Size.MaxLines; max > 0 && s.lines > max {
+		reasons = append(reasons, fmt.Sprintf("%d lines changed (pr.size.max_lines: %d)", s.lines, max))
+	}
+	return reasons
+}
+
+// printPRSize shows the size summary before the PR is created, warning when
+// it exceeds the thresholds
+func printPRSize(cfg *config.Config, s *prSize) {
+	config.Info("PR size: %s", s.totals())
+	if largest := s.largest(""); len(largest) > 0 {
+		fmt.Fprintf(os.Stderr, "  Largest: %s\n", strings.Join(largest, ", "))
+	}
+	if len(s.migrations) > 0 {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Yellow("Migrations:"), strings.Join(s.migrations, ", "))
+	}
+	if len(s.lockfiles) > 0 {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Yellow("Lockfiles:"), strings.Join(s.lockfiles, ", "))
+	}
+	if reasons := s.tooLarge(cfg); len(reasons) > 0 {
+		config.Warn("Large PR: %s. Consider splitting it into smaller PRs that are easier to review.", strings.Join(reasons, ", "))
+	}
+}
+
+// markdown renders the size summary for the PR body
+func (s *prSize) markdown() string {
+	lines := []string{"### Size", "", s.totals(), ""}
+	if largest := s.largest("`"); len(largest) > 0 {
+		lines = append(lines, "- Largest: "+strings.Join(largest, ", "))
+	}
+	if len(s.migrations) > 0 {
+		lines = append(lines, "- Migrations: `"+strings.Join(s.migrations, "`, `")+"`")
+	}
+	if len(s.lockfiles) > 0 {
+		lines = append(lines, "- Lockfiles: `"+strings.Join(s.lockfiles, "`, `")+"`")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// prTemplateDir holds a repository's named PR templates, as used by GitHub
+const prTemplateDir = ".github/PULL_REQUEST_TEMPLATE"
+
+// defaultPRTemplates are the places GitHub looks for a repository's PR template
+var defaultPRTemplates = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+// prTemplateData is the issue context templates are rendered with, e.g.
+// "Fixes {{.Title}} ({{.Closes}})"
+type prTemplateData struct {
+	Number int
+	Title  string
+	Labels string // Comma-separated
+	Branch string
+	Closes string // "Closes #42", or "Fixes AB#42" for Azure Boards
+}
+
+// namedPRTemplate returns the path of a template in prTemplateDir, with or
+// without its .md extension, or "" if there is none
+func namedPRTemplate(worktreePath, name string) string {
+	dir := filepath.Join(worktreePath, prTemplateDir)
+	for _, candidate := range []string{name, name + ".md"} {
+		path := filepath.Join(dir, filepath.Base(candidate))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// availablePRTemplates lists the names of the templates in prTemplateDir
+func availablePRTemplates(worktreePath string) []string {
+	entries, _ := os.ReadDir(filepath.Join(worktreePath, prTemplateDir))
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".md") {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".md"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findPRTemplate picks the PR template for an issue: the one named with
+// --template, the one pr.templates maps one of its labels to, one named after
+// a label, or the repository's default template. It returns "" without any.
+func findPRTemplate(cfg *config.Config, worktreePath, name string, labels []github.Label) (string, error) {
+	if name != "" {
+		if path := namedPRTemplate(worktreePath, name); path != "" {
+			return path, nil
+		}
+		available := availablePRTemplates(worktreePath)
+		if len(available) == 0 {
+			return "", errs.NotFound("No PR template %q: %s has no templates", name, prTemplateDir)
+		}
+		return "", errs.NotFound("No PR template %q in %s (available: %s)", name, prTemplateDir, strings.Join(available, ", "))
+	}
+
+	for _, label := range labels {
+		if mapped, ok := cfg.PR.Templates[label.Name]; ok {
+			if path := namedPRTemplate(worktreePath, mapped); path != "" {
+				return path, nil
+			}
+			config.Warn("PR template %q for label %s not found in %s", mapped, label.Name, prTemplateDir)
+		}
+	}
+	for _, label := range labels {
+		if path := namedPRTemplate(worktreePath, label.Name); path != "" {
+			return path, nil
+		}
+	}
+
+	for _, candidate := range defaultPRTemplates {
+		path := filepath.Join(worktreePath, candidate)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// renderPRBody fills in a PR template with the issue context. The body always
+// links the issue, so the closing reference is added if the template lacks it.
+// Templates that aren't valid Go templates are used as they are.
+func renderPRBody(path string, data prTemplateData) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	body := string(content)
+	if tmpl, err := template.New(filepath.Base(path)).Parse(body); err != nil {
+		config.Warn("Using PR template %s as is: %v", filepath.Base(path), err)
+	} else {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		body = buf.String()
+	}
+
+	if !strings.Contains(body, data.Closes) {
+		body = data.Closes + "\n\n" + body
+	}
+	return strings.TrimSpace(body) + "\n", nil
+}
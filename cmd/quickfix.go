@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var quickfixCmd = &cobra.Command{
+	Use:   "quickfix <description>",
+	Short: "Issue, worktree, commit and PR for a one-line fix in one go",
+	Long: `Run the whole cycle for a tiny change: create an issue titled after the description
+(or use --issue), create its worktree, wait while you make the change there (or apply
+--patch), commit it with the description as message, then push and open the PR as
+gwi pr does.
+
+With --auto-merge, gwi waits for the PR's checks and merges it once they all passed,
+with merge_strategy. It exits with code 7 when a check fails; the PR stays open.`,
+	Example: `  gwi quickfix "Fix typo in the install instructions"
+  git diff | gwi quickfix "Bump the timeout to 30s" --patch - --auto-merge`,
+	Args: cobra.ExactArgs(1),
+	Run:  runQuickfix,
+}
+
+var (
+	quickfixIssue         int
+	quickfixPatch         string
+	quickfixLabels        []string
+	quickfixAutoMerge     bool
+	quickfixChecksTimeout time.Duration
+)
+
+func init() {
+	quickfixCmd.Flags().IntVar(&quickfixIssue, "issue", 0, "Fix this issue instead of creating one")
+	quickfixCmd.Flags().StringVar(&quickfixPatch, "patch", "", "Apply this patch instead of waiting for the change (- reads it from stdin)")
+	quickfixCmd.Flags().StringSliceVarP(&quickfixLabels, "label", "l", nil, "Label to put on the created issue (repeatable)")
+	quickfixCmd.Flags().BoolVar(&quickfixAutoMerge, "auto-merge", false, "Merge the PR once its checks passed")
+	quickfixCmd.Flags().DurationVar(&quickfixChecksTimeout, "checks-timeout", 30*time.Minute, "How long --auto-merge waits for pending checks")
+	quickfixCmd.MarkFlagsMutuallyExclusive("issue", "label")
+}
+
+func runQuickfix(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	description := strings.TrimSpace(args[0])
+	if description == "" {
+		config.Fail(errs.New(errs.KindUsage, "Describe the fix, e.g. gwi quickfix \"Fix typo in README\""))
+	}
+	if quickfixPatch == "" && config.Headless() {
+		config.Fail(errs.New(errs.KindUsage, "Nobody can make the change without a terminal; pass it with --patch"))
+	}
+	patch, cleanup, err := quickfixPatchFile(quickfixPatch)
+	if err != nil {
+		config.Fail(err)
+	}
+	defer cleanup()
+
+	issueNumber := quickfixIssue
+	if issueNumber == 0 {
+		issueNumber = createQuickfixIssue(cfg, description)
+	}
+
+	worktreePath := createWorktree(cfg, repoInfo, issueNumber, true)
+	branchName := filepath.Base(worktreePath)
+	config.Success("Worktree created at: %s", worktreePath)
+
+	if patch != "" {
+		if err := git.ApplyPatch(worktreePath, patch); err != nil {
+			config.Fail(fmt.Errorf("failed to apply the patch: %w\n\n  The worktree is kept; fix it up there and run: gwi pr %d", err, issueNumber))
+		}
+		config.Success("Applied %s", quickfixPatch)
+	} else {
+		waitForQuickfix(worktreePath, issueNumber)
+	}
+	if !git.HasUncommittedChanges(worktreePath) {
+		config.Fail(errs.Aborted("Nothing changed in %s. Remove the worktree with: gwi rm %d", worktreePath, issueNumber))
+	}
+
+	if err := git.CommitWithHooks(worktreePath, description); err != nil {
+		config.Fail(fmt.Errorf("failed to commit: %w\n\n  The worktree is kept; commit there and run: gwi pr %d", err, issueNumber))
+	}
+	config.Success("Committed: %s", description)
+
+	prWaitChecks = quickfixAutoMerge
+	prChecksTimeout = quickfixChecksTimeout
+	runPR(cmd, []string{strconv.Itoa(issueNumber)})
+
+	if quickfixAutoMerge {
+		mergeQuickfix(cfg, repoInfo, branchName, issueNumber)
+	}
+}
+
+// quickfixPatchFile returns the path of the patch to apply, an absolute one
+// as git applies it in the worktree. A patch on stdin is saved to a temporary
+// file first; cleanup removes it.
+func quickfixPatchFile(patch string) (string, func(), error) {
+	if patch == "" {
+		return "", func() {}, nil
+	}
+	if patch != "-" {
+		path, err := filepath.Abs(patch)
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			return "", nil, errs.NotFound("Patch %s not found", patch)
+		}
+		return path, func() {}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "gwi-quickfix-*.patch")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+	_, err = io.Copy(tmp, os.Stdin)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to read the patch from stdin: %w", err)
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+// createQuickfixIssue opens the issue a quick fix closes and adds it to the
+// selected projects, where creating the worktree moves it to In Progress
+func createQuickfixIssue(cfg *config.Config, description string) int {
+	if _, ok := azureBoards(cfg); ok {
+		config.Fail(errs.New(errs.KindUsage, "Can't create Azure Boards work items; pass the work item with --issue"))
+	}
+	number, err := github.CreateIssue(description, "", quickfixLabels...)
+	if err != nil {
+		config.Fail(err)
+	}
+	config.Success("Created issue #%d %s", number, description)
+
+	if cfg.GitHub.ProjectsEnabled {
+		if owner, repo, err := github.CurrentRepo(); err == nil {
+			if _, err := github.AddIssueToProjects(cfg, owner, repo, number); err != nil && cfg.Verbose {
+				config.Warn("Could not add issue #%d to a project: %v", number, err)
+			}
+		}
+	}
+	return number
+}
+
+// waitForQuickfix waits until the change has been made in the worktree
+func waitForQuickfix(worktreePath string, issueNumber int) {
+	config.Info("Make the change in %s", worktreePath)
+	for {
+		answer := promptLine("Press Enter to commit it, or type 'abort' to stop: ")
+		if strings.EqualFold(answer, "abort") {
+			config.Fail(errs.Aborted("Aborted. The worktree is kept; finish with 'gwi pr %d' or remove it with 'gwi rm %d'", issueNumber, issueNumber))
+		}
+		status, _ := git.GetStatusShort(worktreePath)
+		if status != "" {
+			fmt.Fprintln(os.Stderr, status)
+			return
+		}
+		config.Warn("No changes in %s yet", worktreePath)
+	}
+}
+
+// mergeQuickfix merges the PR gwi pr opened, whose checks passed, and moves
+// the issue to Done
+func mergeQuickfix(cfg *config.Config, repoInfo *git.RepoInfo, branchName string, issueNumber int) {
+	prNumber, ok := linkedPRNumber(repoInfo, branchName)
+	if !ok {
+		config.Fail(errs.NotFound("Cannot merge: no PR linked to %s", branchName))
+	}
+	config.Info("Merging PR #%d (%s)...", prNumber, cfg.MergeStrategy)
+	if err := github.MergePR(prNumber, cfg.MergeStrategy); err != nil {
+		config.Fail(fmt.Errorf("failed to merge PR #%d: %w\n\n  It may need a review first; merge it on GitHub once approved.", prNumber, err))
+	}
+	config.Success("Merged PR #%d", prNumber)
+	unlinkPR(repoInfo, branchName)
+
+	if tracksStatus(cfg) {
+		if updates, err := updateIssueStatus(cfg, issueNumber, statusDone); err != nil {
+			if cfg.Verbose {
+				config.Warn("Failed to update project status: %v", err)
+			}
+		} else {
+			config.Info("Updated issue #%d to '%s' in %s", issueNumber, statusValue(cfg, statusDone), trackerName(cfg))
+			printProjectUpdates(updates)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Tag releases of merged issues",
+}
+
+var releasePrepareCmd = &cobra.Command{
+	Use:   "prepare [version]",
+	Short: "Tag a release with notes from the issues merged since the last one",
+	Long: `Collect the issues merged into the main branch since the last version tag and
+draft release notes grouped by label (release.groups). After confirmation, tag
+the main branch as pushed, push the tag, create the GitHub Release and move the
+issues to the release.status column of the project board, if it has one.
+
+An issue is included when a commit since the last tag closes it (Closes #N,
+Fixes #N, ...) or merges its branch, or when it was closed as completed after
+the last tag was made, as gwi merge does.
+
+Without a version, the last one is bumped: the minor version when the release
+has issues in the first group (Features by default), the patch version otherwise.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReleasePrepare,
+}
+
+var (
+	releaseDryRun bool
+	releaseYes    bool
+	releaseFrom   string
+)
+
+func init() {
+	releasePrepareCmd.Flags().BoolVarP(&releaseDryRun, "dry-run", "n", false, "Print the release notes without tagging or releasing")
+	releasePrepareCmd.Flags().BoolVarP(&releaseYes, "yes", "y", false, "Don't ask for confirmation")
+	releasePrepareCmd.Flags().StringVar(&releaseFrom, "from", "", "Tag to collect issues since, instead of the last version tag")
+	releaseCmd.AddCommand(releasePrepareCmd)
+}
+
+// releaseMergeRef finds the issue whose branch a merge commit merges
+var releaseMergeRef = regexp.MustCompile(`^Merge (?:branch '|pull request #\d+ from [^/\s]+/)(\d+)-`)
+
+// releaseClosedLimit is how many recently closed issues and merged PRs are checked
+const releaseClosedLimit = 500
+
+func runReleasePrepare(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	if _, err := git.GetRepoInfo(); err != nil {
+		return err
+	}
+	if _, ok := azureBoards(cfg); ok {
+		return errs.New(errs.KindUsage, "gwi release prepare reads merged GitHub issues and is not supported with Azure Boards")
+	}
+	if err := github.CheckAuth(); err != nil {
+		return err
+	}
+	mainWorktree, err := git.GetMainWorktreePath()
+	if err != nil {
+		return err
+	}
+
+	if err := git.Fetch(); err != nil {
+		config.Warn("Failed to fetch, the release may miss recent merges: %v", err)
+	}
+	ref := git.PushedRef(cfg.MainBranch)
+
+	lastTag := releaseFrom
+	if lastTag == "" {
+		lastTag = git.LatestTag(mainWorktree, ref, cfg.Release.TagPrefix)
+	} else if !git.TagExists(mainWorktree, lastTag) {
+		return errs.NotFound("Tag %s not found", lastTag)
+	}
+	if lastTag == "" {
+		config.Info("No %s* tag yet, collecting all merged issues", cfg.Release.TagPrefix)
+	} else {
+		config.Info("Collecting issues merged since %s...", lastTag)
+	}
+
+	issues, err := collectReleasedIssues(mainWorktree, lastTag, ref, cfg.MainBranch)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		config.Success("No issues merged since %s, nothing to release", lastTag)
+		return nil
+	}
+	groups := groupReleaseIssues(cfg, issues)
+
+	version := ""
+	if len(args) > 0 {
+		version = args[0]
+	} else {
+		hasFeatures := len(cfg.Release.Groups) > 0 && len(groups[0].issues) > 0
+		if version, err = nextVersion(lastTag, cfg.Release.TagPrefix, hasFeatures); err != nil {
+			return err
+		}
+	}
+	tag := version
+	if !strings.HasPrefix(tag, cfg.Release.TagPrefix) {
+		tag = cfg.Release.TagPrefix + tag
+	}
+	if git.TagExists(mainWorktree, tag) {
+		return errs.Conflict("Tag %s already exists", tag)
+	}
+
+	notes := releaseNotes(groups, lastTag, tag)
+	if releaseDryRun {
+		fmt.Println(notes)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%s\n\n", notes)
+	if !releaseYes && !confirmPrompt(fmt.Sprintf("Tag %s as %s and create the GitHub Release?", ref, tag)) {
+		return errs.Aborted("Aborted")
+	}
+
+	if err := git.CreateTag(mainWorktree, tag, ref, "Release "+tag); err != nil {
+		return fmt.Errorf("failed to tag %s: %w", tag, err)
+	}
+	config.Info("Pushing %s to %s...", tag, git.PushRemote())
+	if err := git.PushTag(mainWorktree, tag); err != nil {
+		return fmt.Errorf("failed to push %s: %w", tag, err)
+	}
+	releaseURL, err := github.CreateRelease(tag, tag, notes)
+	if err != nil {
+		return err
+	}
+	config.Success("Released %s with %d issue(s): %s", tag, len(issues), releaseURL)
+
+	if cfg.GitHub.ProjectsEnabled && cfg.Release.Status != "" {
+		markReleased(cfg, issues)
+	}
+	return nil
+}
+
+// collectReleasedIssues returns the issues merged into ref since lastTag (all
+// of ref's history without one), by number: those a commit closes or merges
+// the branch of, and those closed by a PR into base whose commits are among
+// them. Issues closed as not planned are left out.
+func collectReleasedIssues(mainWorktree, lastTag, ref, base string) ([]github.Issue, error) {
+	revRange := ref
+	if lastTag != "" {
+		revRange = lastTag + ".." + ref
+	}
+	messages, err := git.GetCommitMessages(mainWorktree, revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits: %w", err)
+	}
+	referenced := make(map[int]bool)
+	for _, message := range messages {
+		for _, match := range closingRef.FindAllStringSubmatch(message, -1) {
+			n, _ := strconv.Atoi(match[1])
+			referenced[n] = true
+		}
+		if match := releaseMergeRef.FindStringSubmatch(message); match != nil {
+			n, _ := strconv.Atoi(match[1])
+			referenced[n] = true
+		}
+	}
+
+	// A PR is in the release when its merge commit is, or its head for
+	// branches merged locally, e.g. by gwi merge
+	shas, err := git.GetRangeSHAs(mainWorktree, revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits: %w", err)
+	}
+	inRange := make(map[string]bool, len(shas))
+	for _, sha := range shas {
+		inRange[sha] = true
+	}
+	owner, repo, err := github.CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+	prs, err := github.ListMergedPRs(base, releaseClosedLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if inRange[pr.MergeCommit.Oid] || inRange[pr.HeadRefOid] {
+			for _, n := range pr.ClosedIssues(owner, repo) {
+				referenced[n] = true
+			}
+		}
+	}
+
+	closed, err := github.ListClosedIssues(releaseClosedLimit)
+	if err != nil {
+		return nil, err
+	}
+	var issues []github.Issue
+	for _, issue := range closed {
+		if referenced[issue.Number] {
+			delete(referenced, issue.Number)
+			if issue.StateReason != "NOT_PLANNED" {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	// Referenced issues closed before the list's limit
+	for n := range referenced {
+		if issue, err := github.GetIssue(n); err == nil && issue.State == "CLOSED" && issue.StateReason != "NOT_PLANNED" {
+			issues = append(issues, *issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Number < issues[j].Number })
+	return issues, nil
+}
+
+// releaseGroup is a section of the release notes with its issues
+type releaseGroup struct {
+	title  string
+	issues []github.Issue
+}
+
+// groupReleaseIssues sorts issues into the release.groups, in order, with an
+// "Other Changes" group last for issues matching none of them
+func groupReleaseIssues(cfg *config.Config, issues []github.Issue) []releaseGroup {
+	groups := make([]releaseGroup, len(cfg.Release.Groups)+1)
+	for i, group := range cfg.Release.Groups {
+		groups[i].title = group.Title
+	}
+	other := len(groups) - 1
+	groups[other].title = "Other Changes"
+
+	for _, issue := range issues {
+		target := other
+		for i, group := range cfg.Release.Groups {
+			if slices.ContainsFunc(issue.Labels, func(l github.Label) bool {
+				return slices.ContainsFunc(group.Labels, func(name string) bool { return strings.EqualFold(name, l.Name) })
+			}) {
+				target = i
+				break
+			}
+		}
+		groups[target].issues = append(groups[target].issues, issue)
+	}
+	return groups
+}
+
+// releaseNotes renders the groups as Markdown, skipping empty ones
+func releaseNotes(groups []releaseGroup, lastTag, tag string) string {
+	var lines []string
+	for _, group := range groups {
+		if len(group.issues) == 0 {
+			continue
+		}
+		lines = append(lines, "## "+group.title, "")
+		for _, issue := range group.issues {
+			lines = append(lines, fmt.Sprintf("- %s (#%d)", issue.Title, issue.Number))
+		}
+		lines = append(lines, "")
+	}
+	if lastTag != "" {
+		lines = append(lines, fmt.Sprintf("**Full Changelog**: %s...%s", lastTag, tag))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// nextVersion bumps the minor or patch version of the last tag, which must
+// look like <prefix>MAJOR.MINOR.PATCH. Without a last tag it is 0.1.0.
+func nextVersion(lastTag, prefix string, minor bool) (string, error) {
+	if lastTag == "" {
+		return prefix + "0.1.0", nil
+	}
+	parts := strings.Split(strings.TrimPrefix(lastTag, prefix), ".")
+	numbers := make([]int, 3)
+	if len(parts) != 3 {
+		return "", errs.New(errs.KindUsage, "Cannot bump %s, pass the version: gwi release prepare <version>", lastTag)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return "", errs.New(errs.KindUsage, "Cannot bump %s, pass the version: gwi release prepare <version>", lastTag)
+		}
+		numbers[i] = n
+	}
+	if minor {
+		numbers[1]++
+		numbers[2] = 0
+	} else {
+		numbers[2]++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
+
+// markReleased moves the released issues to release.status on the project
+// board, unless the board has no such status
+func markReleased(cfg *config.Config, issues []github.Issue) {
+	moved := 0
+	for _, issue := range issues {
+		updates, err := github.UpdateIssueStatus(issue.Number, cfg.Release.Status, cfg)
+		if errors.Is(err, github.ErrOptionNotFound) {
+			config.Info("The project has no '%s' status, leaving the issues' status as is", cfg.Release.Status)
+			return
+		}
+		if err != nil {
+			config.Warn("Failed to move #%d to '%s': %v", issue.Number, cfg.Release.Status, err)
+			continue
+		}
+		for _, update := range updates {
+			if update.Err != nil {
+				config.Warn("Failed to move #%d to '%s' in %s: %v", issue.Number, cfg.Release.Status, update.Project, update.Err)
+			}
+		}
+		moved++
+	}
+	if moved > 0 {
+		config.Info("Moved %d issue(s) to '%s' in GitHub Projects", moved, cfg.Release.Status)
+	}
+}
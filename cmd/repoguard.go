@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// repoFlag names the repository to run in, as org/repo or a path
+var repoFlag string
+
+// applyRepo changes to the repository named with --repo
+func applyRepo(cmd *cobra.Command) {
+	if !usesRepoFlag(cmd) || repoFlag == "" {
+		return
+	}
+	dir, err := resolveRepoFlag(repoFlag)
+	if err != nil {
+		config.Fail(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		config.Fail(err)
+	}
+}
+
+// applyRepoLayout stops when the current directory is laid out as a worktree
+// of one repository but git resolves it to another, e.g. in a repository
+// nested in a worktree or through a symlink, as commands would then act on the
+// wrong worktree base. It runs once the remotes are applied, as the GitHub
+// repository is that of the push remote.
+func applyRepoLayout(cmd *cobra.Command) {
+	if !usesRepoFlag(cmd) || repoFlag != "" {
+		return
+	}
+	if err := checkRepoLayout(config.Load()); err != nil {
+		config.Fail(err)
+	}
+}
+
+// usesRepoFlag reports whether cmd takes gwi's --repo flag
+func usesRepoFlag(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "_cd", "_list", "env", "_fake-gh":
+		return false
+	}
+	// Commands with a --repo flag of their own, such as gwi board, mean a GitHub repository
+	flag := cmd.Flags().Lookup("repo")
+	return flag != nil && flag == cmd.Root().PersistentFlags().Lookup("repo")
+}
+
+// resolveRepoFlag returns the directory of the repository given with --repo:
+// a path, or org/repo of a repository gwi has worked in before
+func resolveRepoFlag(name string) (string, error) {
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return filepath.Abs(name)
+	}
+	org, repo, ok := strings.Cut(name, "/")
+	if ok {
+		for _, cached := range index.All() {
+			if strings.EqualFold(cached.Org, org) && strings.EqualFold(cached.Repo, repo) && cached.MainPath != "" {
+				return cached.MainPath, nil
+			}
+		}
+	}
+	return "", errs.NotFound("Unknown repository %s: pass its path, or run gwi in it once so gwi knows where it is", name)
+}
+
+// checkRepoLayout compares the repository the current directory's path
+// belongs to under worktree_base with the one git finds there
+func checkRepoLayout(cfg *config.Config) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Join(cfg.WorktreeBase, "github.com"), cwd)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+	parts := strings.Split(rel, string(os.PathSeparator))
+	if len(parts) < 3 {
+		return nil
+	}
+	layoutRepo := parts[0] + "/" + parts[1]
+	worktreeDir := filepath.Join(cfg.WorktreeBasePath(parts[0], parts[1]), parts[2])
+
+	commonDir, err := git.GetCommonDir(cwd)
+	if err != nil {
+		// Not in a repository at all; commands report that themselves
+		return nil
+	}
+	gitRepo := layoutRepo
+	if repoInfo, err := git.GetRepoInfo(); err == nil {
+		gitRepo = repoInfo.Org + "/" + repoInfo.Repo
+	}
+	expectedDir, err := git.GetCommonDir(worktreeDir)
+	sameRepo := err != nil || canonicalPath(expectedDir) == canonicalPath(commonDir)
+	if sameRepo && strings.EqualFold(gitRepo, layoutRepo) {
+		return nil
+	}
+
+	// The other repository may be unknown to gwi, so it is offered by path
+	choices := "--repo " + layoutRepo
+	if mainPath, err := git.GetMainWorktreePath(); err == nil {
+		choices += " or --repo " + mainPath
+	}
+	return errs.Conflict("%s is laid out as a worktree of %s, but git resolves it to %s (%s).\n\n  A repository nested in the worktree, or a symlink, would make gwi act on the wrong worktree base. Choose the repository with %s.",
+		cwd, layoutRepo, gitRepo, commonDir, choices)
+}
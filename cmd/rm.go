@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/issueid"
 	"github.com/spf13/cobra"
 )
 
@@ -17,11 +20,11 @@ var skipConfirm bool
 var deleteBranch bool
 
 var rmCmd = &cobra.Command{
-	Use:   "rm [issue-number]",
+	Use:   "rm [issue-number|key]",
 	Short: "Delete worktree",
-	Long:  `Remove a worktree for the given issue number.`,
+	Long:  `Remove a worktree for the given issue number or project key (e.g. JIRA-123).`,
 	Args:  cobra.MaximumNArgs(1),
-	Run:   runRm,
+	RunE:  runRm,
 }
 
 func init() {
@@ -30,36 +33,38 @@ func init() {
 	rmCmd.Flags().BoolVarP(&deleteBranch, "delete-branch", "D", false, "Also delete the local and remote branch")
 }
 
-func runRm(cmd *cobra.Command, args []string) {
+func runRm(cmd *cobra.Command, args []string) error {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		return err
 	}
 
-	var issueNumber int
+	var id issueid.ID
 	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
 
 	if len(args) > 0 && args[0] != "--force" && args[0] != "-f" && args[0] != "--yes" && args[0] != "-y" {
-		issueNumber, err = strconv.Atoi(args[0])
-		if err != nil {
-			config.Die("Invalid issue number: %s", args[0])
-		}
+		id = parseIssueID(args[0])
 	} else {
 		// Always show interactive selection for rm
-		issueNumber, err = selectWorktree(repoInfo, cfg)
+		id, err = selectWorktree(repoInfo, cfg)
 		if err != nil {
-			config.Die("No worktree selected")
+			return errs.Aborted("No worktree selected")
 		}
 	}
 
-	worktreePath := git.FindWorktreeByIssue(base, issueNumber)
+	worktreePath := git.FindWorktreeByID(base, id)
 	if worktreePath == "" {
-		config.Die("No worktree found for issue #%d", issueNumber)
+		return errs.NotFound("No worktree found for issue %s", id)
 	}
 
 	worktreeName := filepath.Base(worktreePath)
 
+	guardMainWorktree(cfg, worktreePath, "remove")
+	if deleteBranch {
+		guardBranch(cfg, worktreeName, "delete")
+	}
+
 	// Check if we're inside the worktree
 	needCd := false
 	if git.IsInsideWorktree(worktreePath) {
@@ -70,9 +75,9 @@ func runRm(cmd *cobra.Command, args []string) {
 	// Check if PR is merged before confirmation
 	prMerged := false
 	autoDeleteBranch := false
-	if !deleteBranch {
+	if !deleteBranch && !isProtectedBranch(cfg, worktreeName) {
 		// Check if there's a PR for this branch
-		if prNumber, err := github.GetPRForBranch(worktreeName); err == nil {
+		if prNumber, err := findPRForBranch(repoInfo, worktreeName); err == nil {
 			if merged, err := github.IsPRMerged(prNumber); err == nil && merged {
 				prMerged = true
 				autoDeleteBranch = true
@@ -80,6 +85,16 @@ func runRm(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Check everything up front and report it in one summary
+	pf := newPreflight("remove " + worktreeName)
+	pf.checkWorktreeRemoval(worktreePath)
+	deleteLocal, deleteRemote := false, false
+	if deleteBranch || autoDeleteBranch {
+		deleteLocal = pf.checkBranchDeletion(worktreeName, worktreePath)
+		deleteRemote = pf.checkRemoteDeletion(worktreeName)
+	}
+	pf.report()
+
 	// Confirm removal (unless --yes flag is set)
 	if !skipConfirm {
 		if deleteBranch || autoDeleteBranch {
@@ -92,7 +107,7 @@ func runRm(cmd *cobra.Command, args []string) {
 			fmt.Fprintf(os.Stderr, "Remove worktree %s%s%s?\n", config.Yellow(""), worktreeName, config.Yellow(""))
 		}
 		if !confirmPrompt("Confirm") {
-			config.Die("Aborted")
+			return errs.Aborted("Aborted")
 		}
 	}
 
@@ -105,24 +120,38 @@ func runRm(cmd *cobra.Command, args []string) {
 	if needCd {
 		mainPath, err := git.GetMainWorktreePath()
 		if err == nil && mainPath != "" {
-			fmt.Printf("__GWI_CD_TO__:%s\n", mainPath)
+			printCdTo(mainPath)
 		}
 	}
 
 	// Get branch name before removing (it's the same as the worktree directory name)
 	branchName := worktreeName
 
+	if !forceRemove && git.HasUncommittedChanges(worktreePath) {
+		return errs.Conflict("Worktree has uncommitted changes. Use --force to remove anyway.")
+	}
+	journalRemoval(repoInfo, undoEntry{
+		Op:       "rm",
+		Branch:   branchName,
+		Worktree: worktreePath,
+		Remote:   deleteBranch && deleteRemote,
+	})
 	config.Info("Removing worktree: %s", worktreePath)
 
 	if err := git.RemoveWorktree(worktreePath, forceRemove); err != nil {
 		if !forceRemove && git.HasUncommittedChanges(worktreePath) {
-			config.Die("Worktree has uncommitted changes. Use --force to remove anyway.")
+			return errs.Conflict("Worktree has uncommitted changes. Use --force to remove anyway.")
 		}
-		config.Die("Failed to remove worktree: %v", err)
+		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
 	// Prune any stale worktree entries to ensure clean state
 	git.PruneWorktrees()
+	index.Refresh(cfg, repoInfo)
+	deps.Forget(worktreePath)
+	if issueNumber, ok := id.Number(); ok {
+		forgetActiveIssue(repoInfo, issueNumber)
+	}
 
 	config.Success("Worktree removed.")
 
@@ -131,24 +160,26 @@ func runRm(cmd *cobra.Command, args []string) {
 		config.Info("PR has been merged. Automatically deleting branches.")
 	}
 
-	// Update GitHub Project status back to "Todo" when removing worktree
+	// Move the issue back to "Todo" when removing worktree
 	// Only update to "Todo" if PR wasn't merged (if merged, it should stay "Done")
-	if cfg.GitHub.ProjectsEnabled && !prMerged {
+	if tracksStatus(cfg) && !prMerged {
 		if issueNum, ok := github.ParseIssueFromBranch(branchName); ok {
-			if err := github.UpdateIssueStatus(issueNum, cfg.GitHub.TodoValue, cfg); err != nil {
+			if updates, err := updateIssueStatus(cfg, issueNum, statusTodo); err != nil {
 				if cfg.Verbose {
 					config.Warn("Failed to update project status: %v", err)
 				}
 			} else {
-				config.Info("Updated issue #%d to '%s' in GitHub Projects", issueNum, cfg.GitHub.TodoValue)
+				config.Info("Updated issue #%d to '%s' in %s", issueNum, statusValue(cfg, statusTodo), trackerName(cfg))
+				printProjectUpdates(updates)
 			}
 		}
 	}
 
 	// Delete branches if requested or if PR was merged
 	if deleteBranch {
+		unlinkPR(repoInfo, branchName)
 		// Delete local branch
-		if git.BranchExists(branchName) {
+		if deleteLocal && git.BranchExists(branchName) {
 			config.Info("Deleting local branch: %s", branchName)
 			if err := git.DeleteBranch(branchName); err != nil {
 				config.Error("Failed to delete local branch: %v", err)
@@ -158,7 +189,7 @@ func runRm(cmd *cobra.Command, args []string) {
 		}
 
 		// Delete remote branch
-		if git.RemoteBranchExists(branchName) {
+		if deleteRemote {
 			config.Info("Deleting remote branch: %s", branchName)
 			if err := git.DeleteRemoteBranch(branchName); err != nil {
 				config.Error("Failed to delete remote branch: %v", err)
@@ -167,4 +198,5 @@ func runRm(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
+	return nil
 }
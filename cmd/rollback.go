@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// createRollback tracks what createWorktree has created so a failed or
+// interrupted create can be undone instead of leaving half a worktree behind
+type createRollback struct {
+	once     sync.Once
+	worktree string
+	branch   string // Local branch created together with the worktree
+	topDir   string // Outermost directory that did not exist before
+	signals  chan os.Signal
+}
+
+// beginCreate records the state before a worktree is created and arms the
+// rollback for Fail, Exit and Ctrl-C
+func beginCreate(worktreePath, branchName string) *createRollback {
+	rb := &createRollback{worktree: worktreePath}
+	if !git.BranchExists(branchName) {
+		rb.branch = branchName
+	}
+	for dir := filepath.Dir(worktreePath); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+			break
+		}
+		rb.topDir = dir
+	}
+
+	config.AtExit(func(code int) {
+		if code != 0 {
+			rb.rollback()
+		}
+	})
+
+	// git receives the same interrupt and fails; make sure we clean up after it
+	rb.signals = make(chan os.Signal, 1)
+	signal.Notify(rb.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-rb.signals; ok {
+			rb.rollback()
+			os.Exit(130)
+		}
+	}()
+	return rb
+}
+
+// commit marks the worktree as complete; later failures no longer roll back
+func (rb *createRollback) commit() {
+	rb.once.Do(func() {})
+	signal.Stop(rb.signals)
+	close(rb.signals)
+}
+
+func (rb *createRollback) rollback() {
+	rb.once.Do(func() {
+		config.Warn("Rolling back partially created worktree...")
+
+		if _, err := os.Stat(rb.worktree); err == nil {
+			if err := git.RemoveWorktree(rb.worktree, true); err != nil {
+				config.Warn("Could not remove %s: %v", rb.worktree, err)
+			}
+		}
+		git.PruneWorktrees()
+
+		if rb.branch != "" && git.BranchExists(rb.branch) {
+			if err := git.DeleteBranch(rb.branch); err != nil {
+				config.Warn("Could not delete branch %s: %v", rb.branch, err)
+			}
+		}
+
+		// Remove directories we created, as long as nothing else ended up in them
+		if rb.topDir != "" {
+			for dir := filepath.Dir(rb.worktree); ; dir = filepath.Dir(dir) {
+				if os.Remove(dir) != nil || dir == rb.topDir {
+					break
+				}
+			}
+		}
+	})
+}
@@ -1,28 +1,200 @@
 package cmd
 
 import (
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/stats"
+	"github.com/enterprisemodules/gwi/internal/timeout"
+	"github.com/enterprisemodules/gwi/internal/version"
+	"github.com/enterprisemodules/gwi/internal/xdg"
 	"github.com/spf13/cobra"
 )
 
+var (
+	redactOutput bool
+	errorFormat  string
+	timeFormat   string
+)
+
+// commandStarted is set once a command passed argument and flag validation;
+// errors before that are usage errors
+var commandStarted bool
+
 var rootCmd = &cobra.Command{
 	Use:   "gwi",
 	Short: "Git Worktree Issue CLI",
-	Long:  `gwi integrates GitHub issues with git worktrees for streamlined development.`,
+	Long: `gwi integrates GitHub issues with git worktrees for streamlined development.
+
+Run without a command inside an issue's worktree, gwi shows the issue, the
+state of its branch, its PR and dev server, and suggests what to do next.
+Elsewhere in a repository it lists the worktrees.`,
+	Run: runDashboard,
+	// Errors are reported by Execute, with their exit code
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		commandStarted = true
+		if err := applyErrorFormat(); err != nil {
+			config.Fail(err)
+		}
+		// The status summary runs from tmux and prompts; keep it quick and unrecorded
+		if cmd == statusCmd && (statusSummaryLine || statusRefreshSummary) {
+			return
+		}
+		migrateLegacyPaths()
+		applyRepo(cmd)
+		applyRemotes(cmd)
+		applyRepoLayout(cmd)
+		applyTimeouts(cmd)
+		if timeFormat != "" {
+			if err := config.SetTimeFormat(timeFormat); err != nil {
+				config.Fail(errs.New(errs.KindUsage, "Invalid --time-format: %v", err))
+			}
+		}
+		if redactOutput || os.Getenv("GWI_REDACT") == "1" {
+			config.SetRedact(true)
+		}
+		startStats(cmd)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		stats.Finish(false)
+	},
+}
+
+// untrackedCommands are never recorded; _cd, _list and env must not load
+// config so they can resolve paths from the cached index
+var untrackedCommands = map[string]bool{
+	"stats": true,
+	"_cd":   true,
+	"_list": true,
+	"env":   true,
+	// Runs on shell startup, usually without doing anything
+	"_startup-check": true,
+	// Runs in place of gh in sandbox mode
+	"_fake-gh": true,
+	// Runs from git hooks on every commit and push
+	"_guard": true,
+}
+
+// migrateLegacyPaths moves files from where older versions kept them to
+// their XDG base directories
+func migrateLegacyPaths() {
+	for _, move := range xdg.Migrate() {
+		if move.Err != nil {
+			config.Warn("Failed to move %s to %s: %v", move.From, move.To, move.Err)
+		} else {
+			config.Info("Moved %s to %s", move.From, move.To)
+		}
+	}
+}
+
+// applyRemotes points git at the configured fetch and push remotes, which a
+// repository can override with gwi.fetchRemote and gwi.pushRemote. _cd, _list
+// and env apply them only when they miss the index and load the config.
+func applyRemotes(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "_cd", "_list", "env", "_fake-gh":
+		return
+	}
+	useRepoRemotes(config.Load())
 }
 
-// Execute runs the root command
-func Execute() error {
-	return rootCmd.Execute()
+// useRepoRemotes sets the remotes of the repository in the current directory
+func useRepoRemotes(cfg *config.Config) {
+	fetch, push := cfg.Remote.Fetch, cfg.Remote.Push
+	if val := git.GetConfig(".", "gwi.fetchRemote"); val != "" {
+		fetch = val
+	}
+	if val := git.GetConfig(".", "gwi.pushRemote"); val != "" {
+		push = val
+	}
+	git.SetRemotes(fetch, push)
+}
+
+// applyTimeouts bounds git, gh and az calls by the configured timeouts
+func applyTimeouts(cmd *cobra.Command) {
+	switch cmd.Name() {
+	case "_cd", "_list", "env", "_fake-gh":
+		return
+	}
+	cfg := config.Load()
+	timeout.Set(cfg.Timeouts.Default, cfg.Timeouts.Commands)
+}
+
+// startStats begins recording usage statistics for cmd when tracking is enabled
+func startStats(cmd *cobra.Command) {
+	// Subcommands are recorded by their path, e.g. "pr amend-title", as leaf
+	// names like "cycle" aren't unique
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if untrackedCommands[strings.Fields(name)[0]] || strings.HasPrefix(name, "__") {
+		return
+	}
+	if !config.Load().TrackStats {
+		return
+	}
+
+	stats.Start(name)
+	config.AtExit(func(code int) {
+		stats.Finish(code != 0)
+	})
+}
+
+// Execute runs the root command. Errors are reported and end the process
+// with the exit code of their kind (see internal/errs).
+func Execute() {
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+	// An invalid format is reported as text, ahead of the error it hides
+	if ferr := applyErrorFormat(); ferr != nil {
+		config.Error("%v", ferr)
+	}
+	if !commandStarted && errs.KindOf(err) == errs.KindGeneral {
+		err = errs.New(errs.KindUsage, "%v\nRun '%s --help' for usage.", err, cmd.CommandPath())
+	}
+	config.Fail(err)
+}
+
+// applyErrorFormat enables JSON errors from --error-format or GWI_ERROR_FORMAT
+func applyErrorFormat() error {
+	format, source := errorFormat, "--error-format"
+	if format == "" {
+		format, source = os.Getenv("GWI_ERROR_FORMAT"), "GWI_ERROR_FORMAT"
+	}
+	switch format {
+	case "", "text":
+		config.SetJSONErrors(false)
+	case "json":
+		config.SetJSONErrors(true)
+	default:
+		return errs.New(errs.KindUsage, "Invalid %s %q: use 'text' or 'json'", source, format)
+	}
+	return nil
 }
 
 func init() {
+	rootCmd.Version = version.Get().String()
+	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.PersistentFlags().BoolVar(&redactOutput, "redact", false, "Redact accounts, IDs and titles from output (safe for pasting into issues)")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, "error-format", "", "Print errors as 'text' or 'json'")
+	rootCmd.PersistentFlags().StringVar(&timeFormat, "time-format", "", "Show times as 'relative', 'iso', 'datetime', 'local' or a Go layout")
+	rootCmd.PersistentFlags().StringVar(&repoFlag, "repo", "", "Run in this repository (org/repo or a path) instead of the current directory's")
+
 	// Add all subcommands
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(internalCreateCmd)
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(prCmd)
+	rootCmd.AddCommand(quickfixCmd)
+	rootCmd.AddCommand(verifyCmd)
 	rootCmd.AddCommand(mergeCmd)
 	rootCmd.AddCommand(rmCmd)
+	rootCmd.AddCommand(undoCmd)
 	rootCmd.AddCommand(cdCmd)
 	rootCmd.AddCommand(internalCdCmd)
 	rootCmd.AddCommand(mainCmd)
@@ -31,6 +203,7 @@ func init() {
 	rootCmd.AddCommand(internalListCmd)
 	rootCmd.AddCommand(internalStartCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(internalStatusOfCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(activateCmd)
 	rootCmd.AddCommand(upCmd)
@@ -39,4 +212,32 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(debugCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(boardCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(depsCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(cherryCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(parkCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(takeoverCmd)
+	rootCmd.AddCommand(issuesCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(releaseCmd)
+	rootCmd.AddCommand(hooksCmd)
+	rootCmd.AddCommand(checksCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(unarchiveCmd)
+	rootCmd.AddCommand(sandboxCmd)
+	rootCmd.AddCommand(internalFakeGHCmd)
+	rootCmd.AddCommand(internalStartupCheckCmd)
+	rootCmd.AddCommand(guardCmd)
+	rootCmd.AddCommand(internalGuardCmd)
 }
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// ruleOutcome is what the rules matching an issue decided
+type ruleOutcome struct {
+	base          string // Branch to use instead of main_branch, if set
+	mergeStrategy string // Merge strategy to use instead of merge_strategy, if set
+}
+
+// matchesRule reports whether a rule applies to an issue at event ("create",
+// "pr" or "merge")
+func matchesRule(rule config.Rule, issue *github.Issue, event string) bool {
+	if len(rule.Events) > 0 && !slices.Contains(rule.Events, event) {
+		return false
+	}
+	if len(rule.Labels) == 0 {
+		return true
+	}
+	for _, label := range issue.Labels {
+		for _, want := range rule.Labels {
+			if strings.EqualFold(label.Name, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyRules evaluates the configured rules for an issue at event, showing
+// their warnings. At create, a rule requiring --force stops unless force is set.
+func applyRules(cfg *config.Config, issue *github.Issue, event string, force bool) (ruleOutcome, error) {
+	var outcome ruleOutcome
+	for _, rule := range cfg.Rules {
+		if !matchesRule(rule, issue, event) {
+			continue
+		}
+		if rule.Warn != "" {
+			config.Warn("Issue #%d: %s", issue.Number, rule.Warn)
+		}
+		if rule.RequireForce && event == "create" && !force {
+			return outcome, errs.Conflict("Issue #%d needs --force to create its worktree (see rules in the config)", issue.Number)
+		}
+		if outcome.base == "" {
+			outcome.base = rule.Base
+		}
+		if outcome.mergeStrategy == "" {
+			outcome.mergeStrategy = rule.MergeStrategy
+		}
+	}
+	return outcome, nil
+}
+
+// baseBranch returns the branch an issue's work is based on: the rules' base,
+// or main_branch
+func (o ruleOutcome) baseBranch(cfg *config.Config) string {
+	if o.base != "" {
+		return o.base
+	}
+	return cfg.MainBranch
+}
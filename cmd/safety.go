@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// isMainWorktree reports whether path is (or is inside) the repository's main worktree
+func isMainWorktree(path string) bool {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil || mainPath == "" {
+		return false
+	}
+	return samePath(path, mainPath)
+}
+
+// samePath compares two paths after resolving symlinks
+func samePath(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// isProtectedBranch reports whether branch must never be deleted
+func isProtectedBranch(cfg *config.Config, branch string) bool {
+	if branch == cfg.MainBranch || branch == "main" || branch == "master" {
+		return true
+	}
+	for _, protected := range cfg.Safety.ProtectedBranches {
+		if branch == protected {
+			return true
+		}
+	}
+	return false
+}
+
+// guardMainWorktree refuses to continue when a destructive action targets the main worktree
+func guardMainWorktree(cfg *config.Config, path, action string) {
+	if !cfg.Safety.ProtectMain || !isMainWorktree(path) {
+		return
+	}
+	config.Fail(errs.Conflict("Refusing to %s the main worktree (%s).\n\n  This is where your repository lives. To override, set safety.protect_main: false.", action, path))
+}
+
+// guardBranch refuses to continue when a destructive action targets a protected branch
+func guardBranch(cfg *config.Config, branch, action string) {
+	if !cfg.Safety.ProtectMain || !isProtectedBranch(cfg, branch) {
+		return
+	}
+	config.Fail(errs.Conflict("Refusing to %s protected branch '%s'.\n\n  To override, set safety.protect_main: false.", action, branch))
+}
+
+// requireSigned reports whether signed commits are required, globally or for the repository at path
+func requireSigned(cfg *config.Config, path string) bool {
+	return cfg.Signing.RequireSigned || git.GetConfig(path, "gwi.requireSigned") == "true"
+}
+
+// guardSignedCommits refuses to continue when signing is required and revRange contains unsigned commits
+func guardSignedCommits(cfg *config.Config, path, revRange, action string) {
+	if !requireSigned(cfg, path) {
+		return
+	}
+
+	unsigned, err := git.GetUnsignedCommits(path, revRange)
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to verify commit signatures: %w", err))
+	}
+	if len(unsigned) == 0 {
+		return
+	}
+
+	var lines []string
+	for _, commit := range unsigned {
+		reason := "unsigned"
+		if commit.Status == "B" {
+			reason = "bad signature"
+		}
+		lines = append(lines, "    "+commit.Hash+" "+commit.Subject+" ("+reason+")")
+	}
+	config.Fail(errs.Conflict("Refusing to %s: signed commits are required.\n\n%s\n\n  Re-sign with: git rebase --exec 'git commit --amend --no-edit -S' <base>",
+		action, strings.Join(lines, "\n")))
+}
+
+// checkMergeSignature refuses to push a merge whose commit isn't validly
+// signed, resetting mainBranch to premergeSHA so nothing unsigned is left behind
+func checkMergeSignature(mainWorktree, mainBranch, premergeSHA string) error {
+	status, err := git.GetSignatureStatus(mainWorktree, "HEAD")
+	if err == nil && (status == "G" || status == "U") {
+		config.Info("Merged commit signature: %s", describeSignature(status))
+		return nil
+	}
+	if premergeSHA != "" {
+		if err := git.ResetHard(mainWorktree, premergeSHA); err != nil {
+			config.Warn("Failed to reset %s: %v", mainBranch, err)
+		}
+	}
+	return errs.Conflict("Refusing to push %s: signed commits are required and the merge commit's signature is %s.\n\n  Check that git signs merges (commit.gpgSign), then run gwi merge again.",
+		mainBranch, describeSignature(status))
+}
+
+// describeSignature explains git's %G? signature code
+func describeSignature(status string) string {
+	switch status {
+	case "G":
+		return "good"
+	case "U":
+		return "good (unknown validity)"
+	case "X":
+		return "good (expired signature)"
+	case "Y":
+		return "good (expired key)"
+	case "R":
+		return "revoked key"
+	case "E":
+		return "cannot be checked (missing key or allowed signers)"
+	case "B":
+		return "bad"
+	default:
+		return "unsigned"
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/sandbox"
+	"github.com/spf13/cobra"
+)
+
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox",
+	Short: "Try gwi against a throwaway repository and a fake GitHub",
+}
+
+var sandboxInitCmd = &cobra.Command{
+	Use:   "init [dir]",
+	Short: "Create a sandbox and print the variables to enter it",
+	Long: `Create a sandbox: a bare "origin" repository, a clone of it posing as
+github.com/sandbox/app, a separate HOME, and a fake GitHub with a few open issues.
+An existing sandbox in dir is replaced. The default dir is GWI_SANDBOX_DIR, or
+gwi-sandbox in the temp directory.
+
+To enter the sandbox in the current shell, run: eval "$(gwi sandbox init)"
+
+With GWI_SANDBOX=1, gwi talks to the fake GitHub instead of running gh. Its state
+is kept in github.json in the sandbox, which can be edited to simulate failing
+checks, reviews or merge conflicts.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSandboxInit,
+}
+
+// internalFakeGHCmd stands in for gh in sandbox mode (see github.Command)
+var internalFakeGHCmd = &cobra.Command{
+	Use:                "_fake-gh",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(sandbox.RunGH(args, os.Stdout, os.Stderr))
+	},
+}
+
+func init() {
+	sandboxCmd.AddCommand(sandboxInitCmd)
+}
+
+func runSandboxInit(cmd *cobra.Command, args []string) {
+	dir := sandbox.Dir()
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		config.Fail(err)
+	}
+	if err := sandbox.Init(dir); err != nil {
+		config.Fail(fmt.Errorf("failed to create sandbox: %w", err))
+	}
+
+	config.Success("Created sandbox in %s", dir)
+	// Keep gwi's files inside the sandbox's HOME
+	fmt.Printf("unset XDG_CONFIG_HOME XDG_STATE_HOME XDG_CACHE_HOME XDG_DATA_HOME GWI_CONFIG_DIR GWI_STATE_DIR GWI_CACHE_DIR GWI_DATA_DIR\n")
+	fmt.Printf("export GWI_SANDBOX=1\n")
+	fmt.Printf("export GWI_SANDBOX_DIR=%s\n", shellQuote(dir))
+	fmt.Printf("export HOME=%s\n", shellQuote(sandbox.HomePath(dir)))
+	fmt.Printf("cd %s\n", shellQuote(sandbox.RepoPath(dir)))
+}
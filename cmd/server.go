@@ -5,35 +5,72 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/hooks"
 	"github.com/spf13/cobra"
 )
 
 var upCmd = &cobra.Command{
-	Use:   "up",
+	Use:   "up [task]",
 	Short: "Start dev server in tmux session",
-	Long:  `Start the development server in a background tmux session.`,
-	Run:   runUp,
+	Long: `Start the development server in a background tmux session.
+
+With a task name, start the .gwi/up.<task> hook in an extra window of the same
+session instead, e.g. 'gwi up worker' runs .gwi/up.worker in a "worker" window.
+
+With --switch <issue>, run the app from another worktree instead: the sessions of
+the repository's other worktrees are stopped with their down hooks, and the server
+is started in the issue's worktree with the port variables of the stopped server
+(server.port_vars, PORT by default), once those ports are free. The current
+directory stays the same.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runUp,
 }
 
 var downCmd = &cobra.Command{
 	Use:   "down",
 	Short: "Stop dev server",
-	Long:  `Stop the running development server tmux session.`,
-	Run:   runDown,
+	Long: `Stop the running development server tmux session.
+
+With --idle, stop every gwi session (in any worktree) that has had no output or
+input for the given duration and has no attached client, e.g. from cron:
+
+  gwi down --idle 2h`,
+	Run: runDown,
+}
+
+var (
+	upSwitch   string
+	downIdle   time.Duration
+	downDryRun bool
+)
+
+func init() {
+	upCmd.Flags().StringVar(&upSwitch, "switch", "", "Stop this repository's running server and start the one of this issue's worktree")
+	downCmd.Flags().DurationVar(&downIdle, "idle", 0, "Stop all gwi sessions idle for at least this long (e.g. 2h)")
+	downCmd.Flags().BoolVar(&downDryRun, "dry-run", false, "With --idle, only list the sessions that would be stopped")
 }
 
+// sessionPathOption is the tmux user option recording a session's worktree
+const sessionPathOption = "@gwi_path"
+
 var logsCmd = &cobra.Command{
-	Use:   "logs",
+	Use:   "logs [task]",
 	Short: "View server logs (attach to tmux)",
-	Long:  `Attach to the tmux session to view server logs. Detach with Ctrl+B D.`,
+	Long:  `Attach to the tmux session to view server logs, or to the window of a task started with 'gwi up <task>'. Detach with Ctrl+B D.`,
+	Args:  cobra.MaximumNArgs(1),
 	Run:   runLogs,
 }
 
+// serverWindow is the tmux window running the plain 'up' hook
+const serverWindow = "server"
+
 func getSessionName() string {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -52,50 +89,95 @@ func tmuxSessionExists(name string) bool {
 	return cmd.Run() == nil
 }
 
+func tmuxWindowExists(session, window string) bool {
+	output, err := exec.Command("tmux", "list-windows", "-t", session, "-F", "#{window_name}").Output()
+	if err != nil {
+		return false
+	}
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name == window {
+			return true
+		}
+	}
+	return false
+}
+
 func runUp(cmd *cobra.Command, args []string) {
 	if !hasTmux() {
-		config.Die("tmux is required for 'gwi up'. Install with: brew install tmux")
+		config.Fail(errs.NotFound("tmux is required for 'gwi up'. Install with: brew install tmux"))
+	}
+	if upSwitch != "" {
+		runUpSwitch(cmd, args)
+		return
 	}
 
 	cfg := config.Load()
 	sessionName := getSessionName()
 
-	// Check if session already exists
-	if tmuxSessionExists(sessionName) {
-		config.Info("Session '%s' already running", sessionName)
-		fmt.Println("  gwi logs    # to view")
-		fmt.Println("  gwi down    # to stop")
+	hookName, window := "up", serverWindow
+	if len(args) > 0 {
+		hookName, window = "up."+args[0], args[0]
+	}
+
+	// Check if the window already exists
+	if tmuxWindowExists(sessionName, window) {
+		config.Info("'%s' already running in session '%s'", window, sessionName)
+		printUpUsage(args)
 		return
 	}
 
-	// Find the 'up' hook
+	// Find the hook
 	cwd, _ := os.Getwd()
 	repoInfo, _ := git.GetRepoInfo()
 
-	upScript := hooks.FindHook("up", cwd, cfg, repoInfo)
+	upScript := hooks.FindHook(hookName, cwd, cfg, repoInfo)
 	if upScript == "" {
-		config.Die("No 'up' hook found. Create .gwi/up with your server start command.")
+		if cfg.Safety.ProtectMain && isMainWorktree(cwd) {
+			config.Fail(errs.NotFound("No '%s' hook found and you are in the main worktree.\n\n  Use 'gwi cd' to switch to an issue worktree, or create .gwi/%s in this repository.", hookName, hookName))
+		}
+		if len(args) > 0 {
+			config.Fail(errs.NotFound("No '%s' hook found. Create .gwi/%s with the command for '%s'.", hookName, hookName, window))
+		}
+		config.Fail(errs.NotFound("No 'up' hook found. Create .gwi/up with your server start command."))
+	}
+	if err := checkHookTools(hookName, upScript, cfg, repoInfo); err != nil {
+		config.Fail(err)
 	}
 
-	config.Info("Starting server in tmux session: %s", sessionName)
+	if tmuxSessionExists(sessionName) {
+		config.Info("Starting %s in tmux window: %s:%s", window, sessionName, window)
 
-	// Create tmux session with default shell (will be user's login shell)
-	tmuxCmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-c", cwd)
-	if err := tmuxCmd.Run(); err != nil {
-		config.Die("Failed to start tmux session: %v", err)
-	}
+		tmuxCmd := exec.Command("tmux", "new-window", "-d", "-t", sessionName+":", "-n", window, "-c", cwd)
+		if err := tmuxCmd.Run(); err != nil {
+			config.Fail(fmt.Errorf("failed to create tmux window: %w", err))
+		}
+	} else {
+		config.Info("Starting %s in tmux session: %s", window, sessionName)
+
+		// Create tmux session with default shell (will be user's login shell)
+		tmuxCmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-n", window, "-c", cwd)
+		if err := tmuxCmd.Run(); err != nil {
+			config.Fail(fmt.Errorf("failed to start tmux session: %w", err))
+		}
 
-	// Set remain-on-exit so session stays open if command exits (for viewing logs)
-	setOptCmd := exec.Command("tmux", "set-option", "-t", sessionName, "remain-on-exit", "on")
-	setOptCmd.Run()
+		// Remember the worktree so idle sessions can be stopped from anywhere
+		exec.Command("tmux", "set-option", "-t", sessionName, sessionPathOption, cwd).Run()
 
-	// Enable mouse support for scrolling through logs (must use -g for mouse to work)
-	setMouseCmd := exec.Command("tmux", "set-option", "-g", "mouse", "on")
-	setMouseCmd.Run()
+		// Set remain-on-exit so session stays open if command exits (for viewing logs)
+		setOptCmd := exec.Command("tmux", "set-option", "-t", sessionName, "remain-on-exit", "on")
+		setOptCmd.Run()
 
-	// Increase scrollback buffer for more log history
-	setHistoryCmd := exec.Command("tmux", "set-option", "-t", sessionName, "history-limit", "50000")
-	setHistoryCmd.Run()
+		// Enable mouse support for scrolling through logs (must use -g for mouse to work)
+		setMouseCmd := exec.Command("tmux", "set-option", "-g", "mouse", "on")
+		setMouseCmd.Run()
+
+		// Increase scrollback buffer for more log history
+		setHistoryCmd := exec.Command("tmux", "set-option", "-t", sessionName, "history-limit", "50000")
+		setHistoryCmd.Run()
+	}
+
+	// Keep the task name even if the hook changes the terminal title
+	exec.Command("tmux", "set-window-option", "-t", sessionName+":"+window, "automatic-rename", "off").Run()
 
 	// Wait for shell to initialize (load .zshrc, RVM, etc.)
 	time.Sleep(300 * time.Millisecond)
@@ -111,29 +193,73 @@ func runUp(cmd *cobra.Command, args []string) {
 	// Load direnv environment, then source the script
 	// eval "$(direnv export $shell)" loads the .envrc vars into current shell
 	sourceCmd := fmt.Sprintf("eval \"$(direnv export %s)\" && source \"%s\"", shellName, upScript)
-	sendKeysCmd := exec.Command("tmux", "send-keys", "-t", sessionName, sourceCmd, "Enter")
+	if len(upCarriedEnv) > 0 {
+		// Carried variables win over the worktree's own, so they go after direnv
+		carried, err := writeEnvFile(upCarriedEnv)
+		if err != nil {
+			config.Fail(fmt.Errorf("not running the %s hook: %w", hookName, err))
+		}
+		sourceCmd = fmt.Sprintf("eval \"$(direnv export %s)\" && source \"%s\" && rm -f \"%s\" && source \"%s\"", shellName, carried, carried, upScript)
+	}
+	envFile, err := hookEnvFile(hookName, cwd, cfg, repoInfo)
+	if err != nil {
+		config.Fail(fmt.Errorf("not running the %s hook: %w", hookName, err))
+	}
+	if envFile != "" {
+		sourceCmd = fmt.Sprintf("source \"%s\" && rm -f \"%s\" && %s", envFile, envFile, sourceCmd)
+	}
+	sendKeysCmd := exec.Command("tmux", "send-keys", "-t", sessionName+":"+window, sourceCmd, "Enter")
 	if err := sendKeysCmd.Run(); err != nil {
-		config.Die("Failed to run up script: %v", err)
+		config.Fail(fmt.Errorf("failed to run %s script: %w", hookName, err))
 	}
 
-	config.Success("Server started")
-	fmt.Println("  gwi logs    # to view")
+	if len(args) > 0 {
+		config.Success("Started %s", window)
+	} else {
+		config.Success("Server started")
+	}
+	printUpUsage(args)
+}
+
+func printUpUsage(args []string) {
+	if len(args) > 0 {
+		fmt.Printf("  gwi logs %s    # to view\n", args[0])
+	} else {
+		fmt.Println("  gwi logs    # to view")
+	}
 	fmt.Println("  gwi down    # to stop")
 }
 
 func runDown(cmd *cobra.Command, args []string) {
 	cfg := config.Load()
+
+	if downIdle > 0 {
+		downIdleSessions(cfg, downIdle, downDryRun)
+		return
+	}
+
 	sessionName := getSessionName()
 
 	if !tmuxSessionExists(sessionName) {
 		config.Warn("No session '%s' running", sessionName)
-		os.Exit(1)
+		config.Exit(1)
 	}
 
-	// Run down hook inside the tmux session (same environment as up)
 	cwd, _ := os.Getwd()
+	if err := stopSession(cfg, sessionName, cwd); err != nil {
+		config.Fail(err)
+	}
+	config.Success("Server stopped")
+}
+
+// stopSession runs the down hook of a worktree inside its tmux session, then kills the session
+func stopSession(cfg *config.Config, sessionName, worktreePath string) error {
+	// Run down hook inside the tmux session (same environment as up)
 	repoInfo, _ := git.GetRepoInfo()
-	downScript := hooks.FindHook("down", cwd, cfg, repoInfo)
+	downScript := hooks.FindHook("down", worktreePath, cfg, repoInfo)
+	if downScript == "" && cfg.Safety.ProtectMain && !cfg.Safety.AllowServerInMain && isMainWorktree(worktreePath) {
+		return fmt.Errorf("refusing to stop session '%s' from the main worktree without a 'down' hook.\n\n  Create .gwi/down, or set safety.allow_server_in_main: true", sessionName)
+	}
 	if downScript != "" {
 		config.Info("Running down hook...")
 
@@ -152,6 +278,13 @@ func runDown(cmd *cobra.Command, args []string) {
 
 		// Run the down hook inside the tmux session
 		sourceCmd := fmt.Sprintf("eval \"$(direnv export %s)\" 2>/dev/null; source \"%s\"", shellName, downScript)
+		envFile, err := hookEnvFile("down", worktreePath, cfg, repoInfo)
+		if err != nil {
+			return fmt.Errorf("not running the down hook: %w", err)
+		}
+		if envFile != "" {
+			sourceCmd = fmt.Sprintf("source \"%s\"; rm -f \"%s\"; %s", envFile, envFile, sourceCmd)
+		}
 		sendKeysCmd := exec.Command("tmux", "send-keys", "-t", sessionName, sourceCmd, "Enter")
 		if err := sendKeysCmd.Run(); err != nil {
 			config.Warn("Failed to run down hook: %v", err)
@@ -165,24 +298,138 @@ func runDown(cmd *cobra.Command, args []string) {
 
 	tmuxCmd := exec.Command("tmux", "kill-session", "-t", sessionName)
 	if err := tmuxCmd.Run(); err != nil {
-		config.Die("Failed to stop session: %v", err)
+		return fmt.Errorf("failed to stop session: %v", err)
 	}
+	return nil
+}
 
-	config.Success("Server stopped")
+// tmuxSession is a running tmux session as reported by list-sessions
+type tmuxSession struct {
+	Name         string
+	Attached     bool
+	LastActivity time.Time
+	Path         string // Worktree recorded by gwi up; empty for other sessions
+}
+
+// listGwiSessions returns the tmux sessions started by gwi up
+func listGwiSessions() ([]tmuxSession, error) {
+	// Without a UTF-8 locale, as under cron, tmux writes tabs in formats as
+	// underscores. Session names can't hold a colon; the path, which can, comes last.
+	format := "#{session_name}:#{session_attached}:#{session_activity}:#{" + sessionPathOption + "}"
+	output, err := exec.Command("tmux", "list-sessions", "-F", format).Output()
+	if err != nil {
+		// No server running means no sessions
+		return nil, nil
+	}
+
+	var sessions []tmuxSession
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, ":", 4)
+		if len(parts) != 4 || parts[3] == "" {
+			continue
+		}
+		activity, _ := strconv.ParseInt(parts[2], 10, 64)
+		sessions = append(sessions, tmuxSession{
+			Name:         parts[0],
+			Attached:     parts[1] != "0",
+			LastActivity: time.Unix(activity, 0),
+			Path:         parts[3],
+		})
+	}
+	return sessions, nil
+}
+
+// downIdleSessions stops gwi sessions without attached clients and no activity within idle
+func downIdleSessions(cfg *config.Config, idle time.Duration, dryRun bool) {
+	if !hasTmux() {
+		config.Fail(errs.NotFound("tmux is not installed"))
+	}
+	sessions, _ := listGwiSessions()
+
+	stopped := 0
+	for _, session := range sessions {
+		idleFor := time.Since(session.LastActivity)
+		if session.Attached || idleFor < idle {
+			continue
+		}
+		idleFor = idleFor.Truncate(time.Second)
+		if dryRun {
+			fmt.Printf("  %s (idle %s)\n", session.Name, idleFor)
+			continue
+		}
+
+		// Hooks are resolved relative to the current directory's repository
+		if err := os.Chdir(session.Path); err != nil {
+			config.Warn("Skipping %s: %v", session.Name, err)
+			continue
+		}
+		config.Info("Session '%s' idle for %s", session.Name, idleFor)
+		if err := stopSession(cfg, session.Name, session.Path); err != nil {
+			config.Warn("Skipping %s: %v", session.Name, err)
+			continue
+		}
+		stopped++
+	}
+
+	if !dryRun {
+		config.Success("Stopped %d idle session(s)", stopped)
+	}
 }
 
 func runLogs(cmd *cobra.Command, args []string) {
 	sessionName := getSessionName()
 
 	if !tmuxSessionExists(sessionName) {
-		config.Die("No session '%s' running. Start with: gwi up", sessionName)
+		config.Fail(errs.NotFound("No session '%s' running. Start with: gwi up", sessionName))
+	}
+
+	target := sessionName
+	if len(args) > 0 {
+		if !tmuxWindowExists(sessionName, args[0]) {
+			config.Fail(errs.NotFound("No window '%s' in session '%s'. Start with: gwi up %s", args[0], sessionName, args[0]))
+		}
+		target = sessionName + ":" + args[0]
+	} else if tmuxWindowExists(sessionName, serverWindow) {
+		target = sessionName + ":" + serverWindow
 	}
+	exec.Command("tmux", "select-window", "-t", target).Run()
 
 	config.Info("Attaching to session (Ctrl+B D to detach)")
 
-	tmuxCmd := exec.Command("tmux", "attach-session", "-t", sessionName)
+	tmuxCmd := exec.Command("tmux", "attach-session", "-t", target)
 	tmuxCmd.Stdin = os.Stdin
 	tmuxCmd.Stdout = os.Stdout
 	tmuxCmd.Stderr = os.Stderr
 	tmuxCmd.Run()
 }
+
+// hookEnvFile writes the issue's status and the hook_env variables of an up or
+// down hook to a private file for the tmux shell to source and remove, so
+// secrets don't show up in the command typed into it. It returns "" when the
+// hook has no variables.
+func hookEnvFile(hookName, worktreePath string, cfg *config.Config, repoInfo *git.RepoInfo) (string, error) {
+	env, err := hooks.Env(hookName, cfg, repoInfo)
+	if err != nil {
+		return "", err
+	}
+	env = append(statusEnv(cfg, worktreePath), env...)
+	if len(env) == 0 {
+		return "", nil
+	}
+	return writeEnvFile(env)
+}
+
+// writeEnvFile writes KEY=VALUE pairs as export statements to a private file
+// for a shell to source
+func writeEnvFile(env []string) (string, error) {
+	f, err := os.CreateTemp("", "gwi-hook-env-")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		fmt.Fprintf(f, "export %s=%s\n", name, shellQuote(value))
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// upCarriedEnv holds the variables gwi up --switch takes over from the
+// stopped server, exported in the new session after the worktree's own
+var upCarriedEnv []string
+
+// portReleaseTimeout is how long gwi up --switch waits for the stopped
+// server to free its ports
+const portReleaseTimeout = 10 * time.Second
+
+// runUpSwitch stops the running servers of the repository and starts the
+// server of the --switch issue's worktree in their place
+func runUpSwitch(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	_, target := resolveIssueWorktree(cfg, repoInfo, []string{upSwitch})
+	name := filepath.Base(target)
+
+	sessions, _ := listGwiSessions()
+	var running []tmuxSession
+	for _, session := range repoSessions(cfg, repoInfo, sessions) {
+		if canonicalPath(session.Path) != canonicalPath(target) {
+			running = append(running, session)
+		}
+	}
+	if len(running) == 0 {
+		config.Info("No other server of %s/%s is running", repoInfo.Org, repoInfo.Repo)
+	}
+
+	carried := carriedPortEnv(cfg, running)
+	for _, session := range running {
+		// Hooks are resolved relative to the current directory's repository
+		if err := os.Chdir(session.Path); err != nil {
+			config.Fail(err)
+		}
+		if err := stopSession(cfg, session.Name, session.Path); err != nil {
+			config.Fail(err)
+		}
+		config.Success("Stopped %s", session.Name)
+	}
+	waitForPorts(carried)
+
+	if err := os.Chdir(target); err != nil {
+		config.Fail(err)
+	}
+	upSwitch = ""
+	upCarriedEnv = carried
+	runUp(cmd, args)
+	if len(running) > 0 {
+		config.Success("Switched the server to %s", name)
+	}
+}
+
+// repoSessions returns the sessions running in the repository's worktrees,
+// its main worktree included
+func repoSessions(cfg *config.Config, repoInfo *git.RepoInfo, sessions []tmuxSession) []tmuxSession {
+	base := canonicalPath(cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo))
+	mainPath, _ := git.GetMainWorktreePath()
+	var matched []tmuxSession
+	for _, session := range sessions {
+		path := canonicalPath(session.Path)
+		if strings.HasPrefix(path, base+string(filepath.Separator)) || (mainPath != "" && path == canonicalPath(mainPath)) {
+			matched = append(matched, session)
+		}
+	}
+	return matched
+}
+
+// carriedPortEnv reads the server.port_vars of the first stopped session's
+// worktree, as direnv loads them there. Without direnv nothing is carried,
+// and the new server uses the ports of its own worktree.
+func carriedPortEnv(cfg *config.Config, sessions []tmuxSession) []string {
+	if len(sessions) == 0 || len(cfg.Server.PortVars) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("direnv"); err != nil {
+		return nil
+	}
+	output, err := exec.Command("direnv", "exec", sessions[0].Path, "env").Output()
+	if err != nil {
+		if cfg.Verbose {
+			config.Warn("Could not read the ports of %s: %v", sessions[0].Name, err)
+		}
+		return nil
+	}
+
+	var env []string
+	for _, line := range strings.Split(string(output), "\n") {
+		name, value, ok := strings.Cut(line, "=")
+		if ok && value != "" && slices.Contains(cfg.Server.PortVars, name) {
+			env = append(env, line)
+		}
+	}
+	return env
+}
+
+// waitForPorts waits until nothing listens on the numeric values of env, so
+// the new server can bind the ports the stopped one used
+func waitForPorts(env []string) {
+	for _, entry := range env {
+		_, value, _ := strings.Cut(entry, "=")
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			continue
+		}
+		address := net.JoinHostPort("localhost", strconv.Itoa(port))
+		deadline := time.Now().Add(portReleaseTimeout)
+		for {
+			conn, err := net.DialTimeout("tcp", address, 200*time.Millisecond)
+			if err != nil {
+				break
+			}
+			conn.Close()
+			if time.Now().After(deadline) {
+				config.Warn("Port %d is still in use; the server may fail to start", port)
+				break
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// snapshotTrailer marks WIP commits created by gwi so they can be undone safely
+const snapshotTrailer = "Gwi-Snapshot: true"
+
+var (
+	snapshotPop     bool
+	snapshotStash   bool
+	snapshotMessage string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [issue-number]",
+	Short: "Save uncommitted work as a WIP commit",
+	Long: `Capture all uncommitted changes (including untracked files) of a worktree as a WIP
+commit, or as a named stash with --stash. Use --pop to undo the latest snapshot and get
+the changes back in the working tree.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runSnapshot,
+}
+
+func init() {
+	snapshotCmd.Flags().BoolVar(&snapshotPop, "pop", false, "Undo the latest snapshot and restore its changes")
+	snapshotCmd.Flags().BoolVar(&snapshotStash, "stash", false, "Store the snapshot as a stash instead of a WIP commit")
+	snapshotCmd.Flags().StringVarP(&snapshotMessage, "message", "m", "", "Describe the snapshot")
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	_, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+	branchName := filepath.Base(worktreePath)
+
+	if snapshotPop {
+		if err := popSnapshot(worktreePath, branchName); err != nil {
+			config.Fail(err)
+		}
+		return
+	}
+
+	if !git.HasUncommittedChanges(worktreePath) {
+		config.Info("Nothing to snapshot, worktree is clean.")
+		return
+	}
+
+	if err := takeSnapshot(cfg, worktreePath, branchName, snapshotMessage, snapshotStash); err != nil {
+		config.Fail(err)
+	}
+}
+
+// takeSnapshot records all uncommitted changes as a WIP commit or a named stash.
+// WIP commits follow the repository's signing configuration.
+func takeSnapshot(cfg *config.Config, worktreePath, branchName, message string, useStash bool) error {
+	stamp := time.Now().Format("2006-01-02 15:04:05")
+	if message == "" {
+		message = stamp
+	}
+
+	if useStash {
+		name := fmt.Sprintf("gwi-snapshot/%s/%s", branchName, message)
+		if err := git.StashPush(worktreePath, name); err != nil {
+			return err
+		}
+		config.Success("Stashed changes as '%s'", name)
+		return nil
+	}
+
+	commitMsg := fmt.Sprintf("WIP: gwi snapshot %s\n\n%s", message, snapshotTrailer)
+	if err := git.CommitAll(worktreePath, commitMsg, requireSigned(cfg, worktreePath)); err != nil {
+		return err
+	}
+	config.Success("Saved changes as WIP commit (undo with: gwi snapshot --pop)")
+	return nil
+}
+
+// popSnapshot undoes the latest snapshot commit, or pops the latest snapshot stash of the branch
+func popSnapshot(worktreePath, branchName string) error {
+	if msg, err := git.GetCommitMessage(worktreePath, "HEAD"); err == nil && strings.Contains(msg, snapshotTrailer) {
+		if err := git.UndoLastCommit(worktreePath); err != nil {
+			return err
+		}
+		config.Success("Restored changes from WIP commit")
+		return nil
+	}
+
+	stashes, err := git.ListStashes(worktreePath)
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+	prefix := "gwi-snapshot/" + branchName + "/"
+	for _, stash := range stashes {
+		if strings.Contains(stash.Message, prefix) {
+			if err := git.StashPop(worktreePath, stash.Ref); err != nil {
+				return err
+			}
+			config.Success("Restored changes from %s", stash.Ref)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no gwi snapshot found for %s", branchName)
+}
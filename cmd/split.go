@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split [files...]",
+	Short: "Move uncommitted changes to another issue's worktree",
+	Long: `Move some of the worktree's uncommitted changes to the worktree of another issue,
+to keep pull requests focused. Without files, pick them from a list; with --patch,
+pick hunks of them as with git add --patch.
+
+The changes go to a new issue titled --title, or to an existing issue with --to.
+Its worktree is created if needed and the changes applied there, with a three-way
+merge if they don't apply cleanly to its branch; only then are they taken out of
+this worktree. Only uncommitted changes can be moved; use gwi cherry for commits.`,
+	Run: runSplit,
+}
+
+var (
+	splitTo    int
+	splitTitle string
+	splitPatch bool
+)
+
+func init() {
+	splitCmd.Flags().IntVar(&splitTo, "to", 0, "Existing issue to move the changes to")
+	splitCmd.Flags().StringVar(&splitTitle, "title", "", "Title of a new issue to move the changes to")
+	splitCmd.Flags().BoolVarP(&splitPatch, "patch", "p", false, "Pick hunks of the files instead of whole files")
+}
+
+func runSplit(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	if splitTo != 0 && splitTitle != "" {
+		config.Fail(errs.New(errs.KindUsage, "--to and --title can't be combined"))
+	}
+
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	sourceIssue, ok := git.DetectIssueNumber(base)
+	if !ok {
+		config.Fail(errs.New(errs.KindUsage, "Run gwi split inside an issue worktree"))
+	}
+	cwd, _ := os.Getwd()
+	source, err := git.GetTopLevel(cwd)
+	if err != nil {
+		config.Fail(err)
+	}
+
+	changed, err := git.ListChangedFiles(source)
+	if err != nil {
+		config.Fail(err)
+	}
+	if len(changed) == 0 {
+		config.Fail(errs.NotFound("No uncommitted changes to split"))
+	}
+	files, err := selectSplitFiles(cwd, source, changed, args)
+	if err != nil {
+		config.Fail(err)
+	}
+	var staged []string
+	for _, file := range files {
+		if file.Staged() {
+			staged = append(staged, file.Path)
+		}
+	}
+	if len(staged) > 0 {
+		config.Fail(errs.Conflict("Staged changes can't be split: %s\n\n  Unstage them first: git restore --staged %s",
+			strings.Join(staged, ", "), strings.Join(staged, " ")))
+	}
+
+	targetIssue, title := splitTo, splitTitle
+	if targetIssue == 0 && title == "" {
+		if config.Headless() {
+			config.Fail(errs.New(errs.KindUsage, "Pass --title for a new issue or --to for an existing one"))
+		}
+		answer := promptLine("Title of the new issue (or #N for an existing one): ")
+		if n, err := strconv.Atoi(strings.TrimPrefix(answer, "#")); err == nil && strings.HasPrefix(answer, "#") {
+			targetIssue = n
+		} else if title = answer; title == "" {
+			config.Fail(errs.Aborted("Aborted"))
+		}
+	}
+	if targetIssue == sourceIssue {
+		config.Fail(errs.New(errs.KindUsage, "The changes are already in #%d's worktree", sourceIssue))
+	}
+
+	patch, err := git.DiffFiles(source, files, splitPatch)
+	if err != nil {
+		config.Fail(err)
+	}
+	if len(patch) == 0 {
+		config.Fail(errs.Aborted("Nothing selected"))
+	}
+	// Kept until the changes have moved, so they can't get lost
+	patchFile := filepath.Join(xdg.StateDir(), "split", fmt.Sprintf("%d-%d.patch", sourceIssue, time.Now().Unix()))
+	if err := os.MkdirAll(filepath.Dir(patchFile), 0755); err == nil {
+		err = os.WriteFile(patchFile, patch, 0600)
+	}
+	if err != nil {
+		config.Fail(err)
+	}
+
+	if targetIssue == 0 {
+		body := fmt.Sprintf("Split off from #%d.", sourceIssue)
+		if targetIssue, err = createIssue(cfg, title, body); err != nil {
+			config.Fail(err)
+		}
+		config.Success("Created issue #%d: %s", targetIssue, title)
+	}
+	target := git.FindWorktreeByIssue(base, targetIssue)
+	if target == "" {
+		config.Info("Creating the worktree of #%d...", targetIssue)
+		target = createWorktree(cfg, repoInfo, targetIssue, true)
+	}
+
+	if err := git.ApplyPatch(target, patchFile); err != nil {
+		if err := git.ApplyPatchThreeWay(target, patchFile); err != nil {
+			config.Fail(fmt.Errorf("%w\n\n  The changes are still here; the patch is kept in %s", err, patchFile))
+		}
+		config.Warn("Applied with a three-way merge; check %s for conflicts", target)
+	}
+	if err := git.RevertPatch(source, patchFile); err != nil {
+		config.Fail(fmt.Errorf("moved the changes to #%d but couldn't take them out of this worktree: %w\n\n  The patch is kept in %s", targetIssue, err, patchFile))
+	}
+	os.Remove(patchFile)
+
+	config.Success("Moved %d file(s) to #%d: %s", len(files), targetIssue, target)
+	fmt.Fprintf(os.Stderr, "  Continue there with: gwi cd %d\n", targetIssue)
+}
+
+// selectSplitFiles picks the changed files to move: the ones named on the
+// command line, relative to the current directory, or from a numbered list
+func selectSplitFiles(cwd, root string, changed []git.ChangedFile, args []string) ([]git.ChangedFile, error) {
+	if len(args) > 0 {
+		var files []git.ChangedFile
+		for _, arg := range args {
+			abs := arg
+			if !filepath.IsAbs(abs) {
+				abs = filepath.Join(cwd, arg)
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return nil, err
+			}
+			rel = filepath.ToSlash(rel)
+			matched := false
+			for _, file := range changed {
+				// A directory selects the changed files below it
+				if file.Path == rel || rel == "." || strings.HasPrefix(file.Path, rel+"/") {
+					files = appendChangedFile(files, file)
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, errs.NotFound("No uncommitted changes in %s", arg)
+			}
+		}
+		return files, nil
+	}
+
+	if config.Headless() {
+		return nil, errs.New(errs.KindUsage, "Pass the files to move")
+	}
+	fmt.Fprintln(os.Stderr, "Uncommitted changes:")
+	for i, file := range changed {
+		fmt.Fprintf(os.Stderr, "  %2d) %s %s\n", i+1, config.Yellow(file.Status), file.Path)
+	}
+	answer := promptLine("Files to move (e.g. 1 3-5): ")
+	var files []git.ChangedFile
+	for _, field := range strings.Fields(strings.ReplaceAll(answer, ",", " ")) {
+		from, to, isRange := strings.Cut(field, "-")
+		first, err := strconv.Atoi(from)
+		last := first
+		if err == nil && isRange {
+			last, err = strconv.Atoi(to)
+		}
+		if err != nil || first < 1 || last > len(changed) || first > last {
+			return nil, errs.New(errs.KindUsage, "Invalid selection: %s", field)
+		}
+		for _, file := range changed[first-1 : last] {
+			files = appendChangedFile(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errs.Aborted("Nothing selected")
+	}
+	return files, nil
+}
+
+// appendChangedFile adds file to files unless it was selected already
+func appendChangedFile(files []git.ChangedFile, file git.ChangedFile) []git.ChangedFile {
+	for _, f := range files {
+		if f.Path == file.Path {
+			return files
+		}
+	}
+	return append(files, file)
+}
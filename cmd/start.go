@@ -1,9 +1,8 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/spf13/cobra"
 )
@@ -30,17 +29,17 @@ func runInternalStart(cmd *cobra.Command, args []string) {
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
 	issueNumber, err := selectIssue(repoInfo)
 	if err != nil {
-		config.Die("No issue selected")
+		config.Fail(errs.Aborted("No issue selected"))
 	}
 
 	// Create worktree silently (for shell integration)
 	worktreePath := createWorktree(cfg, repoInfo, issueNumber, true)
 
 	// Output just the path for shell function to use
-	fmt.Println(worktreePath)
+	printCdPath(worktreePath)
 }
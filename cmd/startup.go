@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/deps"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// Internal command run by the shell integration (gwi init --startup-check)
+var internalStartupCheckCmd = &cobra.Command{
+	Use:    "_startup-check",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run:    runStartupCheck,
+}
+
+var startupCheckForce bool
+
+func init() {
+	internalStartupCheckCmd.Flags().BoolVar(&startupCheckForce, "force", false, "Check even if already checked today")
+}
+
+// startupCheckKey records when the last startup check ran
+const startupCheckKey = "startup/last_check"
+
+// startupCheckInterval rate-limits the startup check
+const startupCheckInterval = 24 * time.Hour
+
+// staleWorktree is a worktree whose issue is closed or whose PR was merged
+type staleWorktree struct {
+	repo   index.Repo
+	path   string
+	reason string
+}
+
+func runStartupCheck(cmd *cobra.Command, args []string) {
+	if !startupCheckForce {
+		var last time.Time
+		if s, err := store.Load(); err == nil && s.Get(startupCheckKey, &last) && time.Since(last) < startupCheckInterval {
+			return
+		}
+	}
+	// Record the check up front so a failing check doesn't delay every new shell
+	store.Update(func(s *store.Store) error {
+		return s.Set(startupCheckKey, time.Now())
+	})
+
+	if err := github.CheckAuth(); err != nil {
+		return
+	}
+
+	stale := findStaleWorktrees()
+	if len(stale) == 0 {
+		return
+	}
+
+	config.Info("gwi: %d worktree(s) can be cleaned up:", len(stale))
+	for _, wt := range stale {
+		fmt.Fprintf(os.Stderr, "  %s/%s %s %s\n", wt.repo.Org, wt.repo.Repo, filepath.Base(wt.path), config.Yellow("("+wt.reason+")"))
+	}
+	if !confirmPrompt("Remove them?") {
+		return
+	}
+
+	var kept []string
+	for _, wt := range stale {
+		if unpushed := removeStaleWorktree(wt); unpushed > 0 {
+			kept = append(kept, fmt.Sprintf("%s/%s %s (%d unpushed commit(s))", wt.repo.Org, wt.repo.Repo, filepath.Base(wt.path), unpushed))
+		}
+	}
+	if len(kept) > 0 {
+		config.Warn("Kept the branches with commits that aren't on the remote:")
+		for _, branch := range kept {
+			fmt.Fprintf(os.Stderr, "  %s\n", branch)
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", config.Dim("Push them, or delete them with: git branch -D <branch>"))
+	}
+}
+
+// findStaleWorktrees checks the worktrees of every known repository. gh and
+// git work on the current directory, so each repository is entered in turn.
+func findStaleWorktrees() []staleWorktree {
+	cfg := config.Load()
+	var stale []staleWorktree
+	for _, repo := range index.All() {
+		if err := os.Chdir(repo.MainPath); err != nil {
+			continue
+		}
+		repoInfo := &git.RepoInfo{Org: repo.Org, Repo: repo.Repo}
+		for _, path := range repo.Worktrees {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			branchName := filepath.Base(path)
+			issueNumber, ok := github.ParseIssueFromBranch(branchName)
+			if !ok {
+				continue
+			}
+
+			if prNumber, ok := mergedPRForBranch(repoInfo, branchName); ok {
+				stale = append(stale, staleWorktree{repo, path, fmt.Sprintf("PR #%d merged", prNumber)})
+			} else if issue, err := getIssue(cfg, issueNumber); err == nil && issue.State == "CLOSED" {
+				stale = append(stale, staleWorktree{repo, path, fmt.Sprintf("issue #%d closed", issueNumber)})
+			}
+		}
+	}
+	return stale
+}
+
+// removeStaleWorktree removes a worktree and its local branch, keeping any
+// worktree that still has uncommitted changes. A branch with commits that
+// aren't on any remote is kept; their number is returned.
+func removeStaleWorktree(wt staleWorktree) (unpushed int) {
+	name := filepath.Base(wt.path)
+	if err := os.Chdir(wt.repo.MainPath); err != nil {
+		config.Warn("Skipping %s: %v", name, err)
+		return 0
+	}
+	if git.HasUncommittedChanges(wt.path) {
+		config.Warn("Skipping %s: it has uncommitted changes", name)
+		return 0
+	}
+	unpushed, _ = git.CountUnpushed(wt.path)
+	repoInfo := &git.RepoInfo{Org: wt.repo.Org, Repo: wt.repo.Repo}
+	journalRemoval(repoInfo, undoEntry{Op: "clean", Branch: name, Worktree: wt.path})
+	if err := git.RemoveWorktree(wt.path, false); err != nil {
+		config.Warn("Failed to remove %s: %v", name, err)
+		return 0
+	}
+	git.PruneWorktrees()
+	if unpushed == 0 {
+		git.DeleteBranch(name)
+	}
+
+	index.Refresh(config.Load(), repoInfo)
+	deps.Forget(wt.path)
+	if issueNumber, ok := github.ParseIssueFromBranch(name); ok {
+		forgetActiveIssue(repoInfo, issueNumber)
+	}
+	config.Success("Removed %s", name)
+	return unpushed
+}
+
+// mergedPRForBranch returns the merged pull request of a branch, checking the
+// linked PR first and linking the one found on GitHub otherwise
+func mergedPRForBranch(repoInfo *git.RepoInfo, branchName string) (int, bool) {
+	if prNumber, ok := linkedPRNumber(repoInfo, branchName); ok {
+		if merged, err := github.IsPRMerged(prNumber); err == nil && merged {
+			return prNumber, true
+		}
+	}
+	prNumber, err := github.GetMergedPRForBranch(branchName)
+	if err != nil {
+		return 0, false
+	}
+	linkPR(repoInfo, branchName, prNumber)
+	return prNumber, true
+}
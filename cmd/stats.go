@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+var resetStats bool
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show local usage statistics",
+	Long: `Show command counts, durations and failure rates recorded on this machine.
+
+Recording is opt-in: set track_stats: true in config.yaml or GWI_TRACK_STATS=1.
+Nothing is ever sent anywhere; statistics live in the local metadata store.`,
+	Run: runStats,
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&resetStats, "reset", false, "Clear all recorded statistics")
+}
+
+func runStats(cmd *cobra.Command, args []string) {
+	if resetStats {
+		if err := stats.Reset(); err != nil {
+			config.Fail(fmt.Errorf("failed to reset statistics: %w", err))
+		}
+		config.Success("Statistics cleared.")
+		return
+	}
+
+	entries, err := stats.All()
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to read statistics: %w", err))
+	}
+
+	if len(entries) == 0 {
+		if !config.Load().TrackStats {
+			config.Info("Statistics are disabled. Enable with track_stats: true or GWI_TRACK_STATS=1")
+		} else {
+			fmt.Println("No statistics recorded yet.")
+		}
+		return
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	// Sorting keeps each command's steps ("create/fetch") directly below it
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMMAND\tRUNS\tFAILED\tAVG\tMAX\tLAST RUN")
+	for _, name := range names {
+		e := entries[name]
+		label := name
+		if idx := strings.Index(name, "/"); idx > 0 {
+			label = "  " + name[idx+1:]
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d (%.0f%%)\t%s\t%s\t%s\n",
+			label, e.Count, e.Failures, e.FailureRate()*100,
+			formatMs(e.AvgMs()), formatMs(e.MaxMs), config.FormatStamp(e.LastRun))
+	}
+	w.Flush()
+}
+
+// formatMs renders a millisecond duration in a compact human form
+func formatMs(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < time.Second {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
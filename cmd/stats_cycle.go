@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cycleSince string
+	cycleJSON  bool
+)
+
+var statsCycleCmd = &cobra.Command{
+	Use:   "cycle",
+	Short: "Show cycle times from the GitHub Project board",
+	Long: `Show how long the repository's issues took from Todo through In Progress to Done,
+read from the status changes GitHub records on the Project board. Issues count
+when they were moved to Done within --since:
+
+  WAIT   Todo (or added to the board) until In Progress
+  CYCLE  In Progress until Done
+  LEAD   Todo until Done
+
+Below the issues, the medians, the 85th percentile of the cycle time and the
+throughput per week are shown for the team. Use --json for dashboards.
+
+GitHub records these changes for the project's built-in Status field only.`,
+	Example: `  gwi stats cycle
+  gwi stats cycle --since 2w --json`,
+	Args: cobra.NoArgs,
+	RunE: runStatsCycle,
+}
+
+func init() {
+	statsCycleCmd.Flags().StringVar(&cycleSince, "since", "30d", "Period to include, as 30d, 2w, a duration like 12h, or a date (2006-01-02)")
+	statsCycleCmd.Flags().BoolVar(&cycleJSON, "json", false, "Print the issues and metrics as JSON")
+	statsCmd.AddCommand(statsCycleCmd)
+}
+
+// cycleIssue is an issue moved to Done in the period, with its durations
+type cycleIssue struct {
+	Number     int       `json:"number"`
+	Title      string    `json:"title"`
+	TodoAt     time.Time `json:"todo_at"`
+	StartedAt  time.Time `json:"started_at"`
+	DoneAt     time.Time `json:"done_at"`
+	WaitHours  float64   `json:"wait_hours"`
+	CycleHours float64   `json:"cycle_hours"`
+	LeadHours  float64   `json:"lead_hours"`
+}
+
+// cycleSummary holds the team metrics over the issues of the period
+type cycleSummary struct {
+	Done              int     `json:"done"`
+	NotStarted        int     `json:"not_started"` // Done without ever being In Progress; left out of the metrics
+	WaitMedianHours   float64 `json:"wait_median_hours"`
+	CycleMedianHours  float64 `json:"cycle_median_hours"`
+	CycleMeanHours    float64 `json:"cycle_mean_hours"`
+	CycleP85Hours     float64 `json:"cycle_p85_hours"`
+	LeadMedianHours   float64 `json:"lead_median_hours"`
+	ThroughputPerWeek float64 `json:"throughput_per_week"`
+}
+
+// cycleReport is what gwi stats cycle --json prints
+type cycleReport struct {
+	Repo    string       `json:"repo"`
+	Project string       `json:"project"`
+	Since   time.Time    `json:"since"`
+	Issues  []cycleIssue `json:"issues"`
+	Summary cycleSummary `json:"summary"`
+}
+
+func runStatsCycle(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return err
+	}
+	if _, ok := azureBoards(cfg); ok {
+		return errs.New(errs.KindUsage, "gwi stats cycle reads the GitHub Project board and is not supported with Azure Boards")
+	}
+	if !cfg.GitHub.ProjectsEnabled {
+		return errs.New(errs.KindUsage, "gwi stats cycle needs the GitHub Project board; enable github.projects_enabled")
+	}
+	since, err := parseSince(cycleSince, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := github.CheckAuth(); err != nil {
+		return err
+	}
+
+	project, err := resolveBoardProject(cfg, repoInfo.Org, repoInfo.Repo)
+	if err != nil {
+		return err
+	}
+	history, err := github.ListProjectStatusHistory(project.ID, cfg.GitHub.StatusFieldName)
+	if err != nil {
+		return err
+	}
+
+	// Only count this repository's issues; projects can span repositories
+	repoName := repoInfo.Org + "/" + repoInfo.Repo
+	history = slices.DeleteFunc(history, func(h github.StatusHistory) bool { return !strings.EqualFold(h.Repo, repoName) })
+
+	report := cycleReport{Repo: repoName, Project: project.Title, Since: since, Issues: []cycleIssue{}}
+	for _, h := range history {
+		issue, done, started := cycleTimes(cfg, h)
+		if !done || issue.DoneAt.Before(since) {
+			continue
+		}
+		if !started {
+			report.Summary.NotStarted++
+			continue
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	slices.SortFunc(report.Issues, func(a, b cycleIssue) int { return a.DoneAt.Compare(b.DoneAt) })
+	report.Summary = summarizeCycles(report.Issues, report.Summary.NotStarted, time.Since(since))
+
+	if cycleJSON {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+	printCycleReport(report)
+	return nil
+}
+
+// parseSince turns --since into the start of the period: a number of days or
+// weeks (30d, 2w), a Go duration (12h) or a date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(value, suffix); ok {
+			if n, err := strconv.Atoi(count); err == nil && n > 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, errs.New(errs.KindUsage, "Invalid --since %q; use e.g. 30d, 2w, 12h or 2006-01-02", value)
+}
+
+// cycleTimes works out when an issue entered Todo, started and was done from
+// its status changes. An issue is done when it is in Done now, as of the last
+// move there; it started with its first move to In Progress before that, and
+// entered Todo with its first move there, or else when it was added.
+func cycleTimes(cfg *config.Config, h github.StatusHistory) (issue cycleIssue, done, started bool) {
+	issue = cycleIssue{Number: h.Number, Title: h.Title, TodoAt: h.AddedAt}
+	if !strings.EqualFold(h.Status, cfg.GitHub.DoneValue) {
+		return issue, false, false
+	}
+	for _, change := range h.Changes {
+		if strings.EqualFold(change.To, cfg.GitHub.DoneValue) {
+			issue.DoneAt, done = change.At, true
+		}
+	}
+	if !done {
+		return issue, false, false
+	}
+
+	inTodo := false
+	for _, change := range h.Changes {
+		if started || change.At.After(issue.DoneAt) {
+			break
+		}
+		if !inTodo && strings.EqualFold(change.To, cfg.GitHub.TodoValue) {
+			issue.TodoAt, inTodo = change.At, true
+		}
+		if strings.EqualFold(change.To, cfg.GitHub.InProgressValue) {
+			issue.StartedAt, started = change.At, true
+		}
+	}
+	if !started {
+		return issue, true, false
+	}
+	// Issues added to the board straight into In Progress never waited
+	if issue.TodoAt.After(issue.StartedAt) {
+		issue.TodoAt = issue.StartedAt
+	}
+	issue.WaitHours = hours(issue.StartedAt.Sub(issue.TodoAt))
+	issue.CycleHours = hours(issue.DoneAt.Sub(issue.StartedAt))
+	issue.LeadHours = hours(issue.DoneAt.Sub(issue.TodoAt))
+	return issue, true, true
+}
+
+// summarizeCycles aggregates the durations of the issues done in period
+func summarizeCycles(issues []cycleIssue, notStarted int, period time.Duration) cycleSummary {
+	summary := cycleSummary{Done: len(issues), NotStarted: notStarted}
+	if len(issues) == 0 {
+		return summary
+	}
+	var wait, cycle, lead []float64
+	for _, issue := range issues {
+		wait = append(wait, issue.WaitHours)
+		cycle = append(cycle, issue.CycleHours)
+		lead = append(lead, issue.LeadHours)
+	}
+	summary.WaitMedianHours = percentile(wait, 50)
+	summary.CycleMedianHours = percentile(cycle, 50)
+	summary.CycleP85Hours = percentile(cycle, 85)
+	summary.LeadMedianHours = percentile(lead, 50)
+	var total float64
+	for _, h := range cycle {
+		total += h
+	}
+	summary.CycleMeanHours = round1(total / float64(len(cycle)))
+	if weeks := period.Hours() / (7 * 24); weeks > 0 {
+		summary.ThroughputPerWeek = round1(float64(len(issues)) / weeks)
+	}
+	return summary
+}
+
+// percentile returns the nearest-rank percentile p of values
+func percentile(values []float64, p float64) float64 {
+	sorted := slices.Sorted(slices.Values(values))
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// hours converts a duration to hours, rounded to a tenth
+func hours(d time.Duration) float64 {
+	return round1(d.Hours())
+}
+
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+// formatHours renders a number of hours like formatIdle does a duration
+func formatHours(h float64) string {
+	return formatIdle(time.Duration(h * float64(time.Hour)))
+}
+
+func printCycleReport(report cycleReport) {
+	if len(report.Issues) == 0 {
+		config.Info("No issues of %s moved to Done on %s since %s", report.Repo, report.Project, report.Since.Format("2006-01-02"))
+		if report.Summary.NotStarted > 0 {
+			config.Info("%d issue(s) went to Done without being In Progress", report.Summary.NotStarted)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ISSUE\tWAIT\tCYCLE\tLEAD\tDONE\tTITLE")
+	for _, issue := range report.Issues {
+		fmt.Fprintf(w, "#%d\t%s\t%s\t%s\t%s\t%s\n", issue.Number,
+			formatHours(issue.WaitHours), formatHours(issue.CycleHours), formatHours(issue.LeadHours),
+			config.FormatStamp(issue.DoneAt), issue.Title)
+	}
+	w.Flush()
+
+	s := report.Summary
+	fmt.Println()
+	fmt.Printf("%d issue(s) done since %s on %s, %.1f per week\n", s.Done, report.Since.Format("2006-01-02"), report.Project, s.ThroughputPerWeek)
+	fmt.Printf("  cycle  median %s, mean %s, 85%% within %s\n", formatHours(s.CycleMedianHours), formatHours(s.CycleMeanHours), formatHours(s.CycleP85Hours))
+	fmt.Printf("  wait   median %s\n", formatHours(s.WaitMedianHours))
+	fmt.Printf("  lead   median %s\n", formatHours(s.LeadMedianHours))
+	if s.NotStarted > 0 {
+		fmt.Println(config.Dim(fmt.Sprintf("  %d issue(s) went to Done without being In Progress and are left out", s.NotStarted)))
+	}
+}
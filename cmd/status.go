@@ -1,106 +1,350 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
-	"regexp"
-	"strconv"
+	"time"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 	"github.com/enterprisemodules/gwi/internal/git"
 	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show status of all worktrees",
-	Long:  `Display all worktrees with their git status, push/pull state, and PR status.`,
-	Run:   runStatus,
+	Long: `Display all worktrees with their git status, push/pull state, and PR status.
+
+Directories under the worktree base are checked against git worktree list: those
+git doesn't know (a missing or broken .git link) are flagged as orphaned, locked
+worktrees and prunable entries (registered, but their directory is gone) are
+listed too, each with a suggested repair.
+
+With --summary, print a one-line overview such as "5 wt · 2 dirty · 3 PR · 1 red"
+(worktrees, with uncommitted changes, open PRs, PRs with failing checks) for a tmux
+status line or shell prompt. It is served from a cache refreshed in the background.
+
+With --workspace, show the repositories of a workspace (a named group of
+repositories under workspaces in the config) in one table, a section per
+repository. --json prints the status as JSON: one repository, or an array of
+them for a workspace.`,
+	Run: runStatus,
+}
+
+var (
+	statusFetch          bool
+	statusSummaryLine    bool
+	statusRefreshSummary bool
+	statusWorkspace      string
+	statusJSON           bool
+)
+
+func init() {
+	statusCmd.Flags().BoolVarP(&statusFetch, "fetch", "f", false, "Fetch from the remotes (with prune) before showing status")
+	statusCmd.Flags().BoolVar(&statusSummaryLine, "summary", false, "Print a cached one-line summary for tmux or a prompt")
+	statusCmd.Flags().BoolVar(&statusRefreshSummary, "refresh-summary", false, "Recompute the cached summary")
+	statusCmd.Flags().MarkHidden("refresh-summary")
+	statusCmd.Flags().StringVarP(&statusWorkspace, "workspace", "w", "", "Show the repositories of a workspace from the config")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "Print the status as JSON")
+	statusCmd.MarkFlagsMutuallyExclusive("fetch", "summary")
+	statusCmd.MarkFlagsMutuallyExclusive("workspace", "summary")
+	statusCmd.MarkFlagsMutuallyExclusive("json", "summary")
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
+	switch {
+	case statusRefreshSummary:
+		refreshStatusSummary()
+		return
+	case statusSummaryLine:
+		printStatusSummary()
+		return
+	case statusWorkspace != "":
+		runWorkspaceStatus()
+		return
+	}
+
 	cfg := config.Load()
 	repoInfo, err := git.GetRepoInfo()
 	if err != nil {
-		config.Die("%v", err)
+		config.Fail(err)
 	}
 
-	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	status := collectRepoStatus(cfg, repoInfo)
+	if statusJSON {
+		data, _ := json.MarshalIndent(status, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
 
 	fmt.Printf("%sgwi status%s for %s%s/%s%s\n", config.Green(""), config.Green(""), config.Blue(""), repoInfo.Org, repoInfo.Repo, config.Blue(""))
 	fmt.Println()
+	status.print(0)
+}
+
+// worktreeStatus is the state of one worktree shown by gwi status
+type worktreeStatus struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Changes int    `json:"changes"` // Uncommitted changes; -1 when not checked
+	Ahead   int    `json:"ahead"`
+	Behind  int    `json:"behind"`
+	PR      int    `json:"pr,omitempty"`
+	PRState string `json:"pr_state,omitempty"` // OPEN, MERGED or CLOSED
+	Server  bool   `json:"server"`
+	Epic    int    `json:"epic,omitempty"`   // Epic the worktree's issue belongs to, in the same repository
+	Health  string `json:"health,omitempty"` // "orphaned" or "locked"; empty when healthy
+	Repair  string `json:"repair,omitempty"` // How to fix the health problem
+
+	hasIssue bool   // Named after an issue, so it can have a PR
+	labels   string // Formatted issue labels
+	epic     string // Name of the issue's epic
+	deps     string // Dependency indicator
+}
+
+// repoStatus is the state of a repository's worktrees
+type repoStatus struct {
+	Repo      string             `json:"repo"`
+	Path      string             `json:"path"`
+	Worktrees []worktreeStatus   `json:"worktrees"`
+	Prunable  []prunableWorktree `json:"prunable,omitempty"` // Worktrees git lists whose directory is gone
+	Error     string             `json:"error,omitempty"`
+}
+
+// collectRepoStatus gathers the status of the worktrees of the repository in
+// the current directory
+func collectRepoStatus(cfg *config.Config, repoInfo *git.RepoInfo) repoStatus {
+	status := repoStatus{Repo: repoInfo.Org + "/" + repoInfo.Repo, Worktrees: []worktreeStatus{}}
+	status.Path, _ = git.GetMainWorktreePath()
+
+	// Directories under the base are cross-checked with the worktrees git knows
+	registry := loadWorktreeRegistry()
+	status.Prunable = registry.prunable()
 
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
 	worktrees, err := git.ListWorktrees(base)
 	if err != nil || len(worktrees) == 0 {
-		fmt.Println("No worktrees found.")
-		return
+		return status
+	}
+	gitDir := status.Path
+	if gitDir == "" {
+		gitDir = worktrees[0]
+	}
+
+	// Worktrees share the object store and remote refs, so one fetch covers them all
+	if statusFetch || cfg.Status.AutoFetch && time.Since(git.LastFetch(gitDir)) >= cfg.Status.FetchInterval {
+		config.Info("Fetching %s from %s...", status.Repo, git.FetchRemote())
+		if err := git.FetchPrune(); err != nil {
+			config.Warn("Failed to fetch, ahead/behind counts may be stale: %v", err)
+		}
+	}
+	checkMainBranch(cfg, false)
+
+	// One call links the open PRs of all worktrees, including PRs opened outside gwi
+	var branches []string
+	for _, dir := range worktrees {
+		if _, ok := issueid.FromName(filepath.Base(dir)); ok {
+			branches = append(branches, filepath.Base(dir))
+		}
 	}
+	linkOpenPRs(repoInfo, branches)
 
-	re := regexp.MustCompile(`^(\d+)-`)
+	meta, _ := store.Load()
+	labels, _ := github.ListIssueLabels(200)
+	epics := issueEpics(cfg, 200)
+	skipChanges := skipChangeScan(gitDir)
+	if skipChanges {
+		config.Info("Large repository: uncommitted changes not checked (git config gwi.largeRepo)")
+	}
 
 	for _, dir := range worktrees {
 		name := filepath.Base(dir)
 		branchName := name
+		wt := worktreeStatus{Name: name, Path: dir, Changes: -1}
+		wt.Health, wt.Repair = registry.check(dir)
+		if wt.Health == healthOrphaned {
+			// Without a working .git link, git commands would run against the wrong repository
+			status.Worktrees = append(status.Worktrees, wt)
+			continue
+		}
+
+		// Worktrees named after an issue number or project key can have a PR
+		_, wt.hasIssue = issueid.FromName(name)
+
+		if !skipChanges {
+			wt.Changes = 0
+			if git.HasUncommittedChanges(dir) {
+				wt.Changes = git.GetUncommittedCount(dir)
+			}
+		}
+
+		wt.Ahead, wt.Behind, _ = git.GetAheadBehind(dir, branchName)
+
+		if wt.hasIssue {
+			var link linkedPR
+			if meta != nil && meta.Get(prKey(repoInfo, branchName), &link) {
+				if state, err := github.GetPRState(link.Number); err == nil {
+					wt.PR, wt.PRState = link.Number, state
+				}
+			}
+		}
+
+		wt.Server = tmuxSessionExists(name)
+		wt.labels = worktreeLabels(labels, dir)
+		if issueNumber, ok := github.ParseIssueFromBranch(name); ok {
+			if epic, ok := epics[issueNumber]; ok {
+				wt.epic = epicName(epic)
+				if epic.Repository.NameWithOwner == "" {
+					wt.Epic = epic.Number
+				}
+			}
+		}
+		wt.deps = depsIndicator(meta, dir)
+		status.Worktrees = append(status.Worktrees, wt)
+	}
+	return status
+}
+
+// print lists the worktrees, padding their names to width
+func (r repoStatus) print(width int) {
+	if r.Error != "" {
+		fmt.Printf("  %s %s\n", config.Red(config.Glyph("failure")), r.Error)
+		return
+	}
+	if len(r.Worktrees) == 0 {
+		fmt.Println("No worktrees found.")
+	}
 
-		// Extract issue number
-		var issueNumber int
-		matches := re.FindStringSubmatch(name)
-		if matches != nil {
-			issueNumber, _ = strconv.Atoi(matches[1])
+	for _, wt := range r.Worktrees {
+		if wt.Health == healthOrphaned {
+			fmt.Printf("  %s %-*s %s\n", config.Red(config.Glyph("failure")), width, wt.Name, config.Red("orphaned"))
+			fmt.Printf("    %s\n", config.Dim(wt.Repair))
+			continue
 		}
 
 		// Check git status
 		var statusIcon string
 		var changes string
-		if git.HasUncommittedChanges(dir) {
-			statusIcon = config.Yellow("●")
-			count := git.GetUncommittedCount(dir)
-			changes = fmt.Sprintf(" (%d changes)", count)
-		} else {
-			statusIcon = config.Green("●")
-			changes = ""
+		switch {
+		case wt.Changes < 0:
+			statusIcon = config.Glyph("unchecked")
+		case wt.Changes > 0:
+			statusIcon = config.Yellow(config.Glyph("dirty"))
+			changes = fmt.Sprintf(" (%d changes)", wt.Changes)
+		default:
+			statusIcon = config.Green(config.Glyph("clean"))
 		}
 
 		// Check if branch is pushed
 		var pushStatus string
-		ahead, behind, err := git.GetAheadBehind(dir, branchName)
-		if err == nil {
-			if ahead > 0 {
-				pushStatus = fmt.Sprintf(" ↑%d", ahead)
-			}
-			if behind > 0 {
-				pushStatus += fmt.Sprintf(" ↓%d", behind)
-			}
+		if wt.Ahead > 0 {
+			pushStatus = fmt.Sprintf(" %s%d", config.Glyph("up"), wt.Ahead)
+		}
+		if wt.Behind > 0 {
+			pushStatus += fmt.Sprintf(" %s%d", config.Glyph("down"), wt.Behind)
 		}
 
 		// Check PR status
 		var prStatus string
-		if issueNumber > 0 {
-			prNumber, err := github.GetPRForBranch(branchName)
-			if err == nil && prNumber > 0 {
-				state, err := github.GetPRState(prNumber)
-				if err == nil {
-					switch state {
-					case "OPEN":
-						prStatus = fmt.Sprintf(" %sPR #%d%s", config.Blue(""), prNumber, config.Blue(""))
-					case "MERGED":
-						prStatus = fmt.Sprintf(" %sPR #%d merged%s", config.Green(""), prNumber, config.Green(""))
-					case "CLOSED":
-						prStatus = fmt.Sprintf(" %sPR #%d closed%s", config.Red(""), prNumber, config.Red(""))
-					}
-				}
-			} else {
-				prStatus = fmt.Sprintf(" %sno PR%s", config.Yellow(""), config.Yellow(""))
-			}
+		switch {
+		case wt.PRState == "OPEN":
+			prStatus = fmt.Sprintf(" %sPR #%d%s", config.Blue(""), wt.PR, config.Blue(""))
+		case wt.PRState == "MERGED":
+			prStatus = fmt.Sprintf(" %sPR #%d merged%s", config.Green(""), wt.PR, config.Green(""))
+		case wt.PRState == "CLOSED":
+			prStatus = fmt.Sprintf(" %sPR #%d closed%s", config.Red(""), wt.PR, config.Red(""))
+		case wt.hasIssue && wt.PR == 0:
+			prStatus = fmt.Sprintf(" %sno PR%s", config.Yellow(""), config.Yellow(""))
 		}
 
 		// Check server status (tmux session)
 		var serverStatus string
-		if tmuxSessionExists(name) {
-			serverStatus = fmt.Sprintf(" %s▶ running%s", config.Green(""), config.Green(""))
+		if wt.Server {
+			serverStatus = " " + config.Green(config.Glyph("running")+" running")
+		}
+
+		var epic string
+		if wt.epic != "" {
+			epic = " " + config.Dim(wt.epic)
+		}
+
+		var health string
+		if wt.Health == healthLocked {
+			health = " " + config.Yellow("locked")
+		}
+
+		fmt.Printf("  %s %-*s%s%s%s%s%s%s%s%s\n", statusIcon, width, wt.Name, wt.labels, epic, changes, pushStatus, prStatus, serverStatus, wt.deps, health)
+		if wt.Repair != "" {
+			fmt.Printf("    %s\n", config.Dim(wt.Repair))
+		}
+	}
+
+	for _, entry := range r.Prunable {
+		fmt.Printf("  %s %s %s\n", config.Yellow(config.Glyph("warn")), entry.Path, config.Yellow("prunable: "+entry.Reason))
+		fmt.Printf("    %s\n", config.Dim(entry.Repair))
+	}
+}
+
+// runWorkspaceStatus shows the status of every repository in a workspace
+func runWorkspaceStatus() {
+	cfg := config.Load()
+	repos, ok := cfg.WorkspaceRepos(statusWorkspace)
+	if !ok {
+		config.Fail(errs.NotFound("Workspace %s not found. Define it under workspaces in config.yaml", statusWorkspace))
+	}
+
+	// Repositories are inspected from their own directory, like a gwi status run there
+	cwd, err := os.Getwd()
+	if err != nil {
+		config.Fail(err)
+	}
+	defer os.Chdir(cwd)
+
+	statuses := make([]repoStatus, 0, len(repos))
+	for _, path := range repos {
+		statuses = append(statuses, workspaceRepoStatus(cfg, path))
+	}
+
+	if statusJSON {
+		data, _ := json.MarshalIndent(statuses, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	// Names are padded across repositories so the sections line up as one table
+	width := 0
+	for _, status := range statuses {
+		for _, wt := range status.Worktrees {
+			width = max(width, len(wt.Name))
 		}
+	}
 
-		fmt.Printf("  %s %s%s%s%s%s\n", statusIcon, name, changes, pushStatus, prStatus, serverStatus)
+	fmt.Printf("%sgwi status%s for workspace %s%s%s\n", config.Green(""), config.Green(""), config.Blue(""), statusWorkspace, config.Blue(""))
+	for _, status := range statuses {
+		fmt.Println()
+		fmt.Printf("%s %s\n", config.Blue(status.Repo), status.Path)
+		status.print(width)
+	}
+}
+
+// workspaceRepoStatus collects the status of the repository at path
+func workspaceRepoStatus(cfg *config.Config, path string) repoStatus {
+	failed := func(err error) repoStatus {
+		return repoStatus{Repo: filepath.Base(path), Path: path, Worktrees: []worktreeStatus{}, Error: err.Error()}
+	}
+	if err := os.Chdir(path); err != nil {
+		return failed(err)
+	}
+	useRepoRemotes(cfg)
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return failed(err)
 	}
+	return collectRepoStatus(cfg, repoInfo)
 }
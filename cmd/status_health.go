@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// Health problems gwi status flags on a worktree
+const (
+	healthOrphaned = "orphaned" // A directory under the base without a working link to the repository
+	healthLocked   = "locked"   // Locked with git worktree lock; prune and remove skip it
+)
+
+// prunableWorktree is a worktree git still lists although its directory is gone
+type prunableWorktree struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Repair string `json:"repair"`
+}
+
+// worktreeRegistry holds what git worktree list reports, by canonical path
+type worktreeRegistry map[string]git.WorktreeInfo
+
+// loadWorktreeRegistry reads the worktrees of the current repository. It
+// returns nil when git can't list them, which leaves the checks out.
+func loadWorktreeRegistry() worktreeRegistry {
+	worktrees, err := git.ListAllWorktrees()
+	if err != nil {
+		return nil
+	}
+	registry := make(worktreeRegistry, len(worktrees))
+	for _, wt := range worktrees {
+		registry[canonicalPath(wt.Path)] = wt
+	}
+	return registry
+}
+
+// check returns the health problem of the worktree directory at path and
+// how to repair it, or two empty strings for a healthy one
+func (r worktreeRegistry) check(path string) (string, string) {
+	if r == nil {
+		return "", ""
+	}
+	wt, ok := r[canonicalPath(path)]
+	switch {
+	case !ok:
+		return healthOrphaned, orphanRepair(path)
+	case wt.Prunable != "":
+		return healthOrphaned, "broken .git link; restore it with: git worktree repair " + path
+	case wt.Locked:
+		repair := "git worktree unlock " + path
+		if wt.LockReason != "" {
+			repair = "locked: " + wt.LockReason + "; unlock with: " + repair
+		}
+		return healthLocked, repair
+	}
+	return "", ""
+}
+
+// prunable lists the worktrees whose directories are gone
+func (r worktreeRegistry) prunable() []prunableWorktree {
+	var entries []prunableWorktree
+	for _, wt := range r {
+		if wt.Prunable == "" {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err == nil {
+			// The directory is still there; check flags it as orphaned
+			continue
+		}
+		entry := prunableWorktree{Path: wt.Path, Reason: wt.Prunable, Repair: "gwi clean (or: git worktree prune)"}
+		if wt.Locked {
+			entry.Repair = "git worktree unlock " + wt.Path + " && git worktree prune"
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// orphanRepair suggests how to fix a directory git doesn't list as a worktree,
+// depending on where its .git file points
+func orphanRepair(path string) string {
+	data, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "no .git link: move out the files worth keeping and delete the directory"
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "not a worktree of this repository: move it out of the worktree base"
+	}
+	if _, err := os.Stat(strings.TrimSpace(gitDir)); err != nil {
+		return "its git metadata was pruned: save your changes, delete the directory and run gwi create again"
+	}
+	return "not registered with git; restore the link with: git worktree repair " + path
+}
+
+// canonicalPath resolves symlinks, so a path reported by git matches the one
+// found under the worktree base
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
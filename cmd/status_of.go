@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/spf13/cobra"
+)
+
+// Internal command for hooks to read an issue's status in the tracker
+var internalStatusOfCmd = &cobra.Command{
+	Use:    "_status-of <issue>",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	Run:    runInternalStatusOf,
+}
+
+func runInternalStatusOf(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	issueNumber := githubIssueNumber(parseIssueID(args[0]), "read the status")
+	if !tracksStatus(cfg) {
+		config.Fail(errs.New(errs.KindUsage, "Issue statuses aren't tracked: github.projects_enabled is off"))
+	}
+
+	status, err := currentStatus(cfg, issueNumber)
+	if err != nil {
+		config.Fail(err)
+	}
+	if status == "" {
+		config.Fail(errs.NotFound("Issue #%d has no status in %s", issueNumber, trackerName(cfg)))
+	}
+	fmt.Println(status)
+}
+
+// statusEnv describes the worktree's issue and the configured statuses for
+// hooks: GWI_ISSUE, GWI_ISSUE_STATUS and GWI_STATUS_TODO, _IN_PROGRESS,
+// _IN_REVIEW and _DONE. The statuses are only set when they are tracked; a
+// status that can't be read is left empty.
+func statusEnv(cfg *config.Config, worktreePath string) []string {
+	var env []string
+	id, ok := issueid.FromName(filepath.Base(worktreePath))
+	if ok {
+		env = append(env, "GWI_ISSUE="+string(id))
+	}
+	if !tracksStatus(cfg) {
+		return env
+	}
+
+	current := ""
+	if issueNumber, ok := id.Number(); ok {
+		status, err := currentStatus(cfg, issueNumber)
+		if err != nil && cfg.Verbose {
+			config.Warn("Could not read the status of #%d: %v", issueNumber, err)
+		}
+		current = status
+	}
+	return append(env,
+		"GWI_ISSUE_STATUS="+current,
+		"GWI_STATUS_TODO="+statusValue(cfg, statusTodo),
+		"GWI_STATUS_IN_PROGRESS="+statusValue(cfg, statusInProgress),
+		"GWI_STATUS_IN_REVIEW="+statusValue(cfg, statusInReview),
+		"GWI_STATUS_DONE="+statusValue(cfg, statusDone),
+	)
+}
+
+// runHook runs a hook with the issue's status in its environment
+func runHook(hookName, worktreePath string, cfg *config.Config, repoInfo *git.RepoInfo) (bool, error) {
+	if hooks.FindHook(hookName, worktreePath, cfg, repoInfo) == "" {
+		return false, nil
+	}
+	return hooks.RunHook(hookName, worktreePath, cfg, repoInfo, statusEnv(cfg, worktreePath)...)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/xdg"
+)
+
+// summaryTTL is how long a status summary is shown before it is refreshed in the background
+const summaryTTL = time.Minute
+
+// statusSummary is the cached one-line overview of a repository's worktrees
+type statusSummary struct {
+	Computed   time.Time `json:"computed"`
+	Refreshing time.Time `json:"refreshing"` // When a background refresh was started
+	Worktrees  int       `json:"worktrees"`
+	Dirty      int       `json:"dirty"`
+	PRs        int       `json:"prs"`
+	Red        int       `json:"red"` // PRs with failing checks
+}
+
+// line formats the summary compactly, e.g. "5 wt · 2 dirty · 3 PR · 1 red"
+func (s statusSummary) line() string {
+	parts := []string{fmt.Sprintf("%d wt", s.Worktrees)}
+	if s.Dirty > 0 {
+		parts = append(parts, fmt.Sprintf("%d dirty", s.Dirty))
+	}
+	if s.PRs > 0 {
+		parts = append(parts, fmt.Sprintf("%d PR", s.PRs))
+	}
+	if s.Red > 0 {
+		parts = append(parts, fmt.Sprintf("%d red", s.Red))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func summaryPath() string {
+	return filepath.Join(xdg.CacheDir(), "summary.json")
+}
+
+// loadSummaries returns the cached summaries, keyed by "org/repo"
+func loadSummaries() map[string]statusSummary {
+	summaries := make(map[string]statusSummary)
+	if data, err := os.ReadFile(summaryPath()); err == nil {
+		json.Unmarshal(data, &summaries)
+	}
+	return summaries
+}
+
+func saveSummary(key string, summary statusSummary) {
+	summaries := loadSummaries()
+	summaries[key] = summary
+	data, err := json.Marshal(summaries)
+	if err != nil {
+		return
+	}
+	path := summaryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0644) == nil {
+		os.Rename(tmp, path)
+	}
+}
+
+// printStatusSummary prints the cached summary of the current repository
+// without loading config or calling GitHub, so it can run from a tmux status
+// line or prompt. A stale summary is refreshed in the background for the next
+// call; without one, the worktree counts are computed right away.
+func printStatusSummary() {
+	repo := cachedRepo()
+	if repo == nil {
+		return
+	}
+	key := repo.Org + "/" + repo.Repo
+
+	summary, ok := loadSummaries()[key]
+	if !ok {
+		summary = localSummary(repo)
+	}
+	if time.Since(summary.Computed) > summaryTTL && time.Since(summary.Refreshing) > summaryTTL {
+		summary.Refreshing = time.Now()
+		saveSummary(key, summary)
+		refreshSummaryInBackground()
+	}
+	fmt.Println(summary.line())
+}
+
+// localSummary counts the worktrees and those with uncommitted changes, which
+// large repositories don't count
+func localSummary(repo *index.Repo) statusSummary {
+	worktrees := repo.Existing()
+	summary := statusSummary{Worktrees: len(worktrees)}
+	if skipChangeScan(repo.MainPath) {
+		return summary
+	}
+	for _, dir := range worktrees {
+		if git.HasUncommittedChanges(dir) {
+			summary.Dirty++
+		}
+	}
+	return summary
+}
+
+// refreshStatusSummary recomputes the summary of the current repository,
+// including the checks of the worktrees' open PRs, and caches it
+func refreshStatusSummary() {
+	repo := cachedRepo()
+	if repo == nil {
+		return
+	}
+
+	summary := localSummary(repo)
+	branches := make(map[string]bool)
+	for _, dir := range repo.Existing() {
+		branches[filepath.Base(dir)] = true
+	}
+	if prs, err := github.ListOpenPRsWithChecks(); err == nil {
+		for _, pr := range prs {
+			if !branches[pr.HeadRefName] {
+				continue
+			}
+			summary.PRs++
+			for _, check := range pr.StatusCheckRollup {
+				if !check.Pending() && !check.Passed() {
+					summary.Red++
+					break
+				}
+			}
+		}
+	}
+	summary.Computed = time.Now()
+	saveSummary(repo.Org+"/"+repo.Repo, summary)
+}
+
+// refreshSummaryInBackground starts gwi to refresh the summary, without waiting for it
+func refreshSummaryInBackground() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe, "status", "--refresh-summary")
+	if cmd.Start() == nil {
+		cmd.Process.Release()
+	}
+}
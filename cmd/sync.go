@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/metasync"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync metadata with your other machines",
+	Long: `Merge the gwi metadata store (active issues and other shared state) with the
+copy in the configured sync backend, a git repository or a secret gist, and
+share the result. Conflicts are resolved per key: the most recent change wins.
+
+Machine-specific data such as dependency fingerprints and usage statistics
+is never synced.
+
+Run inside a repository, gwi sync also fetches and warns when the local main
+branch is behind or has diverged from the remote, offering to fast-forward it.`,
+	Args: cobra.NoArgs,
+	Run:  runSync,
+}
+
+// activeKeyPrefix namespaces the issues with a worktree on any machine
+const activeKeyPrefix = "active/"
+
+// activeIssue records a worktree created for an issue, shared through gwi sync
+type activeIssue struct {
+	Branch  string    `json:"branch"`
+	Title   string    `json:"title"`
+	Host    string    `json:"host"`
+	Started time.Time `json:"started"`
+}
+
+func activeKey(repoInfo *git.RepoInfo, issueNumber int) string {
+	return fmt.Sprintf("%s%s/%s/%d", activeKeyPrefix, repoInfo.Org, repoInfo.Repo, issueNumber)
+}
+
+// recordActiveIssue marks an issue as being worked on from this machine
+func recordActiveIssue(repoInfo *git.RepoInfo, issueNumber int, branch, title string) {
+	host, _ := os.Hostname()
+	store.Update(func(s *store.Store) error {
+		return s.Set(activeKey(repoInfo, issueNumber), activeIssue{
+			Branch:  branch,
+			Title:   title,
+			Host:    host,
+			Started: time.Now(),
+		})
+	})
+}
+
+// forgetActiveIssue clears an issue whose worktree was removed
+func forgetActiveIssue(repoInfo *git.RepoInfo, issueNumber int) {
+	store.Update(func(s *store.Store) error {
+		s.Delete(activeKey(repoInfo, issueNumber))
+		return nil
+	})
+}
+
+func runSync(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+
+	backend, err := metasync.New(cfg)
+	if err != nil {
+		config.Fail(err)
+	}
+
+	config.Info("Syncing metadata via %s...", cfg.Sync.Backend)
+	result, err := metasync.Sync(backend)
+	if err != nil {
+		config.Fail(fmt.Errorf("sync failed: %w", err))
+	}
+
+	for _, key := range result.Pulled {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Green(config.Glyph("down")), key)
+	}
+	for _, key := range result.Pushed {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", config.Blue(config.Glyph("up")), key)
+	}
+	config.Success("Synced: %d pulled, %d pushed", len(result.Pulled), len(result.Pushed))
+
+	printActiveIssues()
+
+	// Run inside a repository, also bring its main branch up to date
+	if _, err := git.GetRepoInfo(); err == nil {
+		if err := git.Fetch(); err != nil {
+			config.Warn("Failed to fetch from %s: %v", git.FetchRemote(), err)
+			return
+		}
+		checkMainBranch(cfg, true)
+	}
+}
+
+// trackedIssue is an active issue together with the repository and number from its key
+type trackedIssue struct {
+	activeIssue
+	Org    string
+	Repo   string
+	Number int
+}
+
+// listActiveIssues returns the issues worked on across all machines
+func listActiveIssues() []trackedIssue {
+	s, err := store.Load()
+	if err != nil {
+		return nil
+	}
+
+	var issues []trackedIssue
+	for _, key := range s.Keys(activeKeyPrefix) {
+		var issue activeIssue
+		if !s.Get(key, &issue) {
+			continue
+		}
+		// key is active/<org>/<repo>/<number>
+		parts := strings.Split(strings.TrimPrefix(key, activeKeyPrefix), "/")
+		if len(parts) != 3 {
+			continue
+		}
+		number, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		issues = append(issues, trackedIssue{activeIssue: issue, Org: parts[0], Repo: parts[1], Number: number})
+	}
+	return issues
+}
+
+// where describes the machine an issue is worked on
+func (t trackedIssue) where() string {
+	if host, _ := os.Hostname(); t.Host == host {
+		return "this machine"
+	}
+	return t.Host
+}
+
+// printActiveIssues lists the issues worked on across all machines
+func printActiveIssues() {
+	issues := listActiveIssues()
+	if len(issues) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Active issues:")
+	for _, issue := range issues {
+		fmt.Printf("  %s/%s#%d %s %s\n", issue.Org, issue.Repo, issue.Number, issue.Title, config.Yellow("("+issue.where()+")"))
+	}
+}
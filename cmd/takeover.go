@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	takeoverYes     bool
+	takeoverMessage string
+)
+
+var takeoverCmd = &cobra.Command{
+	Use:   "takeover <issue-number>",
+	Short: "Take over an issue someone else is working on",
+	Long: `Take over an issue assigned to someone else: assign it to you instead, post a
+handover comment, and create a worktree for it. When the previous assignee pushed
+a branch for the issue (<issue-number>-*), the worktree checks it out so you
+continue where they left off. The issue is moved to In Progress like gwi create
+does.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTakeover,
+}
+
+func init() {
+	takeoverCmd.Flags().BoolVarP(&takeoverYes, "yes", "y", false, "Skip confirmation prompt")
+	takeoverCmd.Flags().StringVarP(&takeoverMessage, "message", "m", "", "Add a note to the handover comment")
+}
+
+func runTakeover(cmd *cobra.Command, args []string) error {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		return err
+	}
+	issueNumber, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errs.New(errs.KindUsage, "Invalid issue number: %s", args[0])
+	}
+
+	if err := checkTrackerAuth(cfg); err != nil {
+		return err
+	}
+	issue, err := getIssue(cfg, issueNumber)
+	if err != nil {
+		return err
+	}
+	if issue.State == "CLOSED" {
+		return errs.Conflict("Issue #%d is closed", issueNumber)
+	}
+
+	me, err := trackerUser(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to get your user: %w", err)
+	}
+	var others []string
+	for _, assignee := range issue.Assignees {
+		if !strings.EqualFold(assignee.Login, me) {
+			others = append(others, assignee.Login)
+		}
+	}
+	if len(others) == 0 {
+		return errs.Conflict("Issue #%d isn't assigned to anyone else. Start it with: gwi create %d", issueNumber, issueNumber)
+	}
+
+	// Pick up the branch the previous assignee pushed, if any
+	config.Info("Fetching from %s...", git.FetchRemote())
+	if err := git.Fetch(); err != nil {
+		config.Warn("Failed to fetch: %v", err)
+	}
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	existing := git.FindWorktreeByID(base, parseIssueID(args[0]))
+	branch := ""
+	if branches := git.RemoteBranchesWithPrefix(fmt.Sprintf("%d-", issueNumber)); len(branches) > 0 {
+		branch = branches[0]
+	}
+
+	fmt.Fprintf(os.Stderr, "Take over #%d %s from %s?\n", issueNumber, issue.Title, config.Yellow(strings.Join(others, ", ")))
+	switch {
+	case existing != "":
+		fmt.Fprintf(os.Stderr, "  Worktree %s already exists and is kept\n", existing)
+	case branch != "":
+		fmt.Fprintf(os.Stderr, "  Their branch %s will be checked out in a new worktree\n", branch)
+	default:
+		fmt.Fprintf(os.Stderr, "  No branch for #%d was pushed; a new one is created\n", issueNumber)
+	}
+	if !takeoverYes && !confirmPrompt("Confirm") {
+		return errs.Aborted("Aborted")
+	}
+
+	if err := reassignIssue(cfg, issueNumber, me, others); err != nil {
+		return err
+	}
+	config.Success("Assigned #%d to %s", issueNumber, me)
+
+	comment := handoverComment(cfg, others, branch)
+	if err := commentOnIssue(cfg, issueNumber, comment); err != nil {
+		config.Warn("Failed to post handover comment: %v", err)
+	}
+
+	if existing != "" {
+		if tracksStatus(cfg) {
+			if updates, err := updateIssueStatus(cfg, issueNumber, statusInProgress); err != nil {
+				config.Warn("Failed to update project status: %v", err)
+			} else {
+				printProjectUpdates(updates)
+			}
+		}
+		printCdTo(existing)
+		return nil
+	}
+
+	// The worktree step checks out an existing remote branch of that name
+	createBranch = branch
+	createWorktree(cfg, repoInfo, issueNumber, false)
+	return nil
+}
+
+// handoverComment tells the previous assignees who took over, and from which branch
+func handoverComment(cfg *config.Config, others []string, branch string) string {
+	mentions := make([]string, len(others))
+	for i, login := range others {
+		mentions[i] = login
+		if _, ok := azureBoards(cfg); !ok {
+			mentions[i] = "@" + login
+		}
+	}
+	comment := "Taking over this issue from " + strings.Join(mentions, ", ")
+	if branch != "" {
+		comment += ", continuing on branch `" + branch + "`"
+	}
+	comment += "."
+	if takeoverMessage != "" {
+		comment += "\n\n" + takeoverMessage
+	}
+	return comment
+}
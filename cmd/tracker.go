@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/enterprisemodules/gwi/internal/azure"
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+)
+
+// Issue numbers refer to GitHub issues, or with tracker "azure" to Azure
+// Boards work items. Pull requests are always on GitHub.
+
+// closingRef finds the issues a commit message closes with a GitHub closing
+// keyword, e.g. "Fixes #12"
+var closingRef = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)`)
+
+// issueStatus is a step in an issue's workflow, named by the github.*_value
+// or azure.*_value settings
+type issueStatus int
+
+const (
+	statusTodo issueStatus = iota
+	statusInProgress
+	statusInReview
+	statusDone
+	statusPaused
+)
+
+// azureBoards returns the Azure Boards client when issues are tracked there
+func azureBoards(cfg *config.Config) (*azure.Boards, bool) {
+	if cfg.Tracker != "azure" {
+		return nil, false
+	}
+	return &azure.Boards{Organization: cfg.Azure.Organization, Project: cfg.Azure.Project}, true
+}
+
+// trackerName names where issue statuses are kept, for messages
+func trackerName(cfg *config.Config) string {
+	if _, ok := azureBoards(cfg); ok {
+		return "Azure Boards"
+	}
+	return "GitHub Projects"
+}
+
+// tracksStatus reports whether issue statuses are updated: always for Azure
+// Boards work items, with github.projects_enabled for GitHub issues
+func tracksStatus(cfg *config.Config) bool {
+	if _, ok := azureBoards(cfg); ok {
+		return true
+	}
+	return cfg.GitHub.ProjectsEnabled
+}
+
+// statusSetting returns the setting holding a status's value in the tracker
+func statusSetting(cfg *config.Config, status issueStatus) *string {
+	settings := []*string{&cfg.GitHub.TodoValue, &cfg.GitHub.InProgressValue, &cfg.GitHub.InReviewValue, &cfg.GitHub.DoneValue, &cfg.GitHub.PausedValue}
+	if _, ok := azureBoards(cfg); ok {
+		settings = []*string{&cfg.Azure.TodoValue, &cfg.Azure.InProgressValue, &cfg.Azure.InReviewValue, &cfg.Azure.DoneValue, &cfg.Azure.PausedValue}
+	}
+	return settings[status]
+}
+
+// statusValue returns the configured value of a status in the tracker
+func statusValue(cfg *config.Config, status issueStatus) string {
+	return *statusSetting(cfg, status)
+}
+
+// overrideStatus uses value, given with --status, for a status in this run
+// instead of the configured one. Without status tracking it only warns.
+func overrideStatus(cfg *config.Config, status issueStatus, value string) {
+	if value == "" {
+		return
+	}
+	if !tracksStatus(cfg) {
+		config.Warn("Ignoring --status %q: github.projects_enabled is off", value)
+		return
+	}
+	*statusSetting(cfg, status) = value
+}
+
+// currentStatus returns an issue's status in GitHub Projects, or the state of
+// a work item; "" when the issue isn't in a project or has no status there
+func currentStatus(cfg *config.Config, issueNumber int) (string, error) {
+	if boards, ok := azureBoards(cfg); ok {
+		item, err := boards.GetWorkItem(issueNumber)
+		if err != nil {
+			return "", err
+		}
+		return item.State, nil
+	}
+	return github.GetIssueProjectStatus(issueNumber, cfg.GitHub.StatusFieldName, cfg.GitHub.Projects)
+}
+
+// checkTrackerAuth verifies gh and, for Azure Boards, az are logged in
+func checkTrackerAuth(cfg *config.Config) error {
+	if err := github.CheckAuth(); err != nil {
+		return err
+	}
+	if _, ok := azureBoards(cfg); ok {
+		return azure.CheckAuth()
+	}
+	return nil
+}
+
+// getIssue fetches an issue by number. Work items are returned as issues:
+// their tags become labels, and they are CLOSED once in a closed state.
+func getIssue(cfg *config.Config, issueNumber int) (*github.Issue, error) {
+	boards, ok := azureBoards(cfg)
+	if !ok {
+		return github.GetIssue(issueNumber)
+	}
+	item, err := boards.GetWorkItem(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	issue := workItemIssue(cfg, *item)
+	return &issue, nil
+}
+
+// listOpenIssues lists open issues with their status, for selectors. GitHub
+// lists them by order, one of issueSorts, so limit takes the first in it.
+func listOpenIssues(cfg *config.Config, limit int, order string) ([]github.Issue, error) {
+	boards, ok := azureBoards(cfg)
+	if !ok {
+		return github.ListOpenIssuesWithStatus(limit, cfg.GitHub.StatusFieldName, github.IssueSearchSorts[order])
+	}
+	items, err := boards.ListOpenWorkItems(limit, closedStates(cfg))
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]github.Issue, 0, len(items))
+	for _, item := range items {
+		issues = append(issues, workItemIssue(cfg, item))
+	}
+	return issues, nil
+}
+
+// closedStates are the work item states that count as closed
+func closedStates(cfg *config.Config) []string {
+	states := []string{"Closed", "Done", "Removed"}
+	if !slices.Contains(states, cfg.Azure.DoneValue) {
+		states = append(states, cfg.Azure.DoneValue)
+	}
+	return states
+}
+
+func workItemIssue(cfg *config.Config, item azure.WorkItem) github.Issue {
+	issue := github.Issue{Number: item.ID, Title: item.Title, State: "OPEN", ProjectStatus: item.State}
+	if slices.Contains(closedStates(cfg), item.State) {
+		issue.State = "CLOSED"
+	}
+	for _, tag := range item.Tags {
+		issue.Labels = append(issue.Labels, github.Label{Name: tag})
+	}
+	if item.AssignedTo != "" {
+		issue.Assignees = []github.User{{Login: item.AssignedTo}}
+	}
+	return issue
+}
+
+// trackerUser returns who you are in the tracker: your GitHub login, or the
+// account the Azure CLI is logged in as
+func trackerUser(cfg *config.Config) (string, error) {
+	if _, ok := azureBoards(cfg); ok {
+		return azure.CurrentUser()
+	}
+	return github.CurrentUser()
+}
+
+// reassignIssue assigns an issue to you and removes the other assignees;
+// work items have a single assignee
+func reassignIssue(cfg *config.Config, issueNumber int, me string, others []string) error {
+	if boards, ok := azureBoards(cfg); ok {
+		return boards.SetField(issueNumber, "System.AssignedTo", me)
+	}
+	return github.ReassignIssue(issueNumber, me, others)
+}
+
+// createIssue opens a GitHub issue and returns its number. Work items can't
+// be created from gwi.
+func createIssue(cfg *config.Config, title, body string) (int, error) {
+	if _, ok := azureBoards(cfg); ok {
+		return 0, errs.New(errs.KindUsage, "Can't create Azure Boards work items; create one and pass its number")
+	}
+	return github.CreateIssue(title, body)
+}
+
+// commentOnIssue comments on an issue, or the discussion of a work item
+func commentOnIssue(cfg *config.Config, issueNumber int, comment string) error {
+	if boards, ok := azureBoards(cfg); ok {
+		return boards.AddComment(issueNumber, comment)
+	}
+	return github.CommentOnIssue(issueNumber, comment)
+}
+
+// updateIssueStatus moves an issue to a status in GitHub Projects, or sets
+// azure.state_field of a work item. It returns the outcome per project, which
+// work items don't have.
+func updateIssueStatus(cfg *config.Config, issueNumber int, status issueStatus) ([]github.ProjectUpdate, error) {
+	return setIssueStatus(cfg, issueNumber, statusValue(cfg, status))
+}
+
+// setIssueStatus moves an issue to a status by its value, such as one
+// recorded earlier with currentStatus
+func setIssueStatus(cfg *config.Config, issueNumber int, value string) ([]github.ProjectUpdate, error) {
+	if boards, ok := azureBoards(cfg); ok {
+		return nil, boards.SetField(issueNumber, cfg.Azure.StateField, value)
+	}
+	return github.UpdateIssueStatus(issueNumber, value, cfg)
+}
+
+// printProjectUpdates lists the projects a status update went to, when it
+// went to several or failed in some
+func printProjectUpdates(updates []github.ProjectUpdate) {
+	failed := slices.ContainsFunc(updates, func(u github.ProjectUpdate) bool { return u.Err != nil })
+	if len(updates) < 2 && !failed {
+		return
+	}
+	for _, update := range updates {
+		if update.Err != nil {
+			fmt.Fprintf(os.Stderr, "  %s %s %s\n", config.Red(config.Glyph("failure")), update.Project, config.Dim(update.Err.Error()))
+		} else {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", config.Green(config.Glyph("success")), update.Project)
+		}
+	}
+}
+
+// closeIssue closes a GitHub issue with a comment, if any. Work items only get
+// the comment; their state moves to done with the other status updates.
+func closeIssue(cfg *config.Config, issueNumber int, comment string) error {
+	if boards, ok := azureBoards(cfg); ok {
+		if comment == "" {
+			return nil
+		}
+		return boards.AddComment(issueNumber, comment)
+	}
+	return github.CloseIssue(issueNumber, comment)
+}
+
+// closingReference links a pull request to its issue so merging it closes
+// the issue; Azure Boards recognizes AB# references in GitHub pull requests
+func closingReference(cfg *config.Config, issueNumber int) string {
+	if _, ok := azureBoards(cfg); ok {
+		return fmt.Sprintf("Fixes AB#%d", issueNumber)
+	}
+	return fmt.Sprintf("Closes #%d", issueNumber)
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/index"
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/enterprisemodules/gwi/internal/xdg"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore what the last rm, merge or clean removed",
+	Long: `Restore the worktree and branch removed by the last destructive gwi command in
+this repository: gwi rm, the cleanup after gwi merge, or gwi clean (including
+--policy and removing stale worktrees at startup). The local branch is
+recreated at the commit it pointed to, pushed again if it was deleted from the
+remote, the worktree is recreated and uncommitted changes that were discarded
+with it are applied.
+
+Only the local state is restored: merged commits stay on main, and closed
+issues and project statuses are left as they are. With --list, show the
+journal of recent operations instead.`,
+	Args: cobra.NoArgs,
+	Run:  runUndo,
+}
+
+var (
+	undoYes  bool
+	undoList bool
+)
+
+func init() {
+	undoCmd.Flags().BoolVarP(&undoYes, "yes", "y", false, "Skip confirmation prompt")
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List the operations that can be undone, latest first")
+}
+
+const (
+	undoKeyPrefix = "undo/"
+	// undoJournalSize is how many operations are kept per repository
+	undoJournalSize = 10
+)
+
+// undoEntry records a removal in the operation journal, with what is needed
+// to restore it
+type undoEntry struct {
+	Op       string    `json:"op"` // Command that removed it: rm, merge or clean
+	Branch   string    `json:"branch"`
+	Head     string    `json:"head"`               // Commit the branch pointed to
+	Worktree string    `json:"worktree,omitempty"` // Removed worktree, if any
+	Patch    string    `json:"patch,omitempty"`    // Saved uncommitted changes of the worktree
+	Remote   bool      `json:"remote,omitempty"`   // The branch was deleted from the push remote
+	Time     time.Time `json:"time"`
+}
+
+func undoKey(repoInfo *git.RepoInfo) string {
+	return fmt.Sprintf("%s%s/%s", undoKeyPrefix, repoInfo.Org, repoInfo.Repo)
+}
+
+// undoDir holds the uncommitted changes saved for undo
+func undoDir() string {
+	return filepath.Join(xdg.StateDir(), "undo")
+}
+
+// journalRemoval records a branch and worktree about to be removed, so gwi
+// undo can restore them. Uncommitted changes of the worktree are saved as a
+// patch. Failing to record only warns; it never stops the removal.
+func journalRemoval(repoInfo *git.RepoInfo, entry undoEntry) {
+	entry.Time = time.Now()
+	head, err := git.ResolveCommit(entry.Worktree, entry.Branch)
+	if err != nil {
+		if entry.Worktree == "" {
+			return
+		}
+		if head, err = git.GetHeadCommit(entry.Worktree); err != nil {
+			config.Warn("Not recording %s for gwi undo: %v", entry.Branch, err)
+			return
+		}
+	}
+	entry.Head = head
+
+	if entry.Worktree != "" && git.HasUncommittedChanges(entry.Worktree) {
+		patch, err := git.DiffWorkingTree(entry.Worktree)
+		if err == nil {
+			file := filepath.Join(undoDir(), fmt.Sprintf("%d-%s.patch", entry.Time.UnixNano(), entry.Branch))
+			if err = os.MkdirAll(undoDir(), 0755); err == nil {
+				err = os.WriteFile(file, patch, 0600)
+			}
+			entry.Patch = file
+		}
+		if err != nil {
+			config.Warn("Uncommitted changes of %s can't be undone: %v", entry.Branch, err)
+			entry.Patch = ""
+		}
+	}
+
+	err = store.Update(func(s *store.Store) error {
+		var journal []undoEntry
+		s.Get(undoKey(repoInfo), &journal)
+		journal = append(journal, entry)
+		if len(journal) > undoJournalSize {
+			for _, old := range journal[:len(journal)-undoJournalSize] {
+				if old.Patch != "" {
+					os.Remove(old.Patch)
+				}
+			}
+			journal = journal[len(journal)-undoJournalSize:]
+		}
+		return s.Set(undoKey(repoInfo), journal)
+	})
+	if err != nil {
+		config.Warn("Not recording %s for gwi undo: %v", entry.Branch, err)
+	}
+}
+
+func runUndo(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		config.Fail(err)
+	}
+	var journal []undoEntry
+	s.Get(undoKey(repoInfo), &journal)
+	if len(journal) == 0 {
+		config.Fail(errs.NotFound("Nothing to undo in %s/%s", repoInfo.Org, repoInfo.Repo))
+	}
+
+	if undoList {
+		for i := len(journal) - 1; i >= 0; i-- {
+			fmt.Println(describeUndo(journal[i]))
+		}
+		return
+	}
+
+	entry := journal[len(journal)-1]
+	fmt.Fprintf(os.Stderr, "Last operation: %s\n", describeUndo(entry))
+	if !undoYes && !confirmPrompt("Restore it?") {
+		config.Fail(errs.Aborted("Aborted"))
+	}
+
+	mainWorktree, err := git.GetMainWorktreePath()
+	if err != nil {
+		config.Fail(fmt.Errorf("failed to get main worktree: %w", err))
+	}
+	if err := restoreUndoEntry(cfg, repoInfo, mainWorktree, entry); err != nil {
+		config.Fail(err)
+	}
+
+	store.Update(func(s *store.Store) error {
+		var journal []undoEntry
+		s.Get(undoKey(repoInfo), &journal)
+		if n := len(journal); n > 0 && journal[n-1].Time.Equal(entry.Time) {
+			journal = journal[:n-1]
+		}
+		if len(journal) == 0 {
+			s.Delete(undoKey(repoInfo))
+			return nil
+		}
+		return s.Set(undoKey(repoInfo), journal)
+	})
+	if entry.Patch != "" {
+		os.Remove(entry.Patch)
+	}
+
+	config.Success("Undid %s of %s", entry.Op, entry.Branch)
+	if entry.Worktree != "" {
+		printCdTo(entry.Worktree)
+	}
+}
+
+// restoreUndoEntry recreates the branch, remote branch and worktree of an
+// entry, skipping what exists again
+func restoreUndoEntry(cfg *config.Config, repoInfo *git.RepoInfo, mainWorktree string, entry undoEntry) error {
+	if git.BranchExists(entry.Branch) {
+		config.Info("Branch %s exists, leaving it as is", entry.Branch)
+	} else {
+		if _, err := git.ResolveCommit(mainWorktree, entry.Head); err != nil {
+			return errs.NotFound("Commit %s of %s is gone (garbage collected?)", entry.Head, entry.Branch)
+		}
+		if err := git.CreateBranch(mainWorktree, entry.Branch, entry.Head); err != nil {
+			return fmt.Errorf("failed to recreate branch %s: %w", entry.Branch, err)
+		}
+		config.Success("Recreated branch %s at %s", entry.Branch, entry.Head[:min(7, len(entry.Head))])
+	}
+
+	if entry.Remote && !git.RemoteBranchExists(entry.Branch) {
+		config.Info("Pushing %s to %s...", entry.Branch, git.PushRemote())
+		if err := git.Push(mainWorktree, entry.Branch); err != nil {
+			config.Warn("Failed to push %s: %v", entry.Branch, err)
+		}
+	}
+
+	if entry.Worktree == "" {
+		return nil
+	}
+	if _, err := os.Stat(entry.Worktree); err == nil {
+		config.Info("Worktree %s exists, leaving it as is", entry.Worktree)
+		return nil
+	}
+	if other := git.BranchWorktree(entry.Branch); other != "" {
+		return errs.Conflict("Branch %s is checked out at %s", entry.Branch, other)
+	}
+	if err := git.CreateWorktreeFromBranch(entry.Worktree, entry.Branch); err != nil {
+		return fmt.Errorf("failed to recreate worktree: %w", err)
+	}
+	index.Refresh(cfg, repoInfo)
+	if err := applyWorktreeGitConfig(cfg, repoInfo, entry.Worktree, false); err != nil {
+		config.Warn("%v", err)
+	}
+
+	if entry.Patch != "" {
+		if err := git.ApplyPatch(entry.Worktree, entry.Patch); err != nil {
+			return fmt.Errorf("%w; apply %s by hand", err, entry.Patch)
+		}
+		config.Success("Restored uncommitted changes")
+	}
+	return nil
+}
+
+// describeUndo summarizes a journal entry on one line
+func describeUndo(entry undoEntry) string {
+	var removed []string
+	if entry.Worktree != "" {
+		removed = append(removed, "worktree")
+	}
+	removed = append(removed, "branch")
+	if entry.Remote {
+		removed = append(removed, "remote branch")
+	}
+	if entry.Patch != "" {
+		removed = append(removed, "uncommitted changes")
+	}
+	return fmt.Sprintf("%s  %s %s (%s)", config.Dim(config.FormatStamp(entry.Time)),
+		config.Cyan("gwi "+entry.Op), entry.Branch, strings.Join(removed, ", "))
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/hooks"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [issue-number]",
+	Short: "Run the repository's tests and linters in a worktree",
+	Long: `Run the verify pipeline in the issue's worktree: the commands of verify.steps in
+order, then the .gwi/verify hook, if there is one. Their output is streamed as
+they run. The pipeline stops at the first failing step, and gwi verify exits
+with code 7.
+
+gwi pr runs the pipeline first with --verify, or always with verify.on_pr. A
+failure stops the PR from being created; otherwise the steps are listed in a
+"Verification" section of the PR body.`,
+	Example: `  gwi verify
+  gwi verify 42`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runVerify,
+}
+
+// verifyHook is the hook run after the verify.steps
+const verifyHook = "verify"
+
+// verifyStep is a command of the verify pipeline, or the verify hook
+type verifyStep struct {
+	name string
+	run  string // Shell command, or the path of the hook
+	hook bool
+}
+
+// verifyResult is the outcome of a step of the verify pipeline
+type verifyResult struct {
+	step     verifyStep
+	duration time.Duration
+	err      error
+	skipped  bool // Not run, as an earlier step failed
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+	repoInfo, err := git.GetRepoInfo()
+	if err != nil {
+		config.Fail(err)
+	}
+	_, worktreePath := resolveIssueWorktree(cfg, repoInfo, args)
+
+	results, err := verifyWorktree(cfg, repoInfo, worktreePath)
+	if len(results) > 0 {
+		printVerifyResults(results)
+	}
+	if err != nil {
+		config.Fail(err)
+	}
+	config.Success("All %d verify step(s) passed", len(results))
+}
+
+// verifySteps returns the pipeline of a worktree: the repository's
+// verify.steps, then its verify hook
+func verifySteps(cfg *config.Config, repoInfo *git.RepoInfo, worktreePath string) []verifyStep {
+	var steps []verifyStep
+	for _, step := range cfg.VerifySteps(repoInfo.Org, repoInfo.Repo) {
+		steps = append(steps, verifyStep{name: step.Name, run: step.Run})
+	}
+	if hook := hooks.FindHook(verifyHook, worktreePath, cfg, repoInfo); hook != "" {
+		steps = append(steps, verifyStep{name: verifyHook + " hook", run: hook, hook: true})
+	}
+	return steps
+}
+
+// verifyWorktree runs the verify pipeline in worktreePath, stopping at the
+// first failing step. The error tells which step failed.
+func verifyWorktree(cfg *config.Config, repoInfo *git.RepoInfo, worktreePath string) ([]verifyResult, error) {
+	steps := verifySteps(cfg, repoInfo, worktreePath)
+	if len(steps) == 0 {
+		return nil, errs.New(errs.KindUsage, "Nothing to verify: configure verify.steps or add a .gwi/verify hook")
+	}
+	env, err := hooks.Env(verifyHook, cfg, repoInfo)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := issueid.FromName(filepath.Base(worktreePath)); ok {
+		env = append(env, "GWI_ISSUE="+string(id))
+	}
+
+	var results []verifyResult
+	var failed error
+	for i, step := range steps {
+		if failed != nil {
+			results = append(results, verifyResult{step: step, skipped: true})
+			continue
+		}
+		config.Info("[%d/%d] %s: %s", i+1, len(steps), step.name, step.run)
+		start := time.Now()
+		err := runVerifyStep(step, worktreePath, env)
+		results = append(results, verifyResult{step: step, duration: time.Since(start), err: err})
+		if err != nil {
+			failed = errs.NotReady("verify_failed", "Verify step %s failed: %v", step.name, err)
+		}
+	}
+	return results, failed
+}
+
+// runVerifyStep runs a step in the worktree with its output on stderr, as
+// stdout is kept for gwi's own output
+func runVerifyStep(step verifyStep, worktreePath string, env []string) error {
+	cmd := exec.Command("sh", "-c", step.run)
+	if step.hook {
+		cmd = exec.Command(step.run)
+	}
+	cmd.Dir = worktreePath
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+
+	// Credentials are always masked, as in hook output
+	out := config.NewRedactWriter(os.Stderr)
+	defer out.Flush()
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// printVerifyResults summarizes the pipeline, one line per step
+func printVerifyResults(results []verifyResult) {
+	fmt.Fprintln(os.Stderr)
+	for _, result := range results {
+		switch {
+		case result.skipped:
+			fmt.Fprintf(os.Stderr, "  %s\n", config.Dim("- "+result.step.name+" (skipped)"))
+		case result.err != nil:
+			fmt.Fprintf(os.Stderr, "  %s %s %s\n", config.Red(config.Glyph("failure")), result.step.name, config.Dim(formatMs(result.duration.Milliseconds())))
+		default:
+			fmt.Fprintf(os.Stderr, "  %s %s %s\n", config.Green(config.Glyph("success")), result.step.name, config.Dim(formatMs(result.duration.Milliseconds())))
+		}
+	}
+}
+
+// verifySection lists the passed steps for the PR body
+func verifySection(results []verifyResult) prBodySection {
+	section := prBodySection{heading: "Verification"}
+	for _, result := range results {
+		item := fmt.Sprintf("`%s` passed in %s", result.step.name, formatMs(result.duration.Milliseconds()))
+		if !result.step.hook {
+			// The hook's path is local to this machine
+			item = fmt.Sprintf("`%s` (`%s`) passed in %s", result.step.name, result.step.run, formatMs(result.duration.Milliseconds()))
+		}
+		section.items = append(section.items, item)
+	}
+	return section
+}
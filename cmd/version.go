@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the gwi version, commit and build date, and check whether a newer
+release is available. Include this output in bug reports.`,
+	Args: cobra.NoArgs,
+	Run:  runVersion,
+}
+
+var (
+	versionJSON    bool
+	versionNoCheck bool
+)
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build information as JSON")
+	versionCmd.Flags().BoolVar(&versionNoCheck, "no-check", false, "Don't check for a newer release")
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	info := version.Get()
+
+	var latest string
+	var checkErr error
+	if !versionNoCheck {
+		latest, checkErr = version.Latest()
+	}
+	updateAvailable := version.Newer(latest, info.Version)
+
+	if versionJSON {
+		out := struct {
+			version.Info
+			Latest          string `json:"latest,omitempty"`
+			UpdateAvailable bool   `json:"update_available"`
+		}{info, latest, updateAvailable}
+		data, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println(info.String())
+	switch {
+	case versionNoCheck:
+	case checkErr != nil:
+		config.Warn("Could not check for a newer release: %v", checkErr)
+	case updateAvailable:
+		config.Warn("gwi %s is available: https://github.com/%s/releases/latest", latest, version.Repo)
+	}
+}
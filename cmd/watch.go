@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [issue-number]",
+	Short: "Follow an issue's activity in the terminal",
+	Long: `Poll an issue for new comments, label changes, and linked PR events and print them
+as a live feed. Without an issue number, the issue of the current worktree is used.
+Press Ctrl-C to stop.
+
+Changes to the config file are picked up while watching; the settings that
+changed are logged.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runWatch,
+}
+
+var (
+	watchInterval time.Duration
+	watchHistory  int
+)
+
+func init() {
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "i", 30*time.Second, "Polling interval")
+	watchCmd.Flags().IntVarP(&watchHistory, "history", "n", 10, "Number of past events to show on start")
+}
+
+// watchIgnored are timeline events that only add noise to the feed
+var watchIgnored = map[string]bool{
+	"subscribed":   true,
+	"unsubscribed": true,
+	"mentioned":    true,
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	cfg := config.Load()
+
+	var issueNumber int
+	if len(args) > 0 {
+		num, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil {
+			config.Fail(errs.New(errs.KindUsage, "Invalid issue number: %s", args[0]))
+		}
+		issueNumber = num
+	} else {
+		num, ok := detectCurrentIssue(cfg)
+		if !ok {
+			config.Fail(errs.New(errs.KindUsage, "Not in an issue worktree. Usage: gwi watch <issue-number>"))
+		}
+		issueNumber = num
+	}
+	if watchInterval < 5*time.Second {
+		config.Fail(errs.New(errs.KindUsage, "Interval must be at least 5s"))
+	}
+
+	issue, err := github.GetIssue(issueNumber)
+	if err != nil {
+		config.Fail(err)
+	}
+	config.Info("Watching #%d: %s (every %s, Ctrl-C to stop)", issue.Number, issue.Title, watchInterval)
+
+	seen := make(map[string]bool)
+	prStates := make(map[int]string)
+	first := true
+	configWatcher := config.NewWatcher(cfg)
+	defer configWatcher.Close()
+
+	for {
+		// Reloading applies display settings to the feed's output
+		cfg, _ = configWatcher.Check()
+		events, err := github.GetIssueTimeline(issueNumber)
+		if err != nil {
+			config.Warn("%v", err)
+		} else {
+			var fresh []github.TimelineEvent
+			for _, event := range events {
+				if watchIgnored[event.Event] || seen[event.Key()] {
+					continue
+				}
+				seen[event.Key()] = true
+				fresh = append(fresh, event)
+			}
+			if first && len(fresh) > watchHistory {
+				fresh = fresh[len(fresh)-watchHistory:]
+			}
+			for _, event := range fresh {
+				fmt.Println(formatTimelineEvent(&event))
+			}
+			watchPRStates(events, prStates, first)
+			first = false
+		}
+		time.Sleep(watchInterval)
+	}
+}
+
+// watchPRStates reports state changes of linked pull requests since the last poll
+func watchPRStates(events []github.TimelineEvent, states map[int]string, first bool) {
+	for _, event := range events {
+		if !event.LinkedPR() {
+			continue
+		}
+		number := event.Source.Issue.Number
+		state := event.PRState()
+		previous, known := states[number]
+		states[number] = state
+		if !first && known && previous != state {
+			fmt.Printf("%s %s PR #%d is now %s\n",
+				config.Blue(time.Now().Format("15:04")), config.Yellow(config.Glyph("dot")), number, state)
+		}
+	}
+}
+
+// formatTimelineEvent renders a timeline event as a single feed line
+func formatTimelineEvent(e *github.TimelineEvent) string {
+	stamp := config.Blue(config.FormatStamp(e.CreatedAt))
+	who := "@" + e.Author()
+
+	var what string
+	switch e.Event {
+	case "commented":
+		body := []rune(strings.Join(strings.Fields(e.Body), " "))
+		if len(body) > 100 {
+			body = append(body[:97], []rune("...")...)
+		}
+		what = fmt.Sprintf("%s commented: %s", who, string(body))
+	case "labeled":
+		what = fmt.Sprintf("%s added label %s", who, config.Green(e.Label.Name))
+	case "unlabeled":
+		what = fmt.Sprintf("%s removed label %s", who, config.Red(e.Label.Name))
+	case "assigned":
+		what = fmt.Sprintf("%s assigned @%s", who, e.Assignee.Login)
+	case "unassigned":
+		what = fmt.Sprintf("%s unassigned @%s", who, e.Assignee.Login)
+	case "renamed":
+		what = fmt.Sprintf("%s renamed the issue to %q", who, e.Rename.To)
+	case "cross-referenced":
+		kind := "issue"
+		if e.LinkedPR() {
+			kind = "PR"
+		}
+		what = fmt.Sprintf("%s linked %s #%d %s (%s)", who, kind, e.Source.Issue.Number, e.Source.Issue.Title, e.PRState())
+	case "closed", "reopened":
+		what = fmt.Sprintf("%s %s the issue", who, e.Event)
+	default:
+		what = fmt.Sprintf("%s %s", who, strings.ReplaceAll(e.Event, "_", " "))
+	}
+	return stamp + " " + what
+}
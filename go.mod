@@ -3,6 +3,7 @@ module github.com/enterprisemodules/gwi
 go 1.24.7
 
 require (
+	github.com/fsnotify/fsnotify v1.8.0
 	github.com/spf13/cobra v1.10.2
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -10,4 +11,5 @@ require (
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
@@ -0,0 +1,175 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/errs"
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// Boards talks to Azure Boards through the Azure CLI (az) and its
+// azure-devops extension. Empty Organization and Project fall back to the
+// defaults set with: az devops configure --defaults
+type Boards struct {
+	Organization string // e.g. https://dev.azure.com/acme
+	Project      string
+}
+
+// WorkItem is an Azure Boards work item
+type WorkItem struct {
+	ID         int
+	Title      string
+	State      string
+	Type       string   // Bug, User Story, Task, ...
+	Tags       []string // Azure Boards has tags rather than labels
+	AssignedTo string   // Unique name (usually the email address) of the assignee
+}
+
+// workItemJSON is a work item as printed by az boards
+type workItemJSON struct {
+	ID     int `json:"id"`
+	Fields struct {
+		Title      string   `json:"System.Title"`
+		State      string   `json:"System.State"`
+		Type       string   `json:"System.WorkItemType"`
+		Tags       string   `json:"System.Tags"` // "tag1; tag2"
+		AssignedTo identity `json:"System.AssignedTo"`
+	} `json:"fields"`
+}
+
+// identity is a person field of a work item: an object in current versions of
+// the Azure DevOps API, "Name <email>" in older ones
+type identity struct {
+	UniqueName string `json:"uniqueName"`
+}
+
+func (i *identity) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		i.UniqueName = s
+		if start, end := strings.LastIndex(s, "<"), strings.LastIndex(s, ">"); start >= 0 && end > start {
+			i.UniqueName = s[start+1 : end]
+		}
+		return nil
+	}
+	type plain identity
+	return json.Unmarshal(data, (*plain)(i))
+}
+
+func (w workItemJSON) workItem() WorkItem {
+	item := WorkItem{ID: w.ID, Title: w.Fields.Title, State: w.Fields.State, Type: w.Fields.Type, AssignedTo: w.Fields.AssignedTo.UniqueName}
+	for _, tag := range strings.Split(w.Fields.Tags, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+	return item
+}
+
+// command returns an az boards command, with the organization and, when
+// withProject is set, the project
+func (b *Boards) command(withProject bool, args ...string) *exec.Cmd {
+	args = append([]string{"boards"}, args...)
+	if b.Organization != "" {
+		args = append(args, "--org", b.Organization)
+	}
+	if withProject && b.Project != "" {
+		args = append(args, "--project", b.Project)
+	}
+	args = append(args, "--output", "json")
+	return timeout.Command("az", args...)
+}
+
+// CheckAuth verifies that the Azure CLI is installed and logged in
+func CheckAuth() error {
+	if _, err := exec.LookPath("az"); err != nil {
+		return errs.Auth("Azure CLI not found. Install it, then run: az extension add --name azure-devops")
+	}
+	if err := timeout.Command("az", "account", "show", "--output", "none").Run(); err != nil {
+		return errs.Auth("Azure CLI not authenticated. Run: az login")
+	}
+	return nil
+}
+
+// CurrentUser returns the unique name of the user the Azure CLI is logged in as
+func CurrentUser() (string, error) {
+	output, err := timeout.Command("az", "account", "show", "--query", "user.name", "--output", "tsv").Output()
+	if err != nil {
+		return "", errs.Auth("Azure CLI not authenticated. Run: az login")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetWorkItem fetches a work item by ID
+func (b *Boards) GetWorkItem(id int) (*WorkItem, error) {
+	output, err := b.command(false, "work-item", "show", "--id", strconv.Itoa(id)).Output()
+	if err != nil {
+		return nil, errs.NotFound("work item %d not found", id)
+	}
+
+	var w workItemJSON
+	if err := json.Unmarshal(output, &w); err != nil {
+		return nil, err
+	}
+	item := w.workItem()
+	return &item, nil
+}
+
+// ListOpenWorkItems lists the project's work items that aren't in one of the
+// closed states, most recently changed first
+func (b *Boards) ListOpenWorkItems(limit int, closedStates []string) ([]WorkItem, error) {
+	quoted := make([]string, len(closedStates))
+	for i, state := range closedStates {
+		quoted[i] = "'" + strings.ReplaceAll(state, "'", "''") + "'"
+	}
+	wiql := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.Tags], [System.AssignedTo] FROM WorkItems" +
+		" WHERE [System.TeamProject] = @project"
+	if len(quoted) > 0 {
+		wiql += " AND [System.State] NOT IN (" + strings.Join(quoted, ", ") + ")"
+	}
+	wiql += " ORDER BY [System.ChangedDate] DESC"
+
+	output, err := b.command(true, "query", "--wiql", wiql).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to query work items: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	var results []workItemJSON
+	if err := json.Unmarshal(output, &results); err != nil {
+		return nil, err
+	}
+	var items []WorkItem
+	for _, w := range results {
+		if len(items) == limit {
+			break
+		}
+		items = append(items, w.workItem())
+	}
+	return items, nil
+}
+
+// SetField sets a field of a work item, e.g. System.State or a board's column field
+func (b *Boards) SetField(id int, field, value string) error {
+	return b.update(id, "--fields", field+"="+value)
+}
+
+// AddComment adds a comment to the discussion of a work item
+func (b *Boards) AddComment(id int, comment string) error {
+	return b.update(id, "--discussion", comment)
+}
+
+func (b *Boards) update(id int, args ...string) error {
+	args = append([]string{"work-item", "update", "--id", strconv.Itoa(id)}, args...)
+	output, err := b.command(false, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to update work item %d: %s", id, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
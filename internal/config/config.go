@@ -2,20 +2,99 @@ package config
 
 import (
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/enterprisemodules/gwi/internal/sandbox"
+	"github.com/enterprisemodules/gwi/internal/xdg"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds all gwi configuration
 type Config struct {
-	WorktreeBase  string       `yaml:"worktree_base"`
-	MergeStrategy string       `yaml:"merge_strategy"`
-	AutoActivate  bool         `yaml:"auto_activate"`
-	HookDir       string       `yaml:"hook_dir"`
-	MainBranch    string       `yaml:"main_branch"`
-	GitHub        GitHubConfig `yaml:"github"`
-	Verbose       bool         `yaml:"verbose"`
+	WorktreeBase  string           `yaml:"worktree_base"`
+	MergeStrategy string           `yaml:"merge_strategy"`
+	Merge         MergeConfig      `yaml:"merge"`
+	AutoActivate  bool             `yaml:"auto_activate"`
+	HookDir       string           `yaml:"hook_dir"`
+	ArchiveDir    string           `yaml:"archive_dir"`
+	MainBranch    string           `yaml:"main_branch"`
+	Tracker       string           `yaml:"tracker"` // Where issue numbers point: "github" or "azure" (Azure Boards)
+	GitHub        GitHubConfig     `yaml:"github"`
+	Azure         AzureConfig      `yaml:"azure"`
+	Verbose       bool             `yaml:"verbose"`
+	TrackStats    bool             `yaml:"track_stats"`
+	OpenBrowser   bool             `yaml:"open_browser"`
+	GitHooks      GitHooksConfig   `yaml:"git_hooks"`
+	Safety        SafetyConfig     `yaml:"safety"`
+	Signing       SigningConfig    `yaml:"signing"`
+	Slug          SlugConfig       `yaml:"slug"`
+	PR            PRConfig         `yaml:"pr"`
+	Sync          SyncConfig       `yaml:"sync"`
+	Create        CreateConfig     `yaml:"create"`
+	Status        StatusConfig     `yaml:"status"`
+	Clean         CleanConfig      `yaml:"clean"`
+	Focus         FocusConfig      `yaml:"focus"`
+	Server        ServerConfig     `yaml:"server"`
+	Verify        VerifyConfig     `yaml:"verify"`
+	LargeFiles    LargeFilesConfig `yaml:"large_files"`
+	Diff          DiffConfig       `yaml:"diff"`
+	Attach        AttachConfig     `yaml:"attach"`
+	Remote        RemoteConfig     `yaml:"remote"`
+	Timeouts      TimeoutConfig    `yaml:"timeouts"`
+	Rules         []Rule           `yaml:"rules"`
+	GitConfig     []GitConfig      `yaml:"git_config"`
+	HookEnv       []HookEnv        `yaml:"hook_env"`
+	HookRequires  []HookRequire    `yaml:"hook_requires"`
+	Backport      BackportConfig   `yaml:"backport"`
+	Display       DisplayConfig    `yaml:"display"`
+	Progress      ProgressConfig   `yaml:"progress"`
+	Release       ReleaseConfig    `yaml:"release"`
+	// Workspaces group repositories, given by the path of their main clone, under a name
+	Workspaces map[string][]string `yaml:"workspaces"`
+}
+
+// DefaultMergeComment is the comment gwi merge closes issues with: the branch
+// merged into and the message of the branch's last commit
+const DefaultMergeComment = "**Merged into {{.Branch}}**\n\n{{.Message}}"
+
+// MergeConfig controls the comment gwi merge closes the issue with
+type MergeConfig struct {
+	// Comment is a Go template of the comment, e.g. "Merged in {{.PR}}"; "off"
+	// closes the issue without one
+	Comment string `yaml:"comment"`
+	// EditComment opens the comment in $EDITOR for review before it is posted
+	EditComment bool `yaml:"edit_comment"`
+}
+
+// BackportConfig controls the backport PRs gwi merge --backport opens
+type BackportConfig struct {
+	// Labels put on backport PRs; {branch} is replaced by the target branch
+	Labels []string `yaml:"labels"`
+}
+
+// ProgressConfig controls the progress updates gwi posts on the issue when
+// the worktree is created, the PR is opened and the branch is merged
+type ProgressConfig struct {
+	// "off", "comments" (one hidden-marked comment per update) or "status"
+	// (a single "gwi status" comment that is edited with each update)
+	Mode string `yaml:"mode"`
+}
+
+// ReleaseConfig controls the tags, release notes and statuses of gwi release prepare
+type ReleaseConfig struct {
+	TagPrefix string         `yaml:"tag_prefix"` // Prefix of version tags, e.g. v for v1.2.3
+	Status    string         `yaml:"status"`     // Project status released issues move to, if the project has it; empty disables
+	Groups    []ReleaseGroup `yaml:"groups"`     // Release note sections, in order; issues go in the first group with one of their labels
+}
+
+// ReleaseGroup is a section of the release notes
+type ReleaseGroup struct {
+	Title  string   `yaml:"title"`
+	Labels []string `yaml:"labels"`
 }
 
 // GitHubConfig holds GitHub Projects integration settings
@@ -26,7 +105,229 @@ type GitHubConfig struct {
 	InProgressValue string `yaml:"in_progress_value"`
 	InReviewValue   string `yaml:"in_review_value"`
 	DoneValue       string `yaml:"done_value"`
+	PausedValue     string `yaml:"paused_value"` // Status of issues put aside with gwi pause; empty leaves it
 	CheckScopes     bool   `yaml:"check_scopes"`
+	// MissingScopes is what happens without the project scope: "prompt" to
+	// refresh it (failing when headless), "fail", or "skip" project updates
+	MissingScopes string `yaml:"missing_scopes"`
+	// ProjectOwner is the organization (or user) whose projects issues are added
+	// to when they aren't on them yet; requires Projects
+	ProjectOwner string `yaml:"project_owner"`
+	// Projects limits status updates to these project titles or numbers; empty means all
+	Projects []string `yaml:"projects"`
+	// EstimateField is the project field 'gwi plan import' puts estimates in
+	EstimateField string `yaml:"estimate_field"`
+}
+
+// AzureConfig connects gwi to Azure Boards when tracker is "azure". Work items
+// then drive worktree creation, and their state follows the worktree's
+// progress like the GitHub Projects status does.
+type AzureConfig struct {
+	Organization    string `yaml:"organization"` // e.g. https://dev.azure.com/acme; empty uses az devops defaults
+	Project         string `yaml:"project"`
+	StateField      string `yaml:"state_field"` // System.State, or a board's column field (WEF_..._Kanban.Column)
+	TodoValue       string `yaml:"todo_value"`
+	InProgressValue string `yaml:"in_progress_value"`
+	InReviewValue   string `yaml:"in_review_value"`
+	DoneValue       string `yaml:"done_value"`
+	PausedValue     string `yaml:"paused_value"` // Empty leaves the state of paused work items
+}
+
+// GitHooksConfig controls how repository git hooks are enabled in new worktrees
+type GitHooksConfig struct {
+	Install bool   `yaml:"install"`
+	Source  string `yaml:"source"` // Hooks directory, relative to the worktree or absolute
+	Mode    string `yaml:"mode"`   // "path" sets core.hooksPath for the worktree, "copy" installs into the repository hooks dir
+}
+
+// SafetyConfig guards the main worktree and default branch against destructive commands
+type SafetyConfig struct {
+	ProtectMain       bool     `yaml:"protect_main"`
+	ProtectedBranches []string `yaml:"protected_branches"` // Never deleted, in addition to main_branch
+	AllowServerInMain bool     `yaml:"allow_server_in_main"`
+}
+
+// SigningConfig controls commit signature requirements
+type SigningConfig struct {
+	// RequireSigned refuses to push or merge unsigned commits. Can also be enabled
+	// for a single repository with: git config gwi.requireSigned true
+	RequireSigned bool `yaml:"require_signed"`
+}
+
+// SlugConfig controls how branch names are generated from issue titles
+type SlugConfig struct {
+	MaxLength int      `yaml:"max_length"`
+	StopWords []string `yaml:"stop_words"`
+}
+
+// PRConfig controls how pull requests are created
+type PRConfig struct {
+	// ConventionalTitle derives the PR title from the branch's conventional
+	// commits (e.g. "feat(api): ...") instead of using the issue title
+	ConventionalTitle bool `yaml:"conventional_title"`
+	// Templates maps issue labels to templates in .github/PULL_REQUEST_TEMPLATE,
+	// e.g. bug: bugfix.md
+	Templates map[string]string `yaml:"templates"`
+	Size      PRSizeConfig      `yaml:"size"`
+	// Components name the parts of a monorepo; the PR title is prefixed with
+	// the components the diff touches, e.g. "api: Fix login timeout"
+	Components []PRComponent `yaml:"components"`
+	// Sections turn the branch's commit trailers into sections of the PR body,
+	// e.g. "Test-Plan:" lines into a "Test plan" section
+	Sections []PRSection `yaml:"sections"`
+}
+
+// PRSection is a section of the PR body collected from commit trailers
+type PRSection struct {
+	Trailer string `yaml:"trailer"` // Trailer key, e.g. Changelog; matched ignoring case
+	Heading string `yaml:"heading"` // Section heading; a heading of the template with this text is filled in
+}
+
+// PRComponent is a component or package of a monorepo, found by path prefix
+type PRComponent struct {
+	Name   string   `yaml:"name"`   // Title prefix, e.g. api
+	Paths  []string `yaml:"paths"`  // Path prefixes in the repository, e.g. services/api/
+	Labels []string `yaml:"labels"` // Labels put on PRs touching the component
+	Repos  []string `yaml:"repos"`  // org/repo patterns such as acme/*; empty matches all repositories
+}
+
+// PRSizeConfig controls the size summary gwi pr prints before creating a PR
+type PRSizeConfig struct {
+	MaxFiles int  `yaml:"max_files"` // Warn above this many changed files; 0 disables
+	MaxLines int  `yaml:"max_lines"` // Warn above this many changed lines, lockfiles excluded; 0 disables
+	InBody   bool `yaml:"in_body"`   // Append the summary to the PR body
+}
+
+// SyncConfig configures sharing the metadata store between machines
+type SyncConfig struct {
+	Backend string `yaml:"backend"` // "git" or "gist"; empty disables sync
+	Repo    string `yaml:"repo"`    // Remote URL of the repository used by the git backend
+	Gist    string `yaml:"gist"`    // ID of the secret gist used by the gist backend
+}
+
+// CreateConfig controls the steps gwi create runs for a new worktree
+type CreateConfig struct {
+	// Steps run in order; leave one out to disable it. Besides the built-in
+	// steps, "hook:<name>" runs a named hook and "run:<command>" a shell command.
+	Steps     []string `yaml:"steps"`
+	CopyFiles []string `yaml:"copy_files"` // Globs copied from the main worktree (copy_files step), e.g. .env
+	Editor    string   `yaml:"editor"`     // Command opening the worktree (open_editor step), e.g. "code"
+	// Sort orders the issue selector: newest, oldest, updated (recent activity),
+	// reactions (most 👍 first), comments or priority (by priority_labels)
+	Sort           string   `yaml:"sort"`
+	PriorityLabels []string `yaml:"priority_labels"` // Labels from highest to lowest priority, e.g. [P0, P1, P2]
+}
+
+// StatusConfig controls how gwi status refreshes remote tracking branches
+type StatusConfig struct {
+	AutoFetch     bool          `yaml:"auto_fetch"`     // Fetch before showing status
+	FetchInterval time.Duration `yaml:"fetch_interval"` // Skip auto fetch if the last fetch is more recent
+}
+
+// CleanConfig is the worktree retention policy applied by gwi clean --policy
+type CleanConfig struct {
+	MaxAge   time.Duration `yaml:"max_age"`   // Remove worktrees without activity for this long; 0 disables
+	MaxCount int           `yaml:"max_count"` // Keep at most this many worktrees per repository, the most recently active; 0 disables
+	MergedPR bool          `yaml:"merged_pr"` // Remove worktrees whose PR was merged
+}
+
+// ServerConfig controls the dev server sessions of gwi up
+type ServerConfig struct {
+	// PortVars are the variables gwi up --switch carries over from the stopped
+	// server, so the server of the other worktree takes over its ports
+	PortVars []string `yaml:"port_vars"`
+}
+
+// VerifyConfig is the pipeline gwi verify runs in a worktree, such as the
+// repository's tests and linters
+type VerifyConfig struct {
+	Steps []VerifyStep `yaml:"steps"` // Run in order; a .gwi/verify hook runs after them
+	OnPR  bool         `yaml:"on_pr"` // gwi pr verifies first; --verify and --no-verify override it
+}
+
+// VerifyStep is a command of the verify pipeline
+type VerifyStep struct {
+	Name  string   `yaml:"name"`  // Shown in the output and the PR body, e.g. lint
+	Run   string   `yaml:"run"`   // Shell command run in the worktree, e.g. make lint
+	Repos []string `yaml:"repos"` // org/repo patterns such as acme/*; empty matches all repositories
+}
+
+// LargeFilesConfig controls the check gwi pr runs before pushing for large
+// files that aren't stored in Git LFS
+type LargeFilesConfig struct {
+	MaxMB int  `yaml:"max_mb"` // Warn about files of this many megabytes or more; 0 disables
+	Block bool `yaml:"block"`  // Refuse to push them instead of warning
+}
+
+// FocusConfig limits how many issues are worked on at the same time
+type FocusConfig struct {
+	WIPLimit int  `yaml:"wip_limit"` // Maximum number of active issues; 0 disables the limit
+	Block    bool `yaml:"block"`     // Refuse to start another issue instead of warning
+}
+
+// DiffConfig controls how gwi diff displays changes
+type DiffConfig struct {
+	Tool string `yaml:"tool"` // "delta", "difftastic", or empty for git's configured pager
+}
+
+// AttachConfig controls where gwi attach uploads files
+type AttachConfig struct {
+	// Tag of the prerelease the files are uploaded to as assets; it is created on first use
+	ReleaseTag string `yaml:"release_tag"`
+}
+
+// RemoteConfig names the git remotes gwi fetches from and pushes to, for
+// repositories whose origin is a read-only mirror. They can also be set for a
+// single repository with: git config gwi.fetchRemote / gwi.pushRemote
+type RemoteConfig struct {
+	Fetch string `yaml:"fetch"` // Remote fetched from and compared against
+	Push  string `yaml:"push"`  // Remote branches are pushed to; its URL names the GitHub repository
+}
+
+// TimeoutConfig bounds how long gwi waits for git, gh and az, so a flaky
+// network fails a command instead of hanging it
+type TimeoutConfig struct {
+	Default time.Duration `yaml:"default"` // Any call without an override; 0 disables
+	// Overrides by program or subcommand, e.g. gh: 2m or "git fetch": 10m; 0 disables
+	Commands map[string]time.Duration `yaml:"commands"`
+}
+
+// Rule changes how gwi handles issues with certain labels. Rules are evaluated
+// in order: every matching rule warns, and the first one to set a base or
+// merge strategy wins.
+type Rule struct {
+	Labels        []string `yaml:"labels"`         // Matches issues with any of these labels; empty matches all issues
+	Events        []string `yaml:"events"`         // "create", "pr" and/or "merge"; empty means all
+	Warn          string   `yaml:"warn"`           // Warning shown when the rule matches
+	RequireForce  bool     `yaml:"require_force"`  // Refuse to create a worktree without --force
+	Base          string   `yaml:"base"`           // Branch to start from, open the PR against and merge into, instead of main_branch
+	MergeStrategy string   `yaml:"merge_strategy"` // Overrides merge_strategy for gwi merge
+}
+
+// GitConfig is a set of git config entries set with git config --worktree in
+// new worktrees of matching repositories, e.g. a work email for one org.
+// Entries are applied in order, so later ones override earlier ones.
+type GitConfig struct {
+	Repos  []string          `yaml:"repos"`  // org/repo patterns such as acme/*; empty matches all repositories
+	Config map[string]string `yaml:"config"` // e.g. user.email: me@acme.com
+}
+
+// HookEnv is a set of environment variables for the hooks of matching
+// repositories. Entries are applied in order, so later ones override earlier
+// ones. Values may use ${VAR} and refer to a secret provider (see hooks.Env).
+type HookEnv struct {
+	Repos []string          `yaml:"repos"` // org/repo patterns such as acme/*; empty matches all repositories
+	Hooks []string          `yaml:"hooks"` // Hook name patterns such as up or up.*; empty matches all hooks
+	Vars  map[string]string `yaml:"vars"`  // e.g. DATABASE_URL: postgres://${DB_HOST}/app
+}
+
+// HookRequire declares tools the hooks of matching repositories need in the
+// shell they run in, such as direnv or a version manager. gwi up checks them
+// before starting a session; gwi env doctor checks all hooks.
+type HookRequire struct {
+	Repos []string `yaml:"repos"` // org/repo patterns such as acme/*; empty matches all repositories
+	Hooks []string `yaml:"hooks"` // Hook name patterns such as up or up.*; empty matches all hooks
+	Tools []string `yaml:"tools"` // Commands or shell functions, e.g. direnv, rvm, nvm
 }
 
 // Load returns the configuration from YAML file and environment variables
@@ -38,8 +339,10 @@ func Load() *Config {
 		WorktreeBase:  filepath.Join(home, "worktrees"),
 		MergeStrategy: "squash",
 		AutoActivate:  false,
-		HookDir:       filepath.Join(home, ".config", "gwi", "hooks"),
+		HookDir:       filepath.Join(xdg.ConfigDir(), "hooks"),
+		ArchiveDir:    filepath.Join(xdg.DataDir(), "archive"),
 		MainBranch:    "main",
+		Tracker:       "github",
 		Verbose:       false,
 		GitHub: GitHubConfig{
 			ProjectsEnabled: true,
@@ -48,13 +351,99 @@ func Load() *Config {
 			InProgressValue: "In Progress",
 			InReviewValue:   "In Review",
 			DoneValue:       "Done",
+			PausedValue:     "Paused",
 			CheckScopes:     true,
+			MissingScopes:   "prompt",
+			EstimateField:   "Estimate",
+		},
+		Server: ServerConfig{
+			PortVars: []string{"PORT"},
+		},
+		Display: DisplayConfig{
+			Palette:    "default",
+			TimeFormat: "relative",
+		},
+		Progress: ProgressConfig{
+			Mode: "off",
+		},
+		Release: ReleaseConfig{
+			TagPrefix: "v",
+			Status:    "Released",
+			Groups: []ReleaseGroup{
+				{Title: "Features", Labels: []string{"enhancement", "feature"}},
+				{Title: "Bug Fixes", Labels: []string{"bug"}},
+				{Title: "Documentation", Labels: []string{"documentation"}},
+			},
+		},
+		Azure: AzureConfig{
+			StateField:      "System.State",
+			TodoValue:       "New",
+			InProgressValue: "Active",
+			InReviewValue:   "Resolved",
+			DoneValue:       "Closed",
+		},
+		GitHooks: GitHooksConfig{
+			Install: false,
+			Source:  ".githooks",
+			Mode:    "path",
+		},
+		Safety: SafetyConfig{
+			ProtectMain: true,
+		},
+		Status: StatusConfig{
+			FetchInterval: 5 * time.Minute,
+		},
+		Clean: CleanConfig{
+			MergedPR: true,
+		},
+		LargeFiles: LargeFilesConfig{
+			MaxMB: 10,
+		},
+		PR: PRConfig{
+			Size: PRSizeConfig{
+				MaxFiles: 30,
+				MaxLines: 500,
+			},
+			Sections: []PRSection{
+				{Trailer: "Changelog", Heading: "Changelog"},
+				{Trailer: "Breaking", Heading: "Breaking changes"},
+				{Trailer: "Test-Plan", Heading: "Test plan"},
+			},
+		},
+		Create: CreateConfig{
+			Steps: []string{"fetch", "worktree", "copy_files", "hooks", "project_update"},
+			Sort:  "newest",
+		},
+		Attach: AttachConfig{
+			ReleaseTag: "gwi-attachments",
+		},
+		Merge: MergeConfig{
+			Comment: DefaultMergeComment,
+		},
+		Remote: RemoteConfig{
+			Fetch: "origin",
+			Push:  "origin",
+		},
+		Timeouts: TimeoutConfig{
+			Default: 5 * time.Minute,
+			Commands: map[string]time.Duration{
+				"gh": 2 * time.Minute,
+				"az": 2 * time.Minute,
+				// Runs the repository's commit hooks, which may run a test suite
+				"git commit": 0,
+			},
+		},
+		Backport: BackportConfig{
+			Labels: []string{"backport"},
+		},
+		Slug: SlugConfig{
+			MaxLength: 50,
+			StopWords: []string{"a", "an", "the", "and", "or", "of", "to", "in", "on", "for", "with", "is", "be"},
 		},
 	}
 
 	// Try to load from YAML config file
-	configPath := filepath.Join(home, ".config", "gwi", "config.yaml")
-	if data, err := os.ReadFile(configPath); err == nil {
+	if data, err := os.ReadFile(Path()); err == nil {
 		_ = yaml.Unmarshal(data, cfg)
 	}
 
@@ -65,18 +454,186 @@ func Load() *Config {
 	if val := os.Getenv("GWI_MERGE_STRATEGY"); val != "" {
 		cfg.MergeStrategy = val
 	}
+	if val := os.Getenv("GWI_MERGE_COMMENT"); val != "" {
+		cfg.Merge.Comment = val
+	}
+	if val := os.Getenv("GWI_MERGE_EDIT_COMMENT"); val != "" {
+		cfg.Merge.EditComment = val == "1" || val == "true"
+	}
 	if val := os.Getenv("GWI_AUTO_ACTIVATE"); val == "1" {
 		cfg.AutoActivate = true
 	}
 	if val := os.Getenv("GWI_HOOK_DIR"); val != "" {
 		cfg.HookDir = val
 	}
+	if val := os.Getenv("GWI_ARCHIVE_DIR"); val != "" {
+		cfg.ArchiveDir = val
+	}
 	if val := os.Getenv("GWI_MAIN_BRANCH"); val != "" {
 		cfg.MainBranch = val
 	}
+	if val := os.Getenv("GWI_TRACKER"); val != "" {
+		cfg.Tracker = val
+	}
+	if val := os.Getenv("GWI_AZURE_ORG"); val != "" {
+		cfg.Azure.Organization = val
+	}
+	if val := os.Getenv("GWI_AZURE_PROJECT"); val != "" {
+		cfg.Azure.Project = val
+	}
+	if val := os.Getenv("GWI_AZURE_STATE_FIELD"); val != "" {
+		cfg.Azure.StateField = val
+	}
 	if val := os.Getenv("GWI_VERBOSE"); val == "1" {
 		cfg.Verbose = true
 	}
+	if val := os.Getenv("GWI_TRACK_STATS"); val != "" {
+		cfg.TrackStats = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_OPEN_BROWSER"); val != "" {
+		cfg.OpenBrowser = val == "1" || val == "true"
+	}
+
+	if val := os.Getenv("GWI_GIT_HOOKS"); val != "" {
+		cfg.GitHooks.Install = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_GIT_HOOKS_SOURCE"); val != "" {
+		cfg.GitHooks.Source = val
+	}
+	if val := os.Getenv("GWI_GIT_HOOKS_MODE"); val != "" {
+		cfg.GitHooks.Mode = val
+	}
+
+	if val := os.Getenv("GWI_PROTECT_MAIN"); val != "" {
+		cfg.Safety.ProtectMain = val != "false" && val != "0"
+	}
+
+	if val := os.Getenv("GWI_REQUIRE_SIGNED"); val != "" {
+		cfg.Signing.RequireSigned = val == "1" || val == "true"
+	}
+
+	if val := os.Getenv("GWI_SLUG_MAX_LENGTH"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.Slug.MaxLength = n
+		}
+	}
+	if val, ok := os.LookupEnv("GWI_SLUG_STOP_WORDS"); ok {
+		cfg.Slug.StopWords = strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+
+	if val := os.Getenv("GWI_PR_CONVENTIONAL_TITLE"); val != "" {
+		cfg.PR.ConventionalTitle = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_PR_MAX_FILES"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.PR.Size.MaxFiles = n
+		}
+	}
+	if val := os.Getenv("GWI_PR_MAX_LINES"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.PR.Size.MaxLines = n
+		}
+	}
+	if val := os.Getenv("GWI_PR_SIZE_IN_BODY"); val != "" {
+		cfg.PR.Size.InBody = val == "1" || val == "true"
+	}
+
+	if val := os.Getenv("GWI_STATUS_AUTO_FETCH"); val != "" {
+		cfg.Status.AutoFetch = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_STATUS_FETCH_INTERVAL"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			cfg.Status.FetchInterval = d
+		}
+	}
+
+	if val := os.Getenv("GWI_CLEAN_MAX_AGE"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			cfg.Clean.MaxAge = d
+		}
+	}
+	if val := os.Getenv("GWI_CLEAN_MAX_COUNT"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.Clean.MaxCount = n
+		}
+	}
+	if val := os.Getenv("GWI_CLEAN_MERGED_PR"); val != "" {
+		cfg.Clean.MergedPR = val == "1" || val == "true"
+	}
+
+	if val, ok := os.LookupEnv("GWI_BACKPORT_LABELS"); ok {
+		cfg.Backport.Labels = strings.FieldsFunc(val, func(r rune) bool { return r == ',' })
+	}
+
+	if val := os.Getenv("GWI_CREATE_STEPS"); val != "" {
+		cfg.Create.Steps = strings.Split(val, ",")
+	}
+	if val := os.Getenv("GWI_COPY_FILES"); val != "" {
+		cfg.Create.CopyFiles = strings.Split(val, ",")
+	}
+	if val := os.Getenv("GWI_EDITOR"); val != "" {
+		cfg.Create.Editor = val
+	}
+	if val := os.Getenv("GWI_CREATE_SORT"); val != "" {
+		cfg.Create.Sort = val
+	}
+	if val := os.Getenv("GWI_PRIORITY_LABELS"); val != "" {
+		cfg.Create.PriorityLabels = strings.Split(val, ",")
+	}
+
+	if val := os.Getenv("GWI_WIP_LIMIT"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.Focus.WIPLimit = n
+		}
+	}
+	if val := os.Getenv("GWI_WIP_BLOCK"); val != "" {
+		cfg.Focus.Block = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_VERIFY_ON_PR"); val != "" {
+		cfg.Verify.OnPR = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_LARGE_FILES_MAX_MB"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			cfg.LargeFiles.MaxMB = n
+		}
+	}
+	if val := os.Getenv("GWI_LARGE_FILES_BLOCK"); val != "" {
+		cfg.LargeFiles.Block = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_SERVER_PORT_VARS"); val != "" {
+		cfg.Server.PortVars = strings.Split(val, ",")
+	}
+
+	if val := os.Getenv("GWI_DIFF_TOOL"); val != "" {
+		cfg.Diff.Tool = val
+	}
+
+	if val := os.Getenv("GWI_ATTACH_RELEASE_TAG"); val != "" {
+		cfg.Attach.ReleaseTag = val
+	}
+
+	if val := os.Getenv("GWI_REMOTE_FETCH"); val != "" {
+		cfg.Remote.Fetch = val
+	}
+	if val := os.Getenv("GWI_REMOTE_PUSH"); val != "" {
+		cfg.Remote.Push = val
+	}
+
+	if val := os.Getenv("GWI_TIMEOUT"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			cfg.Timeouts.Default = d
+		}
+	}
+
+	if val := os.Getenv("GWI_SYNC_BACKEND"); val != "" {
+		cfg.Sync.Backend = val
+	}
+	if val := os.Getenv("GWI_SYNC_REPO"); val != "" {
+		cfg.Sync.Repo = val
+	}
+	if val := os.Getenv("GWI_SYNC_GIST"); val != "" {
+		cfg.Sync.Gist = val
+	}
 
 	// GitHub Projects configuration
 	if val := os.Getenv("GWI_GITHUB_PROJECTS_ENABLED"); val != "" {
@@ -97,14 +654,162 @@ func Load() *Config {
 	if val := os.Getenv("GWI_GITHUB_DONE"); val != "" {
 		cfg.GitHub.DoneValue = val
 	}
+	if val, ok := os.LookupEnv("GWI_GITHUB_PAUSED"); ok {
+		cfg.GitHub.PausedValue = val
+	}
 	if val := os.Getenv("GWI_GITHUB_CHECK_SCOPES"); val == "0" || val == "false" {
 		cfg.GitHub.CheckScopes = false
 	}
+	if val := os.Getenv("GWI_GITHUB_MISSING_SCOPES"); val != "" {
+		cfg.GitHub.MissingScopes = val
+	}
+	if val := os.Getenv("GWI_GITHUB_PROJECT_OWNER"); val != "" {
+		cfg.GitHub.ProjectOwner = val
+	}
+	if val := os.Getenv("GWI_GITHUB_PROJECTS"); val != "" {
+		cfg.GitHub.Projects = strings.Split(val, ",")
+	}
+	if val := os.Getenv("GWI_GITHUB_ESTIMATE_FIELD"); val != "" {
+		cfg.GitHub.EstimateField = val
+	}
+	// The sandbox's fake GitHub has no Projects
+	if sandbox.Enabled() {
+		cfg.GitHub.ProjectsEnabled = false
+	}
+	if val, ok := os.LookupEnv("GWI_RELEASE_STATUS"); ok {
+		cfg.Release.Status = val
+	}
+	if val := os.Getenv("GWI_PROGRESS"); val != "" {
+		cfg.Progress.Mode = val
+	}
+	if val := os.Getenv("GWI_ASCII"); val != "" {
+		cfg.Display.ASCII = val == "1" || val == "true"
+	}
+	if val := os.Getenv("GWI_PALETTE"); val != "" {
+		cfg.Display.Palette = val
+	}
+	if val := os.Getenv("GWI_TIME_FORMAT"); val != "" {
+		cfg.Display.TimeFormat = val
+	}
+	if val := os.Getenv("GWI_LOCALE"); val != "" {
+		cfg.Display.Locale = val
+	}
+	// https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		cfg.Display.Palette = "none"
+	}
+	applyDisplay(cfg.Display)
 
 	return cfg
 }
 
+// WorkspaceRepos returns the repository paths of a workspace, with ~ expanded
+func (c *Config) WorkspaceRepos(name string) ([]string, bool) {
+	repos, ok := c.Workspaces[name]
+	if !ok {
+		return nil, false
+	}
+	home, _ := os.UserHomeDir()
+	paths := make([]string, len(repos))
+	for i, repo := range repos {
+		if rest, ok := strings.CutPrefix(repo, "~/"); ok {
+			repo = filepath.Join(home, rest)
+		}
+		paths[i] = repo
+	}
+	return paths, true
+}
+
 // WorktreeBasePath returns the worktree base path for a given org/repo
 func (c *Config) WorktreeBasePath(org, repo string) string {
 	return filepath.Join(c.WorktreeBase, "github.com", org, repo)
 }
+
+// WorktreeGitConfig returns the git_config entries for a repository's
+// worktrees, later entries overriding earlier ones
+func (c *Config) WorktreeGitConfig(org, repo string) map[string]string {
+	result := make(map[string]string)
+	for _, entry := range c.GitConfig {
+		if !matchesRepo(entry.Repos, org+"/"+repo) {
+			continue
+		}
+		for key, value := range entry.Config {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// PRComponents returns the pr.components of a repository
+func (c *Config) PRComponents(org, repo string) []PRComponent {
+	var components []PRComponent
+	for _, component := range c.PR.Components {
+		if matchesRepo(component.Repos, org+"/"+repo) {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// VerifySteps returns the verify.steps of a repository
+func (c *Config) VerifySteps(org, repo string) []VerifyStep {
+	var steps []VerifyStep
+	for _, step := range c.Verify.Steps {
+		if matchesRepo(step.Repos, org+"/"+repo) {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// HookEnvVars returns the hook_env variables for a hook of a repository,
+// later entries overriding earlier ones. Values are returned unexpanded.
+func (c *Config) HookEnvVars(org, repo, hook string) map[string]string {
+	result := make(map[string]string)
+	for _, entry := range c.HookEnv {
+		if !matchesRepo(entry.Repos, org+"/"+repo) || !matchesHook(entry.Hooks, hook) {
+			continue
+		}
+		for key, value := range entry.Vars {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// HookRequiredTools returns the hook_requires tools for a hook of a repository
+func (c *Config) HookRequiredTools(org, repo, hook string) []string {
+	var tools []string
+	for _, entry := range c.HookRequires {
+		if matchesRepo(entry.Repos, org+"/"+repo) && matchesHook(entry.Hooks, hook) {
+			tools = append(tools, entry.Tools...)
+		}
+	}
+	return tools
+}
+
+// matchesHook reports whether a hook name matches one of the patterns
+func matchesHook(patterns []string, hook string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, hook); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRepo reports whether name (org/repo) matches one of the patterns
+func matchesRepo(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package config
+
+import "strings"
+
+// DisplayConfig controls how status is drawn, for terminals without Unicode
+// symbols and for readers who can't tell the default colors apart
+type DisplayConfig struct {
+	ASCII   bool              `yaml:"ascii"`   // Plain ASCII glyphs instead of Unicode symbols
+	Palette string            `yaml:"palette"` // "default", "colorblind" or "none"
+	Glyphs  map[string]string `yaml:"glyphs"`  // Glyph overrides by name, e.g. dirty: "✎"
+
+	TimeFormat string `yaml:"time_format"` // "relative", "iso", "datetime", "local" or a Go layout
+	Locale     string `yaml:"locale"`      // Language of localized times, e.g. de_DE; defaults to LC_TIME or LANG
+}
+
+// palette holds the escape codes of the colors gwi uses, by role: red for
+// failures, green for success, yellow for warnings, blue for information
+type palette struct {
+	red, green, yellow, blue, cyan, dim string
+}
+
+// palettes are the color schemes display.palette selects. colorblind uses the
+// Okabe-Ito colors, which stay apart with the common kinds of color blindness.
+var palettes = map[string]palette{
+	"default": {
+		red:    "\033[0;31m",
+		green:  "\033[0;32m",
+		yellow: "\033[0;33m",
+		blue:   "\033[0;34m",
+		cyan:   "\033[36m",
+		dim:    "\033[2m",
+	},
+	"colorblind": {
+		red:    "\033[38;5;166m", // Vermillion
+		green:  "\033[38;5;33m",  // Blue
+		yellow: "\033[38;5;228m", // Yellow
+		blue:   "\033[38;5;74m",  // Sky blue
+		cyan:   "\033[38;5;175m", // Reddish purple
+		dim:    "\033[2m",
+	},
+	"none": {},
+}
+
+// Glyph names and their Unicode and ASCII forms
+var (
+	unicodeGlyphs = map[string]string{
+		"info":      "→",
+		"success":   "✓",
+		"warn":      "!",
+		"failure":   "✗",
+		"clean":     "●", // No uncommitted changes
+		"dirty":     "◐", // Uncommitted changes
+		"unchecked": "○", // Changes not checked
+		"up":        "↑", // Ahead, pushed
+		"down":      "↓", // Behind, pulled
+		"running":   "▶",
+		"dot":       "●", // Marks issues with a worktree
+		"more":      "…",
+		"reactions": "👍",
+		"comments":  "💬",
+		"hline":     "─",
+		"vline":     "│",
+	}
+	asciiGlyphs = map[string]string{
+		"info":      "->",
+		"success":   "+",
+		"warn":      "!",
+		"failure":   "x",
+		"clean":     "-",
+		"dirty":     "*",
+		"unchecked": "?",
+		"up":        "^",
+		"down":      "v",
+		"running":   ">",
+		"dot":       "*",
+		"more":      "...",
+		"reactions": "+",
+		"comments":  "c",
+		"hline":     "-",
+		"vline":     "|",
+	}
+)
+
+var (
+	colors = palettes["default"]
+	glyphs = unicodeGlyphs
+)
+
+// applyDisplay switches the output helpers to the configured glyphs, colors
+// and time format
+func applyDisplay(d DisplayConfig) {
+	p, ok := palettes[strings.ToLower(d.Palette)]
+	if !ok {
+		p = palettes["default"]
+	}
+	colors = p
+
+	base := unicodeGlyphs
+	if d.ASCII {
+		base = asciiGlyphs
+	}
+	glyphs = make(map[string]string, len(base))
+	for name, glyph := range base {
+		glyphs[name] = glyph
+	}
+	for name, glyph := range d.Glyphs {
+		glyphs[name] = glyph
+	}
+	applyTimeFormat(d)
+}
+
+// Glyph returns the configured symbol for name, such as "dirty" or "up"
+func Glyph(name string) string {
+	return glyphs[name]
+}
+
+// paint wraps s in a color, leaving it alone without colors
+func paint(color, s string) string {
+	if color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
@@ -0,0 +1,18 @@
+package config
+
+import "os"
+
+// Headless reports whether nobody is there to answer a prompt: in CI, or when
+// stdin isn't a terminal
+func Headless() bool {
+	if val := os.Getenv("CI"); val != "" && val != "0" && val != "false" {
+		return true
+	}
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return true
+	}
+	// /dev/null is a character device too
+	null, err := os.Stat(os.DevNull)
+	return err == nil && os.SameFile(stat, null)
+}
@@ -1,47 +1,106 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-)
+	"strconv"
+	"strings"
 
-// Color codes
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[0;31m"
-	colorGreen  = "\033[0;32m"
-	colorYellow = "\033[0;33m"
-	colorBlue   = "\033[0;34m"
+	"github.com/enterprisemodules/gwi/internal/errs"
 )
 
+// colorReset ends a color; the colors themselves come from the palette
+const colorReset = "\033[0m"
+
 // Info prints an informational message to stderr
 func Info(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s→%s %s\n", colorBlue, colorReset, fmt.Sprintf(format, a...))
+	fmt.Fprintf(os.Stderr, "%s %s\n", Blue(Glyph("info")), Redact(fmt.Sprintf(format, a...)))
 }
 
 // Success prints a success message to stderr
 func Success(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s✓%s %s\n", colorGreen, colorReset, fmt.Sprintf(format, a...))
+	fmt.Fprintf(os.Stderr, "%s %s\n", Green(Glyph("success")), Redact(fmt.Sprintf(format, a...)))
 }
 
 // Warn prints a warning message to stderr
 func Warn(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s!%s %s\n", colorYellow, colorReset, fmt.Sprintf(format, a...))
+	fmt.Fprintf(os.Stderr, "%s %s\n", Yellow(Glyph("warn")), Redact(fmt.Sprintf(format, a...)))
+}
+
+// jsonErrors prints errors as JSON for scripts (--error-format json)
+var jsonErrors bool
+
+// SetJSONErrors switches error output of Fail to JSON
+func SetJSONErrors(enabled bool) {
+	jsonErrors = enabled
+}
+
+// Fail reports err and exits with the exit code of its kind
+func Fail(err error) {
+	code := errs.ExitCode(err)
+	if code == 0 {
+		return
+	}
+	message := Redact(err.Error())
+	if jsonErrors {
+		report := map[string]interface{}{
+			"kind":      errs.KindOf(err),
+			"message":   message,
+			"exit_code": code,
+		}
+		if reason := errs.ReasonOf(err); reason != "" {
+			report["reason"] = reason
+		}
+		data, _ := json.Marshal(map[string]interface{}{"error": report})
+		fmt.Fprintln(os.Stderr, string(data))
+	} else {
+		fmt.Fprintf(os.Stderr, "%s %s\n", Red("Error:"), message)
+	}
+	Exit(code)
+}
+
+var exitHooks []func(code int)
+
+// AtExit registers a function to run before gwi exits through Fail or Exit
+func AtExit(fn func(code int)) {
+	exitHooks = append(exitHooks, fn)
 }
 
-// Die prints an error message and exits
-func Die(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%sError:%s %s\n", colorRed, colorReset, fmt.Sprintf(format, a...))
-	os.Exit(1)
+// Exit runs the registered exit hooks and terminates with the given code
+func Exit(code int) {
+	for _, fn := range exitHooks {
+		fn(code)
+	}
+	os.Exit(code)
 }
 
 // Error prints an error message (without exiting)
 func Error(format string, a ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%sError:%s %s\n", colorRed, colorReset, fmt.Sprintf(format, a...))
+	fmt.Fprintf(os.Stderr, "%s %s\n", Red("Error:"), Redact(fmt.Sprintf(format, a...)))
+}
+
+// HexColor renders s in the xterm-256 color closest to a "#rrggbb" hex color.
+// Invalid colors leave s uncolored.
+func HexColor(hex, s string) string {
+	hex = strings.TrimPrefix(hex, "#")
+	if colors == (palette{}) {
+		return s
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return s
+	}
+	// Map each channel onto the 6x6x6 color cube (indexes 16-231)
+	cube := func(v uint64) uint64 { return (v*5 + 127) / 255 }
+	r, g, b := cube(rgb>>16&0xff), cube(rgb>>8&0xff), cube(rgb&0xff)
+	return fmt.Sprintf("\033[38;5;%dm%s%s", 16+36*r+6*g+b, s, colorReset)
 }
 
 // Color helpers for inline use
-func Red(s string) string    { return colorRed + s + colorReset }
-func Green(s string) string  { return colorGreen + s + colorReset }
-func Yellow(s string) string { return colorYellow + s + colorReset }
-func Blue(s string) string   { return colorBlue + s + colorReset }
+func Red(s string) string    { return paint(colors.red, s) }
+func Green(s string) string  { return paint(colors.green, s) }
+func Yellow(s string) string { return paint(colors.yellow, s) }
+func Blue(s string) string   { return paint(colors.blue, s) }
+func Cyan(s string) string   { return paint(colors.cyan, s) }
+func Dim(s string) string    { return paint(colors.dim, s) }
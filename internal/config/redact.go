@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// redactRule replaces matches of a pattern with a masked value
+type redactRule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// Credentials are always masked, regardless of redaction mode
+var credentialRules = []redactRule{
+	{regexp.MustCompile(`\b(gh[pousr]_)[A-Za-z0-9*]+`), "${1}[REDACTED]"},
+	{regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_*]+`), "github_pat_[REDACTED]"},
+	{regexp.MustCompile(`(?i)(token:\s*)\S+`), "${1}[REDACTED]"},
+	{regexp.MustCompile(`(?i)(authorization:\s*(?:bearer\s+|token\s+)?)\S+`), "${1}[REDACTED]"},
+	{regexp.MustCompile(`(https?://)[^/\s:@]+(?::[^/\s@]*)?@`), "${1}[REDACTED]@"},
+}
+
+// Identifying details are only masked when redaction mode is enabled
+var identityRules = []redactRule{
+	{regexp.MustCompile(`(?i)(logged in to \S+ (?:account|as)\s+)\S+`), "${1}[USER]"},
+	{regexp.MustCompile(`\b(PVT[A-Z]*_)[A-Za-z0-9_-]+`), "${1}[ID]"},
+	{regexp.MustCompile(`("title"\s*:\s*)"(?:[^"\\]|\\.)*"`), `${1}"[TITLE]"`},
+}
+
+var (
+	redactMode  bool
+	redactMutex sync.RWMutex
+)
+
+// SetRedact enables or disables redaction of identifying details in output
+func SetRedact(enabled bool) {
+	redactMutex.Lock()
+	redactMode = enabled
+	redactMutex.Unlock()
+}
+
+// Redacting reports whether redaction mode is enabled
+func Redacting() bool {
+	redactMutex.RLock()
+	defer redactMutex.RUnlock()
+	return redactMode
+}
+
+// Redact masks credentials in s, and identifying details when redaction mode is enabled
+func Redact(s string) string {
+	for _, rule := range credentialRules {
+		s = rule.re.ReplaceAllString(s, rule.repl)
+	}
+	if Redacting() {
+		for _, rule := range identityRules {
+			s = rule.re.ReplaceAllString(s, rule.repl)
+		}
+	}
+	return s
+}
+
+// RedactWriter buffers output line by line and redacts each line before writing it
+type RedactWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewRedactWriter wraps w so that everything written through it is redacted.
+// Call Flush when done to emit any trailing partial line.
+func NewRedactWriter(w io.Writer) *RedactWriter {
+	return &RedactWriter{w: w}
+}
+
+func (r *RedactWriter) Write(p []byte) (int, error) {
+	r.buf.Write(p)
+	for {
+		line, err := r.buf.ReadString('\n')
+		if err != nil {
+			// Incomplete line, keep it for the next write
+			r.buf.Reset()
+			r.buf.WriteString(line)
+			break
+		}
+		if _, err := io.WriteString(r.w, Redact(line)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any buffered partial line
+func (r *RedactWriter) Flush() error {
+	if r.buf.Len() == 0 {
+		return nil
+	}
+	_, err := io.WriteString(r.w, Redact(r.buf.String()))
+	r.buf.Reset()
+	return err
+}
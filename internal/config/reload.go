@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/xdg"
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// Path returns the config file's path
+func Path() string {
+	return filepath.Join(xdg.ConfigDir(), "config.yaml")
+}
+
+// Watcher reloads the configuration when the config file changes, so
+// long-running commands pick up changes without a restart. Changes are
+// reported by fsnotify; where it can't watch the file, its modification time
+// and size are polled. Call Check from the command's loop.
+type Watcher struct {
+	cfg     *Config
+	fs      *fsnotify.Watcher
+	changed chan struct{} // Signalled by fsnotify events for the file
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher watches the config file for changes to cfg
+func NewWatcher(cfg *Config) *Watcher {
+	w := &Watcher{cfg: cfg}
+	w.modTime, w.size = configStat()
+
+	// Editors often replace the file instead of writing it, so the directories
+	// of the file, and of its target if it is a symlink, are watched
+	names := map[string]bool{Path(): true}
+	if target, err := filepath.EvalSymlinks(Path()); err == nil {
+		names[target] = true
+	}
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return w
+	}
+	for name := range names {
+		if err := fs.Add(filepath.Dir(name)); err != nil {
+			fs.Close()
+			return w
+		}
+	}
+	w.fs = fs
+	w.changed = make(chan struct{}, 1)
+	go w.watch(names)
+	return w
+}
+
+// watch turns the fsnotify events for the file into signals on changed
+func (w *Watcher) watch(names map[string]bool) {
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if names[filepath.Clean(event.Name)] {
+				select {
+				case w.changed <- struct{}{}:
+				default:
+				}
+			}
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Close stops watching the file
+func (w *Watcher) Close() {
+	if w.fs != nil {
+		w.fs.Close()
+	}
+}
+
+// Check reloads the configuration when the file changed since the last check,
+// logging the settings that changed. It returns the configuration to use and
+// whether it was reloaded. A file that doesn't parse is reported and the
+// current configuration kept.
+func (w *Watcher) Check() (*Config, bool) {
+	if w.fs != nil {
+		select {
+		case <-w.changed:
+		default:
+			return w.cfg, false
+		}
+	} else {
+		modTime, size := configStat()
+		if modTime.Equal(w.modTime) && size == w.size {
+			return w.cfg, false
+		}
+		w.modTime, w.size = modTime, size
+	}
+
+	if err := validateFile(); err != nil {
+		Warn("Not reloading %s: %v", Path(), err)
+		return w.cfg, false
+	}
+	cfg := Load()
+	changes := changedSettings(w.cfg, cfg)
+	w.cfg = cfg
+	if len(changes) == 0 {
+		return cfg, false
+	}
+	Info("Reloaded %s:", Path())
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  %s\n", change)
+	}
+	return cfg, true
+}
+
+// configStat returns the config file's modification time and size, zero when
+// it doesn't exist
+func configStat() (time.Time, int64) {
+	info, err := os.Stat(Path())
+	if err != nil {
+		return time.Time{}, 0
+	}
+	return info.ModTime(), info.Size()
+}
+
+// validateFile checks that the config file parses. Unknown keys, often a
+// misspelled setting, are reported but don't stop the reload.
+func validateFile() error {
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &Config{}); err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&Config{}); err != nil && !errors.Is(err, io.EOF) {
+		Warn("%s: %v", Path(), err)
+	}
+	return nil
+}
+
+// changedSettings lists the settings that differ between two configurations, one
+// "key: old → new" line each, by their YAML keys
+func changedSettings(old, new *Config) []string {
+	before, after := flatten(old), flatten(new)
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []string
+	for key := range keys {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", key, diffValue(before[key]), diffValue(after[key])))
+		}
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// flatten maps a configuration's settings by dotted YAML key, with lists and
+// other values that aren't sections kept whole
+func flatten(cfg *Config) map[string]any {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil
+	}
+	flat := make(map[string]any)
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		for key, value := range node {
+			if section, ok := value.(map[string]any); ok && len(section) > 0 {
+				walk(prefix+key+".", section)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	walk("", tree)
+	return flat
+}
+
+// diffValue renders a setting's value on one line
+func diffValue(value any) string {
+	if value == nil {
+		return "(unset)"
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	text := strings.TrimSpace(string(data))
+	if strings.Contains(text, "\n") {
+		var compact any
+		_ = yaml.Unmarshal(data, &compact)
+		text = fmt.Sprintf("%v", compact)
+	}
+	return text
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeFormats are the formats display.time_format and --time-format name; any
+// other value is used as a Go time layout, e.g. "02 Jan 15:04"
+var TimeFormats = []string{"relative", "iso", "datetime", "local"}
+
+// relativeLimit is how far back relative times go before the date is shown
+const relativeLimit = 30 * 24 * time.Hour
+
+// timeLocale holds how times are written in a language
+type timeLocale struct {
+	layout  string // Absolute date and time, with "Jan" for the month
+	date    string // Absolute date, with "Jan" for the month
+	months  [12]string
+	units   [3]string // Minutes, hours and days, appended to the count
+	ago     string    // Format of a past relative time
+	in      string    // Format of a future relative time
+	justNow string
+}
+
+var englishUnits = [3]string{"m", "h", "d"}
+
+var englishMonths = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// timeLocales are the languages times are localized in, by language code;
+// en_US is looked up by its full name first
+var timeLocales = map[string]timeLocale{
+	"en_US": {layout: "Jan 2, 2006 15:04", date: "Jan 2, 2006", months: englishMonths, units: englishUnits, ago: "%s ago", in: "in %s", justNow: "just now"},
+	"en":    {layout: "2 Jan 2006 15:04", date: "2 Jan 2006", months: englishMonths, units: englishUnits, ago: "%s ago", in: "in %s", justNow: "just now"},
+	"de": {layout: "2. Jan 2006 15:04", date: "2. Jan 2006", units: [3]string{" Min.", " Std.", " Tg."}, ago: "vor %s", in: "in %s", justNow: "gerade eben",
+		months: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."}},
+	"nl": {layout: "2 Jan 2006 15:04", date: "2 Jan 2006", units: [3]string{"m", "u", "d"}, ago: "%s geleden", in: "over %s", justNow: "zojuist",
+		months: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"}},
+	"fr": {layout: "2 Jan 2006 15:04", date: "2 Jan 2006", units: [3]string{" min", " h", " j"}, ago: "il y a %s", in: "dans %s", justNow: "à l'instant",
+		months: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."}},
+	"es": {layout: "2 Jan 2006 15:04", date: "2 Jan 2006", units: [3]string{" min", " h", " d"}, ago: "hace %s", in: "en %s", justNow: "justo ahora",
+		months: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"}},
+}
+
+var (
+	timeFormat         = "relative"
+	timeFormatOverride string
+	locale             = timeLocales["en"]
+)
+
+// applyTimeFormat switches FormatTime to the configured format and locale.
+// Without display.locale, the locale comes from LC_ALL, LC_TIME or LANG.
+func applyTimeFormat(d DisplayConfig) {
+	timeFormat = d.TimeFormat
+	if timeFormat == "" || ValidateTimeFormat(timeFormat) != nil {
+		timeFormat = "relative"
+	}
+
+	name := d.Locale
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if name != "" {
+			break
+		}
+		name = os.Getenv(env)
+	}
+	locale = lookupLocale(name)
+}
+
+// lookupLocale finds the locale of a name like de_DE.UTF-8 or nl, falling
+// back to English
+func lookupLocale(name string) timeLocale {
+	name, _, _ = strings.Cut(name, ".")
+	name = strings.ReplaceAll(name, "-", "_")
+	if l, ok := timeLocales[name]; ok {
+		return l
+	}
+	lang, _, _ := strings.Cut(name, "_")
+	if l, ok := timeLocales[strings.ToLower(lang)]; ok {
+		return l
+	}
+	return timeLocales["en"]
+}
+
+// SetTimeFormat overrides display.time_format, for --time-format
+func SetTimeFormat(format string) error {
+	if err := ValidateTimeFormat(format); err != nil {
+		return err
+	}
+	timeFormatOverride = format
+	return nil
+}
+
+// ValidateTimeFormat reports whether format is a known format or a Go layout
+func ValidateTimeFormat(format string) error {
+	for _, known := range TimeFormats {
+		if format == known {
+			return nil
+		}
+	}
+	// A layout writes at least one of the reference time's parts
+	if strings.ContainsAny(format, "0123456789") || strings.Contains(format, "Jan") || strings.Contains(format, "Mon") {
+		return nil
+	}
+	return fmt.Errorf("unknown time format %q; use %s, or a Go layout like \"2006-01-02 15:04\"", format, strings.Join(TimeFormats, ", "))
+}
+
+// FormatTime renders a timestamp for people, in the configured format:
+//
+//	relative  3h ago, in 2d; the date once it is over 30 days away
+//	iso       2006-01-02T15:04:05+02:00
+//	datetime  2006-01-02 15:04
+//	local     the date and time as the locale writes them
+//
+// Machine output, such as JSON, keeps RFC 3339 instead.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	t = t.Local()
+	format := currentTimeFormat()
+	switch format {
+	case "relative":
+		return relativeTime(t, time.Now())
+	case "iso":
+		return t.Format(time.RFC3339)
+	case "datetime":
+		return t.Format("2006-01-02 15:04")
+	case "local":
+		return localize(t, locale.layout)
+	}
+	return t.Format(format)
+}
+
+// FormatStamp renders the time of a logged event, such as a line of a feed
+// or a history entry. Such lines aren't updated once printed, so the relative
+// format shows the date and time (datetime) there instead.
+func FormatStamp(t time.Time) string {
+	if currentTimeFormat() == "relative" && !t.IsZero() {
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	return FormatTime(t)
+}
+
+// currentTimeFormat is --time-format, or else display.time_format
+func currentTimeFormat() string {
+	if timeFormatOverride != "" {
+		return timeFormatOverride
+	}
+	return timeFormat
+}
+
+// relativeTime renders t relative to now in the locale's words, in minutes,
+// hours or days
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	switch {
+	case d > relativeLimit:
+		return localize(t, locale.date)
+	case d < time.Minute:
+		return locale.justNow
+	}
+
+	var span string
+	switch {
+	case d >= 24*time.Hour:
+		span = fmt.Sprintf("%d%s", int(d.Hours()/24), locale.units[2])
+	case d >= time.Hour:
+		span = fmt.Sprintf("%d%s", int(d.Hours()), locale.units[1])
+	default:
+		span = fmt.Sprintf("%d%s", int(d.Minutes()), locale.units[0])
+	}
+	if future {
+		return fmt.Sprintf(locale.in, span)
+	}
+	return fmt.Sprintf(locale.ago, span)
+}
+
+// localize formats t with layout, naming the month in the locale's language
+func localize(t time.Time, layout string) string {
+	if !strings.Contains(layout, "Jan") {
+		return t.Format(layout)
+	}
+	before, after, _ := strings.Cut(layout, "Jan")
+	return t.Format(before) + locale.months[t.Month()-1] + t.Format(after)
+}
@@ -0,0 +1,125 @@
+package deps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// keyPrefix namespaces lockfile fingerprints in the metadata store
+const keyPrefix = "deps/"
+
+// Lockfile describes a dependency lockfile and the command that installs from it
+type Lockfile struct {
+	Name    string
+	Install []string
+}
+
+// Lockfiles lists the lockfiles gwi knows how to install from, in install order
+var Lockfiles = []Lockfile{
+	{Name: "Gemfile.lock", Install: []string{"bundle", "install"}},
+	{Name: "package-lock.json", Install: []string{"npm", "ci"}},
+	{Name: "yarn.lock", Install: []string{"yarn", "install", "--frozen-lockfile"}},
+	{Name: "pnpm-lock.yaml", Install: []string{"pnpm", "install", "--frozen-lockfile"}},
+	{Name: "go.sum", Install: []string{"go", "mod", "download"}},
+	{Name: "poetry.lock", Install: []string{"poetry", "install"}},
+	{Name: "Pipfile.lock", Install: []string{"pipenv", "sync"}},
+	{Name: "Cargo.lock", Install: []string{"cargo", "fetch"}},
+	{Name: "composer.lock", Install: []string{"composer", "install"}},
+}
+
+// Fingerprint returns a hash per lockfile present in the worktree
+func Fingerprint(worktreePath string) map[string]string {
+	result := make(map[string]string)
+	for _, lock := range Lockfiles {
+		if sum, err := hashFile(filepath.Join(worktreePath, lock.Name)); err == nil {
+			result[lock.Name] = sum
+		}
+	}
+	return result
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Record stores the current lockfile fingerprint as installed
+func Record(worktreePath string) error {
+	fingerprint := Fingerprint(worktreePath)
+	return store.Update(func(s *store.Store) error {
+		return s.Set(keyPrefix+worktreePath, fingerprint)
+	})
+}
+
+// Forget removes the stored fingerprint for a worktree
+func Forget(worktreePath string) error {
+	return store.Update(func(s *store.Store) error {
+		s.Delete(keyPrefix + worktreePath)
+		return nil
+	})
+}
+
+// Changed returns the lockfiles that differ from the last install. known is
+// false when dependencies were never installed through gwi for this worktree.
+func Changed(s *store.Store, worktreePath string) (changed []string, known bool) {
+	var recorded map[string]string
+	if !s.Get(keyPrefix+worktreePath, &recorded) {
+		return nil, false
+	}
+
+	current := Fingerprint(worktreePath)
+	for name, sum := range current {
+		if recorded[name] != sum {
+			changed = append(changed, name)
+		}
+	}
+	for name := range recorded {
+		if _, ok := current[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, true
+}
+
+// InstallCommands returns the install commands for the lockfiles present in the worktree
+func InstallCommands(worktreePath string) [][]string {
+	var commands [][]string
+	for _, lock := range Lockfiles {
+		if _, err := os.Stat(filepath.Join(worktreePath, lock.Name)); err == nil {
+			commands = append(commands, lock.Install)
+		}
+	}
+	return commands
+}
+
+// RunInstall runs an install command in the worktree, streaming its output
+func RunInstall(worktreePath string, command []string) error {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = worktreePath
+	// Credentials are always masked, as in hook output
+	stdout, stderr := config.NewRedactWriter(os.Stdout), config.NewRedactWriter(os.Stderr)
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
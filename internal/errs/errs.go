@@ -0,0 +1,116 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies an error; each kind has its own exit code so scripts can
+// tell, say, a missing worktree from a missing GitHub login
+type Kind string
+
+const (
+	KindGeneral  Kind = "error"
+	KindUsage    Kind = "usage"
+	KindNotFound Kind = "not_found"
+	KindAuth     Kind = "auth"
+	KindConflict Kind = "conflict"
+	KindAborted  Kind = "aborted"
+	KindNotReady Kind = "not_ready"
+)
+
+// exitCodes are the documented exit codes per kind
+var exitCodes = map[Kind]int{
+	KindGeneral:  1,
+	KindUsage:    2,
+	KindNotFound: 3,
+	KindAuth:     4,
+	KindConflict: 5,
+	KindAborted:  6,
+	KindNotReady: 7,
+}
+
+// Error is an error of a specific kind
+type Error struct {
+	Kind    Kind
+	Message string
+	Reason  string // Machine-readable detail, e.g. "checks_failing"
+	Err     error  // Underlying error, if any
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of the given kind
+func New(kind Kind, format string, a ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, a...)}
+}
+
+// Wrap classifies err, keeping its message
+func Wrap(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// NotFound reports a missing worktree, issue, branch or similar
+func NotFound(format string, a ...interface{}) error {
+	return New(KindNotFound, format, a...)
+}
+
+// Auth reports missing or insufficient GitHub authentication
+func Auth(format string, a ...interface{}) error {
+	return New(KindAuth, format, a...)
+}
+
+// Conflict reports a clash with existing state, such as an existing worktree
+// or a merge conflict
+func Conflict(format string, a ...interface{}) error {
+	return New(KindConflict, format, a...)
+}
+
+// Aborted reports that the user declined to continue
+func Aborted(format string, a ...interface{}) error {
+	return New(KindAborted, format, a...)
+}
+
+// NotReady reports that a pull request doesn't meet the requirements for an
+// unattended merge; reason identifies the first unmet requirement
+func NotReady(reason, format string, a ...interface{}) error {
+	return &Error{Kind: KindNotReady, Reason: reason, Message: fmt.Sprintf(format, a...)}
+}
+
+// ReasonOf returns the machine-readable reason of err, if any
+func ReasonOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
+
+// KindOf returns the kind of err, KindGeneral for unclassified errors
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindGeneral
+}
+
+// ExitCode returns the exit code for err, 0 for nil
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	return exitCodes[KindOf(err)]
+}
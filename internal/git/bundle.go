@@ -0,0 +1,83 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// CreateBundle writes the commits of branch that aren't in exclude (e.g.
+// "origin/main") to a bundle file. Leave exclude empty to bundle all history.
+func CreateBundle(path, file, branch, exclude string) error {
+	args := []string{"bundle", "create", "--quiet", file, "refs/heads/" + branch}
+	if exclude != "" {
+		args = append(args, "^"+exclude)
+	}
+	cmd := timeout.Command("git", args...)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git bundle failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// FetchBundle restores branch from a bundle file into the current repository
+func FetchBundle(file, branch string) error {
+	ref := "refs/heads/" + branch
+	cmd := timeout.Command("git", "fetch", "--quiet", file, ref+":"+ref)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fetch from bundle failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CountCommits returns the number of commits in a revision range
+func CountCommits(path, revRange string) (int, error) {
+	cmd := timeout.Command("git", "rev-list", "--count", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// DiffWorkingTree returns a binary patch of all uncommitted changes against
+// HEAD, untracked files included. A temporary index is used, so the
+// worktree's own index is left alone.
+func DiffWorkingTree(path string) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "gwi-index-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+
+	for _, args := range [][]string{{"read-tree", "HEAD"}, {"add", "--all"}} {
+		cmd := timeout.Command("git", args...)
+		cmd.Dir = path
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(output)))
+		}
+	}
+
+	cmd := timeout.Command("git", "diff", "--cached", "--binary", "HEAD")
+	cmd.Dir = path
+	cmd.Env = env
+	return cmd.Output()
+}
+
+// ApplyPatch applies a patch file to the working tree at path
+func ApplyPatch(path, file string) error {
+	cmd := timeout.Command("git", "apply", "--binary", file)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
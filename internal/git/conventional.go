@@ -0,0 +1,97 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit is a commit subject in Conventional Commits form,
+// e.g. "feat(api)!: add pagination"
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+var conventionalRegexp = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]+)\))?(!)?: +(.+)$`)
+
+// conventionalTypes orders commit types by how much they say about a change;
+// the most significant type among a branch's commits becomes the PR type
+var conventionalTypes = []string{"feat", "fix", "perf", "refactor", "revert", "docs", "style", "test", "build", "ci", "chore"}
+
+// ParseConventionalCommit parses a commit subject; ok is false for subjects
+// that don't follow the convention or use an unknown type
+func ParseConventionalCommit(subject string) (c ConventionalCommit, ok bool) {
+	m := conventionalRegexp.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return c, false
+	}
+	c = ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Breaking:    m[3] == "!",
+		Description: strings.TrimSpace(m[4]),
+	}
+	if typeRank(c.Type) < 0 {
+		return c, false
+	}
+	return c, true
+}
+
+// String formats the commit back into a conventional subject
+func (c ConventionalCommit) String() string {
+	s := c.Type
+	if c.Scope != "" {
+		s += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		s += "!"
+	}
+	return s + ": " + c.Description
+}
+
+func typeRank(t string) int {
+	for i, known := range conventionalTypes {
+		if t == known {
+			return i
+		}
+	}
+	return -1
+}
+
+// ConventionalTitle derives a pull request title from commit subjects (oldest
+// first). The most significant type wins, the scope is kept when all commits of
+// that type share it, and the description comes from the first such commit.
+// ok is false when none of the subjects are conventional commits.
+func ConventionalTitle(subjects []string) (string, bool) {
+	var commits []ConventionalCommit
+	for _, subject := range subjects {
+		if c, ok := ParseConventionalCommit(subject); ok {
+			commits = append(commits, c)
+		}
+	}
+	if len(commits) == 0 {
+		return "", false
+	}
+
+	var title *ConventionalCommit
+	breaking := false
+	for i := range commits {
+		c := &commits[i]
+		breaking = breaking || c.Breaking
+		if title == nil || typeRank(c.Type) < typeRank(title.Type) {
+			title = c
+		}
+	}
+
+	result := *title
+	result.Breaking = breaking
+	for _, c := range commits {
+		if c.Type == result.Type && c.Scope != result.Scope {
+			result.Scope = ""
+			break
+		}
+	}
+	return result.String(), true
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// MergeBase returns the best common ancestor of two commits
+func MergeBase(path, a, b string) (string, error) {
+	cmd := timeout.Command("git", "merge-base", a, b)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("no merge base between %s and %s", a, b)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ShowDiff runs git diff against base in the worktree at path, on the
+// terminal so git's pager is used. gitConfig holds extra "key=value"
+// settings, e.g. to select a pager or external diff tool.
+func ShowDiff(path, base string, gitConfig, args []string) error {
+	var cmdArgs []string
+	for _, c := range gitConfig {
+		cmdArgs = append(cmdArgs, "-c", c)
+	}
+	cmdArgs = append(cmdArgs, "diff")
+	cmdArgs = append(cmdArgs, args...)
+	cmdArgs = append(cmdArgs, base)
+	cmd := timeout.Interactive("git", cmdArgs...)
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// PatchStat returns the diffstat of a patch
+func PatchStat(patch []byte) (string, error) {
+	cmd := timeout.Command("git", "apply", "--stat", "-")
+	cmd.Stdin = bytes.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git apply --stat failed: %s", strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// Worktrees share one object database and one set of remote refs, so a fetch
+// from any of them updates all. Fetches are coordinated through a lock on a
+// file in the common git directory: concurrent gwi processes wait for the fetch
+// in progress instead of tripping over git's own lock files, and reuse its
+// result. The operating system releases the lock when its holder exits, even
+// on Ctrl-C or a crash.
+const (
+	fetchLockName = "gwi-fetch.lock"
+	fetchLockWait = 2 * time.Minute // Give up waiting for another fetch after this long
+	// fetchedName is touched after each fetch; FETCH_HEAD is written to the
+	// fetching worktree's own git directory, so it can't tell the others
+	fetchedName = "gwi-fetched"
+)
+
+// Fetch fetches from the fetch remote, and the push remote if it differs
+func Fetch() error {
+	return coordinatedFetch(false)
+}
+
+// FetchPrune fetches like Fetch, with pruning
+func FetchPrune() error {
+	return coordinatedFetch(true)
+}
+
+// coordinatedFetch fetches the repository of the current directory while
+// holding its fetch lock. A fetch another process completed while this one
+// waited for the lock counts as done.
+func coordinatedFetch(prune bool) error {
+	commonDir, err := GetCommonDir(".")
+	if err != nil {
+		return runFetch(prune)
+	}
+
+	start := time.Now()
+	unlock, waited, err := lockFetch(filepath.Join(commonDir, fetchLockName))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if waited && fetchedTime(commonDir).After(start) {
+		return nil
+	}
+	if err := runFetch(prune); err != nil {
+		return err
+	}
+	markFetched(commonDir)
+	return nil
+}
+
+func runFetch(prune bool) error {
+	args := []string{"fetch", "--multiple"}
+	if prune {
+		args = append(args, "--prune")
+	}
+	return timeout.Command("git", append(args, remotes()...)...).Run()
+}
+
+// lockFetch acquires the fetch lock of a repository, waiting while another
+// process holds it. waited reports whether it had to wait.
+func lockFetch(path string) (unlock func(), waited bool, err error) {
+	deadline := time.Now().Add(fetchLockWait)
+	for {
+		unlock, ok, err := tryLock(path)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			return unlock, waited, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, false, errors.New("another gwi process is still fetching " + filepath.Dir(path))
+		}
+		waited = true
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// markFetched records that the repository with this common git directory was
+// just fetched
+func markFetched(commonDir string) {
+	path := filepath.Join(commonDir, fetchedName)
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		os.WriteFile(path, nil, 0644)
+	}
+}
+
+// fetchedTime returns when gwi last fetched the repository with this common
+// git directory, or else when git last wrote FETCH_HEAD there
+func fetchedTime(commonDir string) time.Time {
+	for _, name := range []string{fetchedName, "FETCH_HEAD"} {
+		if info, err := os.Stat(filepath.Join(commonDir, name)); err == nil {
+			return info.ModTime()
+		}
+	}
+	return time.Time{}
+}
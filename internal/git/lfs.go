@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// LargeFile is a file added by a commit that hasn't been pushed
+type LargeFile struct {
+	Path string
+	Size int64
+	Hash string
+}
+
+// LargeUnpushedFiles returns the files of at least minSize bytes in the
+// commits of HEAD that aren't on any remote, largest first. Files in Git LFS
+// are committed as small pointers, so they never count. A file committed in
+// several versions is listed with its largest one.
+func LargeUnpushedFiles(path string, minSize int64) ([]LargeFile, error) {
+	list := timeout.Command("git", "rev-list", "--objects", "HEAD", "--not", "--remotes")
+	list.Dir = path
+	objects, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unpushed objects: %w", err)
+	}
+	if len(bytes.TrimSpace(objects)) == 0 {
+		return nil, nil
+	}
+
+	check := timeout.Command("git", "cat-file", "--batch-check=%(objecttype) %(objectname) %(objectsize) %(rest)")
+	check.Dir = path
+	check.Stdin = bytes.NewReader(objects)
+	output, err := check.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object sizes: %w", err)
+	}
+
+	largest := make(map[string]LargeFile)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 4)
+		if len(fields) < 4 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || size < minSize {
+			continue
+		}
+		if existing, ok := largest[fields[3]]; !ok || size > existing.Size {
+			largest[fields[3]] = LargeFile{Path: fields[3], Size: size, Hash: fields[1]}
+		}
+	}
+
+	files := make([]LargeFile, 0, len(largest))
+	for _, file := range largest {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Size > files[j].Size })
+	return files, nil
+}
+
+// HasLFS reports whether the git lfs extension is installed
+func HasLFS() bool {
+	return timeout.Command("git", "lfs", "version").Run() == nil
+}
+
+// MigrateToLFS rewrites the commits of the branch checked out at path that
+// aren't on any remote to store paths in Git LFS, adding them to
+// .gitattributes. Without refs given, git lfs migrate takes the current branch
+// and leaves out everything the remote-tracking refs reach.
+func MigrateToLFS(path string, paths []string) error {
+	args := []string{"lfs", "migrate", "import", "--yes", "--include=" + strings.Join(paths, ",")}
+	cmd := timeout.Command("git", args...)
+	cmd.Dir = path
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package git
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorSharingViolation is returned when another process has the file open
+const errorSharingViolation syscall.Errno = 32
+
+// tryLock opens path without sharing it, which locks it until the handle is
+// closed; ok is false when another process holds it
+func tryLock(path string) (unlock func(), ok bool, err error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, false, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return func() { syscall.CloseHandle(handle) }, true, nil
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// Repositories whose origin is a read-only mirror fetch from one remote and
+// push to another. Worktrees are based on main from the fetch remote, while
+// branches and main are pushed to the push remote, whose URL also names the
+// GitHub repository.
+var (
+	fetchRemote = "origin"
+	pushRemote  = "origin"
+)
+
+// SetRemotes sets the remotes gwi fetches from and pushes to. Empty names
+// leave the remote unchanged.
+func SetRemotes(fetch, push string) {
+	if fetch != "" {
+		fetchRemote = fetch
+	}
+	if push != "" {
+		pushRemote = push
+	}
+}
+
+// FetchRemote returns the name of the remote fetched from, "origin" by default
+func FetchRemote() string {
+	return fetchRemote
+}
+
+// PushRemote returns the name of the remote pushed to, "origin" by default
+func PushRemote() string {
+	return pushRemote
+}
+
+// RemoteRef returns the remote-tracking ref of a branch on the fetch remote, e.g. origin/main
+func RemoteRef(branch string) string {
+	return fetchRemote + "/" + branch
+}
+
+// PushedRef returns the remote-tracking ref of a branch on the push remote
+func PushedRef(branch string) string {
+	return pushRemote + "/" + branch
+}
+
+// remotes returns the fetch remote, followed by the push remote if it differs
+func remotes() []string {
+	if pushRemote == fetchRemote {
+		return []string{fetchRemote}
+	}
+	return []string{fetchRemote, pushRemote}
+}
+
+// RemoteBranchesWithPrefix lists the branches on the fetch and push remotes
+// whose names start with prefix, most recently committed first, as of the
+// last fetch
+func RemoteBranchesWithPrefix(prefix string) []string {
+	args := []string{"for-each-ref", "--sort=-committerdate", "--format=%(refname)"}
+	for _, remote := range remotes() {
+		args = append(args, "refs/remotes/"+remote+"/"+prefix+"*")
+	}
+	output, err := timeout.Command("git", args...).Output()
+	if err != nil {
+		return nil
+	}
+
+	var branches []string
+	for _, ref := range strings.Fields(string(output)) {
+		for _, remote := range remotes() {
+			if branch, ok := strings.CutPrefix(ref, "refs/remotes/"+remote+"/"); ok && !slices.Contains(branches, branch) {
+				branches = append(branches, branch)
+			}
+		}
+	}
+	return branches
+}
@@ -2,9 +2,15 @@ package git
 
 import (
 	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
 )
 
 // RepoInfo holds GitHub repository information
@@ -13,12 +19,13 @@ type RepoInfo struct {
 	Repo string
 }
 
-// GetRepoInfo extracts org/repo from the current git repository
+// GetRepoInfo extracts org/repo from the push remote of the current git
+// repository, which is the GitHub repository when origin is a mirror
 func GetRepoInfo() (*RepoInfo, error) {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	cmd := timeout.Command("git", "config", "--get", "remote."+pushRemote+".url")
 	output, err := cmd.Output()
 	if err != nil {
-		return nil, errors.New("not in a git repository with origin remote")
+		return nil, errors.New("not in a git repository with " + pushRemote + " remote")
 	}
 
 	remoteURL := strings.TrimSpace(string(output))
@@ -66,23 +73,19 @@ func ParseRemoteURL(remoteURL string) (*RepoInfo, error) {
 	return nil, errors.New("could not parse GitHub org/repo from remote URL: " + remoteURL)
 }
 
-// Fetch fetches from origin
-func Fetch() error {
-	cmd := exec.Command("git", "fetch", "origin")
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	return cmd.Run()
-}
-
-// FetchPrune fetches from origin with pruning
-func FetchPrune() error {
-	cmd := exec.Command("git", "fetch", "origin", "--prune")
-	return cmd.Run()
+// LastFetch returns when the repository containing path was last fetched,
+// shared by all its worktrees. It is zero if the repository was never fetched.
+func LastFetch(path string) time.Time {
+	commonDir, err := GetCommonDir(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fetchedTime(commonDir)
 }
 
 // GetMainWorktreePath returns the path to the main worktree
 func GetMainWorktreePath() (string, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
+	cmd := timeout.Command("git", "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -100,45 +103,56 @@ func GetMainWorktreePath() (string, error) {
 
 // BranchExists checks if a branch exists locally
 func BranchExists(branchName string) bool {
-	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branchName)
+	cmd := timeout.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branchName)
 	return cmd.Run() == nil
 }
 
-// RemoteBranchExists checks if a branch exists on origin
+// RemoteBranchExists checks if a branch exists on the push remote
 func RemoteBranchExists(branchName string) bool {
-	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", "refs/remotes/origin/"+branchName)
+	return refExists("refs/remotes/" + PushedRef(branchName))
+}
+
+// RemoteBranchRef returns the remote-tracking ref of a branch, preferring the
+// push remote over a mirror that may lag behind, or "" if neither has it
+func RemoteBranchRef(branchName string) string {
+	for _, ref := range []string{PushedRef(branchName), RemoteRef(branchName)} {
+		if refExists("refs/remotes/" + ref) {
+			return ref
+		}
+	}
+	return ""
+}
+
+func refExists(ref string) bool {
+	cmd := timeout.Command("git", "show-ref", "--verify", "--quiet", ref)
 	return cmd.Run() == nil
 }
 
+// CreateBranch creates a local branch at startPoint without checking it out
+func CreateBranch(path, branchName, startPoint string) error {
+	cmd := timeout.Command("git", "branch", branchName, startPoint)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // DeleteBranch deletes a local branch
 func DeleteBranch(branchName string) error {
-	cmd := exec.Command("git", "branch", "-D", branchName)
+	cmd := timeout.Command("git", "branch", "-D", branchName)
 	return cmd.Run()
 }
 
-// DeleteRemoteBranch deletes a branch from origin
+// DeleteRemoteBranch deletes a branch from the push remote
 func DeleteRemoteBranch(branchName string) error {
-	cmd := exec.Command("git", "push", "origin", "--delete", branchName)
+	cmd := timeout.Command("git", "push", pushRemote, "--delete", branchName)
 	return cmd.Run()
 }
 
-// GetLastCommitMessage returns the last commit message for the current branch or a specific ref
-func GetLastCommitMessage(ref string) (string, error) {
-	args := []string{"log", "-1", "--pretty=%B"}
-	if ref != "" {
-		args = append(args, ref)
-	}
-	cmd := exec.Command("git", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
 // Checkout switches to the specified branch in the main worktree
 func Checkout(mainWorktree, branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
+	cmd := timeout.Command("git", "checkout", branch)
 	cmd.Dir = mainWorktree
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -149,7 +163,19 @@ func Checkout(mainWorktree, branch string) error {
 
 // MergeBranch merges a branch into the current branch in the main worktree using fast-forward only
 func MergeBranch(mainWorktree, branch string) error {
-	cmd := exec.Command("git", "merge", branch, "--ff-only")
+	cmd := timeout.Command("git", "merge", branch, "--ff-only")
+	cmd.Dir = mainWorktree
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MergeCommit merges a branch into the current branch in the main worktree,
+// always creating a merge commit
+func MergeCommit(mainWorktree, branch string) error {
+	cmd := timeout.Command("git", "merge", "--no-ff", "--no-edit", branch)
 	cmd.Dir = mainWorktree
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -158,10 +184,57 @@ func MergeBranch(mainWorktree, branch string) error {
 	return nil
 }
 
-// PushMain pushes the main branch to origin
+// FetchPullHead fetches the head of a GitHub pull request, which GitHub keeps
+// as pull/<number>/head even for forks and deleted branches, into a local branch
+func FetchPullHead(prNumber int, branchName string) error {
+	cmd := timeout.Command("git", "fetch", "--quiet", fetchRemote, "pull/"+strconv.Itoa(prNumber)+"/head:refs/heads/"+branchName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ResetHard moves the current branch of the worktree to ref, discarding
+// changes, e.g. to undo a merge
+func ResetHard(path, ref string) error {
+	cmd := timeout.Command("git", "reset", "--hard", ref)
+	cmd.Dir = path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PushRef force-pushes src, e.g. HEAD, to ref on the push remote. A ref
+// outside refs/heads keeps commits safe without updating a branch, its pull
+// request or CI.
+func PushRef(path, src, ref string) error {
+	cmd := timeout.Command("git", "push", "--force", pushRemote, src+":"+ref)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DeleteRemoteRef deletes a ref from the push remote
+func DeleteRemoteRef(path, ref string) error {
+	cmd := timeout.Command("git", "push", pushRemote, "--delete", ref)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PushMain pushes the main branch to the push remote
 func PushMain(mainWorktree, branch string) error {
-	cmd := exec.Command("git", "push", "origin", branch)
+	cmd := timeout.Command("git", "push", pushRemote, branch)
 	cmd.Dir = mainWorktree
+	// Pushing main here is intended; let a gwi guard pre-push hook through
+	cmd.Env = append(os.Environ(), "GWI_GUARD=0")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return errors.New(strings.TrimSpace(string(output)))
@@ -169,9 +242,109 @@ func PushMain(mainWorktree, branch string) error {
 	return nil
 }
 
+// GetHeadCommit returns the full SHA of HEAD in the given worktree
+func GetHeadCommit(path string) (string, error) {
+	cmd := timeout.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// BranchIssue returns the issue a local branch was created for, as recorded by
+// SetBranchIssue, or "" when none was
+func BranchIssue(branchName string) string {
+	return GetConfig("", "branch."+branchName+".gwi-issue")
+}
+
+// SetBranchIssue records the issue a local branch was created for, such as
+// "github#42". git drops it with the branch.
+func SetBranchIssue(branchName, issue string) error {
+	cmd := timeout.Command("git", "config", "branch."+branchName+".gwi-issue", issue)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetConfig returns the effective value of a git config key in the given worktree, or "" if unset
+func GetConfig(path, key string) string {
+	cmd := timeout.Command("git", "config", "--get", key)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// SetWorktreeConfig sets a git config key for a single worktree, enabling
+// per-worktree configuration in the repository if necessary
+func SetWorktreeConfig(path, key, value string) error {
+	if GetConfig(path, "extensions.worktreeConfig") != "true" {
+		enable := timeout.Command("git", "config", "extensions.worktreeConfig", "true")
+		enable.Dir = path
+		if output, err := enable.CombinedOutput(); err != nil {
+			return errors.New(strings.TrimSpace(string(output)))
+		}
+	}
+
+	cmd := timeout.Command("git", "config", "--worktree", key, value)
+	cmd.Dir = path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetCommonDir returns the absolute path of the repository's shared git directory
+func GetCommonDir(path string) (string, error) {
+	cmd := timeout.Command("git", "rev-parse", "--git-common-dir")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// GetHooksDir returns the absolute path of the directory git runs hooks
+// from, which is core.hooksPath when set
+func GetHooksDir(path string) (string, error) {
+	cmd := timeout.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// GetTopLevel returns the root directory of the worktree containing path
+func GetTopLevel(path string) (string, error) {
+	cmd := timeout.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(path string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := timeout.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -179,3 +352,34 @@ func GetCurrentBranch(path string) (string, error) {
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// IsAncestor reports whether ancestor is reachable from ref
+func IsAncestor(path, ancestor, ref string) bool {
+	cmd := timeout.Command("git", "merge-base", "--is-ancestor", ancestor, ref)
+	cmd.Dir = path
+	return cmd.Run() == nil
+}
+
+// MergeTreeConflicts does an in-memory merge of branch into base and returns
+// the conflicting files. It requires git 2.38 or newer.
+func MergeTreeConflicts(path, base, branch string) ([]string, error) {
+	cmd := timeout.Command("git", "merge-tree", "--write-tree", "--name-only", "--no-messages", base, branch)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err == nil {
+		return nil, nil
+	}
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 1 {
+		return nil, errors.New("git merge-tree --write-tree is not available (requires git 2.38+)")
+	}
+
+	// Exit code 1: the first line is the tree, followed by the conflicted files
+	var files []string
+	for i, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if i > 0 && line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
@@ -5,23 +5,57 @@ import (
 	"strings"
 )
 
+// SlugOptions controls how branch name slugs are generated
+type SlugOptions struct {
+	MaxLength int      // Maximum slug length; 0 means unlimited
+	StopWords []string // Words dropped from the slug
+}
+
 // Slugify converts a string to a URL-safe slug
 func Slugify(s string) string {
-	// Convert to lowercase
-	s = strings.ToLower(s)
+	return SlugifyWith(s, SlugOptions{MaxLength: 50})
+}
+
+// SlugifyWith converts a string to a URL-safe slug, transliterating non-ASCII
+// letters and dropping stop words
+func SlugifyWith(s string, opts SlugOptions) string {
+	// Convert to lowercase ASCII
+	s = Transliterate(strings.ToLower(s))
 
 	// Replace any non-alphanumeric character with a dash
 	re := regexp.MustCompile(`[^a-z0-9]+`)
 	s = re.ReplaceAllString(s, "-")
 
+	// Drop stop words, unless that would leave nothing
+	if len(opts.StopWords) > 0 {
+		stop := make(map[string]bool, len(opts.StopWords))
+		for _, word := range opts.StopWords {
+			stop[strings.ToLower(word)] = true
+		}
+		var kept []string
+		for _, word := range strings.Split(s, "-") {
+			if word != "" && !stop[word] {
+				kept = append(kept, word)
+			}
+		}
+		if len(kept) > 0 {
+			s = strings.Join(kept, "-")
+		}
+	}
+
 	// Remove leading/trailing dashes
 	s = strings.Trim(s, "-")
 
-	// Truncate to 50 characters
-	if len(s) > 50 {
-		s = s[:50]
+	// Truncate, preferring a word boundary
+	if opts.MaxLength > 0 && len(s) > opts.MaxLength {
+		cut := s[:opts.MaxLength]
+		if s[opts.MaxLength] != '-' {
+			if i := strings.LastIndex(cut, "-"); i > opts.MaxLength/2 {
+				cut = cut[:i]
+			}
+		}
 		// Don't end with a dash
-		s = strings.TrimRight(s, "-")
+		s = strings.TrimRight(cut, "-")
 	}
 
 	return s
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// ChangedFile is a file with uncommitted changes in a worktree
+type ChangedFile struct {
+	Path     string
+	OrigPath string // Where a renamed file came from
+	Status   string // Two-letter status of git status --short, e.g. " M" or "??"
+}
+
+// Staged reports whether the file has changes in the index
+func (f ChangedFile) Staged() bool {
+	return f.Status[0] != ' ' && f.Status[0] != '?'
+}
+
+// Untracked reports whether git doesn't track the file yet
+func (f ChangedFile) Untracked() bool {
+	return f.Status == "??"
+}
+
+// ListChangedFiles returns the files with uncommitted changes, untracked
+// files included
+func ListChangedFiles(path string) ([]ChangedFile, error) {
+	cmd := timeout.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ChangedFile
+	entries := strings.Split(strings.TrimRight(string(output), "\x00"), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		file := ChangedFile{Status: entry[:2], Path: entry[3:]}
+		// Renames and copies are followed by the original path
+		if (file.Status[0] == 'R' || file.Status[0] == 'C') && i+1 < len(entries) {
+			i++
+			file.OrigPath = entries[i]
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// DiffFiles returns a binary patch of the uncommitted changes to files against
+// HEAD, like DiffWorkingTree. With interactive, git add --patch asks which
+// hunks of tracked files to include; untracked files are included whole.
+func DiffFiles(path string, files []ChangedFile, interactive bool) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "gwi-index-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+
+	var whole, hunks []string
+	for _, file := range files {
+		if interactive && !file.Untracked() && file.OrigPath == "" {
+			hunks = append(hunks, file.Path)
+			continue
+		}
+		whole = append(whole, file.Path)
+		if file.OrigPath != "" {
+			whole = append(whole, file.OrigPath)
+		}
+	}
+
+	steps := [][]string{{"read-tree", "HEAD"}}
+	if len(whole) > 0 {
+		steps = append(steps, append([]string{"add", "--all", "--"}, whole...))
+	}
+	for _, args := range steps {
+		cmd := timeout.Command("git", args...)
+		cmd.Dir = path
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(output)))
+		}
+	}
+	if len(hunks) > 0 {
+		cmd := timeout.Interactive("git", append([]string{"add", "--patch", "--"}, hunks...)...)
+		cmd.Dir = path
+		cmd.Env = env
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("git add --patch failed: %w", err)
+		}
+	}
+
+	cmd := timeout.Command("git", "diff", "--cached", "--binary", "HEAD")
+	cmd.Dir = path
+	cmd.Env = env
+	return cmd.Output()
+}
+
+// ApplyPatchThreeWay applies a patch file made against another base, falling
+// back on a three-way merge; conflicts are left in the files
+func ApplyPatchThreeWay(path, file string) error {
+	cmd := timeout.Command("git", "apply", "--binary", "--3way", file)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply --3way failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RevertPatch takes the changes of a patch file out of the working tree
+func RevertPatch(path, file string) error {
+	cmd := timeout.Command("git", "apply", "--binary", "--reverse", file)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply --reverse failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package git
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// LatestTag returns the most recent tag reachable from ref whose name starts
+// with prefix, or "" when there is none
+func LatestTag(path, ref, prefix string) string {
+	cmd := timeout.Command("git", "describe", "--tags", "--abbrev=0", "--match", prefix+"*", ref)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// TagExists reports whether a tag exists locally
+func TagExists(path, tag string) bool {
+	cmd := timeout.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+tag)
+	cmd.Dir = path
+	return cmd.Run() == nil
+}
+
+// TagTime returns when a tag was made: the tagger date of an annotated tag,
+// the commit date of a lightweight one
+func TagTime(path, tag string) (time.Time, error) {
+	cmd := timeout.Command("git", "for-each-ref", "--format=%(creatordate:unix)", "refs/tags/"+tag)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// CreateTag creates an annotated tag on ref
+func CreateTag(path, tag, ref, message string) error {
+	cmd := timeout.Command("git", "tag", "--annotate", tag, ref, "--message", message)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PushTag pushes a tag to the push remote
+func PushTag(path, tag string) error {
+	cmd := timeout.Command("git", "push", pushRemote, "refs/tags/"+tag)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetCommitMessages returns the full messages of the commits in revRange,
+// merge commits included
+func GetCommitMessages(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "log", "--format=%B%x00", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	for _, message := range strings.Split(string(output), "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// GetRangeSHAs returns the full SHAs of the commits in revRange, merge
+// commits included
+func GetRangeSHAs(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "rev-list", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
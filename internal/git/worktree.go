@@ -1,18 +1,21 @@
 package git
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/timeout"
 )
 
 // HasUncommittedChanges checks if a directory has uncommitted git changes
 func HasUncommittedChanges(path string) bool {
-	cmd := exec.Command("git", "status", "--porcelain")
+	cmd := timeout.Command("git", "status", "--porcelain")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -23,7 +26,7 @@ func HasUncommittedChanges(path string) bool {
 
 // GetStatusShort returns the short git status output
 func GetStatusShort(path string) (string, error) {
-	cmd := exec.Command("git", "status", "--short")
+	cmd := timeout.Command("git", "status", "--short")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -43,12 +46,81 @@ func IsInsideWorktree(worktreePath string) bool {
 
 // FindWorktreeByIssue finds a worktree directory by issue number
 func FindWorktreeByIssue(base string, issueNumber int) string {
-	pattern := filepath.Join(base, strconv.Itoa(issueNumber)+"-*")
-	matches, err := filepath.Glob(pattern)
-	if err != nil || len(matches) == 0 {
+	return FindWorktreeByID(base, issueid.FromNumber(issueNumber))
+}
+
+// FindWorktreeByID finds a worktree directory by issue number or project key
+func FindWorktreeByID(base string, id issueid.ID) string {
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if got, ok := issueid.FromName(entry.Name()); ok && got == id {
+			return filepath.Join(base, entry.Name())
+		}
+	}
+	return ""
+}
+
+// WorktreeInfo is an entry of git worktree list
+type WorktreeInfo struct {
+	Path       string
+	Branch     string // Empty for a detached HEAD
+	Locked     bool
+	LockReason string
+	Prunable   string // Why git worktree prune would remove the entry; empty if it wouldn't
+}
+
+// ListAllWorktrees returns every worktree of the current repository, the main worktree first
+func ListAllWorktrees() ([]WorktreeInfo, error) {
+	cmd := timeout.Command("git", "worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var worktrees []WorktreeInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			worktrees = append(worktrees, WorktreeInfo{Path: path})
+			continue
+		}
+		if len(worktrees) == 0 {
+			continue
+		}
+		wt := &worktrees[len(worktrees)-1]
+		switch attr, value, _ := strings.Cut(line, " "); attr {
+		case "branch":
+			wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "locked":
+			wt.Locked, wt.LockReason = true, value
+		case "prunable":
+			wt.Prunable = value
+			if value == "" {
+				wt.Prunable = "gitdir file points to non-existent location"
+			}
+		}
+	}
+	return worktrees, nil
+}
+
+// BranchWorktree returns the path of the worktree that has branchName checked
+// out, or "" when none has. git refuses to check out a branch in two worktrees.
+func BranchWorktree(branchName string) string {
+	worktrees, err := ListAllWorktrees()
+	if err != nil {
 		return ""
 	}
-	return matches[0]
+	for _, wt := range worktrees {
+		if wt.Branch == branchName {
+			return wt.Path
+		}
+	}
+	return ""
 }
 
 // ListWorktrees returns all worktree directories for the given base path
@@ -73,41 +145,39 @@ func ListWorktrees(base string) ([]string, error) {
 
 // DetectIssueNumber extracts issue number from current directory if inside a worktree
 func DetectIssueNumber(base string) (int, bool) {
+	id, ok := DetectIssueID(base)
+	if !ok {
+		return 0, false
+	}
+	return id.Number()
+}
+
+// DetectIssueID extracts the issue number or project key from the current
+// directory if inside a worktree
+func DetectIssueID(base string) (issueid.ID, bool) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return 0, false
+		return "", false
 	}
 
 	if !strings.HasPrefix(cwd, base) {
-		return 0, false
+		return "", false
 	}
 
 	// Get the relative path and extract the first directory component
 	rel, err := filepath.Rel(base, cwd)
 	if err != nil {
-		return 0, false
+		return "", false
 	}
 
 	// Get the first path component (the worktree directory name)
 	parts := strings.Split(rel, string(os.PathSeparator))
 	if len(parts) == 0 {
-		return 0, false
+		return "", false
 	}
-	dirname := parts[0]
 
-	// Extract issue number from directory name (e.g., "42-fix-bug")
-	re := regexp.MustCompile(`^(\d+)-`)
-	matches := re.FindStringSubmatch(dirname)
-	if matches == nil {
-		return 0, false
-	}
-
-	num, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0, false
-	}
-
-	return num, true
+	// Extract the ID from the directory name (e.g., "42-fix-bug" or "JIRA-123-fix-bug")
+	return issueid.FromName(parts[0])
 }
 
 // CreateWorktree creates a new git worktree
@@ -117,7 +187,7 @@ func CreateWorktree(path, branchName, baseBranch string) error {
 		return err
 	}
 
-	cmd := exec.Command("git", "worktree", "add", path, "-b", branchName, baseBranch)
+	cmd := timeout.Command("git", "worktree", "add", path, "-b", branchName, baseBranch)
 	cmd.Stdout = os.Stderr // Output to stderr so it doesn't interfere with path capture
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -129,7 +199,7 @@ func CreateWorktreeFromBranch(path, branchName string) error {
 		return err
 	}
 
-	cmd := exec.Command("git", "worktree", "add", path, branchName)
+	cmd := timeout.Command("git", "worktree", "add", path, branchName)
 	cmd.Stdout = os.Stderr // Output to stderr so it doesn't interfere with path capture
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -141,7 +211,7 @@ func CreateWorktreeFromRemote(path, branchName, remoteBranch string) error {
 		return err
 	}
 
-	cmd := exec.Command("git", "worktree", "add", path, "-b", branchName, remoteBranch)
+	cmd := timeout.Command("git", "worktree", "add", path, "-b", branchName, remoteBranch)
 	cmd.Stdout = os.Stderr // Output to stderr so it doesn't interfere with path capture
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
@@ -153,7 +223,7 @@ func RemoveWorktree(path string, force bool) error {
 	if force {
 		args = append(args, "--force")
 	}
-	cmd := exec.Command("git", args...)
+	cmd := timeout.Command("git", args...)
 	err := cmd.Run()
 
 	// If the worktree removal failed (e.g., exit status 128), check if the directory still exists
@@ -177,23 +247,75 @@ func RemoveWorktree(path string, force bool) error {
 
 // PruneWorktrees prunes worktrees that no longer exist
 func PruneWorktrees() (string, error) {
-	cmd := exec.Command("git", "worktree", "prune", "-v")
+	cmd := timeout.Command("git", "worktree", "prune", "-v")
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
 
-// Push pushes a branch to origin
+// Push pushes a branch to the push remote
 func Push(path, branchName string) error {
-	cmd := exec.Command("git", "push", "-u", "origin", branchName)
+	cmd := timeout.Command("git", "push", "-u", pushRemote, branchName)
 	cmd.Dir = path
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-// GetAheadBehind returns the ahead/behind counts for a branch relative to its remote
+// CountUnpushed returns the number of commits of HEAD that aren't on any remote branch
+func CountUnpushed(path string) (int, error) {
+	cmd := timeout.Command("git", "rev-list", "--count", "HEAD", "--not", "--remotes")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// CommitTime returns the committer date of the commit ref points to
+func CommitTime(path, ref string) (time.Time, error) {
+	cmd := timeout.Command("git", "log", "-1", "--format=%ct", ref, "--")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(ts, 0), nil
+}
+
+// LastActivity returns when a worktree was last worked in: the later of its
+// last commit and the last change to its HEAD (checkout, commit, reset). The
+// index isn't used, as git status rewrites it.
+func LastActivity(path string) time.Time {
+	var last time.Time
+	cmd := timeout.Command("git", "log", "-1", "--format=%ct", "HEAD")
+	cmd.Dir = path
+	if output, err := cmd.Output(); err == nil {
+		if ts, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			last = time.Unix(ts, 0)
+		}
+	}
+	cmd = timeout.Command("git", "rev-parse", "--git-path", "logs/HEAD")
+	cmd.Dir = path
+	if output, err := cmd.Output(); err == nil {
+		reflog := strings.TrimSpace(string(output))
+		if !filepath.IsAbs(reflog) {
+			reflog = filepath.Join(path, reflog)
+		}
+		if info, err := os.Stat(reflog); err == nil && info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+	return last
+}
+
+// GetAheadBehind returns the ahead/behind counts for a branch relative to the push remote
 func GetAheadBehind(path, branchName string) (ahead, behind int, err error) {
-	cmd := exec.Command("git", "rev-list", "--left-right", "--count", "origin/"+branchName+"...HEAD")
+	cmd := timeout.Command("git", "rev-list", "--left-right", "--count", PushedRef(branchName)+"...HEAD")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -210,9 +332,50 @@ func GetAheadBehind(path, branchName string) (ahead, behind int, err error) {
 	return ahead, behind, nil
 }
 
+// BranchDivergence counts the commits a local branch has that upstream
+// doesn't (ahead), and the other way around (behind)
+func BranchDivergence(path, branch, upstream string) (ahead, behind int, err error) {
+	cmd := timeout.Command("git", "rev-list", "--left-right", "--count", upstream+"...refs/heads/"+branch)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	parts := strings.Fields(strings.TrimSpace(string(output)))
+	if len(parts) != 2 {
+		return 0, 0, nil
+	}
+	behind, _ = strconv.Atoi(parts[0])
+	ahead, _ = strconv.Atoi(parts[1])
+	return ahead, behind, nil
+}
+
+// FastForwardBranch moves a local branch forward to ref. Where the branch is
+// checked out, git merge --ff-only updates that worktree, refusing to touch
+// uncommitted changes; otherwise only the branch moves.
+func FastForwardBranch(branch, ref string) error {
+	worktrees, err := ListAllWorktrees()
+	if err != nil {
+		return err
+	}
+	cmd := timeout.Command("git", "fetch", "--quiet", ".", ref+":"+branch)
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			cmd = timeout.Command("git", "merge", "--ff-only", "--quiet", ref)
+			cmd.Dir = wt.Path
+			break
+		}
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.New(strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // GetLocalBranches returns all local branch names
 func GetLocalBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
+	cmd := timeout.Command("git", "branch", "--format=%(refname:short)")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -230,7 +393,7 @@ func GetLocalBranches() ([]string, error) {
 
 // GetUncommittedCount returns the number of uncommitted changes
 func GetUncommittedCount(path string) int {
-	cmd := exec.Command("git", "status", "--short")
+	cmd := timeout.Command("git", "status", "--short")
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -243,3 +406,379 @@ func GetUncommittedCount(path string) int {
 	}
 	return len(lines)
 }
+
+// Stash is an entry in the stash list
+type Stash struct {
+	Ref     string // e.g. stash@{0}
+	Message string
+}
+
+// CommitWithHooks stages all changes, including untracked files, and commits them.
+// Unlike CommitAll, the repository's commit hooks run, with their output on stderr.
+func CommitWithHooks(path, message string) error {
+	add := timeout.Command("git", "add", "-A")
+	add.Dir = path
+	if output, err := add.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	cmd := timeout.Command("git", "commit", "-m", message)
+	cmd.Dir = path
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	return nil
+}
+
+// CommitAll stages all changes, including untracked files, and commits them without running hooks.
+// The repository's signing configuration applies; sign forces a signed commit.
+func CommitAll(path, message string, sign bool) error {
+	add := timeout.Command("git", "add", "-A")
+	add.Dir = path
+	if output, err := add.CombinedOutput(); err != nil {
+		return fmt.Errorf("git add failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	args := []string{"commit", "--no-verify", "-m", message}
+	if sign {
+		args = append(args, "-S")
+	}
+	cmd := timeout.Command("git", args...)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UndoLastCommit removes the last commit, keeping its changes in the working tree
+func UndoLastCommit(path string) error {
+	cmd := timeout.Command("git", "reset", "HEAD~1")
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetCommitMessage returns the full message of a commit in the given worktree
+func GetCommitMessage(path, ref string) (string, error) {
+	cmd := timeout.Command("git", "log", "-1", "--pretty=%B", ref)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// StashPush stashes all changes, including untracked files, under the given message
+func StashPush(path, message string) error {
+	cmd := timeout.Command("git", "stash", "push", "--include-untracked", "-m", message)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git stash failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// StashPop applies and drops a stash entry
+func StashPop(path, ref string) error {
+	cmd := timeout.Command("git", "stash", "pop", ref)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git stash pop failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ListStashes returns the repository's stash entries, newest first
+func ListStashes(path string) ([]Stash, error) {
+	cmd := timeout.Command("git", "stash", "list", "--format=%gd%x00%gs")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []Stash
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		stashes = append(stashes, Stash{Ref: parts[0], Message: parts[1]})
+	}
+	return stashes, nil
+}
+
+// UnsignedCommit is a commit without a valid signature
+type UnsignedCommit struct {
+	Hash    string
+	Subject string
+	Status  string // git's %G? code: N (none) or B (bad)
+}
+
+// GetUnsignedCommits returns the commits in revRange that are unsigned or have a bad signature
+func GetUnsignedCommits(path, revRange string) ([]UnsignedCommit, error) {
+	cmd := timeout.Command("git", "log", "--format=%h%x00%G?%x00%s", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var unsigned []UnsignedCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.SplitN(line, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if parts[1] == "N" || parts[1] == "B" {
+			unsigned = append(unsigned, UnsignedCommit{Hash: parts[0], Status: parts[1], Subject: parts[2]})
+		}
+	}
+	return unsigned, nil
+}
+
+// GetSignatureStatus returns git's signature code (%G?) for a commit
+func GetSignatureStatus(path, ref string) (string, error) {
+	cmd := timeout.Command("git", "log", "-1", "--format=%G?", ref)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetChangedFiles returns the files changed in revRange
+func GetChangedFiles(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "diff", "--name-only", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// FileStat is the number of lines added and deleted in a file
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// GetDiffStats returns the lines added and deleted per file changed in revRange
+func GetDiffStats(path, revRange string) ([]FileStat, error) {
+	cmd := timeout.Command("git", "diff", "--numstat", "--no-renames", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []FileStat
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat := FileStat{Path: fields[2], Binary: fields[0] == "-"}
+		stat.Added, _ = strconv.Atoi(fields[0])
+		stat.Deleted, _ = strconv.Atoi(fields[1])
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+// GetCommitSubjects returns the subjects of the commits in revRange, oldest first
+func GetCommitSubjects(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "log", "--reverse", "--format=%s", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			subjects = append(subjects, line)
+		}
+	}
+	return subjects, nil
+}
+
+// GetCommitSHAs returns the full hashes of the commits in revRange, oldest
+// first, leaving out merge commits
+func GetCommitSHAs(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "rev-list", "--reverse", "--no-merges", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// Commit is a commit as listed by GetCommits
+type Commit struct {
+	Hash    string // Abbreviated
+	Subject string
+	Author  string
+}
+
+// GetCommits returns the commits in revRange, oldest first
+func GetCommits(path, revRange string) ([]Commit, error) {
+	cmd := timeout.Command("git", "log", "--reverse", "--format=%h%x1f%s%x1f%an", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) == 3 {
+			commits = append(commits, Commit{Hash: fields[0], Subject: fields[1], Author: fields[2]})
+		}
+	}
+	return commits, nil
+}
+
+// GetContributors returns the authors of the commits in revRange and the
+// co-authors their Co-authored-by trailers credit, as "Name <email>", oldest
+// first and without duplicates
+func GetContributors(path, revRange string) ([]string, error) {
+	cmd := timeout.Command("git", "log", "--reverse",
+		"--format=%an <%ae>%x1f%(trailers:key=Co-authored-by,valueonly,separator=%x1f)%x00", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var contributors []string
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		for _, name := range strings.Split(entry, "\x1f") {
+			name = strings.TrimSpace(name)
+			if key := strings.ToLower(name); name != "" && !seen[key] {
+				seen[key] = true
+				contributors = append(contributors, name)
+			}
+		}
+	}
+	return contributors, nil
+}
+
+// Trailer is a "Key: value" line at the end of a commit message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// GetTrailers returns the trailers of the commits in revRange, oldest commit
+// first. Values folded over several lines are joined.
+func GetTrailers(path, revRange string) ([]Trailer, error) {
+	cmd := timeout.Command("git", "log", "--reverse",
+		"--format=%(trailers:only,unfold)%x00", revRange)
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var trailers []Trailer
+	for _, entry := range strings.Split(string(output), "\x00") {
+		for _, line := range strings.Split(entry, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if key, value = strings.TrimSpace(key), strings.TrimSpace(value); ok && key != "" && value != "" {
+				trailers = append(trailers, Trailer{Key: key, Value: value})
+			}
+		}
+	}
+	return trailers, nil
+}
+
+// InteractiveRebase runs an interactive rebase onto base in the worktree at
+// path, squashing fixup! and squash! commits automatically. The editor runs on
+// the terminal; git's output goes to stderr, as stdout is reserved for paths.
+func InteractiveRebase(path, base string) error {
+	cmd := timeout.Interactive("git", "rebase", "--interactive", "--autosquash", base)
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// ResolveCommit returns the full SHA of a commit-ish in the given worktree
+func ResolveCommit(path, ref string) (string, error) {
+	cmd := timeout.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown commit: %s", ref)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CherryPick applies commits on top of the worktree's branch, recording where they came from
+func CherryPick(path string, shas ...string) error {
+	cmd := timeout.Command("git", append([]string{"cherry-pick", "-x"}, shas...)...)
+	cmd.Dir = path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cherry-pick failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CherryPickContinue commits a cherry-pick after its conflicts were resolved
+func CherryPickContinue(path string) error {
+	if err := timeout.Command("git", "-C", path, "add", "-u").Run(); err != nil {
+		return err
+	}
+	cmd := timeout.Command("git", "cherry-pick", "--continue")
+	cmd.Dir = path
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("cherry-pick --continue failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CherryPickAbort cancels an in-progress cherry-pick
+func CherryPickAbort(path string) error {
+	cmd := timeout.Command("git", "cherry-pick", "--abort")
+	cmd.Dir = path
+	return cmd.Run()
+}
+
+// GetConflictedFiles returns the files with unresolved merge conflicts
+func GetConflictedFiles(path string) []string {
+	files, _ := GetChangedFiles(path, "--diff-filter=U")
+	return files
+}
+
+// HasConflictMarkers reports whether a file still contains conflict markers
+func HasConflictMarkers(path, file string) bool {
+	data, err := os.ReadFile(filepath.Join(path, file))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "\n<<<<<<< ") || strings.HasPrefix(string(data), "<<<<<<< ")
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// WorkflowRun is a GitHub Actions workflow run
+type WorkflowRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// WorkflowJob is a job of a workflow run; jobs show up as checks on the PR
+type WorkflowJob struct {
+	ID         int64  `json:"id"`
+	RunID      int64  `json:"run_id"`
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+}
+
+// Failed reports whether the run finished unsuccessfully
+func (r WorkflowRun) Failed() bool {
+	return isFailedConclusion(r.Conclusion)
+}
+
+// Failed reports whether the job finished unsuccessfully
+func (j WorkflowJob) Failed() bool {
+	return isFailedConclusion(j.Conclusion)
+}
+
+func isFailedConclusion(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
+
+// GetPRHeadSHA returns the commit a pull request's checks run on
+func GetPRHeadSHA(prNumber int) (string, error) {
+	output, err := cachedOutput("pr", "view", strconv.Itoa(prNumber), "--json", "headRefOid", "--jq", ".headRefOid")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ListWorkflowRuns returns the workflow runs for a commit of the current repository
+func ListWorkflowRuns(sha string) ([]WorkflowRun, error) {
+	var runs []WorkflowRun
+	err := ghAPIList(fmt.Sprintf("repos/{owner}/{repo}/actions/runs?head_sha=%s", sha), ".workflow_runs[]", func(d *json.Decoder) error {
+		var run WorkflowRun
+		if err := d.Decode(&run); err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		return nil
+	})
+	return runs, err
+}
+
+// ListWorkflowJobs returns the jobs of the latest attempt of a workflow run
+func ListWorkflowJobs(runID int64) ([]WorkflowJob, error) {
+	var jobs []WorkflowJob
+	err := ghAPIList(fmt.Sprintf("repos/{owner}/{repo}/actions/runs/%d/jobs", runID), ".jobs[]", func(d *json.Decoder) error {
+		var job WorkflowJob
+		if err := d.Decode(&job); err != nil {
+			return err
+		}
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, err
+}
+
+// RerunFailedJobs re-runs the failed jobs of a workflow run
+func RerunFailedJobs(runID int64) error {
+	return ghAPIPost(fmt.Sprintf("repos/{owner}/{repo}/actions/runs/%d/rerun-failed-jobs", runID))
+}
+
+// RerunJob re-runs a single job (and the jobs depending on it)
+func RerunJob(jobID int64) error {
+	return ghAPIPost(fmt.Sprintf("repos/{owner}/{repo}/actions/jobs/%d/rerun", jobID))
+}
+
+// ghAPIList fetches all pages of a REST endpoint and decodes the items selected by jq
+func ghAPIList(endpoint, jq string, decode func(d *json.Decoder) error) error {
+	output, err := cachedOutput("api", "--paginate", endpoint, "--jq", jq)
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("failed to fetch %s: %s", endpoint, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(output)))
+	for {
+		if err := decode(decoder); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func ghAPIPost(endpoint string) error {
+	cmd := Command("api", "--method", "POST", endpoint)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnsureAttachmentRelease creates the prerelease attachments are uploaded to,
+// tagging the default branch, unless it exists
+func EnsureAttachmentRelease(tag string) error {
+	if err := Command("release", "view", tag, "--json", "tagName").Run(); err == nil {
+		return nil
+	}
+	output, err := Command("release", "create", tag, "--prerelease",
+		"--title", "Attachments",
+		"--notes", "Screenshots and other files attached to pull requests and issues with gwi attach.").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to create release %s: %s", tag, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// UploadReleaseAsset uploads a file as an asset of a release under the given
+// name and returns its download URL
+func UploadReleaseAsset(tag, file, name string) (string, error) {
+	dir, err := os.MkdirTemp("", "gwi-attach-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	upload := filepath.Join(dir, name)
+	if err := copyFile(file, upload); err != nil {
+		return "", err
+	}
+
+	if output, err := Command("release", "upload", tag, upload).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %s", filepath.Base(file), strings.TrimSpace(string(output)))
+	}
+
+	output, err := Command("release", "view", tag, "--json", "assets").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the assets of release %s: %w", tag, err)
+	}
+	var release struct {
+		Assets []struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"assets"`
+	}
+	if err := json.Unmarshal(output, &release); err != nil {
+		return "", fmt.Errorf("failed to parse release assets: %w", err)
+	}
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			return asset.URL, nil
+		}
+	}
+	return "", fmt.Errorf("uploaded %s, but it isn't among the assets of release %s", name, tag)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// GetPRBody returns the description of a pull request
+func GetPRBody(prNumber int) (string, error) {
+	output, err := Command("pr", "view", strconv.Itoa(prNumber), "--json", "body", "--jq", ".body").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// EditPRBody replaces the description of a pull request
+func EditPRBody(prNumber int, body string) error {
+	return editBody("pr", prNumber, body)
+}
+
+// EditIssueBody replaces the description of an issue
+func EditIssueBody(issueNumber int, body string) error {
+	return editBody("issue", issueNumber, body)
+}
+
+func editBody(kind string, number int, body string) error {
+	output, err := Command(kind, "edit", strconv.Itoa(number), "--body", body).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to edit #%d: %s", number, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -3,17 +3,52 @@ package github
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/errs"
 )
 
 // Issue represents a GitHub issue
 type Issue struct {
-	Number        int    `json:"number"`
-	Title         string `json:"title"`
-	State         string `json:"state"`
-	ProjectStatus string // Status in GitHub Projects (e.g., "In Progress")
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"`
+	State         string    `json:"state"`
+	Labels        []Label   `json:"labels"`
+	Author        User      `json:"author"`
+	Assignees     []User    `json:"assignees"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	ClosedAt      time.Time `json:"closedAt"`
+	StateReason   string    `json:"stateReason"` // COMPLETED or NOT_PLANNED for closed issues
+	ProjectStatus string    // Status in GitHub Projects (e.g., "In Progress")
+	ThumbsUp      int       // 👍 reactions, from ListOpenIssuesWithStatus
+	Comments      int       // Number of comments, from ListOpenIssuesWithStatus
+}
+
+// User is a GitHub account
+type User struct {
+	Login string `json:"login"`
+}
+
+// IsAssignedTo reports whether login is one of the issue's assignees
+func (i Issue) IsAssignedTo(login string) bool {
+	for _, assignee := range i.Assignees {
+		if strings.EqualFold(assignee.Login, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// Label is an issue label with its hex color (without "#")
+type Label struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
 }
 
 // PullRequest represents a GitHub pull request
@@ -23,30 +58,87 @@ type PullRequest struct {
 	Mergeable         string        `json:"mergeable"`
 	MergeStateStatus  string        `json:"mergeStateStatus"`
 	HeadRefName       string        `json:"headRefName"`
+	HeadRefOid        string        `json:"headRefOid"`        // Commit the checks and reviews are of
+	IsCrossRepository bool          `json:"isCrossRepository"` // Opened from a fork
+	ReviewDecision    string        `json:"reviewDecision"`    // APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED or empty
+	LatestReviews     []Review      `json:"latestReviews"`
 	StatusCheckRollup []CheckStatus `json:"statusCheckRollup"`
 }
 
-// CheckStatus represents a CI check status
+// Review is a reviewer's latest review of a PR
+type Review struct {
+	State string `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, ...
+}
+
+// Approved reports whether the PR is approved. Without required reviews
+// GitHub reports no review decision, so the latest reviews decide.
+func (pr *PullRequest) Approved() bool {
+	if pr.ReviewDecision != "" {
+		return pr.ReviewDecision == "APPROVED"
+	}
+	approved := false
+	for _, review := range pr.LatestReviews {
+		switch review.State {
+		case "CHANGES_REQUESTED":
+			return false
+		case "APPROVED":
+			approved = true
+		}
+	}
+	return approved
+}
+
+// CheckStatus represents a CI check status: a check run (Name, Status,
+// Conclusion) or a commit status (Context, State)
 type CheckStatus struct {
 	Name       string `json:"name"`
+	Status     string `json:"status"`
 	Conclusion string `json:"conclusion"`
+	Context    string `json:"context"`
+	State      string `json:"state"`
+}
+
+// Label returns the name the check is shown under
+func (c CheckStatus) Label() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Context
+}
+
+// Pending reports whether the check hasn't finished yet
+func (c CheckStatus) Pending() bool {
+	if c.Context != "" {
+		return c.State == "PENDING" || c.State == "EXPECTED"
+	}
+	return c.Status != "COMPLETED"
+}
+
+// Passed reports whether the check finished without failing
+func (c CheckStatus) Passed() bool {
+	if c.Context != "" {
+		return c.State == "SUCCESS"
+	}
+	switch c.Conclusion {
+	case "SUCCESS", "NEUTRAL", "SKIPPED":
+		return true
+	}
+	return false
 }
 
 // CheckAuth verifies that gh is authenticated
 func CheckAuth() error {
-	cmd := exec.Command("gh", "auth", "status")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("GitHub CLI not authenticated. Run: gh auth login")
+	if _, err := cachedOutput("auth", "status"); err != nil {
+		return errs.Auth("GitHub CLI not authenticated. Run: gh auth login")
 	}
 	return nil
 }
 
 // GetIssue fetches an issue by number
 func GetIssue(issueNumber int) (*Issue, error) {
-	cmd := exec.Command("gh", "issue", "view", strconv.Itoa(issueNumber), "--json", "number,title,state")
-	output, err := cmd.Output()
+	output, err := cachedOutput("issue", "view", strconv.Itoa(issueNumber), "--json", "number,title,body,state,stateReason,labels,assignees")
 	if err != nil {
-		return nil, fmt.Errorf("issue #%d not found", issueNumber)
+		return nil, errs.NotFound("issue #%d not found", issueNumber)
 	}
 
 	var issue Issue
@@ -56,10 +148,14 @@ func GetIssue(issueNumber int) (*Issue, error) {
 	return &issue, nil
 }
 
-// ListOpenIssues lists open issues for the current repository
-func ListOpenIssues(limit int) ([]Issue, error) {
-	cmd := exec.Command("gh", "issue", "list", "--state", "open", "--limit", strconv.Itoa(limit), "--json", "number,title")
-	output, err := cmd.Output()
+// ListOpenIssues lists open issues for the current repository, in the order
+// of the search qualifier order ("" for newest first)
+func ListOpenIssues(limit int, order string) ([]Issue, error) {
+	args := []string{"issue", "list", "--state", "open", "--limit", strconv.Itoa(limit), "--json", "number,title,labels,author,assignees"}
+	if order != "" {
+		args = append(args, "--search", order)
+	}
+	output, err := cachedOutput(args...)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +167,51 @@ func ListOpenIssues(limit int) ([]Issue, error) {
 	return issues, nil
 }
 
-// CreatePR creates a pull request
-func CreatePR(path, title, body, branchName string) (string, error) {
-	cmd := exec.Command("gh", "pr", "create",
+// ListIssueLabels returns the labels of open issues in the current repository by issue number
+func ListIssueLabels(limit int) (map[int][]Label, error) {
+	issues, err := ListOpenIssues(limit, "")
+	if err != nil {
+		return nil, err
+	}
+	labels := make(map[int][]Label, len(issues))
+	for _, issue := range issues {
+		labels[issue.Number] = issue.Labels
+	}
+	return labels, nil
+}
+
+// CreateIssue opens an issue in the current repository and returns its number
+func CreateIssue(title, body string, labels ...string) (int, error) {
+	args := []string{"issue", "create", "--title", title, "--body", body}
+	if len(labels) > 0 {
+		args = append(args, "--label", strings.Join(labels, ","))
+	}
+	output, err := Command(args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return 0, fmt.Errorf("failed to create issue: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return 0, err
+	}
+	url := strings.TrimSpace(string(output))
+	number, err := strconv.Atoi(url[strings.LastIndex(url, "/")+1:])
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output from gh issue create: %s", url)
+	}
+	return number, nil
+}
+
+// CreatePR creates a pull request, against the repository's default branch
+// unless base is set
+func CreatePR(path, title, body, branchName, base string) (string, error) {
+	args := []string{"pr", "create",
 		"--title", title,
 		"--body", body,
-		"--head", branchName)
+		"--head", branchName}
+	if base != "" {
+		args = append(args, "--base", base)
+	}
+	cmd := Command(args...)
 	cmd.Dir = path
 	output, err := cmd.Output()
 	if err != nil {
@@ -88,10 +223,88 @@ func CreatePR(path, title, body, branchName string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ListClosedIssues lists the most recently closed issues of the current
+// repository, with when and why they were closed
+func ListClosedIssues(limit int) ([]Issue, error) {
+	output, err := cachedOutput("issue", "list", "--state", "closed", "--limit", strconv.Itoa(limit), "--json", "number,title,labels,closedAt,stateReason")
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	if err := json.Unmarshal(output, &issues); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// MergedPR is a merged pull request with the issues it closed
+type MergedPR struct {
+	Number      int    `json:"number"`
+	HeadRefOid  string `json:"headRefOid"`
+	MergeCommit struct {
+		Oid string `json:"oid"`
+	} `json:"mergeCommit"`
+	ClosingIssuesReferences []struct {
+		Number     int `json:"number"`
+		Repository struct {
+			Name  string `json:"name"`
+			Owner User   `json:"owner"`
+		} `json:"repository"`
+	} `json:"closingIssuesReferences"`
+}
+
+// ClosedIssues returns the numbers of the issues of repository owner/repo the
+// PR closed when it was merged
+func (pr MergedPR) ClosedIssues(owner, repo string) []int {
+	var numbers []int
+	for _, ref := range pr.ClosingIssuesReferences {
+		if strings.EqualFold(ref.Repository.Owner.Login, owner) && strings.EqualFold(ref.Repository.Name, repo) {
+			numbers = append(numbers, ref.Number)
+		}
+	}
+	return numbers
+}
+
+// ListMergedPRs lists the most recently merged pull requests into base
+func ListMergedPRs(base string, limit int) ([]MergedPR, error) {
+	output, err := cachedOutput("pr", "list", "--state", "merged", "--base", base, "--limit", strconv.Itoa(limit),
+		"--json", "number,headRefOid,mergeCommit,closingIssuesReferences")
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []MergedPR
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// CreateRelease publishes a GitHub Release for an existing tag and returns its URL
+func CreateRelease(tag, title, notes string) (string, error) {
+	output, err := Command("release", "create", tag, "--title", title, "--notes", notes, "--verify-tag").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("failed to create release: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AddPRLabels adds labels to a pull request (number or URL)
+func AddPRLabels(pr string, labels []string) error {
+	output, err := Command("pr", "edit", pr, "--add-label", strings.Join(labels, ",")).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to label PR: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // GetPRForBranch gets the PR number for a branch
 func GetPRForBranch(branchName string) (int, error) {
-	cmd := exec.Command("gh", "pr", "list", "--head", branchName, "--json", "number", "--jq", ".[0].number")
-	output, err := cmd.Output()
+	output, err := cachedOutput("pr", "list", "--head", branchName, "--json", "number", "--jq", ".[0].number")
 	if err != nil {
 		return 0, err
 	}
@@ -104,11 +317,47 @@ func GetPRForBranch(branchName string) (int, error) {
 	return strconv.Atoi(numStr)
 }
 
+// GetMergedPRForBranch gets the number of a merged PR for a branch
+func GetMergedPRForBranch(branchName string) (int, error) {
+	output, err := cachedOutput("pr", "list", "--head", branchName, "--state", "merged", "--json", "number", "--jq", ".[0].number")
+	if err != nil {
+		return 0, err
+	}
+
+	numStr := strings.TrimSpace(string(output))
+	if numStr == "" {
+		return 0, fmt.Errorf("no merged PR found for branch: %s", branchName)
+	}
+
+	return strconv.Atoi(numStr)
+}
+
+// GetPRURL returns the web URL of a pull request
+func GetPRURL(prNumber int) (string, error) {
+	output, err := cachedOutput("pr", "view", strconv.Itoa(prNumber), "--json", "url", "--jq", ".url")
+	if err != nil {
+		return "", fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// HasPushAccess reports whether the current user can push to the current repository
+func HasPushAccess() (bool, error) {
+	output, err := cachedOutput("repo", "view", "--json", "viewerPermission", "--jq", ".viewerPermission")
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(string(output)) {
+	case "ADMIN", "MAINTAIN", "WRITE":
+		return true, nil
+	}
+	return false, nil
+}
+
 // GetPRStatus gets the status of a PR
 func GetPRStatus(prNumber int) (*PullRequest, error) {
-	cmd := exec.Command("gh", "pr", "view", strconv.Itoa(prNumber),
-		"--json", "mergeable,mergeStateStatus,statusCheckRollup,state,headRefName")
-	output, err := cmd.Output()
+	output, err := cachedOutput("pr", "view", strconv.Itoa(prNumber),
+		"--json", "mergeable,mergeStateStatus,statusCheckRollup,state,headRefName,headRefOid,isCrossRepository,reviewDecision,latestReviews")
 	if err != nil {
 		return nil, err
 	}
@@ -121,10 +370,28 @@ func GetPRStatus(prNumber int) (*PullRequest, error) {
 	return &pr, nil
 }
 
+// WaitForMergeable returns the PR's mergeable state (MERGEABLE or CONFLICTING).
+// GitHub computes it lazily and reports UNKNOWN until it is ready, so this
+// polls with exponential backoff and returns UNKNOWN only after giving up.
+func WaitForMergeable(prNumber int, attempts int) (string, error) {
+	delay := time.Second
+	for i := 0; ; i++ {
+		pr, err := GetPRStatus(prNumber)
+		if err != nil {
+			return "", err
+		}
+		if pr.Mergeable != "UNKNOWN" || i+1 >= attempts {
+			return pr.Mergeable, nil
+		}
+		time.Sleep(delay)
+		Refresh()
+		delay *= 2
+	}
+}
+
 // GetPRState gets just the state of a PR
 func GetPRState(prNumber int) (string, error) {
-	cmd := exec.Command("gh", "pr", "view", strconv.Itoa(prNumber), "--json", "state", "--jq", ".state")
-	output, err := cmd.Output()
+	output, err := cachedOutput("pr", "view", strconv.Itoa(prNumber), "--json", "state", "--jq", ".state")
 	if err != nil {
 		return "", err
 	}
@@ -133,22 +400,112 @@ func GetPRState(prNumber int) (string, error) {
 
 // MergePR merges a pull request
 func MergePR(prNumber int, strategy string) error {
-	cmd := exec.Command("gh", "pr", "merge", strconv.Itoa(prNumber), "--"+strategy, "--delete-branch")
+	cmd := Command("pr", "merge", strconv.Itoa(prNumber), "--"+strategy, "--delete-branch")
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	return cmd.Run()
 }
 
+// OpenPRInBrowser opens a pull request (number or URL) in the web browser
+func OpenPRInBrowser(pr string) error {
+	cmd := Command("pr", "view", pr, "--web")
+	return cmd.Run()
+}
+
+// OpenCommitInBrowser opens a commit of the current repository in the web browser
+func OpenCommitInBrowser(sha string) error {
+	cmd := Command("browse", sha)
+	return cmd.Run()
+}
+
+// RepoURL returns the web URL of the repository gh resolves for the current
+// directory, which carries the host for GitHub Enterprise
+func RepoURL() (string, error) {
+	output, err := cachedOutput("repo", "view", "--json", "url", "--jq", ".url")
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository URL: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitURL returns the web URL of a commit in the current repository
+func CommitURL(sha string) (string, error) {
+	repoURL, err := RepoURL()
+	if err != nil {
+		return "", err
+	}
+	return repoURL + "/commit/" + sha, nil
+}
+
 // CommentOnIssue adds a comment to an issue
 func CommentOnIssue(issueNumber int, body string) error {
-	cmd := exec.Command("gh", "issue", "comment", strconv.Itoa(issueNumber), "--body", body)
+	cmd := Command("issue", "comment", strconv.Itoa(issueNumber), "--body", body)
 	return cmd.Run()
 }
 
+// IssueComment is a comment on an issue
+type IssueComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// FindIssueComment returns the first comment on an issue whose body contains
+// marker, or nil when there is none
+func FindIssueComment(issueNumber int, marker string) (*IssueComment, error) {
+	var found *IssueComment
+	err := ghAPIList(fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments", issueNumber), ".[]", func(d *json.Decoder) error {
+		var comment IssueComment
+		if err := d.Decode(&comment); err != nil {
+			return err
+		}
+		if found == nil && strings.Contains(comment.Body, marker) {
+			found = &comment
+		}
+		return nil
+	})
+	return found, err
+}
+
+// EditIssueComment replaces the body of an issue comment
+func EditIssueComment(id int64, body string) error {
+	cmd := Command("api", "--method", "PATCH", fmt.Sprintf("repos/{owner}/{repo}/issues/comments/%d", id), "-f", "body="+body)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to edit comment: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ReassignIssue assigns an issue to login ("@me" for the authenticated user),
+// removing the given assignees
+func ReassignIssue(issueNumber int, login string, remove []string) error {
+	args := []string{"issue", "edit", strconv.Itoa(issueNumber), "--add-assignee", login}
+	if len(remove) > 0 {
+		args = append(args, "--remove-assignee", strings.Join(remove, ","))
+	}
+	output, err := Command(args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to reassign issue: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // ListOpenPRs lists open PRs with branch info
 func ListOpenPRs() ([]PullRequest, error) {
-	cmd := exec.Command("gh", "pr", "list", "--state", "open", "--json", "number,headRefName")
-	output, err := cmd.Output()
+	output, err := cachedOutput("pr", "list", "--state", "open", "--json", "number,headRefName")
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PullRequest
+	if err := json.Unmarshal(output, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// ListOpenPRsWithChecks lists the open pull requests with their check results
+func ListOpenPRsWithChecks() ([]PullRequest, error) {
+	output, err := cachedOutput("pr", "list", "--state", "open", "--json", "number,headRefName,statusCheckRollup")
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +535,7 @@ func CloseIssue(issueNumber int, comment string) error {
 			return fmt.Errorf("failed to add comment: %w", err)
 		}
 	}
-	cmd := exec.Command("gh", "issue", "close", strconv.Itoa(issueNumber))
+	cmd := Command("issue", "close", strconv.Itoa(issueNumber))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to close issue: %s", strings.TrimSpace(string(output)))
@@ -195,44 +552,59 @@ func IsPRMerged(prNumber int) (bool, error) {
 	return state == "MERGED", nil
 }
 
-// ListOpenIssuesWithStatus lists open issues with their project status
-func ListOpenIssuesWithStatus(limit int, statusFieldName string) ([]Issue, error) {
-	// First get the basic issue list
-	issues, err := ListOpenIssues(limit)
-	if err != nil {
-		return nil, err
-	}
+// IssueSearchSorts are the search qualifiers ordering issue lists, by the
+// name of the order
+var IssueSearchSorts = map[string]string{
+	"newest":    "sort:created-desc",
+	"oldest":    "sort:created-asc",
+	"updated":   "sort:updated-desc",
+	"reactions": "sort:reactions-+1-desc",
+	"comments":  "sort:comments-desc",
+}
 
-	// Get current repository info
-	repoCmd := exec.Command("gh", "repo", "view", "--json", "owner,name")
-	repoOutput, err := repoCmd.Output()
+// ListOpenIssuesWithStatus lists the first limit open issues in the order of
+// the search qualifier order ("" for newest first), with their project status,
+// author, assignees and 👍 and comment counts. The order and the counts come
+// from the same search.
+func ListOpenIssuesWithStatus(limit int, statusFieldName, order string) ([]Issue, error) {
+	owner, repo, err := CurrentRepo()
 	if err != nil {
-		// If we can't get repo info, just return issues without status
-		return issues, nil
-	}
-
-	var repoInfo struct {
-		Owner struct {
-			Login string `json:"login"`
-		} `json:"owner"`
-		Name string `json:"name"`
-	}
-	if err := json.Unmarshal(repoOutput, &repoInfo); err != nil {
-		return issues, nil
+		// Without repo info, just return issues without status
+		return ListOpenIssues(limit, order)
 	}
 
-	// Query to get all issues with their project items and status
 	query := `
-		query($owner: String!, $repo: String!, $limit: Int!) {
-			repository(owner: $owner, name: $repo) {
-				issues(first: $limit, states: OPEN, orderBy: {field: UPDATED_AT, direction: DESC}) {
-					nodes {
+		query($search: String!, $limit: Int!, $field: String!) {
+			search(query: $search, type: ISSUE, first: $limit) {
+				nodes {
+					... on Issue {
 						number
 						title
 						state
+						updatedAt
+						labels(first: 20) {
+							nodes {
+								name
+								color
+							}
+						}
+						author {
+							login
+						}
+						assignees(first: 10) {
+							nodes {
+								login
+							}
+						}
+						reactions(content: THUMBS_UP) {
+							totalCount
+						}
+						comments {
+							totalCount
+						}
 						projectItems(first: 10) {
 							nodes {
-								fieldValueByName(name: "%s") {
+								fieldValueByName(name: $field) {
 									... on ProjectV2ItemFieldSingleSelectValue {
 										name
 									}
@@ -245,65 +617,183 @@ func ListOpenIssuesWithStatus(limit int, statusFieldName string) ([]Issue, error
 		}
 	`
 
-	// Format query with status field name
-	formattedQuery := fmt.Sprintf(query, statusFieldName)
-
-	cmd := exec.Command("gh", "api", "graphql",
-		"-f", "query="+formattedQuery,
-		"-f", "owner="+repoInfo.Owner.Login,
-		"-f", "repo="+repoInfo.Name,
-		"-F", fmt.Sprintf("limit=%d", limit))
-
-	output, err := cmd.Output()
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "search="+strings.TrimSpace(fmt.Sprintf("repo:%s/%s is:issue is:open %s", owner, repo, order)),
+		"-F", "limit="+strconv.Itoa(limit),
+		"-f", "field="+statusFieldName,
+		"--jq", ".data.search.nodes")
 	if err != nil {
 		// If GraphQL fails, return basic issues
-		return issues, nil
-	}
-
-	// Parse the GraphQL response
-	var response struct {
-		Data struct {
-			Repository struct {
-				Issues struct {
-					Nodes []struct {
-						Number       int    `json:"number"`
-						Title        string `json:"title"`
-						State        string `json:"state"`
-						ProjectItems struct {
-							Nodes []struct {
-								FieldValueByName struct {
-									Name string `json:"name"`
-								} `json:"fieldValueByName"`
-							} `json:"nodes"`
-						} `json:"projectItems"`
-					} `json:"nodes"`
-				} `json:"issues"`
-			} `json:"repository"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(output, &response); err != nil {
-		return issues, nil
-	}
-
-	// Create a map to store project status by issue number
-	statusMap := make(map[int]string)
-	for _, node := range response.Data.Repository.Issues.Nodes {
+		return ListOpenIssues(limit, order)
+	}
+
+	var nodes []struct {
+		Number    int       `json:"number"`
+		Title     string    `json:"title"`
+		State     string    `json:"state"`
+		UpdatedAt time.Time `json:"updatedAt"`
+		Labels    struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+		Author    User `json:"author"`
+		Assignees struct {
+			Nodes []User `json:"nodes"`
+		} `json:"assignees"`
+		Reactions struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"reactions"`
+		Comments struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"comments"`
+		ProjectItems struct {
+			Nodes []struct {
+				FieldValueByName struct {
+					Name string `json:"name"`
+				} `json:"fieldValueByName"`
+			} `json:"nodes"`
+		} `json:"projectItems"`
+	}
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return ListOpenIssues(limit, order)
+	}
+
+	issues := make([]Issue, 0, len(nodes))
+	for _, node := range nodes {
+		issue := Issue{
+			Number:    node.Number,
+			Title:     node.Title,
+			State:     node.State,
+			Labels:    node.Labels.Nodes,
+			Author:    node.Author,
+			Assignees: node.Assignees.Nodes,
+			UpdatedAt: node.UpdatedAt,
+			ThumbsUp:  node.Reactions.TotalCount,
+			Comments:  node.Comments.TotalCount,
+		}
 		if len(node.ProjectItems.Nodes) > 0 {
 			// Use the first project's status
-			status := node.ProjectItems.Nodes[0].FieldValueByName.Name
-			if status != "" {
-				statusMap[node.Number] = status
+			issue.ProjectStatus = node.ProjectItems.Nodes[0].FieldValueByName.Name
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// ListMyIssues lists the open issues assigned to or mentioning the
+// authenticated user, most recently updated first
+func ListMyIssues(limit int) ([]Issue, error) {
+	seen := make(map[int]bool)
+	var issues []Issue
+	for _, filter := range []string{"--assignee", "--mention"} {
+		output, err := cachedOutput("issue", "list", "--state", "open", filter, "@me", "--limit", strconv.Itoa(limit),
+			"--json", "number,title,state,labels,author,assignees,updatedAt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		var found []Issue
+		if err := json.Unmarshal(output, &found); err != nil {
+			return nil, err
+		}
+		for _, issue := range found {
+			if !seen[issue.Number] {
+				seen[issue.Number] = true
+				issues = append(issues, issue)
 			}
 		}
 	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].UpdatedAt.After(issues[j].UpdatedAt)
+	})
+	return issues, nil
+}
 
-	// Update issues with their project status
-	for i := range issues {
-		if status, ok := statusMap[issues[i].Number]; ok {
-			issues[i].ProjectStatus = status
+// UnsubscribeFromIssue stops notifications about an issue for the
+// authenticated user
+func UnsubscribeFromIssue(owner, repo string, issueNumber int) error {
+	id, err := GetIssueNodeID(owner, repo, issueNumber)
+	if err != nil {
+		return err
+	}
+	query := `
+		mutation($id: ID!) {
+			updateSubscription(input: {subscribableId: $id, state: UNSUBSCRIBED}) {
+				subscribable {
+					viewerSubscription
+				}
+			}
 		}
+	`
+	output, err := Command("api", "graphql", "-f", "query="+query, "-f", "id="+id).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe: %s", strings.TrimSpace(string(output)))
 	}
+	return nil
+}
 
-	return issues, nil
+// CurrentUser returns the login of the authenticated GitHub user
+func CurrentUser() (string, error) {
+	output, err := cachedOutput("api", "user", "--jq", ".login")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetPRTitle returns the title of a pull request (number or URL)
+func GetPRTitle(pr string) (string, error) {
+	output, err := cachedOutput("pr", "view", pr, "--json", "title", "--jq", ".title")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// EditPRTitle changes the title of a pull request (number or URL)
+func EditPRTitle(pr, title string) error {
+	cmd := Command("pr", "edit", pr, "--title", title)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RequestReviewers requests reviews on a pull request (number or URL) from users or org/team slugs
+func RequestReviewers(pr string, reviewers []string) error {
+	cmd := Command("pr", "edit", pr, "--add-reviewer", strings.Join(reviewers, ","))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// GetPRDiff returns the diff of a pull request as GitHub shows it
+func GetPRDiff(prNumber int) ([]byte, error) {
+	output, err := cachedOutput("pr", "diff", strconv.Itoa(prNumber), "--color", "never")
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to get diff of PR #%d: %s", prNumber, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// ShowPRDiff shows the diff of a pull request on the terminal, through gh's
+// pager or the given one
+func ShowPRDiff(prNumber int, nameOnly bool, pager string) error {
+	args := []string{"pr", "diff", strconv.Itoa(prNumber)}
+	if nameOnly {
+		args = append(args, "--name-only")
+	}
+	cmd := InteractiveCommand(args...)
+	if pager != "" {
+		cmd.Env = append(os.Environ(), "GH_PAGER="+pager)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
 }
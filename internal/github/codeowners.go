@@ -0,0 +1,112 @@
+package github
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeownersLocations are checked in the order GitHub uses
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeownersRule is a single CODEOWNERS line
+type CodeownersRule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// Codeowners holds the parsed rules of a CODEOWNERS file
+type Codeowners struct {
+	Path  string
+	Rules []CodeownersRule
+}
+
+// LoadCodeowners reads the CODEOWNERS file of a worktree. It returns nil when
+// the repository has none.
+func LoadCodeowners(root string) (*Codeowners, error) {
+	for _, location := range codeownersLocations {
+		path := filepath.Join(root, location)
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		co := &Codeowners{Path: location}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if i := strings.Index(line, "#"); i >= 0 {
+				line = line[:i]
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			re, err := codeownersRegexp(fields[0])
+			if err != nil {
+				continue
+			}
+			co.Rules = append(co.Rules, CodeownersRule{Pattern: fields[0], Owners: fields[1:], re: re})
+		}
+		return co, scanner.Err()
+	}
+	return nil, nil
+}
+
+// Match returns the rule that applies to a file. As on GitHub, the last
+// matching line wins; nil means the file has no owner.
+func (co *Codeowners) Match(file string) *CodeownersRule {
+	for i := len(co.Rules) - 1; i >= 0; i-- {
+		if co.Rules[i].re.MatchString(file) {
+			return &co.Rules[i]
+		}
+	}
+	return nil
+}
+
+// codeownersRegexp translates a gitignore-style CODEOWNERS pattern into a regexp
+func codeownersRegexp(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	p := strings.TrimSuffix(pattern, "/")
+	// Patterns containing a slash are relative to the repository root
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	// A matching directory owns everything below it, except for "dir/*"
+	// which GitHub limits to the directory's direct children
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,229 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/errs"
+)
+
+// IssueRef names an issue, such as the epic another issue belongs to
+type IssueRef struct {
+	Number     int    `json:"number"`
+	Title      string `json:"title"`
+	Repository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"` // Empty for the current repository once listed
+}
+
+// Ref is how the issue is referred to: #12, or acme/other#12 when it is in
+// another repository
+func (r IssueRef) Ref() string {
+	return r.Repository.NameWithOwner + "#" + strconv.Itoa(r.Number)
+}
+
+// sameRepo reports whether nameWithOwner is the repository owner/repo
+func sameRepo(nameWithOwner, owner, repo string) bool {
+	return strings.EqualFold(nameWithOwner, owner+"/"+repo)
+}
+
+// issueRelations are the fields an issue relates to its epic by: GitHub's
+// sub-issue parent, or a tasklist tracking it
+type issueRelations struct {
+	Parent          *IssueRef `json:"parent"`
+	TrackedInIssues struct {
+		Nodes []IssueRef `json:"nodes"`
+	} `json:"trackedInIssues"`
+}
+
+// epic returns the issue's parent, or else the first issue tracking it
+func (r issueRelations) epic() (IssueRef, bool) {
+	if r.Parent != nil && r.Parent.Number != 0 {
+		return *r.Parent, true
+	}
+	if len(r.TrackedInIssues.Nodes) > 0 {
+		return r.TrackedInIssues.Nodes[0], true
+	}
+	return IssueRef{}, false
+}
+
+// ListIssueParents returns the epics of the repository's open issues that
+// belong to one, by issue number
+func ListIssueParents(limit int) (map[int]IssueRef, error) {
+	owner, repo, err := CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $limit: Int!) {
+			repository(owner: $owner, name: $repo) {
+				issues(first: $limit, states: OPEN, orderBy: {field: UPDATED_AT, direction: DESC}) {
+					nodes {
+						number
+						parent {
+							...ref
+						}
+						trackedInIssues(first: 1) {
+							nodes {
+								...ref
+							}
+						}
+					}
+				}
+			}
+		}
+
+		fragment ref on Issue {
+			number
+			title
+			repository {
+				nameWithOwner
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
+		"-F", "limit="+strconv.Itoa(limit),
+		"--jq", ".data.repository.issues.nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the epics of issues: %v", err)
+	}
+
+	var nodes []struct {
+		Number int `json:"number"`
+		issueRelations
+	}
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to parse issues: %w", err)
+	}
+
+	parents := make(map[int]IssueRef)
+	for _, node := range nodes {
+		if epic, ok := node.epic(); ok {
+			// Epics in other repositories keep their owner/repo
+			if sameRepo(epic.Repository.NameWithOwner, owner, repo) {
+				epic.Repository.NameWithOwner = ""
+			}
+			parents[node.Number] = epic
+		}
+	}
+	return parents, nil
+}
+
+// ListSubIssues returns an epic's title and its open children: its sub-issues
+// and the issues its tasklists track. Children in other repositories, which
+// sub-issues can be, are left out.
+func ListSubIssues(epicNumber int) (string, []Issue, error) {
+	owner, repo, err := CurrentRepo()
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $number: Int!) {
+			repository(owner: $owner, name: $repo) {
+				issue(number: $number) {
+					title
+					subIssues(first: 100) {
+						nodes {
+							...child
+						}
+					}
+					trackedIssues(first: 100) {
+						nodes {
+							...child
+						}
+					}
+				}
+			}
+		}
+
+		fragment child on Issue {
+			number
+			title
+			state
+			repository {
+				nameWithOwner
+			}
+			author {
+				login
+			}
+			assignees(first: 10) {
+				nodes {
+					login
+				}
+			}
+			labels(first: 20) {
+				nodes {
+					name
+					color
+				}
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
+		"-F", "number="+strconv.Itoa(epicNumber),
+		"--jq", ".data.repository.issue")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get the sub-issues of #%d: %v", epicNumber, err)
+	}
+
+	type child struct {
+		Number     int    `json:"number"`
+		Title      string `json:"title"`
+		State      string `json:"state"`
+		Repository struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+		Author    User `json:"author"`
+		Assignees struct {
+			Nodes []User `json:"nodes"`
+		} `json:"assignees"`
+		Labels struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+	}
+	var epic *struct {
+		Title     string `json:"title"`
+		SubIssues struct {
+			Nodes []child `json:"nodes"`
+		} `json:"subIssues"`
+		TrackedIssues struct {
+			Nodes []child `json:"nodes"`
+		} `json:"trackedIssues"`
+	}
+	if err := json.Unmarshal(output, &epic); err != nil {
+		return "", nil, fmt.Errorf("failed to parse sub-issues: %w", err)
+	}
+	if epic == nil {
+		return "", nil, errs.NotFound("issue #%d not found", epicNumber)
+	}
+
+	var children []Issue
+	seen := make(map[int]bool)
+	for _, node := range append(epic.SubIssues.Nodes, epic.TrackedIssues.Nodes...) {
+		if node.State != "OPEN" || seen[node.Number] || !sameRepo(node.Repository.NameWithOwner, owner, repo) {
+			continue
+		}
+		seen[node.Number] = true
+		children = append(children, Issue{
+			Number:    node.Number,
+			Title:     node.Title,
+			State:     node.State,
+			Author:    node.Author,
+			Assignees: node.Assignees.Nodes,
+			Labels:    node.Labels.Nodes,
+		})
+	}
+	return epic.Title, children, nil
+}
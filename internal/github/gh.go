@@ -0,0 +1,94 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/enterprisemodules/gwi/internal/sandbox"
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// Command returns a command running the GitHub CLI with args. In sandbox mode
+// (GWI_SANDBOX=1) gwi's built-in fake of gh runs instead. The command may change
+// what GitHub reports, so it clears the results cachedOutput keeps.
+func Command(args ...string) *exec.Cmd {
+	Refresh()
+	return command(args...)
+}
+
+// InteractiveCommand is Command for a gh call that reads from the terminal,
+// which has no timeout
+func InteractiveCommand(args ...string) *exec.Cmd {
+	Refresh()
+	return newCommand(timeout.Interactive, args...)
+}
+
+// memo holds the output of read-only gh calls by directory and arguments, so
+// each unique call runs once per gwi invocation
+var memo struct {
+	sync.Mutex
+	outputs map[string][]byte
+}
+
+// cachedOutput runs a read-only gh call and returns its output, reusing the
+// result of the same call made earlier from the same directory. Failed calls
+// are not kept.
+func cachedOutput(args ...string) ([]byte, error) {
+	dir, _ := os.Getwd()
+	key := dir + "\x00" + strings.Join(args, "\x00")
+
+	memo.Lock()
+	output, ok := memo.outputs[key]
+	memo.Unlock()
+	if ok {
+		return output, nil
+	}
+
+	output, err := command(args...).Output()
+	if err != nil {
+		return output, err
+	}
+	memo.Lock()
+	if memo.outputs == nil {
+		memo.outputs = make(map[string][]byte)
+	}
+	memo.outputs[key] = output
+	memo.Unlock()
+	return output, nil
+}
+
+// Refresh forgets the results of earlier gh calls, for commands that poll
+// GitHub for changes
+func Refresh() {
+	memo.Lock()
+	memo.outputs = nil
+	memo.Unlock()
+}
+
+func command(args ...string) *exec.Cmd {
+	return newCommand(timeout.Command, args...)
+}
+
+// newCommand makes the gh call with create, or the fake's in sandbox mode
+func newCommand(create func(string, ...string) *exec.Cmd, args ...string) *exec.Cmd {
+	if sandbox.Enabled() {
+		if self, err := os.Executable(); err == nil {
+			return exec.Command(self, append([]string{"_fake-gh"}, args...)...)
+		}
+	}
+	return create("gh", args...)
+}
+
+// ShellCommand returns a shell command line running the GitHub CLI with args,
+// e.g. for an fzf preview. args are passed to the shell as they are.
+func ShellCommand(args string) string {
+	if sandbox.Enabled() {
+		if self, err := os.Executable(); err == nil {
+			return fmt.Sprintf("'%s' _fake-gh %s", self, args)
+		}
+	}
+	return "gh " + args
+}
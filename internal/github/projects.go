@@ -2,20 +2,27 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/errs"
 )
 
 // ProjectItem represents an issue's association with a GitHub Project
 type ProjectItem struct {
-	ID        string
-	ProjectID string
+	ID            string
+	ProjectID     string
+	ProjectTitle  string
+	ProjectNumber int
+	ProjectOwner  string // Login of the organization or user owning the project
 }
 
 // ProjectField represents a field in a GitHub Project
@@ -51,9 +58,15 @@ func ParseIssueFromBranch(branchName string) (int, bool) {
 	return 0, false
 }
 
-// CheckProjectScopes verifies required GitHub CLI scopes and prompts to refresh if missing
-func CheckProjectScopes() error {
-	cmd := exec.Command("gh", "auth", "status")
+// ErrProjectsSkipped is returned by CheckProjectScopes when the project scope
+// is missing and github.missing_scopes is "skip"
+var ErrProjectsSkipped = errs.Auth("GitHub Projects skipped: the 'project' scope is missing (github.missing_scopes: skip). Add it with: gh auth refresh -s project")
+
+// CheckProjectScopes verifies required GitHub CLI scopes. When they are
+// missing it prompts to refresh them, unless nobody can answer the prompt or
+// github.missing_scopes says to fail or skip instead.
+func CheckProjectScopes(cfg *config.Config) error {
+	cmd := Command("auth", "status")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("GitHub CLI not authenticated. Run: gh auth login")
@@ -61,33 +74,41 @@ func CheckProjectScopes() error {
 
 	outputStr := string(output)
 	hasProject := strings.Contains(outputStr, "project")
+	if hasProject {
+		return nil
+	}
 
-	if !hasProject {
-		config.Warn("Missing required GitHub scopes for Projects integration")
-		config.Info("Attempting to refresh authentication with required scopes...")
+	switch {
+	case cfg.GitHub.MissingScopes == "skip":
+		return ErrProjectsSkipped
+	case cfg.GitHub.MissingScopes == "fail" || config.Headless():
+		// gh auth refresh waits for a browser login nobody completes
+		return errs.Auth("Missing the 'project' scope needed for GitHub Projects. Refresh it in a terminal with: gh auth refresh -s project\n" +
+			"  Or skip project status updates without it: github.missing_scopes: skip (GWI_GITHUB_MISSING_SCOPES=skip)")
+	}
 
-		refreshCmd := exec.Command("gh", "auth", "refresh", "-s", "project")
-		refreshCmd.Stdin = nil // Will prompt user interactively
-		refreshCmd.Stdout = nil
-		refreshCmd.Stderr = nil
+	config.Warn("Missing required GitHub scopes for Projects integration")
+	config.Info("Attempting to refresh authentication with required scopes...")
 
-		if err := refreshCmd.Run(); err != nil {
-			return fmt.Errorf("failed to refresh auth. Please run manually: gh auth refresh -s project")
-		}
+	// stdout may be captured by the shell integration, so gh talks on stderr
+	refreshCmd := InteractiveCommand("auth", "refresh", "-s", "project")
+	refreshCmd.Stdin = os.Stdin
+	refreshCmd.Stdout = os.Stderr
+	refreshCmd.Stderr = os.Stderr
 
-		config.Success("Authentication refreshed with project scopes")
+	if err := refreshCmd.Run(); err != nil {
+		return fmt.Errorf("failed to refresh auth. Please run manually: gh auth refresh -s project")
 	}
 
+	config.Success("Authentication refreshed with project scopes")
 	return nil
 }
 
-// GetProjectItemsForIssue finds all project items for an issue using GraphQL API
-func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
-	// Get current repository info
-	repoCmd := exec.Command("gh", "repo", "view", "--json", "owner,name")
-	repoOutput, err := repoCmd.Output()
+// CurrentRepo returns the owner and name of the repository gh resolves for the current directory
+func CurrentRepo() (owner, name string, err error) {
+	repoOutput, err := cachedOutput("repo", "view", "--json", "owner,name")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get repository info")
+		return "", "", fmt.Errorf("failed to get repository info")
 	}
 
 	var repoInfo struct {
@@ -97,20 +118,42 @@ func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
 		Name string `json:"name"`
 	}
 	if err := json.Unmarshal(repoOutput, &repoInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse repository info")
+		return "", "", fmt.Errorf("failed to parse repository info")
 	}
+	return repoInfo.Owner.Login, repoInfo.Name, nil
+}
+
+// GetProjectItemsForIssue finds all project items for an issue in the current repository
+func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
+	owner, repo, err := CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+	return GetProjectItemsForIssueInRepo(owner, repo, issueNumber)
+}
 
+// GetProjectItemsForIssueInRepo finds all project items for an issue using GraphQL API
+func GetProjectItemsForIssueInRepo(owner, repo string, issueNumber int) ([]ProjectItem, error) {
 	// Use GraphQL to get project items with IDs
 	query := `
 		query($owner: String!, $repo: String!, $number: Int!) {
 			repository(owner: $owner, name: $repo) {
 				issue(number: $number) {
-					projectItems(first: 10) {
+					projectItems(first: 50) {
 						nodes {
 							id
 							project {
 								id
 								title
+								number
+								owner {
+									... on Organization {
+										login
+									}
+									... on User {
+										login
+									}
+								}
 							}
 						}
 					}
@@ -119,14 +162,12 @@ func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
 		}
 	`
 
-	cmd := exec.Command("gh", "api", "graphql",
+	output, err := cachedOutput("api", "graphql",
 		"-f", "query="+query,
-		"-f", "owner="+repoInfo.Owner.Login,
-		"-f", "repo="+repoInfo.Name,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
 		"-F", "number="+strconv.Itoa(issueNumber),
 		"--jq", ".data.repository.issue.projectItems.nodes")
-
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project items for issue #%d: %v", issueNumber, err)
 	}
@@ -135,8 +176,12 @@ func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
 	var nodes []struct {
 		ID      string `json:"id"`
 		Project struct {
-			ID    string `json:"id"`
-			Title string `json:"title"`
+			ID     string `json:"id"`
+			Title  string `json:"title"`
+			Number int    `json:"number"`
+			Owner  struct {
+				Login string `json:"login"`
+			} `json:"owner"`
 		} `json:"project"`
 	}
 
@@ -147,14 +192,82 @@ func GetProjectItemsForIssue(issueNumber int) ([]ProjectItem, error) {
 	var items []ProjectItem
 	for _, node := range nodes {
 		items = append(items, ProjectItem{
-			ID:        node.ID,
-			ProjectID: node.Project.ID,
+			ID:            node.ID,
+			ProjectID:     node.Project.ID,
+			ProjectTitle:  node.Project.Title,
+			ProjectNumber: node.Project.Number,
+			ProjectOwner:  node.Project.Owner.Login,
 		})
 	}
 
 	return items, nil
 }
 
+// GetIssueProjectStatus returns an issue's value of the status field in its
+// first project selected by selection (see ProjectSelected) with one set, or
+// "" when it has none
+func GetIssueProjectStatus(issueNumber int, statusFieldName string, selection []string) (string, error) {
+	owner, repo, err := CurrentRepo()
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $number: Int!, $field: String!) {
+			repository(owner: $owner, name: $repo) {
+				issue(number: $number) {
+					projectItems(first: 10) {
+						nodes {
+							project {
+								title
+								number
+							}
+							fieldValueByName(name: $field) {
+								... on ProjectV2ItemFieldSingleSelectValue {
+									name
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
+		"-F", "number="+strconv.Itoa(issueNumber),
+		"-f", "field="+statusFieldName,
+		"--jq", ".data.repository.issue.projectItems.nodes")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the status of issue #%d: %v", issueNumber, err)
+	}
+
+	var nodes []struct {
+		Project struct {
+			Title  string `json:"title"`
+			Number int    `json:"number"`
+		} `json:"project"`
+		FieldValueByName struct {
+			Name string `json:"name"`
+		} `json:"fieldValueByName"`
+	}
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return "", fmt.Errorf("failed to parse project items: %w", err)
+	}
+	for _, node := range nodes {
+		if !ProjectSelected(selection, node.Project.Title, node.Project.Number) {
+			continue
+		}
+		if node.FieldValueByName.Name != "" {
+			return node.FieldValueByName.Name, nil
+		}
+	}
+	return "", nil
+}
+
 // GetProjectField retrieves field information by name with caching using GraphQL
 func GetProjectField(projectID, fieldName string) (*ProjectField, error) {
 	cacheKey := projectID + ":" + fieldName
@@ -189,11 +302,9 @@ func GetProjectField(projectID, fieldName string) (*ProjectField, error) {
 		}
 	`
 
-	cmd := exec.Command("gh", "api", "graphql",
+	output, err := cachedOutput("api", "graphql",
 		"-f", "query="+query,
 		"-f", "projectId="+projectID)
-
-	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get fields for project %s: %v", projectID, err)
 	}
@@ -226,6 +337,9 @@ func GetProjectField(projectID, fieldName string) (*ProjectField, error) {
 	return nil, fmt.Errorf("field '%s' not found in project", fieldName)
 }
 
+// ErrOptionNotFound is returned when a project's status field lacks a value
+var ErrOptionNotFound = errors.New("option not found")
+
 // GetFieldOptionID finds the option ID for a status value (case-insensitive)
 func GetFieldOptionID(field *ProjectField, optionName string) (string, error) {
 	for _, option := range field.Options {
@@ -233,31 +347,123 @@ func GetFieldOptionID(field *ProjectField, optionName string) (string, error) {
 			return option.ID, nil
 		}
 	}
-	return "", fmt.Errorf("option '%s' not found in field '%s'", optionName, field.Name)
+	return "", fmt.Errorf("%w: '%s' in field '%s'", ErrOptionNotFound, optionName, field.Name)
+}
+
+// OptionNames returns the names of the field's options
+func (f *ProjectField) OptionNames() []string {
+	names := make([]string, 0, len(f.Options))
+	for _, option := range f.Options {
+		names = append(names, option.Name)
+	}
+	return names
 }
 
-// UpdateProjectItemStatus updates the status field for a project item
+// statusUpdateAttempts bounds how often a status update is made before
+// giving up on the board showing it
+const statusUpdateAttempts = 3
+
+// ProjectUpdate is the outcome of a status update in one project
+type ProjectUpdate struct {
+	Project string
+	Err     error
+}
+
+// UpdateProjectItemStatus updates the status field for a project item and
+// verifies it took effect. Projects are eventually consistent, so an edit gh
+// reports as successful may not show; it is read back and made again, up to
+// statusUpdateAttempts times with a growing pause.
 func UpdateProjectItemStatus(item ProjectItem, fieldID, optionID string, cfg *config.Config) error {
-	cmd := exec.Command("gh", "project", "item-edit",
-		"--id", item.ID,
-		"--project-id", item.ProjectID,
-		"--field-id", fieldID,
-		"--single-select-option-id", optionID)
+	for attempt := 1; ; attempt++ {
+		cmd := Command("project", "item-edit",
+			"--id", item.ID,
+			"--project-id", item.ProjectID,
+			"--field-id", fieldID,
+			"--single-select-option-id", optionID)
 
-	if cfg.Verbose {
-		config.Info("Updating project item %s in project %s", item.ID, item.ProjectID)
+		if cfg.Verbose {
+			config.Info("Updating project item %s in project %s", item.ID, item.ProjectID)
+		}
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to update item: %s", strings.TrimSpace(string(output)))
+		}
+
+		current, err := GetProjectItemOption(item.ID, fieldID)
+		if err != nil {
+			// The edit itself succeeded; not being able to read it back is no reason to fail
+			if cfg.Verbose {
+				config.Warn("Could not verify the update of project item %s: %v", item.ID, err)
+			}
+			return nil
+		}
+		if current == optionID {
+			return nil
+		}
+		if attempt == statusUpdateAttempts {
+			return fmt.Errorf("the status didn't change after %d attempts", attempt)
+		}
+		if cfg.Verbose {
+			config.Warn("Project item %s doesn't show the new status yet, updating it again", item.ID)
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
 	}
+}
 
-	output, err := cmd.CombinedOutput()
+// GetProjectItemOption returns the ID of the option a project item has in a
+// single-select field, or "" when it has none. It is read uncached, to see
+// updates made in this run.
+func GetProjectItemOption(itemID, fieldID string) (string, error) {
+	query := `
+		query($itemId: ID!) {
+			node(id: $itemId) {
+				... on ProjectV2Item {
+					fieldValues(first: 50) {
+						nodes {
+							... on ProjectV2ItemFieldSingleSelectValue {
+								optionId
+								field {
+									... on ProjectV2SingleSelectField {
+										id
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	output, err := command("api", "graphql",
+		"-f", "query="+query,
+		"-f", "itemId="+itemID,
+		"--jq", ".data.node.fieldValues.nodes").Output()
 	if err != nil {
-		return fmt.Errorf("failed to update item: %s", strings.TrimSpace(string(output)))
+		return "", fmt.Errorf("failed to get the fields of project item %s: %v", itemID, err)
 	}
 
-	return nil
+	var nodes []struct {
+		OptionID string `json:"optionId"`
+		Field    struct {
+			ID string `json:"id"`
+		} `json:"field"`
+	}
+	if err := json.Unmarshal(output, &nodes); err != nil {
+		return "", fmt.Errorf("failed to parse project item fields: %w", err)
+	}
+	for _, node := range nodes {
+		if node.Field.ID == fieldID {
+			return node.OptionID, nil
+		}
+	}
+	return "", nil
 }
 
-// UpdateIssueStatus is the main function to update issue status in all projects
-func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config) error {
+// UpdateIssueStatus is the main function to update issue status in all projects.
+// It returns the outcome per project, and an error when no project was updated.
+func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config) ([]ProjectUpdate, error) {
 	if cfg.Verbose {
 		config.Info("UpdateIssueStatus called for issue #%d with status '%s'", issueNumber, statusValue)
 	}
@@ -267,7 +473,7 @@ func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config)
 		if cfg.Verbose {
 			config.Warn("gh CLI not found in PATH")
 		}
-		return fmt.Errorf("gh CLI not found in PATH")
+		return nil, fmt.Errorf("gh CLI not found in PATH")
 	}
 
 	if cfg.Verbose {
@@ -276,11 +482,17 @@ func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config)
 
 	// Check scopes if enabled
 	if cfg.GitHub.CheckScopes {
-		if err := CheckProjectScopes(); err != nil {
+		if err := CheckProjectScopes(cfg); err != nil {
+			if errors.Is(err, ErrProjectsSkipped) {
+				if cfg.Verbose {
+					config.Info("Project scope missing, skipping status update (github.missing_scopes: skip)")
+				}
+				return nil, nil
+			}
 			if cfg.Verbose {
 				config.Warn("Scope check failed: %v", err)
 			}
-			return err
+			return nil, err
 		}
 		if cfg.Verbose {
 			config.Info("Scopes OK")
@@ -291,20 +503,25 @@ func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config)
 		config.Info("Getting project items for issue #%d...", issueNumber)
 	}
 
-	// Get all project items for this issue
-	items, err := GetProjectItemsForIssue(issueNumber)
+	owner, repo, err := CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the project items for this issue, limited to the configured projects
+	items, err := SelectProjectItems(cfg, owner, repo, issueNumber)
 	if err != nil {
 		if cfg.Verbose {
 			config.Warn("Failed to get project items: %v", err)
 		}
-		return err
+		return nil, err
 	}
 
 	if len(items) == 0 {
 		if cfg.Verbose {
 			config.Info("Issue #%d is not in any GitHub Project", issueNumber)
 		}
-		return nil
+		return nil, nil
 	}
 
 	if cfg.Verbose {
@@ -312,51 +529,650 @@ func UpdateIssueStatus(issueNumber int, statusValue string, cfg *config.Config)
 	}
 
 	// Update each project
+	var updates []ProjectUpdate
 	var lastErr error
 	successCount := 0
 
 	for _, item := range items {
-		// Get the Status field for this project
-		field, err := GetProjectField(item.ProjectID, cfg.GitHub.StatusFieldName)
+		err := SetProjectItemStatus(item, statusValue, cfg)
+		updates = append(updates, ProjectUpdate{Project: item.ProjectTitle, Err: err})
 		if err != nil {
 			if cfg.Verbose {
-				config.Warn("Could not get '%s' field: %v", cfg.GitHub.StatusFieldName, err)
+				config.Warn("Failed to update project item: %v", err)
 			}
 			lastErr = err
 			continue
 		}
+		successCount++
+	}
+
+	if successCount == 0 && lastErr != nil {
+		return updates, lastErr
+	}
+
+	if successCount > 0 {
+		if cfg.Verbose {
+			config.Success("Updated %d project(s) to '%s'", successCount, statusValue)
+		}
+	}
+
+	return updates, nil
+}
+
+// SetProjectItemStatus sets the status field of one project item to statusValue
+func SetProjectItemStatus(item ProjectItem, statusValue string, cfg *config.Config) error {
+	// Get the Status field for this project
+	field, err := GetProjectField(item.ProjectID, cfg.GitHub.StatusFieldName)
+	if err != nil {
+		return err
+	}
+
+	// Get the option ID for the desired status
+	optionID, err := GetFieldOptionID(field, statusValue)
+	if err != nil {
+		return err
+	}
+
+	return UpdateProjectItemStatus(item, field.ID, optionID, cfg)
+}
+
+// Project identifies a GitHub Project (v2)
+type Project struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// BoardItem is an issue on a project board together with its status
+type BoardItem struct {
+	Number int
+	Title  string
+	State  string
+	Repo   string // owner/name of the issue's repository
+	Status string
+}
+
+// ListRepoProjects returns the projects linked to a repository
+func ListRepoProjects(owner, repo string) ([]Project, error) {
+	query := `
+		query($owner: String!, $repo: String!) {
+			repository(owner: $owner, name: $repo) {
+				projectsV2(first: 20) {
+					nodes {
+						id
+						number
+						title
+					}
+				}
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
+		"--jq", ".data.repository.projectsV2.nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects for %s/%s: %v", owner, repo, err)
+	}
+
+	var projects []Project
+	if err := json.Unmarshal(output, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+	return projects, nil
+}
+
+// ListProjectIssues returns all issues on a project board with their status
+func ListProjectIssues(projectID, statusFieldName string) ([]BoardItem, error) {
+	query := `
+		query($projectId: ID!, $after: String) {
+			node(id: $projectId) {
+				... on ProjectV2 {
+					items(first: 100, after: $after) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							fieldValueByName(name: "%s") {
+								... on ProjectV2ItemFieldSingleSelectValue {
+									name
+								}
+							}
+							content {
+								... on Issue {
+									number
+									title
+									state
+									repository {
+										nameWithOwner
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+	formattedQuery := fmt.Sprintf(query, statusFieldName)
 
-		// Get the option ID for the desired status
-		optionID, err := GetFieldOptionID(field, statusValue)
+	var items []BoardItem
+	after := "null"
+	for {
+		output, err := cachedOutput("api", "graphql",
+			"-f", "query="+formattedQuery,
+			"-f", "projectId="+projectID,
+			"-F", "after="+after)
 		if err != nil {
-			if cfg.Verbose {
-				config.Warn("Could not find option '%s': %v", statusValue, err)
+			return nil, fmt.Errorf("failed to get items for project %s: %v", projectID, err)
+		}
+
+		var response struct {
+			Data struct {
+				Node struct {
+					Items struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							FieldValueByName struct {
+								Name string `json:"name"`
+							} `json:"fieldValueByName"`
+							Content struct {
+								Number     int    `json:"number"`
+								Title      string `json:"title"`
+								State      string `json:"state"`
+								Repository struct {
+									NameWithOwner string `json:"nameWithOwner"`
+								} `json:"repository"`
+							} `json:"content"`
+						} `json:"nodes"`
+					} `json:"items"`
+				} `json:"node"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal(output, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse project items: %w", err)
+		}
+
+		for _, node := range response.Data.Node.Items.Nodes {
+			// Skip drafts and pull requests
+			if node.Content.Number == 0 {
+				continue
 			}
-			lastErr = err
-			continue
+			items = append(items, BoardItem{
+				Number: node.Content.Number,
+				Title:  node.Content.Title,
+				State:  node.Content.State,
+				Repo:   node.Content.Repository.NameWithOwner,
+				Status: node.FieldValueByName.Name,
+			})
 		}
 
-		// Update the item
-		if err := UpdateProjectItemStatus(item, field.ID, optionID, cfg); err != nil {
-			if cfg.Verbose {
-				config.Warn("Failed to update project item: %v", err)
+		pageInfo := response.Data.Node.Items.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		after = pageInfo.EndCursor
+	}
+
+	return items, nil
+}
+
+// StatusChange is a move of a project item from one status to another
+type StatusChange struct {
+	At   time.Time
+	From string
+	To   string
+}
+
+// StatusHistory is an issue on a project board with the status changes
+// recorded in its timeline, oldest first
+type StatusHistory struct {
+	Number  int
+	Title   string
+	Repo    string    // owner/name of the issue's repository
+	Status  string    // Current status
+	AddedAt time.Time // When the issue was added to the project
+	Changes []StatusChange
+}
+
+// ListProjectStatusHistory returns the issues on a project board with the
+// changes of their Status field. GitHub records these for the project's
+// built-in Status field only.
+func ListProjectStatusHistory(projectID, statusFieldName string) ([]StatusHistory, error) {
+	query := `
+		query($projectId: ID!, $after: String) {
+			node(id: $projectId) {
+				... on ProjectV2 {
+					items(first: 50, after: $after) {
+						pageInfo {
+							hasNextPage
+							endCursor
+						}
+						nodes {
+							createdAt
+							fieldValueByName(name: "%s") {
+								... on ProjectV2ItemFieldSingleSelectValue {
+									name
+								}
+							}
+							content {
+								... on Issue {
+									number
+									title
+									repository {
+										nameWithOwner
+									}
+									timelineItems(last: 100, itemTypes: [PROJECT_V2_ITEM_STATUS_CHANGED_EVENT]) {
+										nodes {
+											... on ProjectV2ItemStatusChangedEvent {
+												createdAt
+												previousStatus
+												status
+												project {
+													id
+												}
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
 			}
-			lastErr = err
-			continue
 		}
+	`
+	formattedQuery := fmt.Sprintf(query, statusFieldName)
 
-		successCount++
+	var history []StatusHistory
+	after := "null"
+	for {
+		output, err := cachedOutput("api", "graphql",
+			"-f", "query="+formattedQuery,
+			"-f", "projectId="+projectID,
+			"-F", "after="+after)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the status history of project %s: %v", projectID, err)
+		}
+
+		var response struct {
+			Data struct {
+				Node struct {
+					Items struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							CreatedAt        time.Time `json:"createdAt"`
+							FieldValueByName struct {
+								Name string `json:"name"`
+							} `json:"fieldValueByName"`
+							Content struct {
+								Number     int    `json:"number"`
+								Title      string `json:"title"`
+								Repository struct {
+									NameWithOwner string `json:"nameWithOwner"`
+								} `json:"repository"`
+								TimelineItems struct {
+									Nodes []struct {
+										CreatedAt      time.Time `json:"createdAt"`
+										PreviousStatus string    `json:"previousStatus"`
+										Status         string    `json:"status"`
+										Project        struct {
+											ID string `json:"id"`
+										} `json:"project"`
+									} `json:"nodes"`
+								} `json:"timelineItems"`
+							} `json:"content"`
+						} `json:"nodes"`
+					} `json:"items"`
+				} `json:"node"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal(output, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse the project status history: %w", err)
+		}
+
+		for _, node := range response.Data.Node.Items.Nodes {
+			// Skip drafts and pull requests
+			if node.Content.Number == 0 {
+				continue
+			}
+			entry := StatusHistory{
+				Number:  node.Content.Number,
+				Title:   node.Content.Title,
+				Repo:    node.Content.Repository.NameWithOwner,
+				Status:  node.FieldValueByName.Name,
+				AddedAt: node.CreatedAt,
+			}
+			// The timeline holds the changes on every project the issue is on
+			for _, event := range node.Content.TimelineItems.Nodes {
+				if event.Project.ID != projectID {
+					continue
+				}
+				entry.Changes = append(entry.Changes, StatusChange{At: event.CreatedAt, From: event.PreviousStatus, To: event.Status})
+			}
+			history = append(history, entry)
+		}
+
+		pageInfo := response.Data.Node.Items.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		after = pageInfo.EndCursor
 	}
 
-	if successCount == 0 && lastErr != nil {
-		return lastErr
+	return history, nil
+}
+
+// ProjectSelected reports whether a project matches one of the configured
+// titles or numbers. An empty selection matches every project.
+func ProjectSelected(selection []string, title string, number int) bool {
+	if len(selection) == 0 {
+		return true
 	}
+	for _, want := range selection {
+		want = strings.TrimSpace(want)
+		if strings.EqualFold(want, title) || want == strconv.Itoa(number) {
+			return true
+		}
+	}
+	return false
+}
 
-	if successCount > 0 {
-		if cfg.Verbose {
-			config.Success("Updated %d project(s) to '%s'", successCount, statusValue)
+// SelectProjectItems returns the issue's items in the projects selected by
+// github.projects. With github.project_owner set, the issue is first added to
+// selected projects of that organization it isn't on yet.
+func SelectProjectItems(cfg *config.Config, owner, repo string, issueNumber int) ([]ProjectItem, error) {
+	items, err := FindProjectItems(cfg, owner, repo, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if err := AddProjectItem(cfg, owner, repo, issueNumber, &items[i]); err != nil {
+			return items[:i], err
+		}
+	}
+	return items, nil
+}
+
+// FindProjectItems is SelectProjectItems without changing anything: the
+// selected projects of github.project_owner the issue isn't on yet are
+// returned as items without an ID, for AddProjectItem to add.
+func FindProjectItems(cfg *config.Config, owner, repo string, issueNumber int) ([]ProjectItem, error) {
+	items, err := GetProjectItemsForIssueInRepo(owner, repo, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []ProjectItem
+	onProject := make(map[string]bool)
+	for _, item := range items {
+		onProject[item.ProjectID] = true
+		if ProjectSelected(cfg.GitHub.Projects, item.ProjectTitle, item.ProjectNumber) {
+			selected = append(selected, item)
 		}
 	}
 
+	if cfg.GitHub.ProjectOwner == "" || len(cfg.GitHub.Projects) == 0 {
+		return selected, nil
+	}
+
+	projects, err := ListOwnerProjects(cfg.GitHub.ProjectOwner)
+	if err != nil {
+		return selected, err
+	}
+	for _, project := range projects {
+		if onProject[project.ID] || !ProjectSelected(cfg.GitHub.Projects, project.Title, project.Number) {
+			continue
+		}
+		selected = append(selected, ProjectItem{
+			ProjectID:     project.ID,
+			ProjectTitle:  project.Title,
+			ProjectNumber: project.Number,
+			ProjectOwner:  cfg.GitHub.ProjectOwner,
+		})
+	}
+	return selected, nil
+}
+
+// AddProjectItem adds the issue to the project of an item FindProjectItems
+// returned without an ID, and sets the ID. Items with an ID are left alone.
+func AddProjectItem(cfg *config.Config, owner, repo string, issueNumber int, item *ProjectItem) error {
+	if item.ID != "" {
+		return nil
+	}
+	issueID, err := GetIssueNodeID(owner, repo, issueNumber)
+	if err != nil {
+		return err
+	}
+	if item.ID, err = AddIssueToProject(item.ProjectID, issueID); err != nil {
+		return err
+	}
+	if cfg.Verbose {
+		config.Info("Added issue #%d to project %s", issueNumber, item.ProjectTitle)
+	}
 	return nil
 }
+
+// ListOwnerProjects returns the projects owned by an organization or user
+func ListOwnerProjects(owner string) ([]Project, error) {
+	query := `
+		query($owner: String!) {
+			repositoryOwner(login: $owner) {
+				... on ProjectV2Owner {
+					projectsV2(first: 50) {
+						nodes {
+							id
+							number
+							title
+						}
+					}
+				}
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"--jq", ".data.repositoryOwner.projectsV2.nodes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects for %s: %v", owner, err)
+	}
+
+	var projects []Project
+	if err := json.Unmarshal(output, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+	return projects, nil
+}
+
+// GetIssueNodeID returns the GraphQL node ID of an issue
+func GetIssueNodeID(owner, repo string, issueNumber int) (string, error) {
+	query := `
+		query($owner: String!, $repo: String!, $number: Int!) {
+			repository(owner: $owner, name: $repo) {
+				issue(number: $number) {
+					id
+				}
+			}
+		}
+	`
+
+	cmd := Command("api", "graphql",
+		"-f", "query="+query,
+		"-f", "owner="+owner,
+		"-f", "repo="+repo,
+		"-F", "number="+strconv.Itoa(issueNumber),
+		"--jq", ".data.repository.issue.id")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get issue #%d: %v", issueNumber, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AddIssueToProject adds an issue (or other content node) to a project and returns the new item ID
+func AddIssueToProject(projectID, contentID string) (string, error) {
+	query := `
+		mutation($projectId: ID!, $contentId: ID!) {
+			addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
+				item {
+					id
+				}
+			}
+		}
+	`
+
+	cmd := Command("api", "graphql",
+		"-f", "query="+query,
+		"-f", "projectId="+projectID,
+		"-f", "contentId="+contentID,
+		"--jq", ".data.addProjectV2ItemById.item.id")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to add item to project: %s", strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// AddIssueToProjects adds a new issue to the projects selected by
+// github.projects: projects of github.project_owner when set, otherwise the
+// projects linked to the repository. Without a selection the repository's
+// only project is used; with several, it's unclear which one is meant.
+func AddIssueToProjects(cfg *config.Config, owner, repo string, issueNumber int) ([]ProjectItem, error) {
+	projectOwner := owner
+	var projects []Project
+	var err error
+	if cfg.GitHub.ProjectOwner != "" {
+		projectOwner = cfg.GitHub.ProjectOwner
+		projects, err = ListOwnerProjects(projectOwner)
+	} else {
+		projects, err = ListRepoProjects(owner, repo)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []Project
+	for _, project := range projects {
+		if ProjectSelected(cfg.GitHub.Projects, project.Title, project.Number) {
+			selected = append(selected, project)
+		}
+	}
+	if len(cfg.GitHub.Projects) == 0 && len(selected) > 1 {
+		return nil, errs.New(errs.KindUsage, "%s has %d projects; choose the ones to add issues to with github.projects", projectOwner, len(selected))
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+
+	issueID, err := GetIssueNodeID(owner, repo, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	var items []ProjectItem
+	for _, project := range selected {
+		itemID, err := AddIssueToProject(project.ID, issueID)
+		if err != nil {
+			return items, err
+		}
+		items = append(items, ProjectItem{
+			ID:            itemID,
+			ProjectID:     project.ID,
+			ProjectTitle:  project.Title,
+			ProjectNumber: project.Number,
+			ProjectOwner:  projectOwner,
+		})
+	}
+	return items, nil
+}
+
+// SetProjectItemField sets a project field of any editable kind by name: a
+// single-select field to the option named value, a number or text field to
+// value itself
+func SetProjectItemField(item ProjectItem, fieldName, value string) error {
+	query := `
+		query($projectId: ID!) {
+			node(id: $projectId) {
+				... on ProjectV2 {
+					fields(first: 50) {
+						nodes {
+							... on ProjectV2Field {
+								id
+								name
+								dataType
+							}
+							... on ProjectV2SingleSelectField {
+								id
+								name
+								dataType
+								options {
+									id
+									name
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	output, err := cachedOutput("api", "graphql",
+		"-f", "query="+query,
+		"-f", "projectId="+item.ProjectID,
+		"--jq", ".data.node.fields.nodes")
+	if err != nil {
+		return fmt.Errorf("failed to get fields for project %s: %v", item.ProjectTitle, err)
+	}
+
+	var fields []struct {
+		ProjectField
+		DataType string `json:"dataType"`
+	}
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return fmt.Errorf("failed to parse project fields: %w", err)
+	}
+
+	for _, field := range fields {
+		if !strings.EqualFold(field.Name, fieldName) {
+			continue
+		}
+		args := []string{"project", "item-edit", "--id", item.ID, "--project-id", item.ProjectID, "--field-id", field.ID}
+		switch field.DataType {
+		case "SINGLE_SELECT":
+			optionID, err := GetFieldOptionID(&field.ProjectField, value)
+			if err != nil {
+				return err
+			}
+			args = append(args, "--single-select-option-id", optionID)
+		case "NUMBER":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("'%s' is a number field, not '%s'", field.Name, value)
+			}
+			args = append(args, "--number", value)
+		case "TEXT":
+			args = append(args, "--text", value)
+		default:
+			return fmt.Errorf("field '%s' can't be set: it is a %s field", field.Name, strings.ToLower(field.DataType))
+		}
+		if output, err := Command(args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to update item: %s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return fmt.Errorf("field '%s' not found in project %s", fieldName, item.ProjectTitle)
+}
@@ -0,0 +1,97 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// TimelineEvent is an entry of an issue's timeline
+type TimelineEvent struct {
+	ID        int64     `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	User struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body  string `json:"body"`
+	Label struct {
+		Name string `json:"name"`
+	} `json:"label"`
+	Assignee struct {
+		Login string `json:"login"`
+	} `json:"assignee"`
+	Rename struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"rename"`
+	Source struct {
+		Issue struct {
+			Number      int    `json:"number"`
+			Title       string `json:"title"`
+			State       string `json:"state"`
+			PullRequest *struct {
+				MergedAt *time.Time `json:"merged_at"`
+			} `json:"pull_request"`
+		} `json:"issue"`
+	} `json:"source"`
+}
+
+// Author returns the login of whoever caused the event
+func (e *TimelineEvent) Author() string {
+	if e.Actor.Login != "" {
+		return e.Actor.Login
+	}
+	return e.User.Login
+}
+
+// Key identifies an event across polls; not every event type has an ID
+func (e *TimelineEvent) Key() string {
+	return fmt.Sprintf("%s/%d/%s/%s/%d", e.Event, e.ID, e.CreatedAt.Format(time.RFC3339), e.Author(), e.Source.Issue.Number)
+}
+
+// LinkedPR reports whether the event links a pull request to the issue
+func (e *TimelineEvent) LinkedPR() bool {
+	return e.Event == "cross-referenced" && e.Source.Issue.PullRequest != nil
+}
+
+// PRState returns open, closed or merged for a linked pull request
+func (e *TimelineEvent) PRState() string {
+	if e.Source.Issue.PullRequest != nil && e.Source.Issue.PullRequest.MergedAt != nil {
+		return "merged"
+	}
+	return strings.ToLower(e.Source.Issue.State)
+}
+
+// GetIssueTimeline fetches all timeline events of an issue in the current repository
+func GetIssueTimeline(issueNumber int) ([]TimelineEvent, error) {
+	cmd := Command("api", "--paginate",
+		fmt.Sprintf("repos/{owner}/{repo}/issues/%d/timeline", issueNumber),
+		"--jq", ".[]")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to fetch timeline: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+
+	var events []TimelineEvent
+	decoder := json.NewDecoder(strings.NewReader(string(output)))
+	for {
+		var event TimelineEvent
+		if err := decoder.Decode(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
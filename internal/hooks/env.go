@@ -0,0 +1,127 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// secretProviders resolve hook_env values that refer to a secret instead of
+// holding it, by prefix. The rest of the value is passed to the command.
+var secretProviders = []struct {
+	prefix string
+	keep   bool // Pass the prefix along, as op expects the full op:// reference
+	args   []string
+}{
+	{prefix: "op://", keep: true, args: []string{"op", "read", "--no-newline"}},
+	{prefix: "pass:", args: []string{"pass", "show"}},
+	{prefix: "keychain:", args: []string{"security", "find-generic-password", "-w", "-s"}},
+	{prefix: "cmd:", args: []string{"sh", "-c"}},
+}
+
+// Env returns the hook_env variables for a hook as KEY=VALUE pairs, sorted by
+// name. ${VAR} in values is expanded from the other hook_env variables and the
+// environment ($$ is a literal $); values that refer to a secret provider are
+// then resolved:
+//
+//	op://vault/item/field   1Password CLI (op read)
+//	pass:path/to/entry      pass, first line
+//	keychain:service        macOS keychain (security find-generic-password)
+//	cmd:command             output of a shell command
+func Env(hookName string, cfg *config.Config, repoInfo *git.RepoInfo) ([]string, error) {
+	var raw map[string]string
+	if repoInfo != nil {
+		raw = cfg.HookEnvVars(repoInfo.Org, repoInfo.Repo, hookName)
+	} else {
+		raw = cfg.HookEnvVars("", "", hookName)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	resolved := make(map[string]string, len(raw))
+	resolving := make(map[string]bool)
+	var resolve func(name string) (string, error)
+	resolve = func(name string) (string, error) {
+		if value, ok := resolved[name]; ok {
+			return value, nil
+		}
+		if resolving[name] {
+			return "", fmt.Errorf("hook_env: %s refers to itself through other variables", name)
+		}
+		resolving[name] = true
+
+		var expandErr error
+		value := os.Expand(raw[name], func(ref string) string {
+			if ref == "$" {
+				return "$"
+			}
+			// A variable refers to its own value in the environment, as in PATH: ${PATH}:bin
+			if _, ok := raw[ref]; !ok || ref == name {
+				return os.Getenv(ref)
+			}
+			v, err := resolve(ref)
+			if err != nil && expandErr == nil {
+				expandErr = err
+			}
+			return v
+		})
+		if expandErr != nil {
+			return "", expandErr
+		}
+		value, err := resolveSecret(value)
+		if err != nil {
+			return "", fmt.Errorf("hook_env: %s: %w", name, err)
+		}
+		resolved[name] = value
+		return value, nil
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		value, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, name+"="+value)
+	}
+	return env, nil
+}
+
+// resolveSecret fetches the secret a value refers to, or returns the value
+// itself when it doesn't refer to a provider
+func resolveSecret(value string) (string, error) {
+	for _, provider := range secretProviders {
+		ref, ok := strings.CutPrefix(value, provider.prefix)
+		if !ok {
+			continue
+		}
+		if provider.keep {
+			ref = value
+		}
+		cmd := exec.Command(provider.args[0], append(provider.args[1:], ref)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %w", provider.args[0], err)
+		}
+		secret := strings.TrimRight(string(output), "\r\n")
+		// pass keeps metadata on the lines after the password
+		if provider.prefix == "pass:" {
+			secret, _, _ = strings.Cut(secret, "\n")
+		}
+		return secret, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,96 @@
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// InstallGitHooks makes the repository's git hooks active in a new worktree.
+// An existing core.hooksPath is respected and left untouched.
+func InstallGitHooks(worktreePath string, cfg *config.Config) error {
+	if existing := git.GetConfig(worktreePath, "core.hooksPath"); existing != "" {
+		if cfg.Verbose {
+			config.Info("core.hooksPath already set to %s, leaving it as is", existing)
+		}
+		return nil
+	}
+
+	source := cfg.GitHooks.Source
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(worktreePath, source)
+	}
+	info, err := os.Stat(source)
+	if err != nil || !info.IsDir() {
+		if cfg.Verbose {
+			config.Info("No git hooks directory at %s", source)
+		}
+		return nil
+	}
+
+	switch cfg.GitHooks.Mode {
+	case "", "path":
+		if err := git.SetWorktreeConfig(worktreePath, "core.hooksPath", source); err != nil {
+			return fmt.Errorf("failed to set core.hooksPath: %w", err)
+		}
+		config.Info("Git hooks enabled from %s", source)
+	case "copy":
+		commonDir, err := git.GetCommonDir(worktreePath)
+		if err != nil {
+			return fmt.Errorf("failed to locate git directory: %w", err)
+		}
+		count, err := copyHooks(source, filepath.Join(commonDir, "hooks"))
+		if err != nil {
+			return err
+		}
+		config.Info("Installed %d git hook(s) from %s", count, source)
+	default:
+		return fmt.Errorf("unknown git_hooks mode %q (use 'path' or 'copy')", cfg.GitHooks.Mode)
+	}
+	return nil
+}
+
+// copyHooks copies executable hook scripts from src into dst
+func copyHooks(src, dst string) (int, error) {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !isExecutable(filepath.Join(src, entry.Name())) {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return count, fmt.Errorf("failed to install hook %s: %w", entry.Name(), err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
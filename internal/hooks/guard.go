@@ -0,0 +1,96 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/git"
+)
+
+// GuardHooks are the git hooks installed by gwi guard install
+var GuardHooks = []string{"pre-commit", "pre-push"}
+
+// guardMarker identifies hook scripts written by gwi guard
+const guardMarker = "# Installed by gwi guard"
+
+// guardScript runs gwi _guard from a git hook. Commits aren't blocked when
+// gwi is not on the PATH, e.g. in a GUI client.
+func guardScript(hook string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s: keeps commits off the default branch in the main worktree.
+# Remove with: gwi guard uninstall
+command -v gwi >/dev/null 2>&1 || exit 0
+exec gwi _guard %s "$@"
+`, guardMarker, hook)
+}
+
+// GuardStatus describes a guard hook in the repository's hooks directory
+type GuardStatus struct {
+	Hook      string
+	Path      string
+	Installed bool // The hook is gwi's guard
+	Foreign   bool // Another hook is in the way
+}
+
+// GetGuardStatus reports the guard hooks of the repository at path
+func GetGuardStatus(path string) ([]GuardStatus, error) {
+	dir, err := git.GetHooksDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	var statuses []GuardStatus
+	for _, hook := range GuardHooks {
+		status := GuardStatus{Hook: hook, Path: filepath.Join(dir, hook)}
+		if content, err := os.ReadFile(status.Path); err == nil {
+			status.Installed = strings.Contains(string(content), guardMarker)
+			status.Foreign = !status.Installed
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// InstallGuard writes the guard hooks into the repository's hooks directory.
+// Hooks of other tools are left in place unless force is set.
+func InstallGuard(path string, force bool) ([]GuardStatus, error) {
+	statuses, err := GetGuardStatus(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Foreign && !force {
+			return nil, fmt.Errorf("%s already exists; call 'gwi _guard %s' from it, or replace it with --force", status.Path, status.Hook)
+		}
+	}
+	for i, status := range statuses {
+		if err := os.MkdirAll(filepath.Dir(status.Path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(status.Path, []byte(guardScript(status.Hook)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to install %s hook: %w", status.Hook, err)
+		}
+		statuses[i].Installed, statuses[i].Foreign = true, false
+	}
+	return statuses, nil
+}
+
+// UninstallGuard removes the guard hooks, leaving hooks of other tools alone
+func UninstallGuard(path string) (int, error) {
+	statuses, err := GetGuardStatus(path)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, status := range statuses {
+		if !status.Installed {
+			continue
+		}
+		if err := os.Remove(status.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s hook: %w", status.Hook, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -46,23 +46,37 @@ func isExecutable(path string) bool {
 	return info.Mode()&0111 != 0
 }
 
-// RunHook executes a hook script
-func RunHook(hookName, worktreePath string, cfg *config.Config, repoInfo *git.RepoInfo) (bool, error) {
+// RunHook executes a hook script, with extraEnv (KEY=VALUE pairs) and the
+// hook_env variables added to its environment
+func RunHook(hookName, worktreePath string, cfg *config.Config, repoInfo *git.RepoInfo, extraEnv ...string) (bool, error) {
 	hookScript := FindHook(hookName, worktreePath, cfg, repoInfo)
 	if hookScript == "" {
 		return false, nil
 	}
 
+	env, err := Env(hookName, cfg, repoInfo)
+	if err != nil {
+		config.Warn("Not running the %s hook: %v", hookName, err)
+		return true, err
+	}
+
 	config.Info("Running %s hook...", hookName)
 
 	cmd := exec.Command(hookScript)
 	cmd.Dir = worktreePath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Env = append(append(os.Environ(), extraEnv...), env...)
 	cmd.Stdin = os.Stdin
 
-	err := cmd.Run()
-	if err != nil {
+	// Hook output is filtered line by line: credentials are always masked, and
+	// identifying details too in redaction mode
+	stdout := config.NewRedactWriter(os.Stdout)
+	stderr := config.NewRedactWriter(os.Stderr)
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
 		config.Warn("Hook exited with error: %v", err)
 		return true, err
 	}
@@ -0,0 +1,157 @@
+package hooks
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// requiresHeader starts the comment lines in which a hook script declares the
+// tools it needs, e.g. "# gwi-requires: direnv nvm"
+const requiresHeader = "gwi-requires:"
+
+// missingMarker prefixes the tools the check script can't find, to tell them
+// apart from whatever the shell's startup files print
+const missingMarker = "gwi-missing:"
+
+// toolName is what a required tool may be called; the names end up in a shell
+// script
+var toolName = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
+// Requirements returns the tools a hook needs, without duplicates: the ones
+// its script declares in "# gwi-requires:" lines at the top and the ones
+// hook_requires lists for it
+func Requirements(hookName, script string, cfg *config.Config, repoInfo *git.RepoInfo) ([]string, error) {
+	tools, err := declaredTools(script)
+	if err != nil {
+		return nil, err
+	}
+	if repoInfo != nil {
+		tools = append(tools, cfg.HookRequiredTools(repoInfo.Org, repoInfo.Repo, hookName)...)
+	} else {
+		tools = append(tools, cfg.HookRequiredTools("", "", hookName)...)
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, tool := range tools {
+		if !toolName.MatchString(tool) {
+			return nil, fmt.Errorf("invalid tool name %q required by the %s hook", tool, hookName)
+		}
+		if !seen[tool] {
+			seen[tool] = true
+			unique = append(unique, tool)
+		}
+	}
+	return unique, nil
+}
+
+// declaredTools reads the "# gwi-requires:" lines of a script's leading
+// comments; tools are separated by spaces or commas
+func declaredTools(script string) ([]string, error) {
+	f, err := os.Open(script)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tools []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "#")
+		if !ok {
+			break
+		}
+		if list, ok := strings.CutPrefix(strings.TrimSpace(comment), requiresHeader); ok {
+			tools = append(tools, strings.Fields(strings.ReplaceAll(list, ",", " "))...)
+		}
+	}
+	return tools, scanner.Err()
+}
+
+// TmuxShell returns the shell tmux starts sessions with: its default-shell,
+// $SHELL, or sh
+func TmuxShell() string {
+	if output, err := exec.Command("tmux", "show-options", "-gqv", "default-shell").Output(); err == nil {
+		if shell := strings.TrimSpace(string(output)); shell != "" {
+			return shell
+		}
+	}
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// MissingTools returns the tools shell can't find, sorted. They are looked up
+// in an interactive login shell, as tmux starts one, so version managers that
+// are shell functions (rvm, nvm) count and PATH changes of startup files apply.
+func MissingTools(shell string, tools []string) ([]string, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+
+	var script strings.Builder
+	for _, tool := range tools {
+		if filepath.Base(shell) == "fish" {
+			fmt.Fprintf(&script, "type -q %s; or echo %s%s\n", tool, missingMarker, tool)
+		} else {
+			fmt.Fprintf(&script, "command -v %s >/dev/null 2>&1 || echo %s%s\n", tool, missingMarker, tool)
+		}
+	}
+	cmd := timeout.Command(shell, "-l", "-i", "-c", script.String())
+	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		return nil, fmt.Errorf("failed to run %s: %w", shell, err)
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if tool, ok := strings.CutPrefix(strings.TrimSpace(line), missingMarker); ok {
+			missing = append(missing, tool)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// ListHooks returns the names of the hooks FindHook can find for a worktree:
+// executables in its .gwi directory, the main worktree's and the global hook
+// directory of the repository
+func ListHooks(worktreePath string, cfg *config.Config, repoInfo *git.RepoInfo) []string {
+	dirs := []string{filepath.Join(worktreePath, ".gwi")}
+	if mainPath, err := git.GetMainWorktreePath(); err == nil && mainPath != "" {
+		dirs = append(dirs, filepath.Join(mainPath, ".gwi"))
+	}
+	if repoInfo != nil {
+		dirs = append(dirs, filepath.Join(cfg.HookDir, repoInfo.Org, repoInfo.Repo))
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && !seen[name] && isExecutable(filepath.Join(dir, name)) {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
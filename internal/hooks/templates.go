@@ -0,0 +1,182 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Template is a set of hook scripts for a stack, scaffolded by gwi hooks add
+type Template struct {
+	Name        string
+	Description string
+	Detect      []string          // Files in the repository that indicate the stack
+	Hooks       map[string]string // Hook name to script body, without the header
+}
+
+// TemplateHooks are the hooks a template provides, in the order they run
+var TemplateHooks = []string{"activate", "up", "down"}
+
+// composeDown stops services started with Docker Compose, shared by the templates
+const composeDown = `# Stop services started for this worktree
+if [ -f compose.yaml ] || [ -f docker-compose.yml ]; then
+  docker compose down
+fi
+`
+
+// Templates are the built-in hook templates. up and down are sourced in the
+// worktree's tmux session, so they must not exit.
+var Templates = []Template{
+	{
+		Name:        "rails",
+		Description: "Ruby on Rails: bundle, JavaScript packages, db:prepare, bin/dev",
+		Detect:      []string{"bin/rails", "config/application.rb"},
+		Hooks: map[string]string{
+			"activate": `set -e
+bundle install
+if [ -f yarn.lock ]; then
+  yarn install --frozen-lockfile
+elif [ -f package-lock.json ]; then
+  npm ci
+fi
+bin/rails db:prepare
+`,
+			"up": `if [ -x bin/dev ]; then
+  bin/dev
+else
+  bin/rails server
+fi
+`,
+			"down": `rm -f tmp/pids/server.pid
+` + composeDown,
+		},
+	},
+	{
+		Name:        "node",
+		Description: "Node.js: npm, yarn or pnpm install and the dev script",
+		Detect:      []string{"package.json"},
+		Hooks: map[string]string{
+			"activate": `set -e
+if [ -f pnpm-lock.yaml ]; then
+  pnpm install --frozen-lockfile
+elif [ -f yarn.lock ]; then
+  yarn install --frozen-lockfile
+elif [ -f package-lock.json ]; then
+  npm ci
+else
+  npm install
+fi
+`,
+			"up": `if [ -f pnpm-lock.yaml ]; then
+  pnpm dev
+elif [ -f yarn.lock ]; then
+  yarn dev
+else
+  npm run dev
+fi
+`,
+			"down": composeDown,
+		},
+	},
+	{
+		Name:        "go",
+		Description: "Go: module download, air when installed or go run",
+		Detect:      []string{"go.mod"},
+		Hooks: map[string]string{
+			"activate": `set -e
+go mod download
+`,
+			"up": `if command -v air >/dev/null 2>&1; then
+  air
+else
+  go run .
+fi
+`,
+			"down": composeDown,
+		},
+	},
+	{
+		Name:        "python",
+		Description: "Python: poetry, pipenv or a .venv, Django or Flask dev server",
+		Detect:      []string{"pyproject.toml", "requirements.txt", "Pipfile", "manage.py"},
+		Hooks: map[string]string{
+			"activate": `set -e
+if [ -f poetry.lock ]; then
+  poetry install
+elif [ -f Pipfile.lock ]; then
+  pipenv sync --dev
+else
+  python3 -m venv .venv
+  if [ -f requirements.txt ]; then
+    .venv/bin/pip install -r requirements.txt
+  elif [ -f pyproject.toml ]; then
+    .venv/bin/pip install -e .
+  fi
+fi
+`,
+			"up": `if [ -f .venv/bin/activate ]; then
+  source .venv/bin/activate
+fi
+if [ -f manage.py ]; then
+  python manage.py runserver
+else
+  python -m flask run
+fi
+`,
+			"down": composeDown,
+		},
+	},
+}
+
+// FindTemplate returns the built-in template with the given name
+func FindTemplate(name string) (*Template, bool) {
+	for i, t := range Templates {
+		if strings.EqualFold(t.Name, name) {
+			return &Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// DetectTemplate returns the first template whose stack is used in dir
+func DetectTemplate(dir string) (*Template, bool) {
+	for i, t := range Templates {
+		for _, file := range t.Detect {
+			if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+				return &Templates[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Script returns the complete script of one of the template's hooks
+func (t Template) Script(hook string) string {
+	return fmt.Sprintf("#!/bin/bash\n# gwi %s hook, scaffolded from the %s template by gwi hooks add.\n# Adjust it to your project.\n%s", hook, t.Name, t.Hooks[hook])
+}
+
+// WriteTemplate writes the template's hooks into dir/.gwi. Existing hooks are
+// left alone unless force is set; they are returned as skipped.
+func WriteTemplate(t Template, dir string, force bool) (written, skipped []string, err error) {
+	hookDir := filepath.Join(dir, ".gwi")
+	if err := os.MkdirAll(hookDir, 0755); err != nil {
+		return nil, nil, err
+	}
+	for _, hook := range TemplateHooks {
+		path := filepath.Join(hookDir, hook)
+		if _, err := os.Stat(path); err == nil && !force {
+			skipped = append(skipped, path)
+			continue
+		}
+		// WriteFile keeps the mode of a file it replaces
+		if err := os.WriteFile(path, []byte(t.Script(hook)), 0755); err != nil {
+			return written, skipped, fmt.Errorf("failed to write %s hook: %w", hook, err)
+		}
+		if err := os.Chmod(path, 0755); err != nil {
+			return written, skipped, err
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
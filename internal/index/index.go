@@ -0,0 +1,162 @@
+package index
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/git"
+	"github.com/enterprisemodules/gwi/internal/issueid"
+	"github.com/enterprisemodules/gwi/internal/xdg"
+)
+
+// Index caches worktree locations so shell navigation can resolve paths
+// from a single file read, without loading config or running git
+type Index struct {
+	Repos []Repo `json:"repos"`
+}
+
+// Repo holds the cached worktree locations for one repository
+type Repo struct {
+	Org       string   `json:"org"`
+	Repo      string   `json:"repo"`
+	MainPath  string   `json:"main_path"`
+	Base      string   `json:"base"`
+	Worktrees []string `json:"worktrees"`
+}
+
+// Path returns the location of the index file
+func Path() string {
+	return filepath.Join(xdg.CacheDir(), "index.json")
+}
+
+func load() *Index {
+	idx := &Index{}
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		return idx
+	}
+	if json.Unmarshal(data, idx) != nil {
+		return &Index{}
+	}
+	return idx
+}
+
+func (idx *Index) save() error {
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Lookup returns the cached repository whose main worktree or worktree base contains dir
+func Lookup(dir string) *Repo {
+	idx := load()
+	for i := range idx.Repos {
+		repo := &idx.Repos[i]
+		if within(dir, repo.MainPath) || within(dir, repo.Base) {
+			return repo
+		}
+	}
+	return nil
+}
+
+// All returns every cached repository
+func All() []Repo {
+	return load().Repos
+}
+
+// within reports whether path is root or below it
+func within(path, root string) bool {
+	if root == "" {
+		return false
+	}
+	return path == root || strings.HasPrefix(path, root+string(os.PathSeparator))
+}
+
+// Refresh rebuilds the cached entry for the current repository. Errors are
+// ignored: the index is only an accelerator and callers fall back to git.
+func Refresh(cfg *config.Config, repoInfo *git.RepoInfo) {
+	mainPath, err := git.GetMainWorktreePath()
+	if err != nil {
+		return
+	}
+	base := cfg.WorktreeBasePath(repoInfo.Org, repoInfo.Repo)
+	worktrees, err := git.ListWorktrees(base)
+	if err != nil {
+		return
+	}
+
+	entry := Repo{
+		Org:       repoInfo.Org,
+		Repo:      repoInfo.Repo,
+		MainPath:  mainPath,
+		Base:      base,
+		Worktrees: worktrees,
+	}
+
+	idx := load()
+	replaced := false
+	for i := range idx.Repos {
+		if idx.Repos[i].Org == entry.Org && idx.Repos[i].Repo == entry.Repo {
+			idx.Repos[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		idx.Repos = append(idx.Repos, entry)
+	}
+	idx.save()
+}
+
+// Resolve finds a single worktree matching an issue number, project key or
+// name pattern. It reports false when there is no unique match whose directory
+// still exists.
+func (r *Repo) Resolve(pattern string) (string, bool) {
+	if id, ok := issueid.Parse(pattern); ok {
+		for _, wt := range r.Worktrees {
+			if got, ok := issueid.FromName(filepath.Base(wt)); ok && got == id && exists(wt) {
+				return wt, true
+			}
+		}
+	}
+
+	var matches []string
+	for _, wt := range r.Worktrees {
+		if strings.Contains(filepath.Base(wt), pattern) {
+			matches = append(matches, wt)
+		}
+	}
+	if len(matches) == 1 && exists(matches[0]) {
+		return matches[0], true
+	}
+	return "", false
+}
+
+// Existing returns the cached worktrees whose directories still exist
+func (r *Repo) Existing() []string {
+	var result []string
+	for _, wt := range r.Worktrees {
+		if exists(wt) {
+			result = append(result, wt)
+		}
+	}
+	return result
+}
+
+func exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,114 @@
+package index
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/xdg"
+)
+
+// IssueTTL is how long cached open issues are used before fetching them again
+const IssueTTL = 10 * time.Minute
+
+// Issue is an issue cached for shell completion and offline title lookups
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// issueCache holds the open issues of each repository, keyed by "org/repo"
+type issueCache map[string]cachedIssues
+
+type cachedIssues struct {
+	Fetched time.Time `json:"fetched"`
+	Issues  []Issue   `json:"issues"`
+	// Titles of all issues seen, closed ones included; kept when the open
+	// issues are fetched again
+	Titles map[int]string `json:"titles,omitempty"`
+}
+
+func issuesPath() string {
+	return filepath.Join(xdg.CacheDir(), "issues.json")
+}
+
+func loadIssues() issueCache {
+	cache := issueCache{}
+	data, err := os.ReadFile(issuesPath())
+	if err != nil {
+		return cache
+	}
+	if json.Unmarshal(data, &cache) != nil {
+		return issueCache{}
+	}
+	return cache
+}
+
+// OpenIssues returns the cached open issues of a repository, reporting false
+// when there are none or they are older than IssueTTL
+func OpenIssues(org, repo string) ([]Issue, bool) {
+	cached, ok := loadIssues()[org+"/"+repo]
+	if !ok || time.Since(cached.Fetched) > IssueTTL {
+		return cached.Issues, false
+	}
+	return cached.Issues, true
+}
+
+// SaveOpenIssues caches the open issues of a repository
+func SaveOpenIssues(org, repo string, issues []Issue) error {
+	cache := loadIssues()
+	key := org + "/" + repo
+	titles := cache[key].Titles
+	if titles == nil {
+		titles = make(map[int]string)
+	}
+	for _, issue := range issues {
+		titles[issue.Number] = issue.Title
+	}
+	cache[key] = cachedIssues{Fetched: time.Now(), Issues: issues, Titles: titles}
+	return saveIssues(cache)
+}
+
+// RememberIssue caches the title of an issue, open or not, for offline lookups
+func RememberIssue(org, repo string, issue Issue) error {
+	cache := loadIssues()
+	key := org + "/" + repo
+	cached := cache[key]
+	if cached.Titles[issue.Number] == issue.Title {
+		return nil
+	}
+	if cached.Titles == nil {
+		cached.Titles = make(map[int]string)
+	}
+	cached.Titles[issue.Number] = issue.Title
+	cache[key] = cached
+	return saveIssues(cache)
+}
+
+// IssueTitles returns the cached titles of a repository's issues by number
+func IssueTitles(org, repo string) map[int]string {
+	return loadIssues()[org+"/"+repo].Titles
+}
+
+// IssueTitle returns the cached title of an issue
+func IssueTitle(org, repo string, number int) (string, bool) {
+	title, ok := IssueTitles(org, repo)[number]
+	return title, ok
+}
+
+func saveIssues(cache issueCache) error {
+	path := issuesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,64 @@
+package issueid
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ID identifies an issue in its tracker: a GitHub issue number such as "42",
+// or a project key such as "JIRA-123" or "LIN-456" for trackers that use them.
+// Keys are kept in upper case.
+type ID string
+
+var (
+	numberRegexp = regexp.MustCompile(`^#?(\d+)$`)
+	keyRegexp    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*-\d+)$`)
+
+	// Worktree and branch names start with the ID, e.g. "42-fix-bug" or
+	// "JIRA-123-fix-bug". Keys in names are upper case, so that branches such
+	// as "release-2-x" aren't taken for one.
+	numberPrefixRegexp = regexp.MustCompile(`^(\d+)[-_]`)
+	keyPrefixRegexp    = regexp.MustCompile(`^([A-Z][A-Z0-9]+-\d+)(?:[-_]|$)`)
+)
+
+// Parse validates an issue ID given on the command line, such as "42", "#42" or "jira-123"
+func Parse(s string) (ID, bool) {
+	if m := numberRegexp.FindStringSubmatch(s); m != nil {
+		return ID(m[1]), true
+	}
+	if keyRegexp.MatchString(s) {
+		return ID(strings.ToUpper(s)), true
+	}
+	return "", false
+}
+
+// FromName extracts the ID a worktree directory or branch name starts with
+func FromName(name string) (ID, bool) {
+	if m := numberPrefixRegexp.FindStringSubmatch(name); m != nil {
+		return ID(m[1]), true
+	}
+	if m := keyPrefixRegexp.FindStringSubmatch(name); m != nil {
+		return ID(strings.ToUpper(m[1])), true
+	}
+	return "", false
+}
+
+// FromNumber returns the ID of a GitHub issue number
+func FromNumber(n int) ID {
+	return ID(strconv.Itoa(n))
+}
+
+// Number returns the GitHub issue number, reporting false for project keys
+func (id ID) Number() (int, bool) {
+	n, err := strconv.Atoi(string(id))
+	return n, err == nil
+}
+
+// String formats the ID for messages: "#42" for issue numbers, the key otherwise
+func (id ID) String() string {
+	if _, ok := id.Number(); ok {
+		return "#" + string(id)
+	}
+	return string(id)
+}
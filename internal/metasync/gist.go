@@ -0,0 +1,82 @@
+package metasync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/github"
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// gistBackend keeps the metadata in a secret gist, accessed through gh
+type gistBackend struct {
+	id string
+}
+
+// gistFiles is the request body for creating or updating a gist
+type gistFiles struct {
+	Description string                       `json:"description,omitempty"`
+	Public      *bool                        `json:"public,omitempty"`
+	Files       map[string]map[string]string `json:"files"`
+}
+
+func (b *gistBackend) Pull() (*store.Store, error) {
+	if b.id == "" {
+		return store.Decode([]byte("{}"))
+	}
+
+	cmd := github.Command("api", "gists/"+b.id, "--jq", fmt.Sprintf(`.files["%s"].content // "{}"`, fileName))
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to read gist %s: %s", b.id, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	s, err := store.Decode(output)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt %s in gist %s: %w", fileName, b.id, err)
+	}
+	return s, nil
+}
+
+func (b *gistBackend) Push(s *store.Store) error {
+	data, err := s.Encode()
+	if err != nil {
+		return err
+	}
+	body := gistFiles{Files: map[string]map[string]string{fileName: {"content": string(data)}}}
+
+	args := []string{"api", "-X", "PATCH", "gists/" + b.id, "--input", "-", "--jq", ".id"}
+	if b.id == "" {
+		public := false
+		body.Description = "gwi metadata"
+		body.Public = &public
+		args = []string{"api", "-X", "POST", "gists", "--input", "-", "--jq", ".id"}
+	}
+
+	input, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	cmd := github.Command(args...)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("failed to update gist: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return err
+	}
+
+	if b.id == "" {
+		b.id = strings.TrimSpace(string(output))
+		config.Success("Created secret gist %s", b.id)
+		config.Info("Use it on every machine by adding to config.yaml:\n\n  sync:\n    backend: gist\n    gist: %s\n", b.id)
+	}
+	return nil
+}
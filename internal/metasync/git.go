@@ -0,0 +1,106 @@
+package metasync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/store"
+	"github.com/enterprisemodules/gwi/internal/timeout"
+)
+
+// syncBranch is the branch of the sync repository holding the metadata
+const syncBranch = "main"
+
+// gitBackend keeps the metadata in a git repository, checked out under
+// ~/.local/state/gwi/sync (next to the metadata store)
+type gitBackend struct {
+	repo string
+	dir  string
+}
+
+func newGitBackend(repo string) *gitBackend {
+	return &gitBackend{repo: repo, dir: filepath.Join(filepath.Dir(store.Path()), "sync")}
+}
+
+func (b *gitBackend) git(args ...string) (string, error) {
+	cmd := timeout.Command("git", args...)
+	cmd.Dir = b.dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// checkout clones the sync repository on first use and resets it to the remote state
+func (b *gitBackend) checkout() error {
+	if _, err := os.Stat(filepath.Join(b.dir, ".git")); err != nil {
+		if err := os.MkdirAll(b.dir, 0755); err != nil {
+			return err
+		}
+		if _, err := b.git("init", "--quiet"); err != nil {
+			return err
+		}
+		if _, err := b.git("remote", "add", "origin", b.repo); err != nil {
+			return err
+		}
+	} else if _, err := b.git("remote", "set-url", "origin", b.repo); err != nil {
+		return err
+	}
+
+	if _, err := b.git("fetch", "--quiet", "origin"); err != nil {
+		return err
+	}
+	// A new, empty repository has no branch yet
+	if _, err := b.git("rev-parse", "--verify", "--quiet", "origin/"+syncBranch); err != nil {
+		return nil
+	}
+	// Drops local commits left behind by a rejected push
+	_, err := b.git("checkout", "--quiet", "--force", "-B", syncBranch, "origin/"+syncBranch)
+	return err
+}
+
+func (b *gitBackend) Pull() (*store.Store, error) {
+	if err := b.checkout(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(b.dir, fileName))
+	if os.IsNotExist(err) {
+		return store.Decode([]byte("{}"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := store.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt %s in %s: %w", fileName, b.repo, err)
+	}
+	return s, nil
+}
+
+func (b *gitBackend) Push(s *store.Store) error {
+	data, err := s.Encode()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(b.dir, fileName), append(data, '\n'), 0600); err != nil {
+		return err
+	}
+
+	if _, err := b.git("add", fileName); err != nil {
+		return err
+	}
+	if _, err := b.git("diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	host := hostname()
+	message := fmt.Sprintf("Sync from %s at %s", host, time.Now().UTC().Format(time.RFC3339))
+	if _, err := b.git("-c", "user.name=gwi", "-c", "user.email=gwi@"+host, "commit", "--quiet", "--no-verify", "-m", message); err != nil {
+		return err
+	}
+	_, err = b.git("push", "--quiet", "origin", "HEAD:"+syncBranch)
+	return err
+}
@@ -0,0 +1,92 @@
+package metasync
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// fileName is the name of the shared metadata file in the backend
+const fileName = "gwi-state.json"
+
+// tombstoneAge is how long deletions are remembered so they reach other machines
+const tombstoneAge = 90 * 24 * time.Hour
+
+// Backend stores the shared copy of the metadata store
+type Backend interface {
+	// Pull returns the shared store, or an empty store if nothing was shared yet
+	Pull() (*store.Store, error)
+	// Push replaces the shared store
+	Push(s *store.Store) error
+}
+
+// New returns the backend configured in sync.backend
+func New(cfg *config.Config) (Backend, error) {
+	switch cfg.Sync.Backend {
+	case "git":
+		if cfg.Sync.Repo == "" {
+			return nil, fmt.Errorf("sync.repo must be set for the git backend")
+		}
+		return newGitBackend(cfg.Sync.Repo), nil
+	case "gist":
+		return &gistBackend{id: cfg.Sync.Gist}, nil
+	case "":
+		return nil, fmt.Errorf("sync is not configured; set sync.backend to 'git' or 'gist'")
+	default:
+		return nil, fmt.Errorf("unknown sync backend %q (use 'git' or 'gist')", cfg.Sync.Backend)
+	}
+}
+
+// Result lists the keys exchanged by a sync
+type Result struct {
+	Pulled []string // Keys updated from the shared store
+	Pushed []string // Keys where this machine had the newer value
+}
+
+// Sync merges the shared store into the local one, the last writer winning
+// per key, and shares the result. A push that loses a race with another
+// machine is retried once against the new shared state.
+func Sync(backend Backend) (*Result, error) {
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		remote, err := backend.Pull()
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Result{}
+		var shared *store.Store
+		err = store.Update(func(s *store.Store) error {
+			s.PruneTombstones(tombstoneAge)
+			result.Pulled, result.Pushed = s.Merge(remote)
+			shared = s.Synced()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(result.Pulled)
+		sort.Strings(result.Pushed)
+
+		if remote.Equal(shared) {
+			return result, nil
+		}
+		if lastErr = backend.Push(shared); lastErr == nil {
+			return result, nil
+		}
+	}
+	return nil, lastErr
+}
+
+// hostname identifies this machine in sync commit messages
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
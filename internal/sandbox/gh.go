@@ -0,0 +1,732 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ghBoolFlags are the gh flags gwi uses that take no value
+var ghBoolFlags = map[string]bool{
+	"web": true, "squash": true, "merge": true, "rebase": true,
+	"delete-branch": true, "paginate": true, "name-only": true, "verify-tag": true,
+	"prerelease": true,
+}
+
+// closingKeyword matches references that close an issue when a PR is merged
+var closingKeyword = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?) #(\d+)`)
+
+// ghCall is a parsed gh command line
+type ghCall struct {
+	args  []string
+	flags map[string]string
+	out   io.Writer
+	dir   string
+	state *state
+}
+
+func (c *ghCall) has(flag string) bool {
+	_, ok := c.flags[flag]
+	return ok
+}
+
+func (c *ghCall) arg(i int) string {
+	if i < len(c.args) {
+		return c.args[i]
+	}
+	return ""
+}
+
+// RunGH runs a gh command line against the fake GitHub of the sandbox in
+// GWI_SANDBOX_DIR and returns its exit code. Only the commands and flags gwi
+// itself uses are supported.
+func RunGH(args []string, stdout, stderr io.Writer) int {
+	if err := runGH(Dir(), args, stdout); err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func runGH(dir string, args []string, out io.Writer) error {
+	call := &ghCall{flags: map[string]string{}, out: out, dir: dir}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			call.args = append(call.args, arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if key, val, ok := strings.Cut(name, "="); ok {
+			call.flags[key] = val
+		} else if ghBoolFlags[name] || i+1 == len(args) {
+			call.flags[name] = ""
+		} else {
+			call.flags[name] = args[i+1]
+			i++
+		}
+	}
+	if len(call.args) == 0 {
+		return errors.New("gh: no command given")
+	}
+
+	s, err := loadState(dir)
+	if err != nil {
+		return fmt.Errorf("gh (sandbox): no sandbox in %s, run: gwi sandbox init", dir)
+	}
+	call.state = s
+
+	command := call.args[0] + " " + call.arg(1)
+	handler, ok := ghCommands[command]
+	if !ok {
+		handler, ok = ghCommands[call.args[0]]
+	}
+	if !ok {
+		return fmt.Errorf("gh %s: not supported in sandbox mode", strings.Join(args, " "))
+	}
+	if err := handler(call); err != nil {
+		return err
+	}
+	return s.save(dir)
+}
+
+var ghCommands map[string]func(c *ghCall) error
+
+func init() {
+	ghCommands = map[string]func(c *ghCall) error{
+		"auth status":    authStatus,
+		"auth refresh":   func(c *ghCall) error { return nil },
+		"issue view":     issueView,
+		"issue list":     issueList,
+		"issue comment":  issueComment,
+		"issue create":   issueCreate,
+		"issue close":    issueClose,
+		"issue edit":     issueEdit,
+		"pr create":      prCreate,
+		"pr list":        prList,
+		"pr view":        prView,
+		"pr edit":        prEdit,
+		"pr diff":        prDiff,
+		"pr merge":       prMerge,
+		"repo view":      repoView,
+		"release create": releaseCreate,
+		"release view":   releaseView,
+		"release upload": releaseUpload,
+		"api":            api,
+		"browse":         func(c *ghCall) error { return nil },
+	}
+}
+
+func authStatus(c *ghCall) error {
+	fmt.Fprintf(c.out, "github.com\n  ✓ Logged in to github.com account %s (sandbox)\n  - Token scopes: 'project', 'repo'\n", c.state.User)
+	return nil
+}
+
+func (c *ghCall) findIssue(arg string) (*issue, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err == nil {
+		if i := c.state.issue(number); i != nil {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("GraphQL: Could not resolve to an issue or pull request with the number of %s. (repository.issue)", arg)
+}
+
+func issueView(c *ghCall) error {
+	i, err := c.findIssue(c.arg(2))
+	if err != nil {
+		return err
+	}
+	return c.print(i)
+}
+
+func issueList(c *ghCall) error {
+	want := strings.ToUpper(c.flags["state"])
+	if want == "" {
+		want = "OPEN"
+	}
+	limit := 30
+	if n, err := strconv.Atoi(c.flags["limit"]); err == nil {
+		limit = n
+	}
+	issues := []*issue{}
+	for _, i := range c.state.Issues {
+		if (want == "ALL" || i.State == want) && len(issues) < limit && c.involves(i) {
+			issues = append(issues, i)
+		}
+	}
+	return c.print(issues)
+}
+
+// involves applies the --assignee and --mention filters of gh issue list
+func (c *ghCall) involves(i *issue) bool {
+	login := func(flag string) string {
+		if c.flags[flag] == "@me" {
+			return c.state.User
+		}
+		return c.flags[flag]
+	}
+	if assignee := login("assignee"); assignee != "" {
+		return slices.Contains(i.Assignees, user{Login: assignee})
+	}
+	if mention := login("mention"); mention != "" {
+		return slices.ContainsFunc(i.Comments, func(comment string) bool {
+			return strings.Contains(comment, "@"+mention)
+		})
+	}
+	return true
+}
+
+func issueCreate(c *ghCall) error {
+	title := c.flags["title"]
+	if title == "" {
+		return errors.New("gh (sandbox): --title is required")
+	}
+	i := &issue{Number: c.state.NextNumber, Title: title, Body: c.flags["body"], State: "OPEN", Labels: []label{}, Assignees: []user{}}
+	if names, ok := c.flags["label"]; ok {
+		for _, name := range strings.Split(names, ",") {
+			i.Labels = append(i.Labels, label{Name: strings.TrimSpace(name)})
+		}
+	}
+	c.state.Issues = append(c.state.Issues, i)
+	c.state.NextNumber++
+	fmt.Fprintf(c.out, "https://github.com/%s/%s/issues/%d\n", Org, Repo, i.Number)
+	return nil
+}
+
+func issueComment(c *ghCall) error {
+	i, err := c.findIssue(c.arg(2))
+	if err != nil {
+		return err
+	}
+	i.Comments = append(i.Comments, c.flags["body"])
+	return nil
+}
+
+func issueClose(c *ghCall) error {
+	i, err := c.findIssue(c.arg(2))
+	if err != nil {
+		return err
+	}
+	reason := "COMPLETED"
+	if strings.EqualFold(c.flags["reason"], "not planned") {
+		reason = "NOT_PLANNED"
+	}
+	i.close(reason)
+	return nil
+}
+
+func issueEdit(c *ghCall) error {
+	i, err := c.findIssue(c.arg(2))
+	if err != nil {
+		return err
+	}
+	var assignees []user
+	for _, a := range i.Assignees {
+		if !slices.Contains(strings.Split(c.flags["remove-assignee"], ","), a.Login) {
+			assignees = append(assignees, a)
+		}
+	}
+	if login := c.flags["add-assignee"]; login != "" {
+		if login == "@me" {
+			login = c.state.User
+		}
+		assignees = append(assignees, user{Login: login})
+	}
+	i.Assignees = assignees
+	if body, ok := c.flags["body"]; ok {
+		i.Body = body
+	}
+	return nil
+}
+
+func (c *ghCall) prURL(number int) string {
+	return fmt.Sprintf("https://github.com/%s/%s/pull/%d", Org, Repo, number)
+}
+
+// findPR resolves a PR by number, URL or head branch, like gh pr view
+func (c *ghCall) findPR(arg string) (*pullRequest, error) {
+	if arg == "" {
+		return nil, errors.New("gh (sandbox): pass a PR number, URL or branch")
+	}
+	if number, err := strconv.Atoi(strings.TrimPrefix(path.Base(arg), "#")); err == nil {
+		if pr := c.state.pullRequest(number); pr != nil {
+			return pr, nil
+		}
+	}
+	for _, pr := range c.state.PullRequests {
+		if pr.HeadRefName == arg {
+			return pr, nil
+		}
+	}
+	return nil, fmt.Errorf("no pull requests found for %s", arg)
+}
+
+func releaseCreate(c *ghCall) error {
+	tag := c.arg(2)
+	if tag == "" {
+		return errors.New("gh (sandbox): pass the tag to release")
+	}
+	for _, r := range c.state.Releases {
+		if r.Tag == tag {
+			return fmt.Errorf("HTTP 422: Validation Failed (release for %s already exists)", tag)
+		}
+	}
+	c.state.Releases = append(c.state.Releases, &release{Tag: tag, Title: c.flags["title"], Notes: c.flags["notes"]})
+	fmt.Fprintf(c.out, "https://github.com/%s/%s/releases/tag/%s\n", Org, Repo, tag)
+	return nil
+}
+
+func (c *ghCall) findRelease(tag string) (*release, error) {
+	for _, r := range c.state.Releases {
+		if r.Tag == tag {
+			return r, nil
+		}
+	}
+	return nil, errors.New("release not found")
+}
+
+func releaseView(c *ghCall) error {
+	r, err := c.findRelease(c.arg(2))
+	if err != nil {
+		return err
+	}
+	assets := []map[string]any{}
+	for _, name := range r.Assets {
+		assets = append(assets, map[string]any{
+			"name": name,
+			"url":  fmt.Sprintf("https://github.com/%s/%s/releases/download/%s/%s", Org, Repo, r.Tag, name),
+		})
+	}
+	return c.print(map[string]any{"tagName": r.Tag, "name": r.Title, "body": r.Notes, "assets": assets})
+}
+
+func releaseUpload(c *ghCall) error {
+	r, err := c.findRelease(c.arg(2))
+	if err != nil {
+		return err
+	}
+	if len(c.args) < 4 {
+		return errors.New("gh (sandbox): pass the files to upload")
+	}
+	for _, file := range c.args[3:] {
+		if _, err := os.Stat(file); err != nil {
+			return err
+		}
+		name := filepath.Base(file)
+		if slices.Contains(r.Assets, name) {
+			return fmt.Errorf("asset under the same name already exists: [%s]", name)
+		}
+		r.Assets = append(r.Assets, name)
+	}
+	return nil
+}
+
+func prCreate(c *ghCall) error {
+	head := c.flags["head"]
+	if head == "" {
+		return errors.New("gh (sandbox): --head is required")
+	}
+	base := c.flags["base"]
+	if base == "" {
+		base = "main"
+	}
+	if _, err := c.headOid(head); err != nil {
+		return fmt.Errorf("pull request create failed: GraphQL: Head sha can't be blank, Head ref must be a branch (push %s first)", head)
+	}
+	for _, pr := range c.state.PullRequests {
+		if pr.HeadRefName == head && pr.State == "OPEN" {
+			return fmt.Errorf("a pull request for branch %q into branch %q already exists:\n%s", head, base, c.prURL(pr.Number))
+		}
+	}
+
+	pr := &pullRequest{
+		Number:           c.state.NextNumber,
+		Title:            c.flags["title"],
+		Body:             c.flags["body"],
+		State:            "OPEN",
+		HeadRefName:      head,
+		BaseRefName:      base,
+		Mergeable:        "MERGEABLE",
+		MergeStateStatus: "CLEAN",
+		ReviewRequests:   []string{},
+		LatestReviews:    []map[string]any{},
+		StatusCheckRollup: []map[string]any{
+			{"__typename": "CheckRun", "name": "build", "status": "COMPLETED", "conclusion": "SUCCESS"},
+		},
+	}
+	if c.state.NewPRChecks != nil {
+		pr.StatusCheckRollup = c.state.NewPRChecks
+	}
+	c.state.NextNumber++
+	c.state.PullRequests = append(c.state.PullRequests, pr)
+	fmt.Fprintln(c.out, c.prURL(pr.Number))
+	return nil
+}
+
+func prList(c *ghCall) error {
+	want := strings.ToUpper(c.flags["state"])
+	if want == "" {
+		want = "OPEN"
+	}
+	prs := []*pullRequest{}
+	for _, pr := range c.state.PullRequests {
+		if want != "ALL" && pr.State != want {
+			continue
+		}
+		if head, ok := c.flags["head"]; ok && pr.HeadRefName != head {
+			continue
+		}
+		if base, ok := c.flags["base"]; ok && pr.BaseRefName != base {
+			continue
+		}
+		prs = append(prs, pr)
+	}
+	// gh lists the newest first
+	for i, j := 0, len(prs)-1; i < j; i, j = i+1, j-1 {
+		prs[i], prs[j] = prs[j], prs[i]
+	}
+	return c.print(prs)
+}
+
+func prView(c *ghCall) error {
+	pr, err := c.findPR(c.arg(2))
+	if err != nil {
+		return err
+	}
+	if c.has("web") {
+		return nil
+	}
+	return c.print(pr)
+}
+
+func prEdit(c *ghCall) error {
+	pr, err := c.findPR(c.arg(2))
+	if err != nil {
+		return err
+	}
+	if title, ok := c.flags["title"]; ok {
+		pr.Title = title
+	}
+	if body, ok := c.flags["body"]; ok {
+		pr.Body = body
+	}
+	if reviewers, ok := c.flags["add-reviewer"]; ok {
+		pr.ReviewRequests = append(pr.ReviewRequests, strings.Split(reviewers, ",")...)
+	}
+	if labels, ok := c.flags["add-label"]; ok {
+		for _, name := range strings.Split(labels, ",") {
+			pr.Labels = append(pr.Labels, label{Name: name, Color: "ededed"})
+		}
+	}
+	fmt.Fprintln(c.out, c.prURL(pr.Number))
+	return nil
+}
+
+func prDiff(c *ghCall) error {
+	pr, err := c.findPR(c.arg(2))
+	if err != nil {
+		return err
+	}
+	args := []string{"--git-dir", originPath(c.dir), "diff"}
+	if c.has("name-only") {
+		args = append(args, "--name-only")
+	}
+	args = append(args, pr.BaseRefName+"..."+pr.HeadRefName)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return fmt.Errorf("could not find pull request diff: %v", err)
+	}
+	_, err = c.out.Write(output)
+	return err
+}
+
+// prMerge merges the PR's branch into its base in the origin repository and
+// closes the issues the PR body references
+func prMerge(c *ghCall) error {
+	pr, err := c.findPR(c.arg(2))
+	if err != nil {
+		return err
+	}
+	if pr.State != "OPEN" {
+		return fmt.Errorf("Pull request #%d (%s) can't be merged because it is %s", pr.Number, pr.Title, strings.ToLower(pr.State))
+	}
+	if pr.Mergeable != "MERGEABLE" {
+		return fmt.Errorf("Pull request #%d is not mergeable: the merge commit cannot be cleanly created", pr.Number)
+	}
+
+	strategy := "merge"
+	for _, s := range []string{"squash", "rebase"} {
+		if c.has(s) {
+			strategy = s
+		}
+	}
+	if err := mergeInOrigin(c.dir, pr, strategy); err != nil {
+		return err
+	}
+	if c.has("delete-branch") {
+		if err := runGit("--git-dir", originPath(c.dir), "branch", "-D", pr.HeadRefName); err != nil {
+			return err
+		}
+	}
+
+	pr.State = "MERGED"
+	if oid, err := c.headOid(pr.BaseRefName); err == nil {
+		pr.MergeCommit = map[string]any{"oid": oid}
+	}
+	for _, match := range closingKeyword.FindAllStringSubmatch(pr.Body, -1) {
+		number, _ := strconv.Atoi(match[1])
+		if i := c.state.issue(number); i != nil {
+			i.close("COMPLETED")
+		}
+	}
+	return nil
+}
+
+// mergeInOrigin merges a PR in a scratch clone of the origin repository and
+// pushes the result
+func mergeInOrigin(dir string, pr *pullRequest, strategy string) error {
+	tmp, err := os.MkdirTemp("", "gwi-sandbox-merge-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	head := "origin/" + pr.HeadRefName
+	steps := [][]string{{"clone", "--quiet", "--branch", pr.BaseRefName, originPath(dir), tmp}}
+	git := func(args ...string) []string {
+		return append([]string{"-C", tmp, "-c", "user.name=Sandbox GitHub", "-c", "user.email=noreply@example.com", "-c", "commit.gpgsign=false"}, args...)
+	}
+	switch strategy {
+	case "squash":
+		steps = append(steps,
+			git("merge", "--quiet", "--squash", head),
+			git("commit", "--quiet", "-m", fmt.Sprintf("%s (#%d)", pr.Title, pr.Number)))
+	case "rebase":
+		steps = append(steps,
+			git("checkout", "--quiet", "-B", "gwi-sandbox-rebase", head),
+			git("rebase", "--quiet", pr.BaseRefName),
+			git("checkout", "--quiet", pr.BaseRefName),
+			git("merge", "--quiet", "--ff-only", "gwi-sandbox-rebase"))
+	default:
+		steps = append(steps,
+			git("merge", "--quiet", "--no-ff", "-m", fmt.Sprintf("Merge pull request #%d from %s/%s", pr.Number, Org, pr.HeadRefName), head))
+	}
+	steps = append(steps, git("push", "--quiet", "origin", pr.BaseRefName))
+
+	for _, args := range steps {
+		if err := runGit(args...); err != nil {
+			return fmt.Errorf("Pull request #%d is not mergeable: %v", pr.Number, err)
+		}
+	}
+	return nil
+}
+
+// headOid returns the commit a branch points to in the origin repository
+func (c *ghCall) headOid(branch string) (string, error) {
+	cmd := exec.Command("git", "--git-dir", originPath(c.dir), "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func repoView(c *ghCall) error {
+	return c.print(map[string]any{
+		"name":             Repo,
+		"owner":            map[string]any{"login": Org},
+		"nameWithOwner":    Org + "/" + Repo,
+		"url":              fmt.Sprintf("https://github.com/%s/%s", Org, Repo),
+		"viewerPermission": c.state.Permission,
+	})
+}
+
+// Issue comment endpoints of the REST API; comment IDs are the issue number
+// times commentIDBase plus the comment's position
+var (
+	issueCommentsEndpoint = regexp.MustCompile(`^repos/[^/]+/[^/]+/issues/(\d+)/comments$`)
+	issueCommentEndpoint  = regexp.MustCompile(`^repos/[^/]+/[^/]+/issues/comments/(\d+)$`)
+)
+
+const commentIDBase = 10000
+
+func api(c *ghCall) error {
+	endpoint := c.arg(1)
+	if m := issueCommentsEndpoint.FindStringSubmatch(endpoint); m != nil {
+		i, err := c.findIssue(m[1])
+		if err != nil {
+			return err
+		}
+		comments := []map[string]any{}
+		for n, body := range i.Comments {
+			comments = append(comments, map[string]any{"id": i.Number*commentIDBase + n + 1, "body": body})
+		}
+		return c.print(comments)
+	}
+	if m := issueCommentEndpoint.FindStringSubmatch(endpoint); m != nil && c.flags["method"] == "PATCH" {
+		id, _ := strconv.Atoi(m[1])
+		i := c.state.issue(id / commentIDBase)
+		n := id%commentIDBase - 1
+		if i == nil || n < 0 || n >= len(i.Comments) {
+			return fmt.Errorf("gh: Not Found (HTTP 404)")
+		}
+		body, ok := strings.CutPrefix(c.flags["f"], "body=")
+		if !ok {
+			return fmt.Errorf("gh api %s: body is required", endpoint)
+		}
+		i.Comments[n] = body
+		return nil
+	}
+
+	switch endpoint {
+	case "user":
+		return c.print(map[string]any{"login": c.state.User})
+	default:
+		return fmt.Errorf("gh api %s: not supported in sandbox mode", endpoint)
+	}
+}
+
+// print writes v as gh would: as JSON limited to the --json fields, or as
+// the results of the --jq expression
+func (c *ghCall) print(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	c.addComputedFields(value)
+
+	if fields, ok := c.flags["json"]; ok {
+		value = selectFields(value, strings.Split(fields, ","))
+	}
+	expr, ok := c.flags["jq"]
+	if !ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, string(data))
+		return nil
+	}
+
+	results, err := evalJQ(value, expr)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		switch r := result.(type) {
+		case nil:
+			fmt.Fprintln(c.out)
+		case string:
+			fmt.Fprintln(c.out, r)
+		default:
+			data, _ := json.Marshal(r)
+			fmt.Fprintln(c.out, string(data))
+		}
+	}
+	return nil
+}
+
+// addComputedFields adds the fields of PRs that aren't stored in the state
+func (c *ghCall) addComputedFields(value any) {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			c.addComputedFields(item)
+		}
+	case map[string]any:
+		if head, ok := v["headRefName"].(string); ok {
+			number, _ := v["number"].(float64)
+			v["url"] = c.prURL(int(number))
+			v["headRefOid"], _ = c.headOid(head)
+			if body, ok := v["body"].(string); ok {
+				refs := []any{}
+				for _, match := range closingKeyword.FindAllStringSubmatch(body, -1) {
+					number, _ := strconv.Atoi(match[1])
+					refs = append(refs, map[string]any{"number": number,
+						"repository": map[string]any{"name": Repo, "owner": map[string]any{"login": Org}}})
+				}
+				v["closingIssuesReferences"] = refs
+			}
+		}
+	}
+}
+
+// selectFields keeps only the named fields of an object or of each object in a list
+func selectFields(value any, fields []string) any {
+	switch v := value.(type) {
+	case []any:
+		for i, item := range v {
+			v[i] = selectFields(item, fields)
+		}
+		return v
+	case map[string]any:
+		selected := map[string]any{}
+		for _, field := range fields {
+			selected[field] = v[field]
+		}
+		return selected
+	}
+	return value
+}
+
+// jqToken matches one step of the supported jq paths: .field, .[N] or .[]
+var jqToken = regexp.MustCompile(`^\.?(?:([A-Za-z_][A-Za-z0-9_]*)|\[(\d*)\])`)
+
+// evalJQ evaluates the small subset of jq that gwi uses: paths like
+// .state, .[0].number and .[]
+func evalJQ(value any, expr string) ([]any, error) {
+	expr = strings.TrimSpace(expr)
+	results := []any{value}
+	if expr == "." {
+		return results, nil
+	}
+	for expr != "" {
+		match := jqToken.FindStringSubmatch(expr)
+		if match == nil {
+			return nil, fmt.Errorf("jq expression %q: not supported in sandbox mode", expr)
+		}
+		expr = expr[len(match[0]):]
+
+		var next []any
+		for _, r := range results {
+			switch {
+			case match[1] != "":
+				obj, _ := r.(map[string]any)
+				next = append(next, obj[match[1]])
+			case match[2] != "":
+				list, _ := r.([]any)
+				i, _ := strconv.Atoi(match[2])
+				if i < len(list) {
+					next = append(next, list[i])
+				} else {
+					next = append(next, nil)
+				}
+			default:
+				switch v := r.(type) {
+				case []any:
+					next = append(next, v...)
+				case map[string]any:
+					for _, item := range v {
+						next = append(next, item)
+					}
+				}
+			}
+		}
+		results = next
+	}
+	return results, nil
+}
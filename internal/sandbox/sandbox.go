@@ -0,0 +1,112 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// A sandbox is a directory holding a bare "origin" repository, a clone of it
+// that poses as github.com/sandbox/app, a HOME for gwi's config and
+// worktrees, and the state of the fake GitHub. With GWI_SANDBOX=1, gh is
+// replaced by the fake (see RunGH), so commands can be tried end to end
+// without touching a real repository or GitHub.
+const (
+	Org  = "sandbox"
+	Repo = "app"
+
+	stateFile = "github.json"
+)
+
+// Enabled reports whether sandbox mode is on
+func Enabled() bool {
+	val := os.Getenv("GWI_SANDBOX")
+	return val == "1" || val == "true"
+}
+
+// Dir returns the sandbox directory: GWI_SANDBOX_DIR, or gwi-sandbox in the
+// temp directory
+func Dir() string {
+	if dir := os.Getenv("GWI_SANDBOX_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "gwi-sandbox")
+}
+
+// RepoPath returns the main worktree of the sandbox repository
+func RepoPath(dir string) string {
+	return filepath.Join(dir, "repo")
+}
+
+// HomePath returns the HOME to use inside the sandbox
+func HomePath(dir string) string {
+	return filepath.Join(dir, "home")
+}
+
+func originPath(dir string) string {
+	return filepath.Join(dir, "origin.git")
+}
+
+// Init creates a fresh sandbox in dir. An existing sandbox there is replaced;
+// any other non-empty directory is left alone.
+func Init(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		if _, err := os.Stat(filepath.Join(dir, stateFile)); err != nil {
+			return fmt.Errorf("%s is not empty and not a sandbox", dir)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(HomePath(dir), 0755); err != nil {
+		return err
+	}
+
+	origin, repo := originPath(dir), RepoPath(dir)
+	remote := fmt.Sprintf("https://github.com/%s/%s.git", Org, Repo)
+	steps := [][]string{
+		{"init", "--quiet", "--bare", "--initial-branch=main", origin},
+		{"init", "--quiet", "--initial-branch=main", repo},
+		{"-C", repo, "config", "user.name", "Sandbox User"},
+		{"-C", repo, "config", "user.email", "sandbox@example.com"},
+		{"-C", repo, "config", "commit.gpgsign", "false"},
+		{"-C", repo, "remote", "add", "origin", remote},
+		// The remote URL looks like GitHub, but git talks to the bare repository
+		{"-C", repo, "config", "url." + origin + ".insteadOf", remote},
+	}
+	for _, args := range steps {
+		if err := runGit(args...); err != nil {
+			return err
+		}
+	}
+
+	readme := "# Sandbox\n\nA throwaway repository for trying gwi.\n"
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte(readme), 0644); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"-C", repo, "add", "README.md"},
+		{"-C", repo, "commit", "--quiet", "-m", "Initial commit"},
+		{"-C", repo, "push", "--quiet", "-u", "origin", "main"},
+	} {
+		if err := runGit(args...); err != nil {
+			return err
+		}
+	}
+
+	return newState().save(dir)
+}
+
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
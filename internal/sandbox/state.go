@@ -0,0 +1,128 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// state is the fake GitHub's data. It is kept in github.json in the sandbox
+// directory, which tests may edit to set up failing checks, reviews and so on.
+type state struct {
+	User         string         `json:"user"`
+	Permission   string         `json:"viewer_permission"`
+	NextNumber   int            `json:"next_number"` // Issues and PRs share numbers, like on GitHub
+	Issues       []*issue       `json:"issues"`
+	PullRequests []*pullRequest `json:"pull_requests"`
+	Releases     []*release     `json:"releases,omitempty"`
+	// Checks new PRs start with instead of one passing build
+	NewPRChecks []map[string]any `json:"new_pr_checks,omitempty"`
+}
+
+type issue struct {
+	Number    int      `json:"number"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body,omitempty"`
+	State     string   `json:"state"` // OPEN or CLOSED
+	Labels    []label  `json:"labels"`
+	Assignees []user   `json:"assignees"`
+	Comments  []string `json:"comments,omitempty"`
+	// When and why a closed issue was closed: COMPLETED or NOT_PLANNED
+	ClosedAt    string `json:"closedAt,omitempty"`
+	StateReason string `json:"stateReason,omitempty"`
+}
+
+// close closes the issue for a reason, COMPLETED or NOT_PLANNED
+func (i *issue) close(reason string) {
+	i.State = "CLOSED"
+	i.ClosedAt = time.Now().UTC().Format(time.RFC3339)
+	i.StateReason = reason
+}
+
+type release struct {
+	Tag    string   `json:"tag"`
+	Title  string   `json:"title"`
+	Notes  string   `json:"notes"`
+	Assets []string `json:"assets,omitempty"` // File names of the uploaded assets
+}
+
+type user struct {
+	Login string `json:"login"`
+}
+
+type label struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type pullRequest struct {
+	Number            int              `json:"number"`
+	Title             string           `json:"title"`
+	Body              string           `json:"body"`
+	State             string           `json:"state"` // OPEN, CLOSED or MERGED
+	HeadRefName       string           `json:"headRefName"`
+	BaseRefName       string           `json:"baseRefName"`
+	Mergeable         string           `json:"mergeable"`
+	MergeStateStatus  string           `json:"mergeStateStatus"`
+	ReviewDecision    string           `json:"reviewDecision"`
+	ReviewRequests    []string         `json:"reviewRequests"`
+	LatestReviews     []map[string]any `json:"latestReviews"`
+	StatusCheckRollup []map[string]any `json:"statusCheckRollup"`
+	Labels            []label          `json:"labels,omitempty"`
+	MergeCommit       map[string]any   `json:"mergeCommit,omitempty"`
+}
+
+// newState returns the state of a fresh sandbox: a few open issues and no PRs
+func newState() *state {
+	return &state{
+		User:       "sandbox-user",
+		Permission: "ADMIN",
+		NextNumber: 5,
+		Issues: []*issue{
+			{Number: 1, Title: "Fix login redirect", State: "OPEN", Labels: []label{{Name: "bug", Color: "d73a4a"}}},
+			{Number: 2, Title: "Add dark mode", Body: "Acceptance criteria:\n\n- [x] Follow the system setting\n- [ ] Toggle in the settings page", State: "OPEN", Labels: []label{{Name: "enhancement", Color: "a2eeef"}}},
+			{Number: 3, Title: "Update documentation", State: "OPEN", Labels: []label{}},
+			{Number: 4, Title: "Speed up startup", State: "OPEN", Labels: []label{}, Assignees: []user{{Login: "teammate"}}},
+		},
+		PullRequests: []*pullRequest{},
+	}
+}
+
+func loadState(dir string) (*state, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *state) save(dir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, stateFile), append(data, '\n'), 0644)
+}
+
+func (s *state) issue(number int) *issue {
+	for _, i := range s.Issues {
+		if i.Number == number {
+			return i
+		}
+	}
+	return nil
+}
+
+func (s *state) pullRequest(number int) *pullRequest {
+	for _, pr := range s.PullRequests {
+		if pr.Number == number {
+			return pr
+		}
+	}
+	return nil
+}
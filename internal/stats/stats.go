@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/store"
+)
+
+// keyPrefix namespaces usage statistics in the metadata store
+const keyPrefix = "stats/"
+
+// Entry holds aggregated timings for a command or a step within a command
+type Entry struct {
+	Count    int       `json:"count"`
+	Failures int       `json:"failures"`
+	TotalMs  int64     `json:"total_ms"`
+	MaxMs    int64     `json:"max_ms"`
+	LastRun  time.Time `json:"last_run"`
+}
+
+// AvgMs returns the average duration in milliseconds
+func (e Entry) AvgMs() int64 {
+	if e.Count == 0 {
+		return 0
+	}
+	return e.TotalMs / int64(e.Count)
+}
+
+// FailureRate returns the fraction of runs that failed
+func (e Entry) FailureRate() float64 {
+	if e.Count == 0 {
+		return 0
+	}
+	return float64(e.Failures) / float64(e.Count)
+}
+
+// Recording state for the current invocation
+var (
+	mu       sync.Mutex
+	active   bool
+	finished bool
+	command  string
+	started  time.Time
+	steps    = make(map[string]time.Duration)
+)
+
+// Start begins recording the given command. Nothing is recorded unless Start is called.
+func Start(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = true
+	command = strings.TrimPrefix(name, "_")
+	started = time.Now()
+}
+
+// Step times a named step of the current command; call the returned function when it ends
+func Step(name string) func() {
+	begin := time.Now()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if active {
+			steps[name] += time.Since(begin)
+		}
+	}
+}
+
+// Finish records the current command and its steps in the metadata store.
+// It is safe to call more than once; only the first call is recorded.
+func Finish(failed bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if !active || finished {
+		return nil
+	}
+	finished = true
+
+	now := time.Now()
+	return store.Update(func(s *store.Store) error {
+		if err := add(s, command, now.Sub(started), failed, now); err != nil {
+			return err
+		}
+		for step, d := range steps {
+			if err := add(s, command+"/"+step, d, failed, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func add(s *store.Store, name string, d time.Duration, failed bool, now time.Time) error {
+	var e Entry
+	s.Get(keyPrefix+name, &e)
+
+	ms := d.Milliseconds()
+	e.Count++
+	e.TotalMs += ms
+	if ms > e.MaxMs {
+		e.MaxMs = ms
+	}
+	if failed {
+		e.Failures++
+	}
+	e.LastRun = now
+
+	return s.Set(keyPrefix+name, e)
+}
+
+// All returns every recorded entry keyed by command (steps as "command/step")
+func All() (map[string]Entry, error) {
+	s, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Entry)
+	for _, key := range s.Keys(keyPrefix) {
+		var e Entry
+		if s.Get(key, &e) {
+			result[strings.TrimPrefix(key, keyPrefix)] = e
+		}
+	}
+	return result, nil
+}
+
+// Reset removes all recorded statistics
+func Reset() error {
+	return store.Update(func(s *store.Store) error {
+		for _, key := range s.Keys(keyPrefix) {
+			s.Delete(key)
+		}
+		return nil
+	})
+}
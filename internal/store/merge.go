@@ -0,0 +1,112 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Synced returns a copy of the store without local-only keys, as shared with
+// other machines
+func (s *Store) Synced() *Store {
+	out := &Store{Data: make(map[string]json.RawMessage), Modified: make(map[string]time.Time)}
+	for key, raw := range s.Data {
+		if !IsLocal(key) {
+			out.Data[key] = raw
+		}
+	}
+	for key, modified := range s.Modified {
+		out.Modified[key] = modified
+	}
+	return out
+}
+
+// PruneTombstones forgets deletions older than maxAge. Machines that haven't
+// synced within maxAge may bring such keys back.
+func (s *Store) PruneTombstones(maxAge time.Duration) {
+	for key, modified := range s.Modified {
+		if _, ok := s.Data[key]; !ok && time.Since(modified) > maxAge {
+			delete(s.Modified, key)
+		}
+	}
+}
+
+// Merge applies the synced keys of remote to s, the last writer winning per
+// key. It returns the keys taken from remote, and the keys where s holds a
+// newer value than remote.
+func (s *Store) Merge(remote *Store) (pulled, pushed []string) {
+	if s.Modified == nil {
+		s.Modified = make(map[string]time.Time)
+	}
+
+	keys := make(map[string]bool)
+	for _, st := range []*Store{s, remote} {
+		for key := range st.Data {
+			keys[key] = true
+		}
+		for key := range st.Modified {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		if IsLocal(key) {
+			continue
+		}
+		local, remoteModified := s.Modified[key], remote.Modified[key]
+		if equalValue(s.Data[key], remote.Data[key]) {
+			if remoteModified.After(local) {
+				s.Modified[key] = remoteModified
+			}
+			continue
+		}
+
+		// On a tie (e.g. keys written before sync existed) a value beats a missing key
+		_, present := s.Data[key]
+		if !remoteModified.After(local) && (present || remoteModified.Before(local)) {
+			pushed = append(pushed, key)
+			continue
+		}
+		if raw, ok := remote.Data[key]; ok {
+			s.Data[key] = raw
+		} else {
+			delete(s.Data, key)
+		}
+		if !remoteModified.IsZero() {
+			s.Modified[key] = remoteModified
+		}
+		pulled = append(pulled, key)
+	}
+	return pulled, pushed
+}
+
+// Equal reports whether both stores hold the same values and modification times
+func (s *Store) Equal(other *Store) bool {
+	if len(s.Data) != len(other.Data) || len(s.Modified) != len(other.Modified) {
+		return false
+	}
+	for key, raw := range s.Data {
+		if !equalValue(raw, other.Data[key]) {
+			return false
+		}
+	}
+	for key, modified := range s.Modified {
+		if !modified.Equal(other.Modified[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue compares two stored values, treating a missing value as distinct
+// from any present one
+func equalValue(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
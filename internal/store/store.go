@@ -0,0 +1,200 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/xdg"
+)
+
+// Store is a small JSON key/value file holding gwi's local metadata
+type Store struct {
+	path string
+	Data map[string]json.RawMessage `json:"data"`
+	// Modified records when each synced key was last set or deleted. Deleted
+	// keys keep their entry as a tombstone so the deletion can be synced.
+	Modified map[string]time.Time `json:"modified,omitempty"`
+}
+
+// LocalPrefixes namespace keys that only make sense on this machine, such as
+// data keyed by local paths; they are never synced
+var LocalPrefixes = []string{"deps/", "stats/", "startup/", "archive/", "undo/"}
+
+// IsLocal reports whether key belongs to this machine only
+func IsLocal(key string) bool {
+	for _, prefix := range LocalPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Path returns the location of the metadata file
+func Path() string {
+	return filepath.Join(xdg.StateDir(), "state.json")
+}
+
+// Load reads the metadata store, returning an empty store if none exists yet
+func Load() (*Store, error) {
+	path := Path()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Store{path: path, Data: make(map[string]json.RawMessage), Modified: make(map[string]time.Time)}, nil
+		}
+		return nil, err
+	}
+
+	s, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt metadata store %s: %w", path, err)
+	}
+	s.path = path
+	return s, nil
+}
+
+// Decode parses a store from its JSON form, e.g. as fetched from a sync backend
+func Decode(data []byte) (*Store, error) {
+	s := &Store{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Data == nil {
+		s.Data = make(map[string]json.RawMessage)
+	}
+	if s.Modified == nil {
+		s.Modified = make(map[string]time.Time)
+	}
+	return s, nil
+}
+
+// Encode returns the JSON form of the store
+func (s *Store) Encode() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Get decodes the value stored under key into v, reporting whether it was found
+func (s *Store) Get(key string, v interface{}) bool {
+	raw, ok := s.Data[key]
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
+
+// Set stores v under key
+func (s *Store) Set(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.Data[key] = raw
+	s.touch(key)
+	return nil
+}
+
+// Delete removes key from the store
+func (s *Store) Delete(key string) {
+	if _, ok := s.Data[key]; !ok {
+		return
+	}
+	delete(s.Data, key)
+	s.touch(key)
+}
+
+// touch records the modification time of a synced key
+func (s *Store) touch(key string) {
+	if IsLocal(key) {
+		return
+	}
+	if s.Modified == nil {
+		s.Modified = make(map[string]time.Time)
+	}
+	s.Modified[key] = time.Now().UTC()
+}
+
+// Keys returns all keys with the given prefix, sorted
+func (s *Store) Keys(prefix string) []string {
+	var keys []string
+	for key := range s.Data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save writes the store to disk atomically
+func (s *Store) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := s.Encode()
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Update loads the store, applies fn and saves the result while holding a lock,
+// so concurrent gwi invocations don't overwrite each other's changes
+func Update(fn func(s *Store) error) error {
+	unlock, err := lock(Path() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return s.Save()
+}
+
+// lock acquires an exclusive lock file, breaking locks left behind by crashed processes
+func lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		// Break stale locks
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > 10*time.Second {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("metadata store is locked by another gwi process")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
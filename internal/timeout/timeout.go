@@ -0,0 +1,117 @@
+package timeout
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+)
+
+// git, gh and az can hang indefinitely on a flaky network. Their calls are
+// bounded by a default timeout, which can be overridden per program ("gh")
+// or subcommand ("git fetch", "gh pr merge"); 0 means no timeout.
+var (
+	mu        sync.Mutex
+	fallback  time.Duration
+	overrides map[string]time.Duration
+)
+
+// waitDelay is how long a timed out call may take to exit once killed, e.g.
+// while an ssh it started still holds its output open
+const waitDelay = 5 * time.Second
+
+// Set sets the default timeout and the overrides by program or subcommand
+func Set(def time.Duration, commands map[string]time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = def
+	overrides = make(map[string]time.Duration, len(commands))
+	for key, d := range commands {
+		overrides[strings.Join(strings.Fields(key), " ")] = d
+	}
+}
+
+// For returns the timeout of running name with args and the key it is
+// configured by: the longest matching subcommand, the program or "default"
+func For(name string, args ...string) (time.Duration, string) {
+	mu.Lock()
+	defer mu.Unlock()
+	words := append([]string{filepath.Base(name)}, subcommand(args)...)
+	for n := len(words); n > 0; n-- {
+		key := strings.Join(words[:n], " ")
+		if d, ok := overrides[key]; ok {
+			return d, key
+		}
+	}
+	return fallback, "default"
+}
+
+// subcommand returns up to two leading words of args naming the subcommand,
+// skipping options such as git's -C <path> and -c <name>=<value>
+func subcommand(args []string) []string {
+	var words []string
+	for i := 0; i < len(args) && len(words) < 2; i++ {
+		arg := args[i]
+		switch {
+		case arg == "-C" || arg == "-c":
+			i++
+		case strings.HasPrefix(arg, "-"):
+			if len(words) > 0 {
+				return words
+			}
+		default:
+			words = append(words, arg)
+		}
+	}
+	return words
+}
+
+// Command returns exec.Command(name, args...), killed with a warning when it
+// runs longer than its timeout. Calls reading from the terminal are made with
+// Interactive instead.
+func Command(name string, args ...string) *exec.Cmd {
+	d, key := For(name, args...)
+	if d <= 0 {
+		return exec.Command(name, args...)
+	}
+
+	call := strings.Join(append([]string{filepath.Base(name)}, subcommand(args)...), " ")
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = waitDelay
+	cmd.Cancel = func() error {
+		config.Warn("%s timed out after %s. Check your network connection, or raise its timeout: timeouts.commands: {%q: 10m} (0 disables it)",
+			call, seconds(d), suggestedKey(key, call))
+		return cmd.Process.Kill()
+	}
+	// The timer is released when it fires or the command is collected
+	runtime.SetFinalizer(cmd, func(*exec.Cmd) { cancel() })
+	return cmd
+}
+
+// Interactive returns exec.Command(name, args...) for a call that reads from
+// the terminal, such as a pager, an editor or a prompt. It waits for the user,
+// so it has no timeout.
+func Interactive(name string, args ...string) *exec.Cmd {
+	return exec.Command(name, args...)
+}
+
+// suggestedKey is the timeouts.commands key to raise: the one that applied,
+// or the call itself when that was the default
+func suggestedKey(key, call string) string {
+	if key == "default" {
+		return call
+	}
+	return key
+}
+
+// seconds formats d as "Xs", e.g. 120s
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
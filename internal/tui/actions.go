@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/enterprisemodules/gwi/internal/config"
+)
+
+// Action is an operation on the selected option, bound to a key
+type Action struct {
+	Key  string // fzf key, e.g. "ctrl-o"; the first action also runs on enter
+	Name string
+}
+
+// SelectAction presents the options next to a preview pane and returns the
+// action and the option it was chosen for. preview is a shell command run for
+// the highlighted option, with {} replaced by its value. Without fzf, the
+// option and then the action are picked from numbered lists, without preview.
+func SelectAction(header string, options []Option, preview string, actions []Action) (action, value string, err error) {
+	if len(options) == 0 {
+		return "", "", fmt.Errorf("no options to select from")
+	}
+	if len(actions) == 0 {
+		return "", "", fmt.Errorf("no actions to choose from")
+	}
+
+	if !hasFzf() {
+		value, err := selectWithNumbered(header, options)
+		if err != nil {
+			return "", "", err
+		}
+		choices := make([]Option, len(actions))
+		for i, a := range actions {
+			choices[i] = Option{Label: a.Name, Value: a.Name}
+		}
+		action, err := selectWithNumbered("Action", choices)
+		return action, value, err
+	}
+
+	// Lines are "value<TAB>display"; fzf shows the display and hands the
+	// value to the preview
+	var input strings.Builder
+	for _, opt := range options {
+		if opt.Disabled {
+			continue
+		}
+		line := opt.Label
+		if opt.Hint != "" {
+			line += " " + config.Yellow("("+opt.Hint+")")
+		}
+		fmt.Fprintf(&input, "%s\t%s%s%s\n", opt.Value, line, FormatLabels(opt.Labels), opt.formatDetail())
+	}
+
+	keys := make([]string, len(actions))
+	help := make([]string, len(actions))
+	for i, a := range actions {
+		keys[i] = a.Key
+		help[i] = a.Key + ": " + a.Name
+	}
+	help[0] = "enter/" + help[0]
+
+	cmd := exec.Command("fzf", "--height=~80%", "--reverse", "--ansi",
+		"--delimiter=\t", "--with-nth=2..",
+		"--header="+header+"\n"+strings.Join(help, " · "),
+		"--expect="+strings.Join(keys, ","),
+		"--preview="+strings.ReplaceAll(preview, "{}", "{1}"),
+		"--preview-window=right:50%:wrap")
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = strings.NewReader(input.String())
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("no selection made")
+	}
+	// The first line is the pressed key (empty for enter)
+	key, selected, _ := strings.Cut(strings.TrimRight(string(output), "\n"), "\n")
+	value, _, _ = strings.Cut(selected, "\t")
+	if value == "" {
+		return "", "", fmt.Errorf("no selection made")
+	}
+	for _, a := range actions {
+		if a.Key == key {
+			return a.Name, value, nil
+		}
+	}
+	return actions[0].Name, value, nil
+}
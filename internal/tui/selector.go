@@ -19,6 +19,71 @@ type Option struct {
 	Disabled   bool   // If true, option is shown but not selectable
 	Hint       string // Optional hint shown after label (e.g., "already exists")
 	InProgress bool   // If true, option is shown in a different color (yellow)
+	Detail     string // Optional secondary info shown dimmed after the labels (e.g., assignees)
+	Labels     []Label
+}
+
+// Label is a colored tag shown after an option's label
+type Label struct {
+	Name  string
+	Color string // Hex color, e.g. "d73a4a"
+}
+
+// FormatLabels renders labels as colored [name] tags, with a leading space
+func FormatLabels(labels []Label) string {
+	var b strings.Builder
+	for _, label := range labels {
+		b.WriteString(" ")
+		b.WriteString(config.HexColor(label.Color, "["+label.Name+"]"))
+	}
+	return b.String()
+}
+
+// formatDetail renders an option's detail dimmed, with a leading space
+func (o Option) formatDetail() string {
+	if o.Detail == "" {
+		return ""
+	}
+	return " " + config.Dim(o.Detail)
+}
+
+// hasLabel reports whether an option carries the named label
+func (o Option) hasLabel(name string) bool {
+	for _, label := range o.Labels {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByLabel returns the options carrying the named label; an empty name keeps all
+func filterByLabel(options []Option, name string) []Option {
+	if name == "" {
+		return options
+	}
+	var result []Option
+	for _, opt := range options {
+		if opt.hasLabel(name) {
+			result = append(result, opt)
+		}
+	}
+	return result
+}
+
+// distinctLabels returns every label used by the options, in first-seen order
+func distinctLabels(options []Option) []Label {
+	var labels []Label
+	seen := make(map[string]bool)
+	for _, opt := range options {
+		for _, label := range opt.Labels {
+			if !seen[strings.ToLower(label.Name)] {
+				seen[strings.ToLower(label.Name)] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
 }
 
 // hasFzf checks if fzf is available
@@ -39,16 +104,54 @@ func Select(header string, options []Option) (string, error) {
 	return selectWithNumbered(header, options)
 }
 
-// ANSI color codes for fzf
-const (
-	fzfDim    = "\033[2m"
-	fzfReset  = "\033[0m"
-	fzfYellow = "\033[33m"
-	fzfCyan   = "\033[36m"
-)
+// labelFilterKey opens the label filter in the fzf selector
+const labelFilterKey = "ctrl-l"
 
-// selectWithFzf uses fzf for selection
+// selectWithFzf uses fzf for selection. When options carry labels, ctrl-l
+// narrows the list to a single label.
 func selectWithFzf(header string, options []Option) (string, error) {
+	labels := distinctLabels(options)
+	filter := ""
+	for {
+		h := header
+		if filter != "" {
+			h += fmt.Sprintf(" [label: %s]", filter)
+		}
+		if len(labels) > 0 {
+			h += " (" + labelFilterKey + ": filter by label)"
+		}
+
+		key, value, err := runFzf(h, filterByLabel(options, filter), len(labels) > 0)
+		if key != labelFilterKey {
+			return value, err
+		}
+		filter = selectLabel(labels)
+	}
+}
+
+// selectLabel lets the user pick one of the labels, or none to clear the filter
+func selectLabel(labels []Label) string {
+	const all = "(all labels)"
+	cmd := exec.Command("fzf", "--height=~50%", "--reverse", "--ansi", "--header=Filter by label")
+	cmd.Stderr = os.Stderr
+	var input strings.Builder
+	input.WriteString(all + "\n")
+	for _, label := range labels {
+		input.WriteString(config.HexColor(label.Color, label.Name) + "\n")
+	}
+	cmd.Stdin = strings.NewReader(input.String())
+
+	output, err := cmd.Output()
+	selected := stripAnsi(strings.TrimSpace(string(output)))
+	if err != nil || selected == all {
+		return ""
+	}
+	return selected
+}
+
+// runFzf shows the options in fzf and returns the selected value. With
+// expectFilter, it returns early with key set when the label filter key is pressed.
+func runFzf(header string, options []Option, expectFilter bool) (key, value string, err error) {
 	// Separate enabled and disabled options
 	var enabledLabels []string
 	labelToValue := make(map[string]string)
@@ -59,20 +162,21 @@ func selectWithFzf(header string, options []Option) (string, error) {
 
 		// Add color for in-progress items
 		if opt.InProgress && !opt.Disabled {
-			label = fmt.Sprintf("%s%s%s", fzfYellow, opt.Label, fzfReset)
+			label = config.Yellow(opt.Label)
 		}
 
 		if opt.Hint != "" {
 			if opt.InProgress && !opt.Disabled {
-				label = fmt.Sprintf("%s %s(%s)%s", label, fzfCyan, opt.Hint, fzfReset)
+				label = fmt.Sprintf("%s %s", label, config.Cyan("("+opt.Hint+")"))
 			} else {
-				label = fmt.Sprintf("%s %s(%s)%s", opt.Label, fzfYellow, opt.Hint, fzfReset)
+				label = fmt.Sprintf("%s %s", opt.Label, config.Yellow("("+opt.Hint+")"))
 			}
 		}
+		label += FormatLabels(opt.Labels) + opt.formatDetail()
 
 		if opt.Disabled {
 			// Dim the entire line for disabled options
-			disabledLabels = append(disabledLabels, fmt.Sprintf("%s%s%s", fzfDim, label, fzfReset))
+			disabledLabels = append(disabledLabels, config.Dim(label))
 		} else {
 			enabledLabels = append(enabledLabels, label)
 			labelToValue[label] = opt.Value
@@ -82,12 +186,16 @@ func selectWithFzf(header string, options []Option) (string, error) {
 	}
 
 	// Build fzf input: enabled options first, then disabled (shown but not selectable)
-	cmd := exec.Command("fzf", "--height=~50%", "--reverse", "--ansi", "--header="+header)
+	args := []string{"--height=~50%", "--reverse", "--ansi", "--header=" + header}
+	if expectFilter {
+		args = append(args, "--expect="+labelFilterKey)
+	}
+	cmd := exec.Command("fzf", args...)
 	cmd.Stderr = os.Stderr
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	go func() {
@@ -104,28 +212,36 @@ func selectWithFzf(header string, options []Option) (string, error) {
 
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("no selection made")
+		return "", "", fmt.Errorf("no selection made")
 	}
 
-	selected := strings.TrimSpace(string(output))
+	selected := strings.TrimRight(string(output), "\n")
+	if expectFilter {
+		// The first line is the pressed key (empty for enter)
+		key, selected, _ = strings.Cut(selected, "\n")
+		if key == labelFilterKey {
+			return key, "", nil
+		}
+	}
+	selected = strings.TrimSpace(selected)
 	if selected == "" {
-		return "", fmt.Errorf("no selection made")
+		return "", "", fmt.Errorf("no selection made")
 	}
 
 	// Strip ANSI codes for lookup
 	cleanSelected := stripAnsi(selected)
 	// Try to match with hint first, then without
 	if val, ok := labelToValue[selected]; ok {
-		return val, nil
+		return "", val, nil
 	}
 	// Try matching the clean version (might have selected a disabled item somehow)
 	for label, value := range labelToValue {
 		if stripAnsi(label) == cleanSelected || strings.HasPrefix(cleanSelected, stripAnsi(label)+" ") {
-			return value, nil
+			return "", value, nil
 		}
 	}
 
-	return "", fmt.Errorf("invalid selection")
+	return "", "", fmt.Errorf("invalid selection")
 }
 
 // stripAnsi removes ANSI escape codes from a string
@@ -152,7 +268,7 @@ func selectWithNumbered(header string, options []Option) (string, error) {
 				hint = fmt.Sprintf(" (%s)", opt.Hint)
 			}
 			// Use dim/gray appearance for disabled items
-			fmt.Fprintf(os.Stderr, "     \033[2m%s%s\033[0m\n", opt.Label, hint)
+			fmt.Fprintf(os.Stderr, "     %s%s%s\n", config.Dim(opt.Label+hint), FormatLabels(opt.Labels), opt.formatDetail())
 		} else {
 			hint := ""
 			if opt.Hint != "" {
@@ -161,9 +277,9 @@ func selectWithNumbered(header string, options []Option) (string, error) {
 
 			// Apply yellow color for in-progress items
 			if opt.InProgress {
-				fmt.Fprintf(os.Stderr, "  %d) %s%s%s\n", displayNum, config.Yellow(opt.Label), config.Yellow(hint), config.Yellow(""))
+				fmt.Fprintf(os.Stderr, "  %d) %s%s%s%s%s\n", displayNum, config.Yellow(opt.Label), config.Yellow(hint), config.Yellow(""), FormatLabels(opt.Labels), opt.formatDetail())
 			} else {
-				fmt.Fprintf(os.Stderr, "  %d) %s%s\n", displayNum, opt.Label, hint)
+				fmt.Fprintf(os.Stderr, "  %d) %s%s%s%s\n", displayNum, opt.Label, hint, FormatLabels(opt.Labels), opt.formatDetail())
 			}
 			enabledIndices[displayNum] = i
 			displayNum++
@@ -174,8 +290,14 @@ func selectWithNumbered(header string, options []Option) (string, error) {
 		return "", fmt.Errorf("no selectable options available")
 	}
 
+	hasLabels := len(distinctLabels(options)) > 0
+
 	fmt.Fprintln(os.Stderr)
-	fmt.Fprintf(os.Stderr, "Select [1-%d]: ", displayNum-1)
+	if hasLabels {
+		fmt.Fprintf(os.Stderr, "Select [1-%d] or /label to filter: ", displayNum-1)
+	} else {
+		fmt.Fprintf(os.Stderr, "Select [1-%d]: ", displayNum-1)
+	}
 
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
@@ -184,6 +306,13 @@ func selectWithNumbered(header string, options []Option) (string, error) {
 	}
 
 	input = strings.TrimSpace(input)
+	if name, ok := strings.CutPrefix(input, "/"); ok && hasLabels {
+		filtered := filterByLabel(options, strings.TrimSpace(name))
+		if len(filtered) == 0 {
+			return "", fmt.Errorf("no options with label %q", name)
+		}
+		return selectWithNumbered(fmt.Sprintf("%s [label: %s]", header, name), filtered)
+	}
 	choice, err := strconv.Atoi(input)
 	if err != nil || choice < 1 || choice >= displayNum {
 		return "", fmt.Errorf("invalid selection")
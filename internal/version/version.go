@@ -0,0 +1,143 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repo is the GitHub repository gwi releases are published to
+const Repo = "enterprisemodules/gwi"
+
+// Build metadata, set at build time with:
+//
+//	-ldflags "-X github.com/enterprisemodules/gwi/internal/version.Version=v1.2.3 ..."
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build metadata, falling back to the VCS information Go
+// embeds for 'go install' and 'go build' when no ldflags were given
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		// Untagged builds get a v0.0.0-<date>-<commit> pseudo-version; keep "dev" for those
+		if info.Version == "dev" && build.Main.Version != "(devel)" && !strings.HasPrefix(build.Main.Version, "v0.0.0-") && build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			}
+		}
+	}
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	return info
+}
+
+// String formats the build metadata on a single line
+func (i Info) String() string {
+	s := "gwi " + i.Version
+	var details []string
+	if i.Commit != "" {
+		details = append(details, i.Commit)
+	}
+	if i.Date != "" {
+		details = append(details, i.Date)
+	}
+	details = append(details, i.GoVersion, i.Platform)
+	return s + " (" + strings.Join(details, ", ") + ")"
+}
+
+// Latest returns the tag of the latest published release
+func Latest() (string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get("https://api.github.com/repos/" + Repo + "/releases/latest")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub returned %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// Newer reports whether latest is a newer release than current. Development
+// builds and versions that aren't x.y.z are never considered outdated.
+func Newer(latest, current string) bool {
+	l, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parse(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parse splits "v1.2.3" (ignoring any pre-release or describe suffix) into its numbers
+func parse(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
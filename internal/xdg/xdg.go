@@ -0,0 +1,93 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// gwi follows the XDG base directory spec on all platforms: configuration
+// (config.yaml, hooks) under $XDG_CONFIG_HOME, the metadata store under
+// $XDG_STATE_HOME, the worktree index under $XDG_CACHE_HOME and archives
+// under $XDG_DATA_HOME, each in a gwi subdirectory. GWI_CONFIG_DIR,
+// GWI_STATE_DIR, GWI_CACHE_DIR and GWI_DATA_DIR override the directories.
+
+// ConfigDir returns gwi's configuration directory (~/.config/gwi)
+func ConfigDir() string {
+	return dir("GWI_CONFIG_DIR", "XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns gwi's state directory (~/.local/state/gwi)
+func StateDir() string {
+	return dir("GWI_STATE_DIR", "XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// CacheDir returns gwi's cache directory (~/.cache/gwi)
+func CacheDir() string {
+	return dir("GWI_CACHE_DIR", "XDG_CACHE_HOME", ".cache")
+}
+
+// DataDir returns gwi's data directory (~/.local/share/gwi)
+func DataDir() string {
+	return dir("GWI_DATA_DIR", "XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+func dir(override, xdgVar, fallback string) string {
+	if val := os.Getenv(override); val != "" {
+		return val
+	}
+	// The spec says relative paths are invalid and must be ignored
+	if val := os.Getenv(xdgVar); filepath.IsAbs(val) {
+		return filepath.Join(val, "gwi")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, fallback, "gwi")
+}
+
+// Move is a file or directory moved from a legacy location
+type Move struct {
+	From string
+	To   string
+	Err  error
+}
+
+// Migrate moves files from where older versions of gwi kept them: the
+// metadata store used to live in ~/.config/gwi, and config.yaml and hooks
+// are moved when $XDG_CONFIG_HOME points elsewhere. Nothing is moved into
+// directories set with a GWI_*_DIR override, or over existing files.
+func Migrate() []Move {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	legacy := filepath.Join(home, ".config", "gwi")
+
+	var moves []Move
+	move := func(override, from, to string) {
+		if os.Getenv(override) != "" || from == to {
+			return
+		}
+		if _, err := os.Lstat(from); err != nil {
+			return
+		}
+		if _, err := os.Lstat(to); err == nil {
+			return
+		}
+		err := os.MkdirAll(filepath.Dir(to), 0755)
+		if err == nil {
+			err = os.Rename(from, to)
+		}
+		moves = append(moves, Move{From: from, To: to, Err: err})
+	}
+
+	move("GWI_STATE_DIR", filepath.Join(legacy, "state.json"), filepath.Join(StateDir(), "state.json"))
+	move("GWI_STATE_DIR", filepath.Join(legacy, "sync"), filepath.Join(StateDir(), "sync"))
+	move("GWI_CONFIG_DIR", filepath.Join(legacy, "config.yaml"), filepath.Join(ConfigDir(), "config.yaml"))
+	move("GWI_CONFIG_DIR", filepath.Join(legacy, "hooks"), filepath.Join(ConfigDir(), "hooks"))
+	if len(moves) > 0 {
+		if _, err := os.Stat(filepath.Join(legacy, "state.json")); err != nil {
+			os.Remove(filepath.Join(legacy, "state.json.lock"))
+		}
+		os.Remove(legacy) // Only succeeds once it is empty
+	}
+	return moves
+}
@@ -1,13 +1,7 @@
 package main
 
-import (
-	"os"
-
-	"github.com/enterprisemodules/gwi/cmd"
-)
+import "github.com/enterprisemodules/gwi/cmd"
 
 func main() {
-	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
-	}
+	cmd.Execute()
 }
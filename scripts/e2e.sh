@@ -0,0 +1,82 @@
+#!/usr/bin/env bash
+# End-to-end tests: run gwi's create → pr → merge → rm flows in a sandbox
+# against the fake GitHub (see 'gwi sandbox init').
+set -euo pipefail
+
+root=$(cd "$(dirname "$0")/.." && pwd)
+work=$(mktemp -d)
+trap 'rm -rf "$work"' EXIT
+
+go build -o "$work/bin/gwi" "$root"
+export PATH="$work/bin:$PATH"
+unset GWI_SANDBOX_DIR
+eval "$(gwi sandbox init "$work/sandbox" 2>/dev/null)"
+
+export GIT_AUTHOR_NAME="Sandbox User" GIT_AUTHOR_EMAIL="sandbox@example.com"
+export GIT_COMMITTER_NAME="$GIT_AUTHOR_NAME" GIT_COMMITTER_EMAIL="$GIT_AUTHOR_EMAIL"
+repo=$PWD
+failures=0
+
+pass() { printf '\033[0;32m✓\033[0m %s\n' "$1"; }
+fail() { printf '\033[0;31m✗\033[0m %s\n' "$1"; failures=$((failures + 1)); }
+check() {
+  local name=$1
+  shift
+  if "$@" >"$work/out" 2>&1; then pass "$name"; else fail "$name"; sed 's/^/    /' "$work/out"; fi
+}
+
+# issue_state prints the state of an issue in the fake GitHub
+issue_state() { gh_fake issue view "$1" --json state --jq .state; }
+gh_fake() { gwi _fake-gh "$@"; }
+
+# commit_in adds a commit to the worktree at $1
+commit_in() {
+  echo "$2" >"$1/$2.txt"
+  git -C "$1" add "$2.txt"
+  git -C "$1" commit --quiet -m "Add $2"
+}
+
+echo "create → merge"
+wt=$(gwi _create 1 </dev/null 2>"$work/create.log") || { cat "$work/create.log"; exit 1; }
+check "worktree created for issue #1" test -d "$wt"
+check "branch named after the issue" test "$(basename "$wt")" = "1-fix-login-redirect"
+commit_in "$wt" feature
+check "merge issue #1" bash -c "cd '$wt' && gwi merge 1"
+check "worktree removed after merge" test ! -d "$wt"
+check "main pushed to origin" git -C "$repo" fetch --quiet origin main
+check "origin/main has the change" git -C "$repo" cat-file -e origin/main:feature.txt
+check "issue #1 closed" test "$(issue_state 1)" = "CLOSED"
+
+echo "create → pr → merge"
+wt=$(gwi _create 2 </dev/null 2>>"$work/create.log")
+commit_in "$wt" dark-mode
+check "pr for issue #2 with passing checks" bash -c "cd '$wt' && gwi pr --wait-checks 2"
+check "PR opened on the fake GitHub" test "$(gh_fake pr list --head 2-add-dark-mode --json state --jq '.[0].state')" = "OPEN"
+check "merge the PR" gh_fake pr merge 2-add-dark-mode --squash --delete-branch
+check "issue #2 closed by the PR" test "$(issue_state 2)" = "CLOSED"
+
+echo "create → merge --require-green"
+wt=$(gwi _create 3 </dev/null 2>>"$work/create.log")
+commit_in "$wt" docs
+git -C "$wt" push --quiet -u origin HEAD 2>/dev/null
+gh_fake pr create --title "Update documentation" --body "Closes #3" --head "$(basename "$wt")" >/dev/null
+check "unapproved PR isn't merged (exit 7)" bash -c "cd '$wt' && gwi merge --require-green --yes 3; test \$? -eq 7"
+sed -i 's/"reviewDecision": ""/"reviewDecision": "APPROVED"/' "$GWI_SANDBOX_DIR/github.json"
+check "approved PR is merged" bash -c "cd '$wt' && gwi merge --require-green --yes 3"
+check "origin/main has the change" bash -c "git -C '$repo' fetch --quiet origin main && git -C '$repo' cat-file -e origin/main:docs.txt"
+
+echo "create → rm"
+wt=$(gwi _create 4 </dev/null 2>>"$work/create.log")
+check "worktree created for issue #4" test -d "$wt"
+check "rm issue #4" bash -c "cd '$repo' && gwi rm --yes 4"
+check "worktree removed" test ! -d "$wt"
+check "issue #4 still open" test "$(issue_state 4)" = "OPEN"
+
+echo "errors"
+check "unknown issue exits with 3" bash -c "cd '$repo' && gwi _create 99 </dev/null; test \$? -eq 3"
+
+if ((failures > 0)); then
+  echo "$failures check(s) failed"
+  exit 1
+fi
+echo "All checks passed"